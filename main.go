@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	apiConfig "sales/src/api/config"
 	salesService "sales/src/sales/application/service"
@@ -12,8 +15,12 @@ import (
 	salesCache "sales/src/sales/infrastructure/cache"
 	salesClient "sales/src/sales/infrastructure/client"
 	salesController "sales/src/sales/infrastructure/controller"
+	salesFiscal "sales/src/sales/infrastructure/fiscal"
 	salesPersistence "sales/src/sales/infrastructure/persistence"
+	salesWorker "sales/src/sales/infrastructure/worker"
+	"sales/src/shared/domain/criteria"
 	sharedConfig "sales/src/shared/infrastructure/config"
+	"sales/src/shared/infrastructure/database"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq" // Driver de PostgreSQL
@@ -57,6 +64,13 @@ func main() {
 	gzipSharedCfg := sharedConfig.DefaultSharedConfig()
 	sharedConfig.SetupSharedMiddleware(router, gzipSharedCfg)
 
+	// Clave de firma de los cursores de paginación (compartida entre réplicas)
+	if cursorSecret := os.Getenv("CURSOR_SECRET"); cursorSecret != "" {
+		criteria.SetCursorSecret([]byte(cursorSecret))
+	} else {
+		log.Println("⚠️  CURSOR_SECRET no configurado: los cursores de paginación no sobreviven a un reinicio")
+	}
+
 	// Obtener configuración de la base de datos de variables de entorno
 	dbHost := getEnv("DB_HOST", "localhost")
 	dbPort := getEnv("DB_PORT", "5432")
@@ -174,17 +188,26 @@ func setupSalesModule(router *gin.RouterGroup, db *sql.DB, paymentMethodDB *sql.
 
 	// HITO v0.4: Crear servicio de secuencias
 	var sequenceService *salesService.SequenceService
+	var txManager *database.TxManager
 	if db != nil {
 		sequenceService = salesService.NewSequenceService(db)
 		log.Println("✅ Sequence service inicializado")
+
+		// HITO POS-NUMBER: Transacciones compartidas entre servicio de secuencias y repositorios
+		txManager = database.NewTxManager(db)
 	}
 
 	// Crear cliente de stock-service
+	// HITO RESILIENCE: stock-service y PIM con timeout por intento, reintentos (solo idempotentes)
+	// y circuit breaker, configurables con STOCK_SERVICE_* / PIM_SERVICE_*; métricas en /metrics
 	stockClient := salesClient.NewStockClient()
 
 	// Crear cliente de pim-service (para snapshots)
 	pimClient := salesClient.NewPIMClient()
 
+	// HITO CUSTOMER: cliente de customer-service (snapshot del comprador, CUSTOMER_SERVICE_*)
+	customerClient := salesClient.NewCustomerClient()
+
 	// HITO: Inicializar cache de payment methods
 	var pmCache *salesCache.PaymentMethodCache
 	if paymentMethodDB != nil {
@@ -201,9 +224,80 @@ func setupSalesModule(router *gin.RouterGroup, db *sql.DB, paymentMethodDB *sql.
 	// Crear repositorios
 	var salesRepo *salesPersistence.OrderPostgresRepository
 	var posSaleRepo port.PosSaleRepository
+	var cashSessionRepo port.CashSessionRepository
+	var invoiceRepo port.InvoiceRepository
+	var creditNoteRepo port.CreditNoteRepository
+	var taxRepo port.TaxRepository
+	var outboxRepo port.OutboxRepository
+	var stockSagaRepo port.StockSagaRepository
+	var idempotencyRepo port.IdempotencyRepository
 	if db != nil {
 		salesRepo = salesPersistence.NewOrderPostgresRepository(db)
 		posSaleRepo = salesPersistence.NewPosSalePostgresRepository(db)
+		cashSessionRepo = salesPersistence.NewCashSessionPostgresRepository(db)
+		invoiceRepo = salesPersistence.NewInvoicePostgresRepository(db)
+		creditNoteRepo = salesPersistence.NewCreditNotePostgresRepository(db)
+		taxRepo = salesPersistence.NewTaxPostgresRepository(db)
+		outboxRepo = salesPersistence.NewOutboxPostgresRepository(db)
+		stockSagaRepo = salesPersistence.NewStockSagaPostgresRepository(db)
+		idempotencyRepo = salesPersistence.NewIdempotencyPostgresRepository(db)
+	}
+
+	// HITO IDEMPOTENCY: Idempotency-Key en endpoints de venta (sin DB se ignora el header)
+	var idempotencyService *salesService.IdempotencyService
+	if idempotencyRepo != nil {
+		idempotencyService = salesService.NewIdempotencyService(idempotencyRepo)
+	}
+
+	// HITO OUTBOX: Eventos en la misma transacción que la venta + relay al eventbus
+	var outboxService *salesService.OutboxService
+	if outboxRepo != nil {
+		outboxService = salesService.NewOutboxService(outboxRepo)
+		if publishUseCase != nil {
+			interval, err := time.ParseDuration(getEnv("OUTBOX_RELAY_INTERVAL", "2s"))
+			if err != nil {
+				log.Printf("⚠️  Invalid OUTBOX_RELAY_INTERVAL, using 2s: %v", err)
+				interval = 2 * time.Second
+			}
+			relayOutboxUC := salesUseCase.NewRelayOutboxUseCase(outboxRepo, publishUseCase)
+			outboxWorker := salesWorker.NewTickerWorker("Outbox relay", relayOutboxUC, interval, 100)
+			go outboxWorker.Run(context.Background())
+		} else {
+			log.Println("⚠️  Outbox relay disabled (eventbus not configured): events stay PENDING")
+		}
+	}
+
+	// HITO STOCK-SAGA: Log de sagas de stock + worker de reintentos de compensación
+	// Sin DB compensa en memoria (best-effort, sin reintentos)
+	stockSagaService := salesService.NewStockSagaService(stockSagaRepo, stockClient)
+
+	// HITO STOCK-BATCH: venta en lote si stock-service la soporta; si no, por ítem con paralelismo acotado
+	stockSaleParallelism, err := strconv.Atoi(getEnv("STOCK_SALE_PARALLELISM", strconv.Itoa(salesService.DefaultStockSaleParallelism)))
+	if err != nil {
+		log.Printf("⚠️  Invalid STOCK_SALE_PARALLELISM, using %d: %v", salesService.DefaultStockSaleParallelism, err)
+	}
+	stockSaleService := salesService.NewStockSaleService(stockClient, stockSagaService, stockSaleParallelism)
+	var listStuckSagasUC *salesUseCase.ListStuckSagasUseCase
+	if stockSagaRepo != nil {
+		listStuckSagasUC = salesUseCase.NewListStuckSagasUseCase(stockSagaRepo)
+
+		interval, err := time.ParseDuration(getEnv("STOCK_COMPENSATION_INTERVAL", "10s"))
+		if err != nil {
+			log.Printf("⚠️  Invalid STOCK_COMPENSATION_INTERVAL, using 10s: %v", err)
+			interval = 10 * time.Second
+		}
+		retryCompensationsUC := salesUseCase.NewRetryStockCompensationsUseCase(stockSagaRepo, stockSagaService, getEnv("STOCK_SERVICE_TOKEN", ""))
+		compensationWorker := salesWorker.NewTickerWorker("Stock compensation", retryCompensationsUC, interval, 20)
+		go compensationWorker.Run(context.Background())
+	}
+
+	// HITO TAX-IVA: Motor de IVA (sin DB usa la configuración por defecto: IVA 21% incluido)
+	taxService := salesService.NewTaxService(taxRepo)
+
+	// HITO CREDIT-NOTE: Notas de crédito al cancelar órdenes / devolver ventas POS facturadas
+	var creditNoteService *salesService.CreditNoteService
+	if sequenceService != nil && invoiceRepo != nil {
+		creditNoteService = salesService.NewCreditNoteService(invoiceRepo, creditNoteRepo, sequenceService, outboxService)
 	}
 
 	// Crear casos de uso
@@ -214,12 +308,24 @@ func setupSalesModule(router *gin.RouterGroup, db *sql.DB, paymentMethodDB *sql.
 	// POS Sale UseCase - ahora con repo, cache y eventbus
 	var posSaleUC *salesUseCase.POSSaleUseCase
 	var listPosSalesUC *salesUseCase.ListPosSalesUseCase
+	var refundPosSaleUC *salesUseCase.RefundPosSaleUseCase
 	if posSaleRepo != nil {
-		posSaleUC = salesUseCase.NewPOSSaleUseCase(stockClient, posSaleRepo, pmCache, publishUseCase)
+		posSaleUC = salesUseCase.NewPOSSaleUseCase(stockSaleService, posSaleRepo, cashSessionRepo, pmCache, outboxService, sequenceService, txManager, taxService, pimClient, stockSagaService, customerClient)
 		listPosSalesUC = salesUseCase.NewListPosSalesUseCase(posSaleRepo)
+		refundPosSaleUC = salesUseCase.NewRefundPosSaleUseCase(stockSagaService, posSaleRepo, cashSessionRepo, pmCache, outboxService, creditNoteService, txManager)
 	} else {
 		// Fallback sin repo (solo para desarrollo sin DB)
-		posSaleUC = salesUseCase.NewPOSSaleUseCase(stockClient, nil, pmCache, publishUseCase)
+		posSaleUC = salesUseCase.NewPOSSaleUseCase(stockSaleService, nil, nil, pmCache, nil, nil, nil, taxService, pimClient, stockSagaService, customerClient)
+	}
+
+	// HITO POS-CASH - Sesiones de caja
+	var openCashSessionUC *salesUseCase.OpenCashSessionUseCase
+	var closeCashSessionUC *salesUseCase.CloseCashSessionUseCase
+	var getCashSessionUC *salesUseCase.GetCashSessionUseCase
+	if cashSessionRepo != nil {
+		openCashSessionUC = salesUseCase.NewOpenCashSessionUseCase(cashSessionRepo, pmCache)
+		closeCashSessionUC = salesUseCase.NewCloseCashSessionUseCase(cashSessionRepo, pmCache, txManager)
+		getCashSessionUC = salesUseCase.NewGetCashSessionUseCase(cashSessionRepo, pmCache)
 	}
 
 	var createOrderUC *salesUseCase.CreateOrderUseCase
@@ -227,24 +333,114 @@ func setupSalesModule(router *gin.RouterGroup, db *sql.DB, paymentMethodDB *sql.
 	var cancelOrderUC *salesUseCase.CancelOrderUseCase
 	var listOrdersUC *salesUseCase.ListOrdersUseCase
 	var getOrderUC *salesUseCase.GetOrderUseCase
+	// HITO ORDER-FULFILLMENT - Preparación, historial de estados y envíos
+	var transitionOrderUC *salesUseCase.TransitionOrderStatusUseCase
+	var orderHistoryUC *salesUseCase.GetOrderStatusHistoryUseCase
+	var createShipmentUC *salesUseCase.CreateShipmentUseCase
+	var listShipmentsUC *salesUseCase.ListOrderShipmentsUseCase
 	if salesRepo != nil {
-		createOrderUC = salesUseCase.NewCreateOrderUseCase(salesRepo, pimClient, stockClient)
-		confirmOrderUC = salesUseCase.NewConfirmOrderUseCase(salesRepo, stockClient, publishUseCase, sequenceService)
-		cancelOrderUC = salesUseCase.NewCancelOrderUseCase(salesRepo, stockClient)
+		// HITO ORDER-RESERVATION: la orden reserva stock al crearse y vence pasado el TTL
+		reservationTTL, err := time.ParseDuration(getEnv("ORDER_RESERVATION_TTL", "30m"))
+		if err != nil {
+			log.Printf("⚠️  Invalid ORDER_RESERVATION_TTL, using %s: %v", salesUseCase.DefaultOrderReservationTTL, err)
+			reservationTTL = salesUseCase.DefaultOrderReservationTTL
+		}
+		createOrderUC = salesUseCase.NewCreateOrderUseCase(salesRepo, pimClient, stockSaleService, taxService, stockSagaService, txManager, reservationTTL, customerClient)
+		confirmOrderUC = salesUseCase.NewConfirmOrderUseCase(salesRepo, stockClient, outboxService, sequenceService, txManager)
+		cancelOrderUC = salesUseCase.NewCancelOrderUseCase(salesRepo, stockClient, creditNoteService, outboxService, stockSagaService, txManager)
 		listOrdersUC = salesUseCase.NewListOrdersUseCase(salesRepo)
 		getOrderUC = salesUseCase.NewGetOrderUseCase(salesRepo)
+		transitionOrderUC = salesUseCase.NewTransitionOrderStatusUseCase(salesRepo)
+		orderHistoryUC = salesUseCase.NewGetOrderStatusHistoryUseCase(salesRepo)
+		createShipmentUC = salesUseCase.NewCreateShipmentUseCase(salesRepo)
+		listShipmentsUC = salesUseCase.NewListOrderShipmentsUseCase(salesRepo)
+
+		interval, err := time.ParseDuration(getEnv("ORDER_RESERVATION_SWEEP_INTERVAL", "30s"))
+		if err != nil {
+			log.Printf("⚠️  Invalid ORDER_RESERVATION_SWEEP_INTERVAL, using 30s: %v", err)
+			interval = 30 * time.Second
+		}
+		expireReservationsUC := salesUseCase.NewExpireOrderReservationsUseCase(salesRepo, stockClient, getEnv("STOCK_SERVICE_TOKEN", ""))
+		reservationWorker := salesWorker.NewTickerWorker("Order reservation", expireReservationsUC, interval, 20)
+		go reservationWorker.Run(context.Background())
 	}
 
 	// Crear controladores
-	salesCtrl := salesController.NewOrderController(validateStockUC, reserveStockUC, releaseStockUC, createOrderUC, confirmOrderUC, cancelOrderUC, listOrdersUC, getOrderUC, posSaleUC, listPosSalesUC)
+	salesCtrl := salesController.NewOrderController(validateStockUC, reserveStockUC, releaseStockUC, createOrderUC, confirmOrderUC, cancelOrderUC, listOrdersUC, getOrderUC, posSaleUC, listPosSalesUC, idempotencyService)
 
 	// HITO C - Report Controller
-	dailyReportUC := salesUseCase.NewDailyReportUseCase(db)
-	reportCtrl := salesController.NewReportController(dailyReportUC)
+	dailyReportUC := salesUseCase.NewDailyReportUseCase(db, pmCache)
+	salesReportUC := salesUseCase.NewSalesReportUseCase(db, pmCache) // HITO SALES-REPORT
+	reportCtrl := salesController.NewReportController(dailyReportUC, salesReportUC)
+
+	// HITO POS-CASH - Cash Session Controller
+	cashSessionCtrl := salesController.NewCashSessionController(openCashSessionUC, closeCashSessionUC, getCashSessionUC)
+
+	// HITO SEQ-TX - Administración de secuencias
+	var listSequencesUC *salesUseCase.ListSequencesUseCase
+	var seedSequenceUC *salesUseCase.SeedSequenceUseCase
+	if sequenceService != nil {
+		listSequencesUC = salesUseCase.NewListSequencesUseCase(sequenceService)
+		seedSequenceUC = salesUseCase.NewSeedSequenceUseCase(sequenceService)
+	}
+	sequenceCtrl := salesController.NewSequenceController(listSequencesUC, seedSequenceUC)
+
+	// HITO FISCAL - Facturación electrónica (CAE) + worker de reintentos
+	var issueInvoiceUC *salesUseCase.IssueInvoiceUseCase
+	var getInvoiceUC *salesUseCase.GetInvoiceUseCase
+	var retryInvoiceUC *salesUseCase.RetryInvoiceUseCase
+	var fiscalIssuerUC *salesUseCase.FiscalIssuerUseCase
+	var listCreditNotesUC *salesUseCase.ListCreditNotesUseCase
+	if db != nil {
+		fiscalIssuerRepo := salesPersistence.NewFiscalIssuerPostgresRepository(db)
+
+		// Por ahora solo existe el fake local; el adapter WSFE se enchufa acá
+		fiscalAuthority := salesFiscal.NewFakeFiscalAuthority()
+		log.Println("⚠️  Fiscal authority: local fake (CAE simulado)")
+
+		authorizeInvoiceUC := salesUseCase.NewAuthorizeInvoiceUseCase(invoiceRepo, fiscalAuthority, outboxService, txManager)
+		issueInvoiceUC = salesUseCase.NewIssueInvoiceUseCase(invoiceRepo, fiscalIssuerRepo, authorizeInvoiceUC)
+		getInvoiceUC = salesUseCase.NewGetInvoiceUseCase(invoiceRepo)
+		retryInvoiceUC = salesUseCase.NewRetryInvoiceUseCase(invoiceRepo, authorizeInvoiceUC)
+		fiscalIssuerUC = salesUseCase.NewFiscalIssuerUseCase(fiscalIssuerRepo)
+		listCreditNotesUC = salesUseCase.NewListCreditNotesUseCase(invoiceRepo, creditNoteRepo)
+
+		interval, err := time.ParseDuration(getEnv("INVOICE_WORKER_INTERVAL", "15s"))
+		if err != nil {
+			log.Printf("⚠️  Invalid INVOICE_WORKER_INTERVAL, using 15s: %v", err)
+			interval = 15 * time.Second
+		}
+		invoiceWorker := salesWorker.NewTickerWorker("Invoice authorization", authorizeInvoiceUC, interval, 20)
+		go invoiceWorker.Run(context.Background())
+	}
+	invoiceCtrl := salesController.NewInvoiceController(issueInvoiceUC, getInvoiceUC, retryInvoiceUC, fiscalIssuerUC, listCreditNotesUC)
+
+	// HITO TAX-IVA - Configuración de IVA del tenant
+	var taxConfigUC *salesUseCase.TaxConfigUseCase
+	if taxRepo != nil {
+		taxConfigUC = salesUseCase.NewTaxConfigUseCase(taxRepo)
+	}
+	taxCtrl := salesController.NewTaxController(taxConfigUC)
+
+	// HITO POS-REFUND - Anulación / devolución de ventas POS
+	posRefundCtrl := salesController.NewPosRefundController(refundPosSaleUC, idempotencyService)
+
+	// HITO ORDER-FULFILLMENT - Preparación y envío de órdenes
+	fulfillmentCtrl := salesController.NewOrderFulfillmentController(transitionOrderUC, orderHistoryUC, createShipmentUC, listShipmentsUC, idempotencyService)
+
+	// HITO STOCK-SAGA - Sagas de stock trabadas
+	stockSagaCtrl := salesController.NewStockSagaController(listStuckSagasUC)
 
 	// Registrar rutas
 	salesCtrl.RegisterRoutes(router)
 	reportCtrl.RegisterRoutes(router)
+	cashSessionCtrl.RegisterRoutes(router)
+	posRefundCtrl.RegisterRoutes(router)
+	sequenceCtrl.RegisterRoutes(router)
+	invoiceCtrl.RegisterRoutes(router)
+	taxCtrl.RegisterRoutes(router)
+	stockSagaCtrl.RegisterRoutes(router)
+	fulfillmentCtrl.RegisterRoutes(router)
 
 	log.Println("Módulo Sales configurado exitosamente")
 }
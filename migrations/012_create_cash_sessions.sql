@@ -0,0 +1,96 @@
+-- ============================================================================
+-- Migración 012: Sesiones de caja (apertura / cierre / arqueo)
+-- Fecha: 2026-10-17
+-- Hito: POS-CASH - Sesiones de caja por punto de venta
+-- Estrategia: Tablas nuevas + extensión de pos_sales (sin romper)
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Crear tabla cash_sessions
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS cash_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    point_of_sale_id UUID NOT NULL,
+
+    -- Estado de la sesión
+    status VARCHAR(20) NOT NULL CHECK (status IN ('OPEN', 'CLOSED')),
+
+    -- Apertura
+    opening_float DECIMAL(15,2) NOT NULL DEFAULT 0.00 CHECK (opening_float >= 0),
+    opened_by VARCHAR(255),
+    opened_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Cierre
+    closed_by VARCHAR(255),
+    closed_at TIMESTAMP,
+    notes TEXT
+);
+
+-- Solo puede existir UNA sesión abierta por punto de venta
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cash_sessions_open_pos
+ON cash_sessions(tenant_id, point_of_sale_id)
+WHERE status = 'OPEN';
+
+CREATE INDEX IF NOT EXISTS idx_cash_sessions_tenant_opened
+ON cash_sessions(tenant_id, opened_at DESC);
+
+COMMENT ON TABLE cash_sessions IS 'Sesiones de caja (turnos) por punto de venta - HITO POS-CASH';
+COMMENT ON COLUMN cash_sessions.point_of_sale_id IS 'Terminal / caja a la que pertenece la sesión';
+COMMENT ON COLUMN cash_sessions.opening_float IS 'Fondo de caja inicial (efectivo al abrir)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla cash_sessions creada'; END $$;
+
+-- ============================================================================
+-- PASO 2: Crear tabla cash_session_counts (arqueo por método de pago)
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS cash_session_counts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    cash_session_id UUID NOT NULL REFERENCES cash_sessions(id) ON DELETE CASCADE,
+    payment_method_id UUID NOT NULL,
+
+    counted_amount DECIMAL(15,2) NOT NULL CHECK (counted_amount >= 0),
+    expected_amount DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+    difference DECIMAL(15,2) NOT NULL DEFAULT 0.00,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE (cash_session_id, payment_method_id)
+);
+
+COMMENT ON TABLE cash_session_counts IS 'Arqueo de caja: monto contado vs esperado por método de pago';
+COMMENT ON COLUMN cash_session_counts.expected_amount IS 'Monto esperado según pos_sales de la sesión (+ fondo inicial si es efectivo)';
+COMMENT ON COLUMN cash_session_counts.difference IS 'counted_amount - expected_amount (negativo = faltante)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla cash_session_counts creada'; END $$;
+
+-- ============================================================================
+-- PASO 3: Vincular pos_sales a la sesión de caja
+-- ============================================================================
+
+ALTER TABLE pos_sales ADD COLUMN IF NOT EXISTS cash_session_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_pos_sales_cash_session ON pos_sales(cash_session_id);
+
+COMMENT ON COLUMN pos_sales.point_of_sale_id IS 'Terminal / caja donde se registró la venta';
+COMMENT ON COLUMN pos_sales.cash_session_id IS 'Sesión de caja abierta al momento de la venta';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla pos_sales extendida con cash_session_id'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 012 completada exitosamente';
+    RAISE NOTICE 'Tablas creadas:';
+    RAISE NOTICE '  - cash_sessions';
+    RAISE NOTICE '  - cash_session_counts';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - pos_sales (cash_session_id)';
+    RAISE NOTICE '========================================';
+END $$;
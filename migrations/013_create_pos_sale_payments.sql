@@ -0,0 +1,78 @@
+-- ============================================================================
+-- Migración 013: Pagos combinados en ventas POS
+-- Fecha: 2026-10-17
+-- Hito: POS-SPLIT - Múltiples métodos de pago por venta
+-- Estrategia: Tabla nueva + backfill (pos_sales.payment_method_id se mantiene
+--             como método principal por compatibilidad)
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Crear tabla pos_sale_payments
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS pos_sale_payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pos_sale_id UUID NOT NULL REFERENCES pos_sales(id) ON DELETE CASCADE,
+    payment_method_id UUID NOT NULL,
+
+    -- Montos
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    change_amount DECIMAL(15,2) NOT NULL DEFAULT 0.00 CHECK (change_amount >= 0),
+
+    -- Datos del pago
+    reference VARCHAR(255),
+    is_cash BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- Auditoría
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_pos_sale_payments_change CHECK (change_amount <= amount),
+    CONSTRAINT chk_pos_sale_payments_change_cash CHECK (is_cash OR change_amount = 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pos_sale_payments_pos_sale ON pos_sale_payments(pos_sale_id);
+CREATE INDEX IF NOT EXISTS idx_pos_sale_payments_method ON pos_sale_payments(payment_method_id);
+
+COMMENT ON TABLE pos_sale_payments IS 'Pagos de una venta POS (HITO POS-SPLIT) - una venta puede tener N pagos';
+COMMENT ON COLUMN pos_sale_payments.amount IS 'Monto entregado con este método de pago';
+COMMENT ON COLUMN pos_sale_payments.change_amount IS 'Vuelto entregado sobre este pago (solo efectivo)';
+COMMENT ON COLUMN pos_sale_payments.reference IS 'Referencia opcional (cupón, nro. de operación, últimos 4 dígitos)';
+COMMENT ON COLUMN pos_sale_payments.is_cash IS 'TRUE si el método es efectivo (afecta arqueo y vuelto)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla pos_sale_payments creada'; END $$;
+
+-- ============================================================================
+-- PASO 2: Backfill - un pago por cada venta existente
+-- Ventas previas sin vuelto no permiten saber si fueron en efectivo:
+-- se asume efectivo solo si hubo vuelto (change > 0)
+-- ============================================================================
+
+INSERT INTO pos_sale_payments (pos_sale_id, payment_method_id, amount, change_amount, is_cash, created_at)
+SELECT
+    ps.id,
+    ps.payment_method_id,
+    GREATEST(ps.amount_paid, ps.final_amount),
+    ps.change,
+    ps.change > 0,
+    ps.created_at
+FROM pos_sales ps
+WHERE GREATEST(ps.amount_paid, ps.final_amount) > 0
+  AND NOT EXISTS (SELECT 1 FROM pos_sale_payments p WHERE p.pos_sale_id = ps.id);
+
+COMMENT ON COLUMN pos_sales.payment_method_id IS 'Método de pago principal (primer pago). Detalle en pos_sale_payments';
+COMMENT ON COLUMN pos_sales.amount_paid IS 'Suma de pos_sale_payments.amount';
+
+DO $$ BEGIN RAISE NOTICE 'Backfill de pos_sale_payments completado'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 013 completada exitosamente';
+    RAISE NOTICE 'Tabla creada: pos_sale_payments';
+    RAISE NOTICE 'Backfill: 1 pago por venta existente';
+    RAISE NOTICE '========================================';
+END $$;
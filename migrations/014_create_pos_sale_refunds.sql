@@ -0,0 +1,115 @@
+-- ============================================================================
+-- Migración 014: Anulación y devolución de ventas POS
+-- Fecha: 2026-10-17
+-- Hito: POS-REFUND - Void total / devolución por línea con compensación de stock
+-- Estrategia: Documento de devolución nuevo vinculado a la venta original
+--             (pos_sales sigue siendo inmutable salvo status / refunded_amount)
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Estado de la venta y monto devuelto acumulado
+-- ============================================================================
+
+ALTER TABLE pos_sales ADD COLUMN IF NOT EXISTS status VARCHAR(30) NOT NULL DEFAULT 'COMPLETED';
+ALTER TABLE pos_sales ADD COLUMN IF NOT EXISTS refunded_amount DECIMAL(15,2) NOT NULL DEFAULT 0.00;
+
+ALTER TABLE pos_sales DROP CONSTRAINT IF EXISTS chk_pos_sales_status;
+ALTER TABLE pos_sales ADD CONSTRAINT chk_pos_sales_status
+    CHECK (status IN ('COMPLETED', 'PARTIALLY_REFUNDED', 'REFUNDED', 'VOIDED'));
+
+ALTER TABLE pos_sales DROP CONSTRAINT IF EXISTS chk_pos_sales_refunded_amount;
+ALTER TABLE pos_sales ADD CONSTRAINT chk_pos_sales_refunded_amount
+    CHECK (refunded_amount >= 0 AND refunded_amount <= final_amount);
+
+COMMENT ON COLUMN pos_sales.status IS 'COMPLETED, PARTIALLY_REFUNDED (devolución parcial), REFUNDED (todas las líneas devueltas) o VOIDED (anulada)';
+COMMENT ON COLUMN pos_sales.refunded_amount IS 'Suma de pos_sale_refunds.refund_amount de la venta';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla pos_sales extendida con status y refunded_amount'; END $$;
+
+-- ============================================================================
+-- PASO 2: Crear tabla pos_sale_refunds (documento de devolución)
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS pos_sale_refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    pos_sale_id UUID NOT NULL REFERENCES pos_sales(id),
+
+    -- Tipo de documento
+    refund_type VARCHAR(10) NOT NULL CHECK (refund_type IN ('VOID', 'REFUND')),
+    reason TEXT NOT NULL,
+
+    -- Dinero devuelto al cliente
+    refund_amount DECIMAL(15,2) NOT NULL CHECK (refund_amount >= 0),
+    payment_method_id UUID NOT NULL,
+    currency VARCHAR(3) NOT NULL DEFAULT 'ARS',
+
+    -- Caja desde la que se devuelve el dinero (NULL si no había sesión abierta)
+    cash_session_id UUID REFERENCES cash_sessions(id),
+
+    -- Auditoría
+    refunded_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pos_sale_refunds_sale ON pos_sale_refunds(pos_sale_id);
+CREATE INDEX IF NOT EXISTS idx_pos_sale_refunds_created ON pos_sale_refunds(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pos_sale_refunds_session ON pos_sale_refunds(cash_session_id);
+
+COMMENT ON TABLE pos_sale_refunds IS 'Anulaciones (VOID) y devoluciones parciales (REFUND) de ventas POS (HITO POS-REFUND)';
+COMMENT ON COLUMN pos_sale_refunds.refund_amount IS 'Monto devuelto (subtotales de las líneas con el descuento de la venta prorrateado)';
+COMMENT ON COLUMN pos_sale_refunds.payment_method_id IS 'Método por el que se devuelve el dinero (afecta arqueo de caja)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla pos_sale_refunds creada'; END $$;
+
+-- ============================================================================
+-- PASO 3: Crear tabla pos_sale_refund_items
+-- UNIQUE(pos_sale_item_id) impide devolver dos veces la misma línea
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS pos_sale_refund_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pos_sale_refund_id UUID NOT NULL REFERENCES pos_sale_refunds(id) ON DELETE CASCADE,
+    pos_sale_item_id UUID NOT NULL REFERENCES pos_sale_items(id),
+
+    -- Snapshot de la línea devuelta
+    sku VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    subtotal DECIMAL(15,2) NOT NULL,
+    refund_amount DECIMAL(15,2) NOT NULL CHECK (refund_amount >= 0),
+
+    -- Compensación de stock
+    stock_entry_id UUID NOT NULL,
+    stock_compensated BOOLEAN NOT NULL DEFAULT FALSE,
+    compensated_at TIMESTAMP,
+
+    -- Auditoría
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_pos_sale_refund_items_item UNIQUE (pos_sale_item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pos_sale_refund_items_refund ON pos_sale_refund_items(pos_sale_refund_id);
+CREATE INDEX IF NOT EXISTS idx_pos_sale_refund_items_pending
+    ON pos_sale_refund_items(created_at) WHERE stock_compensated = FALSE;
+
+COMMENT ON TABLE pos_sale_refund_items IS 'Líneas devueltas de una venta POS (una línea solo puede devolverse una vez)';
+COMMENT ON COLUMN pos_sale_refund_items.stock_compensated IS 'TRUE si stock-service confirmó la compensación del stock_entry_id';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla pos_sale_refund_items creada'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 014 completada exitosamente';
+    RAISE NOTICE 'Tablas creadas:';
+    RAISE NOTICE '  - pos_sale_refunds';
+    RAISE NOTICE '  - pos_sale_refund_items';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - pos_sales (status, refunded_amount)';
+    RAISE NOTICE '========================================';
+END $$;
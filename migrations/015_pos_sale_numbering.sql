@@ -0,0 +1,71 @@
+-- ============================================================================
+-- Migración 015: Numeración de tickets POS por punto de venta
+-- Fecha: 2026-10-17
+-- Hito: POS-NUMBER - Número correlativo por tenant + punto de venta (0001-00001234)
+-- Estrategia: document_sequences con scope (punto de venta) + tabla points_of_sale
+--             para el número de punto de venta (prefijo del ticket)
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Secuencias con scope (NULL = secuencia única del tenant)
+-- ============================================================================
+
+ALTER TABLE document_sequences ADD COLUMN IF NOT EXISTS scope_id UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_document_sequences_tenant_type_scope
+    ON document_sequences(tenant_id, document_type, COALESCE(scope_id, '00000000-0000-0000-0000-000000000000'::uuid));
+
+COMMENT ON COLUMN document_sequences.scope_id IS 'Ámbito de la secuencia (ej: point_of_sale_id para POS_SALE). NULL = única por tenant';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla document_sequences extendida con scope_id'; END $$;
+
+-- ============================================================================
+-- PASO 2: Crear tabla points_of_sale
+-- Se registra automáticamente en la primera venta del terminal
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS points_of_sale (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    number INT NOT NULL CHECK (number > 0 AND number <= 9999),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_points_of_sale_tenant_number UNIQUE (tenant_id, number)
+);
+
+COMMENT ON TABLE points_of_sale IS 'Puntos de venta (terminales) con su número de 4 dígitos (HITO POS-NUMBER)';
+COMMENT ON COLUMN points_of_sale.id IS 'Mismo UUID que pos_sales.point_of_sale_id / cash_sessions.point_of_sale_id';
+COMMENT ON COLUMN points_of_sale.number IS 'Prefijo del ticket (0001-...)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla points_of_sale creada'; END $$;
+
+-- ============================================================================
+-- PASO 3: Número de ticket en pos_sales
+-- pos_number (migración 010) pasa a ser el correlativo dentro del punto de venta
+-- ============================================================================
+
+ALTER TABLE pos_sales ADD COLUMN IF NOT EXISTS point_of_sale_number INT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pos_sales_pos_number
+    ON pos_sales(tenant_id, point_of_sale_id, pos_number)
+    WHERE pos_number IS NOT NULL;
+
+COMMENT ON COLUMN pos_sales.pos_number IS 'Correlativo sin huecos por tenant + punto de venta (asignado en la tx del insert)';
+COMMENT ON COLUMN pos_sales.point_of_sale_number IS 'Número del punto de venta al momento de la venta (prefijo del ticket)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla pos_sales extendida con point_of_sale_number'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 015 completada exitosamente';
+    RAISE NOTICE 'Tabla creada: points_of_sale';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - document_sequences (scope_id)';
+    RAISE NOTICE '  - pos_sales (point_of_sale_number)';
+    RAISE NOTICE '========================================';
+END $$;
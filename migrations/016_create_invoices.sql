@@ -0,0 +1,129 @@
+-- ============================================================================
+-- Migración 016: Facturación electrónica (comprobantes A/B/C con CAE)
+-- Fecha: 2026-10-17
+-- Hito: FISCAL - Autorización de comprobantes ante el fisco
+-- Estrategia: tabla invoices vinculada a sales_orders / pos_sales por invoice_id
+--             fiscal_status de la venta replica el estado del comprobante
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Crear tabla fiscal_issuers (datos fiscales del tenant)
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS fiscal_issuers (
+    tenant_id UUID PRIMARY KEY,
+    cuit VARCHAR(11) NOT NULL,
+    legal_name VARCHAR(255) NOT NULL DEFAULT '',
+    tax_condition VARCHAR(30) NOT NULL CHECK (tax_condition IN ('RESPONSABLE_INSCRIPTO', 'MONOTRIBUTO', 'EXENTO')),
+    point_of_sale_number INT NOT NULL CHECK (point_of_sale_number > 0 AND point_of_sale_number <= 99999),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE fiscal_issuers IS 'Emisor de comprobantes por tenant (HITO FISCAL)';
+COMMENT ON COLUMN fiscal_issuers.point_of_sale_number IS 'Punto de venta habilitado ante el fisco para factura electrónica';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla fiscal_issuers creada'; END $$;
+
+-- ============================================================================
+-- PASO 2: Crear tabla invoices
+-- invoice_number lo fija el fisco al autorizar (NULL mientras está pendiente)
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    source_type VARCHAR(20) NOT NULL CHECK (source_type IN ('SALES_ORDER', 'POS_SALE')),
+    source_id UUID NOT NULL,
+
+    -- Comprobante
+    invoice_type CHAR(1) NOT NULL CHECK (invoice_type IN ('A', 'B', 'C')),
+    point_of_sale_number INT NOT NULL,
+    invoice_number INT,
+
+    -- Emisor / receptor (copia al momento de emitir)
+    issuer_cuit VARCHAR(11) NOT NULL,
+    issuer_tax_condition VARCHAR(30) NOT NULL,
+    customer_tax_condition VARCHAR(30) NOT NULL,
+    customer_tax_id VARCHAR(11),
+    customer_name VARCHAR(255),
+
+    -- Montos
+    currency VARCHAR(3) NOT NULL,
+    net_amount DECIMAL(15,2) NOT NULL,
+    tax_amount DECIMAL(15,2) NOT NULL,
+    total_amount DECIMAL(15,2) NOT NULL CHECK (total_amount > 0),
+
+    -- Autorización
+    fiscal_status VARCHAR(30) NOT NULL DEFAULT 'PENDING'
+        CHECK (fiscal_status IN ('PENDING', 'PROCESSING', 'APPROVED', 'REJECTED')),
+    cae VARCHAR(14),
+    cae_expires_at TIMESTAMP,
+    rejection_reason TEXT,
+    authorized_at TIMESTAMP,
+
+    -- Reintentos
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+
+    -- Auditoría
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_invoices_source UNIQUE (source_type, source_id)
+);
+
+-- Un número autorizado no se repite por emisor + punto de venta + letra
+CREATE UNIQUE INDEX IF NOT EXISTS uq_invoices_number
+    ON invoices(issuer_cuit, point_of_sale_number, invoice_type, invoice_number)
+    WHERE invoice_number IS NOT NULL;
+
+-- Worker de autorización: pendientes con reintento vencido
+CREATE INDEX IF NOT EXISTS idx_invoices_due
+    ON invoices(next_attempt_at)
+    WHERE fiscal_status = 'PENDING';
+
+CREATE INDEX IF NOT EXISTS idx_invoices_tenant ON invoices(tenant_id, created_at DESC);
+
+COMMENT ON TABLE invoices IS 'Comprobantes electrónicos A/B/C (HITO FISCAL)';
+COMMENT ON COLUMN invoices.invoice_number IS 'Número asignado por el fisco (último autorizado + 1)';
+COMMENT ON COLUMN invoices.cae IS 'Código de Autorización Electrónico';
+COMMENT ON COLUMN invoices.next_attempt_at IS 'Próximo intento ante fallos transitorios (backoff exponencial)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla invoices creada'; END $$;
+
+-- ============================================================================
+-- PASO 3: fiscal_status de las ventas
+-- ============================================================================
+
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS chk_sales_orders_fiscal_status;
+ALTER TABLE sales_orders ADD CONSTRAINT chk_sales_orders_fiscal_status
+    CHECK (fiscal_status IN ('PENDING', 'PROCESSING', 'APPROVED', 'REJECTED'));
+
+ALTER TABLE pos_sales DROP CONSTRAINT IF EXISTS chk_pos_sales_fiscal_status;
+ALTER TABLE pos_sales ADD CONSTRAINT chk_pos_sales_fiscal_status
+    CHECK (fiscal_status IN ('PENDING', 'PROCESSING', 'APPROVED', 'REJECTED'));
+
+COMMENT ON COLUMN sales_orders.fiscal_status IS 'Estado fiscal (PENDING, PROCESSING, APPROVED, REJECTED) - replica invoices.fiscal_status';
+COMMENT ON COLUMN sales_orders.invoice_id IS 'Comprobante emitido para la orden (invoices.id)';
+COMMENT ON COLUMN pos_sales.fiscal_status IS 'Estado fiscal (PENDING, PROCESSING, APPROVED, REJECTED) - replica invoices.fiscal_status';
+COMMENT ON COLUMN pos_sales.invoice_id IS 'Comprobante emitido para la venta (invoices.id)';
+
+DO $$ BEGIN RAISE NOTICE 'fiscal_status de sales_orders / pos_sales restringido'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 016 completada exitosamente';
+    RAISE NOTICE 'Tablas creadas:';
+    RAISE NOTICE '  - fiscal_issuers';
+    RAISE NOTICE '  - invoices';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - sales_orders (CHECK fiscal_status)';
+    RAISE NOTICE '  - pos_sales (CHECK fiscal_status)';
+    RAISE NOTICE '========================================';
+END $$;
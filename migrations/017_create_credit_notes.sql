@@ -0,0 +1,92 @@
+-- ============================================================================
+-- Migración 017: Notas de crédito
+-- Fecha: 2026-10-17
+-- Hito: CREDIT-NOTE - Reversión documentada de ventas facturadas
+-- Estrategia: credit_notes vinculada a invoices, numerada con la secuencia
+--             CREDIT_NOTE del tenant en la misma tx de la cancelación / devolución
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Crear tabla credit_notes
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS credit_notes (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    invoice_id UUID NOT NULL REFERENCES invoices(id),
+    source_type VARCHAR(20) NOT NULL CHECK (source_type IN ('SALES_ORDER', 'POS_SALE')),
+    source_id UUID NOT NULL,
+    reversal_id UUID,
+
+    -- Comprobante
+    credit_note_type CHAR(1) NOT NULL CHECK (credit_note_type IN ('A', 'B', 'C')),
+    point_of_sale_number INT NOT NULL,
+    credit_note_number INT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+
+    -- Montos
+    currency VARCHAR(3) NOT NULL,
+    net_amount DECIMAL(15,2) NOT NULL,
+    tax_amount DECIMAL(15,2) NOT NULL,
+    total_amount DECIMAL(15,2) NOT NULL CHECK (total_amount > 0),
+
+    -- Auditoría
+    issued_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_credit_notes_number UNIQUE (tenant_id, credit_note_number)
+);
+
+-- Una nota de crédito por devolución POS
+CREATE UNIQUE INDEX IF NOT EXISTS uq_credit_notes_reversal
+    ON credit_notes(reversal_id)
+    WHERE reversal_id IS NOT NULL;
+
+-- Una sola nota de crédito por cancelación de orden
+CREATE UNIQUE INDEX IF NOT EXISTS uq_credit_notes_order_cancel
+    ON credit_notes(source_type, source_id)
+    WHERE reversal_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_credit_notes_invoice ON credit_notes(invoice_id);
+CREATE INDEX IF NOT EXISTS idx_credit_notes_tenant ON credit_notes(tenant_id, created_at DESC);
+
+COMMENT ON TABLE credit_notes IS 'Notas de crédito contra facturas (HITO CREDIT-NOTE)';
+COMMENT ON COLUMN credit_notes.reversal_id IS 'pos_sale_refunds.id que origina la nota (NULL = cancelación de orden)';
+COMMENT ON COLUMN credit_notes.credit_note_number IS 'Secuencia CREDIT_NOTE del tenant (sin huecos)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla credit_notes creada'; END $$;
+
+-- ============================================================================
+-- PASO 2: Crear tabla credit_note_items
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS credit_note_items (
+    id UUID PRIMARY KEY,
+    credit_note_id UUID NOT NULL REFERENCES credit_notes(id) ON DELETE CASCADE,
+    source_item_id UUID,
+    sku VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_credit_note_items_note ON credit_note_items(credit_note_id);
+
+COMMENT ON TABLE credit_note_items IS 'Líneas revertidas (cantidades y montos devueltos)';
+COMMENT ON COLUMN credit_note_items.source_item_id IS 'pos_sale_items.id / sales_order_items.id de la línea original';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla credit_note_items creada'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 017 completada exitosamente';
+    RAISE NOTICE 'Tablas creadas:';
+    RAISE NOTICE '  - credit_notes';
+    RAISE NOTICE '  - credit_note_items';
+    RAISE NOTICE '========================================';
+END $$;
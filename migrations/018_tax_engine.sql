@@ -0,0 +1,132 @@
+-- ============================================================================
+-- Migración 018: Motor de IVA
+-- Fecha: 2026-10-17
+-- Hito: TAX-IVA - Alícuotas por tenant (21 / 10,5 / 27 / exento) por SKU o categoría
+-- Estrategia: tax_settings + tax_rules por tenant; desglose neto / IVA / bruto
+--             persistido por línea y por documento. Las ventas previas se
+--             completan como IVA 21% incluido (criterio usado hasta ahora)
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Configuración de IVA del tenant
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS tax_settings (
+    tenant_id UUID PRIMARY KEY,
+    price_mode VARCHAR(10) NOT NULL DEFAULT 'INCLUDED' CHECK (price_mode IN ('INCLUDED', 'EXCLUDED')),
+    default_rate_code VARCHAR(10) NOT NULL DEFAULT 'IVA_21'
+        CHECK (default_rate_code IN ('IVA_21', 'IVA_10_5', 'IVA_27', 'EXENTO')),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE tax_settings IS 'Configuración de IVA por tenant (HITO TAX-IVA). Sin fila = INCLUDED + IVA_21';
+COMMENT ON COLUMN tax_settings.price_mode IS 'INCLUDED: precios finales (IVA se discrimina) / EXCLUDED: precios netos (IVA se suma)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla tax_settings creada'; END $$;
+
+-- ============================================================================
+-- PASO 2: Reglas de alícuota por SKU / categoría
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS tax_rules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('SKU', 'CATEGORY')),
+    scope_value VARCHAR(255) NOT NULL,
+    rate_code VARCHAR(10) NOT NULL CHECK (rate_code IN ('IVA_21', 'IVA_10_5', 'IVA_27', 'EXENTO')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_tax_rules_scope UNIQUE (tenant_id, scope, scope_value)
+);
+
+COMMENT ON TABLE tax_rules IS 'Alícuota de IVA por SKU o categoría PIM (prioridad: SKU > categoría > default)';
+COMMENT ON COLUMN tax_rules.scope_value IS 'SKU o category_id del snapshot de producto de PIM';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla tax_rules creada'; END $$;
+
+-- ============================================================================
+-- PASO 3: Desglose por línea (pos_sale_items / sales_order_items)
+-- ============================================================================
+
+ALTER TABLE pos_sale_items ADD COLUMN IF NOT EXISTS tax_rate_code VARCHAR(10) NOT NULL DEFAULT 'IVA_21';
+ALTER TABLE pos_sale_items ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5,2) NOT NULL DEFAULT 21;
+ALTER TABLE pos_sale_items ADD COLUMN IF NOT EXISTS net_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE pos_sale_items ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE pos_sale_items ADD COLUMN IF NOT EXISTS gross_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+-- Líneas previas: bruto = subtotal con el descuento de la venta prorrateado, IVA 21% incluido
+UPDATE pos_sale_items i
+SET gross_amount = CASE WHEN ps.total_amount > 0
+        THEN ROUND(i.subtotal * ps.final_amount / ps.total_amount, 2)
+        ELSE 0 END
+FROM pos_sales ps
+WHERE ps.id = i.pos_sale_id;
+
+UPDATE pos_sale_items
+SET net_amount = ROUND(gross_amount / 1.21, 2),
+    tax_amount = gross_amount - ROUND(gross_amount / 1.21, 2);
+
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS tax_rate_code VARCHAR(10) NOT NULL DEFAULT 'IVA_21';
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5,2) NOT NULL DEFAULT 21;
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS net_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS gross_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+UPDATE sales_order_items
+SET gross_amount = subtotal,
+    net_amount = ROUND(subtotal / 1.21, 2),
+    tax_amount = subtotal - ROUND(subtotal / 1.21, 2);
+
+COMMENT ON COLUMN pos_sale_items.gross_amount IS 'Importe cobrado por la línea (descuento prorrateado + IVA)';
+COMMENT ON COLUMN pos_sale_items.tax_rate IS 'Porcentaje de la alícuota al momento de la venta';
+COMMENT ON COLUMN sales_order_items.gross_amount IS 'Importe de la línea con IVA';
+
+DO $$ BEGIN RAISE NOTICE 'Desglose por línea agregado a pos_sale_items y sales_order_items'; END $$;
+
+-- ============================================================================
+-- PASO 4: Totales impositivos por documento (pos_sales / sales_orders)
+-- ============================================================================
+
+ALTER TABLE pos_sales ADD COLUMN IF NOT EXISTS price_mode VARCHAR(10) NOT NULL DEFAULT 'INCLUDED';
+ALTER TABLE pos_sales ADD COLUMN IF NOT EXISTS net_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE pos_sales ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+UPDATE pos_sales
+SET net_amount = ROUND(final_amount / 1.21, 2),
+    tax_amount = final_amount - ROUND(final_amount / 1.21, 2);
+
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS price_mode VARCHAR(10) NOT NULL DEFAULT 'INCLUDED';
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS net_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+UPDATE sales_orders
+SET net_amount = ROUND(total_amount / 1.21, 2),
+    tax_amount = total_amount - ROUND(total_amount / 1.21, 2);
+
+ALTER TABLE pos_sales ADD CONSTRAINT chk_pos_sales_price_mode CHECK (price_mode IN ('INCLUDED', 'EXCLUDED'));
+ALTER TABLE sales_orders ADD CONSTRAINT chk_sales_orders_price_mode CHECK (price_mode IN ('INCLUDED', 'EXCLUDED'));
+
+COMMENT ON COLUMN pos_sales.net_amount IS 'Neto gravado (final_amount = net_amount + tax_amount)';
+COMMENT ON COLUMN pos_sales.tax_amount IS 'IVA total de la venta (suma de líneas)';
+COMMENT ON COLUMN sales_orders.net_amount IS 'Neto gravado (total_amount = net_amount + tax_amount)';
+COMMENT ON COLUMN sales_orders.tax_amount IS 'IVA total de la orden (suma de líneas)';
+
+DO $$ BEGIN RAISE NOTICE 'Totales impositivos agregados a pos_sales y sales_orders'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 018 completada exitosamente';
+    RAISE NOTICE 'Tablas creadas:';
+    RAISE NOTICE '  - tax_settings';
+    RAISE NOTICE '  - tax_rules';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - pos_sale_items / sales_order_items (desglose por línea)';
+    RAISE NOTICE '  - pos_sales / sales_orders (neto / IVA del documento)';
+    RAISE NOTICE '========================================';
+END $$;
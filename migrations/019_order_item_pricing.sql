@@ -0,0 +1,81 @@
+-- ============================================================================
+-- Migración 019: Precios reales en órdenes de venta
+-- Fecha: 2026-10-17
+-- Hito: ORDER-PRICE - unit_price / subtotal / total_amount desde el snapshot de PIM
+-- Estrategia: price_overridden marca las líneas con precio manual. Las líneas
+--             previas (subtotal = 0) toman el precio del variant_snapshot y se
+--             recalculan con IVA incluido (criterio de la migración 018)
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Marca de override por línea
+-- ============================================================================
+
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS price_overridden BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN sales_order_items.unit_price IS 'Precio unitario: precio de la variante en PIM u override de la línea';
+COMMENT ON COLUMN sales_order_items.price_overridden IS 'TRUE si unit_price fue informado en el request (no es el del snapshot)';
+COMMENT ON COLUMN sales_order_items.subtotal IS 'unit_price × quantity (importe gravado según price_mode de la orden)';
+
+DO $$ BEGIN RAISE NOTICE 'Columna price_overridden agregada a sales_order_items'; END $$;
+
+-- ============================================================================
+-- PASO 2: Precio de líneas previas desde el variant_snapshot
+-- ============================================================================
+
+UPDATE sales_order_items
+SET unit_price = ROUND((variant_snapshot->>'price')::numeric, 2),
+    subtotal = ROUND((variant_snapshot->>'price')::numeric * quantity, 2)
+WHERE subtotal = 0
+  AND variant_snapshot IS NOT NULL
+  AND jsonb_typeof(variant_snapshot->'price') = 'number';
+
+UPDATE sales_order_items i
+SET gross_amount = i.subtotal,
+    net_amount = ROUND(i.subtotal / (1 + i.tax_rate / 100), 2),
+    tax_amount = i.subtotal - ROUND(i.subtotal / (1 + i.tax_rate / 100), 2)
+FROM sales_orders o
+WHERE o.id = i.sales_order_id
+  AND o.price_mode = 'INCLUDED'
+  AND i.gross_amount = 0
+  AND i.subtotal > 0;
+
+DO $$ BEGIN RAISE NOTICE 'Precios de líneas previas completados desde variant_snapshot'; END $$;
+
+-- ============================================================================
+-- PASO 3: Totales de órdenes previas (suma de líneas)
+-- ============================================================================
+
+UPDATE sales_orders o
+SET net_amount = t.net_amount,
+    tax_amount = t.tax_amount,
+    total_amount = t.gross_amount
+FROM (
+    SELECT sales_order_id,
+           SUM(net_amount) AS net_amount,
+           SUM(tax_amount) AS tax_amount,
+           SUM(gross_amount) AS gross_amount
+    FROM sales_order_items
+    GROUP BY sales_order_id
+) t
+WHERE t.sales_order_id = o.id
+  AND o.total_amount = 0
+  AND t.gross_amount > 0;
+
+DO $$ BEGIN RAISE NOTICE 'Totales recalculados en sales_orders'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 019 completada exitosamente';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - sales_order_items (price_overridden)';
+    RAISE NOTICE 'Datos completados:';
+    RAISE NOTICE '  - unit_price / subtotal desde variant_snapshot';
+    RAISE NOTICE '  - total_amount / net_amount / tax_amount de órdenes';
+    RAISE NOTICE '========================================';
+END $$;
@@ -0,0 +1,48 @@
+-- ============================================================================
+-- Migración 020: Precio de catálogo en ventas POS
+-- Fecha: 2026-10-17
+-- Hito: POS-PRICE - Precio y nombre desde PIM, override solo con permiso
+-- Estrategia: cada línea guarda el precio de lista vigente al vender y, si el
+--             precio cobrado difiere, el operador que autorizó el override.
+--             Las líneas previas toman su unit_price como precio de lista
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Precio de lista y override por línea
+-- ============================================================================
+
+ALTER TABLE pos_sale_items ADD COLUMN IF NOT EXISTS list_price DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE pos_sale_items ADD COLUMN IF NOT EXISTS price_overridden BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE pos_sale_items ADD COLUMN IF NOT EXISTS price_overridden_by VARCHAR(255);
+
+-- Líneas previas: no hay precio de catálogo registrado, se asume el cobrado
+UPDATE pos_sale_items SET list_price = unit_price WHERE list_price = 0;
+
+COMMENT ON COLUMN pos_sale_items.list_price IS 'Precio de la variante en PIM al momento de la venta';
+COMMENT ON COLUMN pos_sale_items.price_overridden IS 'TRUE si unit_price difiere de list_price (override autorizado)';
+COMMENT ON COLUMN pos_sale_items.price_overridden_by IS 'Usuario (X-User-ID) con permiso sales.pos.price_override que cobró otro precio';
+
+DO $$ BEGIN RAISE NOTICE 'Columnas list_price / price_overridden / price_overridden_by agregadas a pos_sale_items'; END $$;
+
+-- ============================================================================
+-- PASO 2: Índice para auditoría de overrides
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_pos_sale_items_price_overridden
+    ON pos_sale_items (pos_sale_id)
+    WHERE price_overridden;
+
+DO $$ BEGIN RAISE NOTICE 'Índice idx_pos_sale_items_price_overridden creado'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 020 completada exitosamente';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - pos_sale_items (list_price, price_overridden, price_overridden_by)';
+    RAISE NOTICE '========================================';
+END $$;
@@ -0,0 +1,70 @@
+-- ============================================================================
+-- Migración 021: Outbox transaccional de eventos
+-- Fecha: 2026-10-17
+-- Hito: OUTBOX - Eventos de venta escritos en la misma tx que el cambio de negocio
+-- Estrategia: outbox_events en la base de órdenes. El relay los reenvía al
+--             eventbus (otra base) con reintentos y backoff; agotados los
+--             intentos quedan DEAD para reproceso manual
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Tabla outbox_events
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY,
+    aggregate_id VARCHAR(255) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    published_by VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'PROCESSING', 'PUBLISHED', 'DEAD')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP
+);
+
+COMMENT ON TABLE outbox_events IS 'Outbox transaccional (HITO OUTBOX): eventos pendientes de reenviar al eventbus';
+COMMENT ON COLUMN outbox_events.payload IS 'Payload tal como se publica (sales.order.confirmed lleva el envelope completo)';
+COMMENT ON COLUMN outbox_events.status IS 'PENDING → PROCESSING → PUBLISHED. DEAD = intentos agotados (dead-letter)';
+COMMENT ON COLUMN outbox_events.next_attempt_at IS 'Próximo reintento (backoff exponencial)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla outbox_events creada'; END $$;
+
+-- ============================================================================
+-- PASO 2: Índices del relay y de auditoría
+-- ============================================================================
+
+-- Cola del relay: solo filas PENDING, en orden de creación
+CREATE INDEX IF NOT EXISTS idx_outbox_events_due
+    ON outbox_events (next_attempt_at, created_at)
+    WHERE status = 'PENDING';
+
+-- Recuperación de PROCESSING abandonados y monitoreo de DEAD
+CREATE INDEX IF NOT EXISTS idx_outbox_events_status
+    ON outbox_events (status, updated_at)
+    WHERE status IN ('PROCESSING', 'DEAD');
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate
+    ON outbox_events (aggregate_type, aggregate_id);
+
+DO $$ BEGIN RAISE NOTICE 'Índices de outbox_events creados'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 021 completada exitosamente';
+    RAISE NOTICE 'Tablas creadas:';
+    RAISE NOTICE '  - outbox_events';
+    RAISE NOTICE 'Reproceso manual de dead-letter:';
+    RAISE NOTICE '  UPDATE outbox_events SET status = ''PENDING'', attempts = 0, next_attempt_at = NOW() WHERE status = ''DEAD'';';
+    RAISE NOTICE '========================================';
+END $$;
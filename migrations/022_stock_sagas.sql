@@ -0,0 +1,96 @@
+-- ============================================================================
+-- Migración 022: Log persistente de sagas de stock
+-- Fecha: 2026-10-17
+-- Hito: STOCK-SAGA - Compensaciones de stock reintentables
+-- Estrategia: cada venta POS / orden multi-ítem registra una saga con un paso
+--             por descuento de stock (stock_entry_id). Si la venta no se
+--             persiste, los pasos se compensan; lo que falle queda
+--             COMPENSATING para el worker (backoff exponencial) y, agotados
+--             los intentos, ESCALATED para intervención manual
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Tabla stock_sagas
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS stock_sagas (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(255) NOT NULL,
+    saga_type VARCHAR(20) NOT NULL
+        CHECK (saga_type IN ('POS_SALE', 'SALES_ORDER')),
+    reference VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'STARTED'
+        CHECK (status IN ('STARTED', 'COMPLETED', 'COMPENSATING', 'COMPENSATED', 'ESCALATED')),
+    compensation_reason VARCHAR(100),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE stock_sagas IS 'Sagas de stock (HITO STOCK-SAGA): una por venta POS u orden que descuenta stock';
+COMMENT ON COLUMN stock_sagas.reference IS 'Referencia enviada a stock-service (POS-... o sales_order_id)';
+COMMENT ON COLUMN stock_sagas.status IS 'STARTED → COMPLETED (misma tx que la venta) | COMPENSATING → COMPENSATED | ESCALATED';
+COMMENT ON COLUMN stock_sagas.next_attempt_at IS 'Próximo reintento de compensación (backoff exponencial)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla stock_sagas creada'; END $$;
+
+-- ============================================================================
+-- PASO 2: Tabla stock_saga_steps
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS stock_saga_steps (
+    id UUID PRIMARY KEY,
+    saga_id UUID NOT NULL REFERENCES stock_sagas(id) ON DELETE CASCADE,
+    sequence INT NOT NULL,
+    sku VARCHAR(255) NOT NULL,
+    quantity DECIMAL(15,3) NOT NULL,
+    stock_entry_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'APPLIED'
+        CHECK (status IN ('APPLIED', 'COMPENSATED')),
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    compensated_at TIMESTAMP,
+    UNIQUE (saga_id, sequence)
+);
+
+COMMENT ON TABLE stock_saga_steps IS 'Descuentos de stock (ProcessSaleAtomic) de cada saga';
+COMMENT ON COLUMN stock_saga_steps.stock_entry_id IS 'Movimiento de stock-service a revertir con CompensateSale';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla stock_saga_steps creada'; END $$;
+
+-- ============================================================================
+-- PASO 3: Índices del worker y de administración
+-- ============================================================================
+
+-- Cola del worker: solo sagas con compensación pendiente
+CREATE INDEX IF NOT EXISTS idx_stock_sagas_due
+    ON stock_sagas (next_attempt_at)
+    WHERE status = 'COMPENSATING';
+
+-- Sagas abandonadas (STARTED) y escaladas para GET /admin/sagas
+CREATE INDEX IF NOT EXISTS idx_stock_sagas_open
+    ON stock_sagas (status, updated_at)
+    WHERE status IN ('STARTED', 'COMPENSATING', 'ESCALATED');
+
+CREATE INDEX IF NOT EXISTS idx_stock_sagas_reference
+    ON stock_sagas (tenant_id, reference);
+
+DO $$ BEGIN RAISE NOTICE 'Índices de stock_sagas creados'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 022 completada exitosamente';
+    RAISE NOTICE 'Tablas creadas:';
+    RAISE NOTICE '  - stock_sagas';
+    RAISE NOTICE '  - stock_saga_steps';
+    RAISE NOTICE 'Reintento manual de sagas escaladas:';
+    RAISE NOTICE '  UPDATE stock_sagas SET status = ''COMPENSATING'', attempts = 0, next_attempt_at = NOW() WHERE status = ''ESCALATED'';';
+    RAISE NOTICE '========================================';
+END $$;
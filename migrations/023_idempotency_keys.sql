@@ -0,0 +1,60 @@
+-- ============================================================================
+-- Migración 023: Claves de idempotencia de endpoints de venta
+-- Fecha: 2026-10-17
+-- Hito: IDEMPOTENCY - Idempotency-Key en POST /pos/sale y POST /orders
+-- Estrategia: una fila por (tenant, clave) con el hash del request y la
+--             respuesta final. La PK serializa los duplicados concurrentes:
+--             solo uno inserta la fila IN_PROGRESS, el resto espera y
+--             reproduce la respuesta guardada. Vencen a las 24 hs
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Tabla idempotency_keys
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    tenant_id VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'IN_PROGRESS'
+        CHECK (status IN ('IN_PROGRESS', 'COMPLETED')),
+    lock_token UUID NOT NULL,
+    response_status INT,
+    response_body BYTEA,
+    locked_until TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, idempotency_key)
+);
+
+COMMENT ON TABLE idempotency_keys IS 'Idempotency-Key por tenant (HITO IDEMPOTENCY): respuesta original para reintentos del terminal';
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'sha256(método + ruta + body). Misma clave con otro hash → 422';
+COMMENT ON COLUMN idempotency_keys.lock_token IS 'Request dueño del IN_PROGRESS (un lock vencido lo puede tomar otro request)';
+COMMENT ON COLUMN idempotency_keys.response_body IS 'Respuesta guardada (solo 2xx / 4xx; un 5xx libera la clave)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla idempotency_keys creada'; END $$;
+
+-- ============================================================================
+-- PASO 2: Índice de limpieza
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at
+    ON idempotency_keys (expires_at);
+
+DO $$ BEGIN RAISE NOTICE 'Índice idx_idempotency_keys_expires_at creado'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 023 completada exitosamente';
+    RAISE NOTICE 'Tablas creadas:';
+    RAISE NOTICE '  - idempotency_keys';
+    RAISE NOTICE 'Limpieza de claves vencidas:';
+    RAISE NOTICE '  DELETE FROM idempotency_keys WHERE expires_at < NOW();';
+    RAISE NOTICE '========================================';
+END $$;
@@ -0,0 +1,79 @@
+-- ============================================================================
+-- Migración 024: Reservas de stock de órdenes con vencimiento
+-- Fecha: 2026-10-17
+-- Hito: ORDER-RESERVATION - Checkout con reserva y TTL
+-- Estrategia: la orden reserva stock al crearse (una reserva por línea, con
+--             referencia propia) y la confirmación consume exactamente esas
+--             reservas. Un worker vence las órdenes CREATED pasado su TTL
+--             (status EXPIRED) y libera sus reservas. Las órdenes previas
+--             (stock ya descontado al crear) quedan sin referencia ni
+--             vencimiento y el worker no las toca
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Estado EXPIRED y vencimiento de la reserva en sales_orders
+-- ============================================================================
+
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS sales_orders_status_check;
+ALTER TABLE sales_orders ADD CONSTRAINT sales_orders_status_check
+    CHECK (status IN ('CREATED', 'CONFIRMED', 'CANCELED', 'EXPIRED'));
+
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS reservation_expires_at TIMESTAMP;
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS reservation_lease_until TIMESTAMP;
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS reservations_released_at TIMESTAMP;
+
+COMMENT ON COLUMN sales_orders.reservation_expires_at IS 'Vencimiento de la reserva de stock (NULL = orden previa a las reservas)';
+COMMENT ON COLUMN sales_orders.reservation_lease_until IS 'Orden EXPIRED tomada por el worker: no se reintenta la liberación antes de este momento';
+COMMENT ON COLUMN sales_orders.reservations_released_at IS 'Todas las reservas de la orden vencida fueron liberadas en stock-service';
+
+DO $$ BEGIN RAISE NOTICE 'sales_orders: estado EXPIRED y columnas de reserva agregadas'; END $$;
+
+-- ============================================================================
+-- PASO 2: Reserva por línea en sales_order_items
+-- ============================================================================
+
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS reservation_reference VARCHAR(255);
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS reservation_status VARCHAR(20)
+    CHECK (reservation_status IN ('RESERVED', 'CONSUMED', 'RELEASED'));
+
+COMMENT ON COLUMN sales_order_items.reservation_reference IS 'Referencia de la reserva en stock-service (<order_id>-ITEM<n>)';
+COMMENT ON COLUMN sales_order_items.reservation_status IS 'RESERVED → CONSUMED (confirmación) | RELEASED (vencimiento). NULL = línea previa, sin reserva';
+
+DO $$ BEGIN RAISE NOTICE 'sales_order_items: columnas de reserva agregadas'; END $$;
+
+-- ============================================================================
+-- PASO 3: Tipo de paso en stock_saga_steps
+-- ============================================================================
+
+ALTER TABLE stock_saga_steps ADD COLUMN IF NOT EXISTS step_type VARCHAR(20) NOT NULL DEFAULT 'SALE'
+    CHECK (step_type IN ('SALE', 'RESERVATION'));
+
+COMMENT ON COLUMN stock_saga_steps.step_type IS 'SALE: se revierte con CompensateSale | RESERVATION: se libera con ReleaseStock';
+COMMENT ON COLUMN stock_saga_steps.stock_entry_id IS 'Movimiento de stock-service a revertir (stock_entry_id o referencia de la reserva)';
+
+DO $$ BEGIN RAISE NOTICE 'stock_saga_steps: columna step_type agregada'; END $$;
+
+-- ============================================================================
+-- PASO 4: Índice del worker de vencimiento
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_sales_orders_reservation_due
+    ON sales_orders (reservation_expires_at)
+    WHERE status IN ('CREATED', 'EXPIRED') AND reservations_released_at IS NULL;
+
+DO $$ BEGIN RAISE NOTICE 'Índice idx_sales_orders_reservation_due creado'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 024 completada exitosamente';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - sales_orders (EXPIRED, reservation_expires_at, reservation_lease_until, reservations_released_at)';
+    RAISE NOTICE '  - sales_order_items (reservation_reference, reservation_status)';
+    RAISE NOTICE '  - stock_saga_steps (step_type)';
+    RAISE NOTICE '========================================';
+END $$;
@@ -0,0 +1,86 @@
+-- ============================================================================
+-- Migración 025: Cancelación de órdenes con motivo y cancelación de líneas
+-- Fecha: 2026-10-17
+-- Hito: ORDER-CANCEL - Cancelación desde CREATED y cancelación parcial
+-- Estrategia: la orden guarda motivo, usuario y momento de la cancelación
+--             (CREATED libera reservas, CONFIRMED revierte el consumo). La
+--             cancelación parcial conserva las líneas con canceled_quantity y
+--             totales recalculados; cada cancelación queda registrada por línea
+--             en sales_order_line_cancellations. Las órdenes previas quedan con
+--             canceled_quantity = 0 y sin motivo
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Motivo y usuario de la cancelación en sales_orders
+-- ============================================================================
+
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS cancel_reason TEXT;
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS canceled_by VARCHAR(255);
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS canceled_at TIMESTAMP;
+
+COMMENT ON COLUMN sales_orders.cancel_reason IS 'Motivo de la cancelación (obligatorio desde ORDER-CANCEL; NULL = cancelada antes)';
+COMMENT ON COLUMN sales_orders.canceled_by IS 'Usuario que canceló la orden (X-User-ID)';
+COMMENT ON COLUMN sales_orders.canceled_at IS 'Momento de la cancelación';
+
+DO $$ BEGIN RAISE NOTICE 'sales_orders: columnas de cancelación agregadas'; END $$;
+
+-- ============================================================================
+-- PASO 2: Unidades canceladas en sales_order_items
+-- ============================================================================
+
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS canceled_quantity INT NOT NULL DEFAULT 0;
+ALTER TABLE sales_order_items DROP CONSTRAINT IF EXISTS sales_order_items_canceled_quantity_check;
+ALTER TABLE sales_order_items ADD CONSTRAINT sales_order_items_canceled_quantity_check
+    CHECK (canceled_quantity >= 0 AND canceled_quantity <= quantity);
+
+COMMENT ON COLUMN sales_order_items.canceled_quantity IS 'Unidades canceladas de la línea; subtotal e IVA corresponden a quantity - canceled_quantity';
+
+DO $$ BEGIN RAISE NOTICE 'sales_order_items: columna canceled_quantity agregada'; END $$;
+
+-- ============================================================================
+-- PASO 3: Registro de cancelaciones parciales
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS sales_order_line_cancellations (
+    id UUID PRIMARY KEY,
+    cancellation_id UUID NOT NULL,
+    tenant_id UUID NOT NULL,
+    sales_order_id UUID NOT NULL REFERENCES sales_orders(id),
+    sales_order_item_id UUID NOT NULL REFERENCES sales_order_items(id),
+    sku VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    net_amount DECIMAL(15,2) NOT NULL,
+    tax_amount DECIMAL(15,2) NOT NULL,
+    gross_amount DECIMAL(15,2) NOT NULL,
+    reason TEXT NOT NULL,
+    canceled_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_order_line_cancellations_order
+    ON sales_order_line_cancellations (sales_order_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_sales_order_line_cancellations_cancellation
+    ON sales_order_line_cancellations (cancellation_id);
+
+COMMENT ON TABLE sales_order_line_cancellations IS 'Líneas canceladas parcialmente de órdenes confirmadas (una fila por línea y cancelación)';
+COMMENT ON COLUMN sales_order_line_cancellations.cancellation_id IS 'Documento de cancelación: agrupa las líneas de un mismo request (reversal_id de la nota de crédito)';
+COMMENT ON COLUMN sales_order_line_cancellations.gross_amount IS 'Bruto que bajó la línea con la cancelación';
+COMMENT ON COLUMN credit_notes.reversal_id IS 'pos_sale_refunds.id o cancellation_id de la cancelación parcial que origina la nota (NULL = cancelación de orden)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla sales_order_line_cancellations creada'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 025 completada exitosamente';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - sales_orders (cancel_reason, canceled_by, canceled_at)';
+    RAISE NOTICE '  - sales_order_items (canceled_quantity)';
+    RAISE NOTICE 'Tablas creadas:';
+    RAISE NOTICE '  - sales_order_line_cancellations';
+    RAISE NOTICE '========================================';
+END $$;
@@ -0,0 +1,107 @@
+-- ============================================================================
+-- Migración 026: Ciclo de vida de preparación y envío de órdenes
+-- Fecha: 2026-10-17
+-- Hito: ORDER-FULFILLMENT - Máquina de estados, historial y envíos
+-- Estrategia: sales_orders admite PICKING, PACKED, SHIPPED, DELIVERED y
+--             RETURNED (las transiciones válidas las impone la entidad). Cada
+--             transición queda en order_status_history con actor y momento.
+--             Los envíos registran transportista, seguimiento y unidades por
+--             línea; sales_order_items.shipped_quantity acumula lo despachado.
+--             Las órdenes previas no tienen historial (no se reconstruye)
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Nuevos estados en sales_orders
+-- ============================================================================
+
+ALTER TABLE sales_orders DROP CONSTRAINT IF EXISTS sales_orders_status_check;
+ALTER TABLE sales_orders ADD CONSTRAINT sales_orders_status_check
+    CHECK (status IN ('CREATED', 'CONFIRMED', 'PICKING', 'PACKED', 'SHIPPED', 'DELIVERED', 'RETURNED', 'CANCELED', 'EXPIRED'));
+
+CREATE INDEX IF NOT EXISTS idx_sales_orders_tenant_status
+    ON sales_orders (tenant_id, status, created_at DESC);
+
+DO $$ BEGIN RAISE NOTICE 'sales_orders: estados de preparación y envío agregados'; END $$;
+
+-- ============================================================================
+-- PASO 2: Historial de estados
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    sales_order_id UUID NOT NULL REFERENCES sales_orders(id),
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor VARCHAR(255),
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order
+    ON order_status_history (sales_order_id, created_at);
+
+COMMENT ON TABLE order_status_history IS 'Transiciones de estado de las órdenes (HITO ORDER-FULFILLMENT)';
+COMMENT ON COLUMN order_status_history.actor IS 'Usuario que hizo la transición (X-User-ID). NULL = sistema (vencimiento de reserva)';
+COMMENT ON COLUMN order_status_history.note IS 'Nota de la transición (motivo de cancelación, envío que la originó)';
+
+DO $$ BEGIN RAISE NOTICE 'Tabla order_status_history creada'; END $$;
+
+-- ============================================================================
+-- PASO 3: Envíos
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS sales_order_shipments (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    sales_order_id UUID NOT NULL REFERENCES sales_orders(id),
+    carrier VARCHAR(100) NOT NULL,
+    tracking_number VARCHAR(255),
+    shipped_by VARCHAR(255),
+    shipped_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS sales_order_shipment_items (
+    id UUID PRIMARY KEY,
+    shipment_id UUID NOT NULL REFERENCES sales_order_shipments(id) ON DELETE CASCADE,
+    sales_order_item_id UUID NOT NULL REFERENCES sales_order_items(id),
+    sku VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_order_shipments_order
+    ON sales_order_shipments (sales_order_id, shipped_at);
+CREATE INDEX IF NOT EXISTS idx_sales_order_shipments_tracking
+    ON sales_order_shipments (tenant_id, tracking_number)
+    WHERE tracking_number IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_sales_order_shipment_items_shipment
+    ON sales_order_shipment_items (shipment_id);
+
+ALTER TABLE sales_order_items ADD COLUMN IF NOT EXISTS shipped_quantity INT NOT NULL DEFAULT 0;
+ALTER TABLE sales_order_items DROP CONSTRAINT IF EXISTS sales_order_items_shipped_quantity_check;
+ALTER TABLE sales_order_items ADD CONSTRAINT sales_order_items_shipped_quantity_check
+    CHECK (shipped_quantity >= 0 AND shipped_quantity <= quantity - canceled_quantity);
+
+COMMENT ON TABLE sales_order_shipments IS 'Envíos de órdenes: una orden puede despacharse en varios (HITO ORDER-FULFILLMENT)';
+COMMENT ON TABLE sales_order_shipment_items IS 'Unidades de cada línea despachadas en el envío';
+COMMENT ON COLUMN sales_order_items.shipped_quantity IS 'Unidades despachadas (suma de sales_order_shipment_items de la línea)';
+
+DO $$ BEGIN RAISE NOTICE 'Tablas sales_order_shipments y sales_order_shipment_items creadas'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 026 completada exitosamente';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - sales_orders (PICKING, PACKED, SHIPPED, DELIVERED, RETURNED)';
+    RAISE NOTICE '  - sales_order_items (shipped_quantity)';
+    RAISE NOTICE 'Tablas creadas:';
+    RAISE NOTICE '  - order_status_history';
+    RAISE NOTICE '  - sales_order_shipments';
+    RAISE NOTICE '  - sales_order_shipment_items';
+    RAISE NOTICE '========================================';
+END $$;
@@ -0,0 +1,56 @@
+-- ============================================================================
+-- Migración 027: Comprador de órdenes y ventas POS
+-- Fecha: 2026-10-17
+-- Hito: CUSTOMER - Snapshot inmutable del cliente
+-- Estrategia: la orden y la venta POS guardan el comprador resuelto en
+--             customer-service (nombre, documento, condición frente al IVA y
+--             domicilio) como JSONB, igual que los snapshots de PIM.
+--             sales_orders.customer_id pasa a ser opcional: el placeholder
+--             00000000-0000-0000-0000-000000000001 que se insertaba siempre se
+--             convierte a NULL (consumidor final). Las ventas previas quedan sin
+--             snapshot y se publican / facturan como consumidor final
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: customer_id opcional en sales_orders
+-- ============================================================================
+
+ALTER TABLE sales_orders ALTER COLUMN customer_id DROP NOT NULL;
+
+UPDATE sales_orders
+SET customer_id = NULL
+WHERE customer_id = '00000000-0000-0000-0000-000000000001';
+
+COMMENT ON COLUMN sales_orders.customer_id IS 'ID del cliente en customer-service (NULL = consumidor final)';
+
+DO $$ BEGIN RAISE NOTICE 'sales_orders: customer_id opcional, placeholder convertido a NULL'; END $$;
+
+-- ============================================================================
+-- PASO 2: Snapshot del comprador
+-- ============================================================================
+
+ALTER TABLE sales_orders ADD COLUMN IF NOT EXISTS customer_snapshot JSONB;
+ALTER TABLE pos_sales ADD COLUMN IF NOT EXISTS customer_snapshot JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_pos_sales_tenant_customer
+    ON pos_sales (tenant_id, customer_id)
+    WHERE customer_id IS NOT NULL;
+
+COMMENT ON COLUMN sales_orders.customer_snapshot IS 'Comprador al crear la orden: name, document_type, document_number, tax_condition, address (HITO CUSTOMER). NULL = orden previa';
+COMMENT ON COLUMN pos_sales.customer_snapshot IS 'Comprador al registrar la venta: name, document_type, document_number, tax_condition, address (HITO CUSTOMER). NULL = venta previa';
+
+DO $$ BEGIN RAISE NOTICE 'Columnas customer_snapshot agregadas'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 027 completada exitosamente';
+    RAISE NOTICE 'Tablas extendidas:';
+    RAISE NOTICE '  - sales_orders (customer_id opcional, customer_snapshot)';
+    RAISE NOTICE '  - pos_sales (customer_snapshot)';
+    RAISE NOTICE '========================================';
+END $$;
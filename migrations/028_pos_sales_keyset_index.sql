@@ -0,0 +1,37 @@
+-- ============================================================================
+-- Migración 028: Índice de paginación del listado de ventas POS
+-- Fecha: 2026-10-17
+-- Hito: POS-SEARCH - GET /pos/sales con filtros y cursor
+-- Estrategia: el listado pagina por keyset sobre (created_at, id) en orden
+--             descendente: WHERE (created_at, id) < (cursor) ORDER BY
+--             created_at DESC, id DESC. idx_pos_sales_tenant_created se recrea
+--             con id como desempate para que el recorrido salga del índice sin
+--             ordenar, aun con varias ventas en el mismo instante
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Recrear idx_pos_sales_tenant_created con desempate por id
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_pos_sales_tenant_created;
+
+CREATE INDEX idx_pos_sales_tenant_created
+    ON pos_sales (tenant_id, created_at DESC, id DESC);
+
+COMMENT ON INDEX idx_pos_sales_tenant_created IS
+    'Listado y reportes de ventas POS por tenant: keyset (created_at, id) DESC (HITO POS-SEARCH)';
+
+DO $$ BEGIN RAISE NOTICE 'Índice idx_pos_sales_tenant_created recreado: (tenant_id, created_at DESC, id DESC)'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 028 completada exitosamente';
+    RAISE NOTICE 'Índice recreado:';
+    RAISE NOTICE '  - idx_pos_sales_tenant_created (tenant_id, created_at DESC, id DESC)';
+    RAISE NOTICE '========================================';
+END $$;
@@ -0,0 +1,37 @@
+-- ============================================================================
+-- Migración 029: Sagas de stock de anulaciones y devoluciones POS
+-- Fecha: 2026-10-17
+-- Hito: POS-REFUND / STOCK-SAGA - Compensación de devoluciones reintentable
+-- Estrategia: la anulación / devolución registra, en la misma tx que el
+--             documento, una saga POS_REFUND (reference = pos_sale_refund_id)
+--             ya COMPENSATING con un paso por stock_entry_id a revertir. El
+--             request compensa enseguida; lo que falle queda para el worker
+--             de reintentos (backoff y ESCALATED) y en GET /admin/sagas.
+--             pos_sale_refund_items.stock_compensated se marca al compensar
+--             cada paso
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Tipo de saga POS_REFUND
+-- ============================================================================
+
+ALTER TABLE stock_sagas DROP CONSTRAINT IF EXISTS stock_sagas_saga_type_check;
+ALTER TABLE stock_sagas ADD CONSTRAINT stock_sagas_saga_type_check
+    CHECK (saga_type IN ('POS_SALE', 'SALES_ORDER', 'POS_REFUND'));
+
+COMMENT ON COLUMN stock_sagas.reference IS 'Referencia de la operación (POS-..., sales_order_id o pos_sale_refund_id)';
+
+DO $$ BEGIN RAISE NOTICE 'stock_sagas: tipo POS_REFUND agregado'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 029 completada exitosamente';
+    RAISE NOTICE 'Constraint actualizado:';
+    RAISE NOTICE '  - stock_sagas_saga_type_check (POS_REFUND)';
+    RAISE NOTICE '========================================';
+END $$;
@@ -0,0 +1,36 @@
+-- ============================================================================
+-- Migración 030: Estado CONSUMING de las reservas de órdenes
+-- Fecha: 2026-10-17
+-- Hito: ORDER-RESERVATION - Confirmaciones concurrentes de una orden
+-- Estrategia: antes de consumir la reserva en stock-service la confirmación
+--             toma cada línea con un UPDATE condicional RESERVED → CONSUMING;
+--             una segunda confirmación concurrente no puede tomarla y responde
+--             409. Una línea que quedó CONSUMING (confirmación interrumpida) la
+--             resuelve el vencimiento de la orden: libera la reserva o, si ya
+--             se consumió, revierte el consumo
+-- ============================================================================
+
+BEGIN;
+
+-- ============================================================================
+-- PASO 1: Estado CONSUMING en sales_order_items.reservation_status
+-- ============================================================================
+
+ALTER TABLE sales_order_items DROP CONSTRAINT IF EXISTS sales_order_items_reservation_status_check;
+ALTER TABLE sales_order_items ADD CONSTRAINT sales_order_items_reservation_status_check
+    CHECK (reservation_status IN ('RESERVED', 'CONSUMING', 'CONSUMED', 'RELEASED'));
+
+COMMENT ON COLUMN sales_order_items.reservation_status IS 'RESERVED → CONSUMING → CONSUMED (confirmación) | RELEASED (vencimiento / cancelación). NULL = línea previa, sin reserva';
+
+DO $$ BEGIN RAISE NOTICE 'sales_order_items: estado de reserva CONSUMING agregado'; END $$;
+
+COMMIT;
+
+DO $$
+BEGIN
+    RAISE NOTICE '========================================';
+    RAISE NOTICE 'Migración 030 completada exitosamente';
+    RAISE NOTICE 'Constraint actualizado:';
+    RAISE NOTICE '  - sales_order_items_reservation_status_check (CONSUMING)';
+    RAISE NOTICE '========================================';
+END $$;
@@ -0,0 +1,20 @@
+package request
+
+// CancelOrderRequest request para cancelar una orden completa
+// HITO ORDER-CANCEL - Desde CREATED o CONFIRMED, con motivo obligatorio
+type CancelOrderRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// CancelOrderLinesRequest request para cancelar unidades de líneas de una orden confirmada
+// HITO ORDER-CANCEL - Cancelación parcial: cada línea indica cuántas unidades cancela
+type CancelOrderLinesRequest struct {
+	Lines  []CancelOrderLineRequest `json:"lines" binding:"required,min=1,dive"`
+	Reason string                   `json:"reason" binding:"required"`
+}
+
+// CancelOrderLineRequest unidades a cancelar de una línea
+type CancelOrderLineRequest struct {
+	ItemID   string `json:"item_id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+}
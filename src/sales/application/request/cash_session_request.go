@@ -0,0 +1,26 @@
+package request
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// OpenCashSessionRequest request para abrir una sesión de caja
+// HITO POS-CASH - Apertura de caja con fondo inicial
+type OpenCashSessionRequest struct {
+	PointOfSaleID uuid.UUID       `json:"point_of_sale_id" binding:"required"`
+	OpeningFloat  decimal.Decimal `json:"opening_float"` // Fondo de caja inicial (default: 0)
+}
+
+// CashCountRequest representa el monto contado para un método de pago
+type CashCountRequest struct {
+	PaymentMethodID uuid.UUID       `json:"payment_method_id" binding:"required"`
+	CountedAmount   decimal.Decimal `json:"counted_amount"`
+}
+
+// CloseCashSessionRequest request para cerrar una sesión de caja con arqueo
+// HITO POS-CASH - Cierre de caja
+type CloseCashSessionRequest struct {
+	Counts []CashCountRequest `json:"counts" binding:"required,min=1,dive"`
+	Notes  string             `json:"notes,omitempty"`
+}
@@ -1,6 +1,8 @@
 package request
 
 // ConfirmOrderRequest representa la petición para confirmar una orden
+// HITO ORDER-RESERVATION - La confirmación consume las reservas de la orden;
+// reference se acepta por compatibilidad pero ya no se usa
 type ConfirmOrderRequest struct {
-	Reference string `json:"reference" binding:"required"`
+	Reference string `json:"reference,omitempty"`
 }
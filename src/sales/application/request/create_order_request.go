@@ -1,13 +1,24 @@
 package request
 
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
 // CreateOrderItemRequest representa un item dentro de una orden
 type CreateOrderItemRequest struct {
 	SKU      string `json:"sku" binding:"required"`
 	Quantity int    `json:"quantity" binding:"required,min=1"`
+
+	// HITO ORDER-PRICE - Override del precio unitario (default: precio de la variante en PIM)
+	UnitPrice *decimal.Decimal `json:"unit_price,omitempty"`
 }
 
 // CreateOrderRequest representa la petición para crear una orden (multi-item)
 type CreateOrderRequest struct {
 	Items     []CreateOrderItemRequest `json:"items" binding:"required,min=1,dive"`
 	Reference string                   `json:"reference,omitempty"`
+
+	// HITO CUSTOMER - Cliente de customer-service (omitido = consumidor final)
+	CustomerID *uuid.UUID `json:"customer_id,omitempty"`
 }
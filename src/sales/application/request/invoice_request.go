@@ -0,0 +1,22 @@
+package request
+
+import "github.com/google/uuid"
+
+// IssueInvoiceRequest request para facturar una orden confirmada o una venta POS
+// HITO FISCAL - La letra (A/B/C) se determina por la condición del emisor y del cliente
+type IssueInvoiceRequest struct {
+	SourceType           string    `json:"source_type" binding:"required"` // SALES_ORDER | POS_SALE
+	SourceID             uuid.UUID `json:"source_id" binding:"required"`
+	CustomerTaxCondition string    `json:"customer_tax_condition,omitempty"` // Default: comprador de la venta o CONSUMIDOR_FINAL
+	CustomerTaxID        string    `json:"customer_tax_id,omitempty"`        // CUIT (obligatorio en factura A)
+	CustomerName         string    `json:"customer_name,omitempty"`
+}
+
+// SaveFiscalIssuerRequest request para cargar los datos fiscales del tenant
+// HITO FISCAL - Emisor de comprobantes
+type SaveFiscalIssuerRequest struct {
+	CUIT              string `json:"cuit" binding:"required"`
+	LegalName         string `json:"legal_name"`
+	TaxCondition      string `json:"tax_condition" binding:"required"` // RESPONSABLE_INSCRIPTO | MONOTRIBUTO | EXENTO
+	PointOfSaleNumber int    `json:"point_of_sale_number" binding:"required"`
+}
@@ -0,0 +1,22 @@
+package request
+
+// TransitionOrderStatusRequest request para avanzar la orden en el ciclo de preparación
+// HITO ORDER-FULFILLMENT - PICKING, PACKED, DELIVERED o RETURNED
+type TransitionOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note,omitempty"`
+}
+
+// CreateShipmentRequest request para registrar un envío de la orden
+// HITO ORDER-FULFILLMENT - Sin líneas despacha todas las unidades pendientes
+type CreateShipmentRequest struct {
+	Carrier        string                `json:"carrier" binding:"required"`
+	TrackingNumber string                `json:"tracking_number,omitempty"`
+	Lines          []ShipmentLineRequest `json:"lines,omitempty" binding:"omitempty,dive"`
+}
+
+// ShipmentLineRequest unidades a despachar de una línea
+type ShipmentLineRequest struct {
+	ItemID   string `json:"item_id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+}
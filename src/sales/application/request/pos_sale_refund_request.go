@@ -0,0 +1,40 @@
+package request
+
+import "github.com/google/uuid"
+
+// VoidPosSaleRequest request para anular una venta POS completa
+// HITO POS-REFUND - Void total
+type VoidPosSaleRequest struct {
+	Reason                string     `json:"reason" binding:"required"`
+	RefundPaymentMethodID *uuid.UUID `json:"refund_payment_method_id,omitempty"` // Default: método principal de la venta
+}
+
+// RefundPosSaleItemRequest línea a devolver con su cantidad
+// Quantity omitida = línea completa; otra cantidad que la vendida se rechaza (400)
+type RefundPosSaleItemRequest struct {
+	ItemID   uuid.UUID `json:"item_id" binding:"required"`
+	Quantity int       `json:"quantity" binding:"omitempty,gt=0"`
+}
+
+// RefundPosSaleRequest request para devolver líneas de una venta POS
+// HITO POS-REFUND - Devolución parcial por línea (cada línea se devuelve completa)
+// Las líneas vienen en items (con cantidad) o en item_ids (formato previo, líneas completas)
+type RefundPosSaleRequest struct {
+	ItemIDs               []uuid.UUID                `json:"item_ids" binding:"required_without=Items"`
+	Items                 []RefundPosSaleItemRequest `json:"items" binding:"omitempty,dive"`
+	Reason                string                     `json:"reason" binding:"required"`
+	RefundPaymentMethodID *uuid.UUID                 `json:"refund_payment_method_id,omitempty"` // Default: método principal de la venta
+}
+
+// NormalizedItems retorna las líneas a devolver
+// Si no viene items, arma líneas completas desde item_ids
+func (r *RefundPosSaleRequest) NormalizedItems() []RefundPosSaleItemRequest {
+	if len(r.Items) > 0 {
+		return r.Items
+	}
+	items := make([]RefundPosSaleItemRequest, len(r.ItemIDs))
+	for i, itemID := range r.ItemIDs {
+		items[i] = RefundPosSaleItemRequest{ItemID: itemID}
+	}
+	return items
+}
@@ -7,21 +7,49 @@ import (
 
 // POSSaleItemRequest representa un item dentro de una venta POS
 // HITO B - Multi-item support
+// HITO POS-PRICE - unit_price es opcional: el precio sale del catálogo (PIM). Si se envía
+// debe coincidir, salvo que el operador tenga permiso de override
 type POSSaleItemRequest struct {
-	SKU       string          `json:"sku" binding:"required"`
-	Quantity  int             `json:"quantity" binding:"required,gt=0"`
-	UnitPrice decimal.Decimal `json:"unit_price" binding:"required"` // Precio unitario
+	SKU       string           `json:"sku" binding:"required"`
+	Quantity  int              `json:"quantity" binding:"required,gt=0"`
+	UnitPrice *decimal.Decimal `json:"unit_price,omitempty"` // Precio esperado por el terminal
+}
+
+// POSSalePaymentRequest representa un pago dentro de una venta POS
+// HITO POS-SPLIT - Pagos combinados
+type POSSalePaymentRequest struct {
+	PaymentMethodID uuid.UUID       `json:"payment_method_id" binding:"required"`
+	Amount          decimal.Decimal `json:"amount" binding:"required"`
+	Reference       *string         `json:"reference,omitempty"` // Opcional (cupón, nro. operación)
 }
 
 // POSSaleRequest request para venta directa POS multi-item
 // HITO B - Refactorizado para multi-item + descuentos
 // HITO: POST /pos/sale devuelve DTO listo para imprimir
+// HITO POS-SPLIT - payments reemplaza a payment_method_id/amount_paid (se mantienen por compatibilidad)
 type POSSaleRequest struct {
-	Items           []POSSaleItemRequest `json:"items" binding:"required,min=1,dive"` // Mínimo 1 item
-	CustomerID      *uuid.UUID           `json:"customer_id"`                         // Opcional (NULL = consumidor final)
-	PaymentMethodID uuid.UUID            `json:"payment_method_id" binding:"required"`
-	DiscountAmount  decimal.Decimal      `json:"discount_amount,omitempty"` // Descuento fijo (default: 0)
-	AmountPaid      decimal.Decimal      `json:"amount_paid" binding:"required"`      // Monto pagado por el cliente
-	Currency        string               `json:"currency,omitempty"`                  // Default: "ARS"
-	Notes           string               `json:"notes,omitempty"`
+	Items           []POSSaleItemRequest    `json:"items" binding:"required,min=1,dive"` // Mínimo 1 item
+	CustomerID      *uuid.UUID              `json:"customer_id"`                         // Opcional (NULL = consumidor final)
+	Payments        []POSSalePaymentRequest `json:"payments" binding:"omitempty,dive"`   // HITO POS-SPLIT
+	PaymentMethodID uuid.UUID               `json:"payment_method_id"`                   // Legacy: pago único
+	DiscountAmount  decimal.Decimal         `json:"discount_amount,omitempty"`           // Descuento fijo (default: 0)
+	AmountPaid      decimal.Decimal         `json:"amount_paid"`                         // Legacy: monto del pago único
+	Currency        string                  `json:"currency,omitempty"`                  // Default: "ARS"
+	Notes           string                  `json:"notes,omitempty"`
+	PointOfSaleID   uuid.UUID               `json:"point_of_sale_id" binding:"required"` // HITO POS-CASH: terminal con sesión de caja abierta
+}
+
+// NormalizedPayments retorna los pagos del request
+// Si no viene payments, construye un pago único desde los campos legacy
+func (r *POSSaleRequest) NormalizedPayments() []POSSalePaymentRequest {
+	if len(r.Payments) > 0 {
+		return r.Payments
+	}
+	if r.PaymentMethodID == uuid.Nil {
+		return nil
+	}
+	return []POSSalePaymentRequest{{
+		PaymentMethodID: r.PaymentMethodID,
+		Amount:          r.AmountPaid,
+	}}
 }
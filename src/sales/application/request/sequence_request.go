@@ -0,0 +1,11 @@
+package request
+
+import "github.com/google/uuid"
+
+// SeedSequenceRequest request para crear / ajustar una secuencia de numeración
+// HITO SEQ-TX - Administración de secuencias
+type SeedSequenceRequest struct {
+	DocumentType  string     `json:"document_type" binding:"required"`
+	ScopeID       *uuid.UUID `json:"scope_id,omitempty"` // Ej: point_of_sale_id para POS_SALE
+	CurrentNumber int        `json:"current_number"`     // Último número ya emitido (el próximo será +1)
+}
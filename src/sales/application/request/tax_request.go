@@ -0,0 +1,16 @@
+package request
+
+// SaveTaxSettingsRequest request para configurar el IVA del tenant
+// HITO TAX-IVA
+type SaveTaxSettingsRequest struct {
+	PriceMode       string `json:"price_mode" binding:"required"`        // INCLUDED | EXCLUDED
+	DefaultRateCode string `json:"default_rate_code" binding:"required"` // IVA_21 | IVA_10_5 | IVA_27 | EXENTO
+}
+
+// SaveTaxRuleRequest request para asignar una alícuota a un SKU o categoría
+// Si ya existe una regla para el mismo scope + scope_value se reemplaza su alícuota
+type SaveTaxRuleRequest struct {
+	Scope      string `json:"scope" binding:"required"`       // SKU | CATEGORY
+	ScopeValue string `json:"scope_value" binding:"required"` // SKU o category_id de PIM
+	RateCode   string `json:"rate_code" binding:"required"`
+}
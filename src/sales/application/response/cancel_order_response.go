@@ -0,0 +1,50 @@
+package response
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CancelOrderResponse respuesta de cancelación de orden
+// HITO CREDIT-NOTE - Incluye la nota de crédito si la orden estaba facturada
+type CancelOrderResponse struct {
+	OrderID    string              `json:"order_id"`
+	Status     string              `json:"status"`
+	CreditNote *CreditNoteResponse `json:"credit_note,omitempty"`
+
+	// HITO ORDER-CANCEL - Motivo y usuario que canceló
+	CancelReason string     `json:"cancel_reason"`
+	CanceledBy   string     `json:"canceled_by,omitempty"`
+	CanceledAt   *time.Time `json:"canceled_at,omitempty"`
+}
+
+// CancelOrderLinesResponse respuesta de la cancelación parcial de líneas (HITO ORDER-CANCEL)
+// Importes cancelados y totales vigentes de la orden
+type CancelOrderLinesResponse struct {
+	CancellationID string                      `json:"cancellation_id"`
+	OrderID        string                      `json:"order_id"`
+	Status         string                      `json:"status"`
+	Reason         string                      `json:"reason"`
+	CanceledBy     string                      `json:"canceled_by,omitempty"`
+	CanceledLines  []CanceledOrderLineResponse `json:"canceled_lines"`
+	CanceledNet    decimal.Decimal             `json:"canceled_net"`
+	CanceledTax    decimal.Decimal             `json:"canceled_tax"`
+	CanceledTotal  decimal.Decimal             `json:"canceled_total"`
+	NetAmount      decimal.Decimal             `json:"net_amount"`
+	TaxAmount      decimal.Decimal             `json:"tax_amount"`
+	TotalAmount    decimal.Decimal             `json:"total_amount"`
+	Items          []OrderItemResponse         `json:"items"`
+	CreditNote     *CreditNoteResponse         `json:"credit_note,omitempty"`
+	CreatedAt      time.Time                   `json:"created_at"`
+}
+
+// CanceledOrderLineResponse unidades canceladas de una línea
+type CanceledOrderLineResponse struct {
+	ItemID      string          `json:"item_id"`
+	SKU         string          `json:"sku"`
+	Quantity    int             `json:"quantity"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	GrossAmount decimal.Decimal `json:"gross_amount"`
+}
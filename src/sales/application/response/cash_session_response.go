@@ -0,0 +1,41 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CashReconciliationLine representa el arqueo de un método de pago
+// HITO POS-CASH - Arqueo de caja
+type CashReconciliationLine struct {
+	PaymentMethodID   uuid.UUID        `json:"payment_method_id"`
+	PaymentMethodName string           `json:"payment_method_name"`
+	IsCash            bool             `json:"is_cash"`
+	SalesCount        int              `json:"sales_count"`
+	SalesAmount       decimal.Decimal  `json:"sales_amount"`             // Suma final_amount
+	ChangeGiven       decimal.Decimal  `json:"change_given"`             // Suma change
+	ExpectedAmount    decimal.Decimal  `json:"expected_amount"`          // sales_amount (+ fondo si es efectivo)
+	CountedAmount     *decimal.Decimal `json:"counted_amount,omitempty"` // NULL = no contado
+	Difference        *decimal.Decimal `json:"difference,omitempty"`     // counted - expected
+}
+
+// CashSessionResponse representa una sesión de caja con su arqueo
+// HITO POS-CASH - Apertura / cierre de caja
+type CashSessionResponse struct {
+	ID              uuid.UUID                `json:"id"`
+	PointOfSaleID   uuid.UUID                `json:"point_of_sale_id"`
+	Status          string                   `json:"status"`
+	OpeningFloat    decimal.Decimal          `json:"opening_float"`
+	OpenedBy        string                   `json:"opened_by,omitempty"`
+	OpenedAt        time.Time                `json:"opened_at"`
+	ClosedBy        string                   `json:"closed_by,omitempty"`
+	ClosedAt        *time.Time               `json:"closed_at,omitempty"`
+	Notes           string                   `json:"notes,omitempty"`
+	SalesCount      int                      `json:"sales_count"`
+	SalesAmount     decimal.Decimal          `json:"sales_amount"`
+	ChangeGiven     decimal.Decimal          `json:"change_given"`
+	TotalDifference decimal.Decimal          `json:"total_difference"` // Suma de diferencias contadas
+	Reconciliation  []CashReconciliationLine `json:"reconciliation"`
+}
@@ -1,16 +1,45 @@
 package response
 
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
 // CreateOrderItemResponse representa un item en la respuesta
 type CreateOrderItemResponse struct {
 	ItemID   string `json:"item_id"`
 	SKU      string `json:"sku"`
 	Quantity int    `json:"quantity"`
+
+	// HITO ORDER-PRICE - Precio y subtotal de la línea
+	UnitPrice       decimal.Decimal `json:"unit_price"`
+	Subtotal        decimal.Decimal `json:"subtotal"`
+	PriceOverridden bool            `json:"price_overridden"`
+
+	// HITO TAX-IVA - Desglose de la línea
+	TaxRateCode string          `json:"tax_rate_code"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	GrossAmount decimal.Decimal `json:"gross_amount"`
+
+	// HITO ORDER-RESERVATION - Referencia de la reserva de stock de la línea
+	ReservationReference string `json:"reservation_reference,omitempty"`
 }
 
 // CreateOrderResponse representa la respuesta de creación de orden (multi-item)
 type CreateOrderResponse struct {
-	OrderID    string                    `json:"order_id"`
-	Items      []CreateOrderItemResponse `json:"items"`
-	TotalItems int                       `json:"total_items"`
-	Status     string                    `json:"status"`
+	OrderID     string                    `json:"order_id"`
+	Items       []CreateOrderItemResponse `json:"items"`
+	TotalItems  int                       `json:"total_items"`
+	Status      string                    `json:"status"`
+	Subtotal    decimal.Decimal           `json:"subtotal"`     // HITO ORDER-PRICE
+	TotalAmount decimal.Decimal           `json:"total_amount"` // HITO ORDER-PRICE
+	Tax         TaxSummaryResponse        `json:"tax"`          // HITO TAX-IVA
+
+	// HITO ORDER-RESERVATION - Sin confirmar antes de este momento la orden vence y libera el stock
+	ReservationExpiresAt *time.Time `json:"reservation_expires_at,omitempty"`
+
+	// HITO CUSTOMER - Comprador de la orden
+	Customer *CustomerResponse `json:"customer"`
 }
@@ -0,0 +1,43 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CreditNoteItemResponse representa una línea revertida
+type CreditNoteItemResponse struct {
+	SourceItemID *uuid.UUID      `json:"source_item_id,omitempty"`
+	SKU          string          `json:"sku"`
+	Quantity     int             `json:"quantity"`
+	Amount       decimal.Decimal `json:"amount"`
+}
+
+// CreditNoteResponse representa una nota de crédito
+// HITO CREDIT-NOTE - Reversión documentada contra la factura original
+type CreditNoteResponse struct {
+	ID               uuid.UUID                `json:"id"`
+	InvoiceID        uuid.UUID                `json:"invoice_id"`
+	SourceType       string                   `json:"source_type"`
+	SourceID         uuid.UUID                `json:"source_id"`
+	ReversalID       *uuid.UUID               `json:"reversal_id,omitempty"`
+	CreditNoteType   string                   `json:"credit_note_type"`
+	CreditNoteNumber int                      `json:"credit_note_number"`
+	FormattedNumber  string                   `json:"formatted_number"` // NC B 0001-00000007
+	Reason           string                   `json:"reason"`
+	Currency         string                   `json:"currency"`
+	NetAmount        decimal.Decimal          `json:"net_amount"`
+	TaxAmount        decimal.Decimal          `json:"tax_amount"`
+	TotalAmount      decimal.Decimal          `json:"total_amount"`
+	IssuedBy         string                   `json:"issued_by,omitempty"`
+	Items            []CreditNoteItemResponse `json:"items"`
+	CreatedAt        time.Time                `json:"created_at"`
+}
+
+// ListCreditNotesResponse notas de crédito emitidas contra una factura
+type ListCreditNotesResponse struct {
+	InvoiceID   uuid.UUID            `json:"invoice_id"`
+	CreditNotes []CreditNoteResponse `json:"credit_notes"`
+}
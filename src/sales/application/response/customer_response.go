@@ -0,0 +1,21 @@
+package response
+
+// CustomerAddressResponse domicilio del comprador
+type CustomerAddressResponse struct {
+	Street     string `json:"street,omitempty"`
+	City       string `json:"city,omitempty"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+}
+
+// CustomerResponse comprador de la venta tal como quedó en el snapshot (HITO CUSTOMER)
+type CustomerResponse struct {
+	CustomerID     string                   `json:"customer_id,omitempty"` // Vacío = consumidor final
+	Name           string                   `json:"name"`
+	DocumentType   string                   `json:"document_type,omitempty"`
+	DocumentNumber string                   `json:"document_number,omitempty"`
+	TaxCondition   string                   `json:"tax_condition"`
+	Email          string                   `json:"email,omitempty"`
+	Address        *CustomerAddressResponse `json:"address,omitempty"`
+}
@@ -3,19 +3,46 @@ package response
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
+// PaymentMethodBreakdown representa el total cobrado con un método de pago
+// HITO POS-SPLIT - Desglose por método de pago
+type PaymentMethodBreakdown struct {
+	PaymentMethodID   uuid.UUID       `json:"payment_method_id"`
+	PaymentMethodName string          `json:"payment_method_name"`
+	SalesCount        int             `json:"sales_count"`     // Ventas que usaron este método
+	AmountReceived    decimal.Decimal `json:"amount_received"` // Suma de montos entregados
+	ChangeGiven       decimal.Decimal `json:"change_given"`    // Vuelto entregado (solo efectivo)
+	RefundedAmount    decimal.Decimal `json:"refunded_amount"` // HITO POS-REFUND - Devuelto por este método
+	NetAmount         decimal.Decimal `json:"net_amount"`      // amount_received - change_given - refunded_amount
+}
+
 // DailyReportResponse representa el reporte diario de ventas
 // HITO C - Reportes Diarios
 type DailyReportResponse struct {
-	Date               string          `json:"date"`                          // YYYY-MM-DD
-	PosSalesCount      int             `json:"pos_sales_count"`               // Cantidad ventas POS
-	OrdersCount        int             `json:"orders_count"`                  // Cantidad órdenes
-	TotalTransactions  int             `json:"total_transactions"`            // pos + orders
-	PosGrossTotal      decimal.Decimal `json:"pos_gross_total"`               // Suma total_amount
-	PosDiscounts       decimal.Decimal `json:"pos_discounts"`                 // Suma discount_amount
-	PosNetTotal        decimal.Decimal `json:"pos_net_total"`                 // Suma final_amount
+	Date               string          `json:"date"`                           // YYYY-MM-DD
+	PosSalesCount      int             `json:"pos_sales_count"`                // Cantidad ventas POS
+	OrdersCount        int             `json:"orders_count"`                   // Cantidad órdenes
+	TotalTransactions  int             `json:"total_transactions"`             // pos + orders
+	PosGrossTotal      decimal.Decimal `json:"pos_gross_total"`                // Suma total_amount
+	PosDiscounts       decimal.Decimal `json:"pos_discounts"`                  // Suma discount_amount
+	PosNetTotal        decimal.Decimal `json:"pos_net_total"`                  // Suma final_amount - devoluciones del día
 	FirstTransactionAt *time.Time      `json:"first_transaction_at,omitempty"` // Primera venta del día
 	LastTransactionAt  *time.Time      `json:"last_transaction_at,omitempty"`  // Última venta del día
+
+	// HITO POS-REFUND - Anulaciones / devoluciones emitidas en el día
+	PosRefundsCount int             `json:"pos_refunds_count"`
+	PosRefundsTotal decimal.Decimal `json:"pos_refunds_total"`
+
+	// HITO POS-SPLIT - Desglose de cobros POS por método de pago
+	PosPaymentMethods []PaymentMethodBreakdown `json:"pos_payment_methods"`
+
+	// HITO TAX-IVA - Neto gravado e IVA de las ventas POS del día
+	PosTaxableNet decimal.Decimal `json:"pos_taxable_net"` // Suma net_amount
+	PosTaxTotal   decimal.Decimal `json:"pos_tax_total"`   // Suma tax_amount
+
+	// HITO TAX-IVA - Desglose por alícuota (ventas POS + órdenes no canceladas del día)
+	TaxBreakdown []TaxBreakdownResponse `json:"tax_breakdown"`
 }
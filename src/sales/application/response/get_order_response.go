@@ -1,14 +1,33 @@
 package response
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // GetOrderResponse representa la respuesta de obtención de una orden
 type GetOrderResponse struct {
-	OrderID   string              `json:"order_id"`
-	TenantID  string              `json:"tenant_id"`
-	Status    string              `json:"status"`
-	CreatedAt string              `json:"created_at"`
-	Items     []OrderItemResponse `json:"items"`
+	OrderID     string              `json:"order_id"`
+	OrderNumber *int                `json:"order_number,omitempty"` // HITO ORDER-SEARCH: asignado al confirmar
+	TenantID    string              `json:"tenant_id"`
+	Status      string              `json:"status"`
+	CreatedAt   string              `json:"created_at"`
+	Items       []OrderItemResponse `json:"items"`
+	Subtotal    decimal.Decimal     `json:"subtotal"`     // HITO ORDER-PRICE
+	TotalAmount decimal.Decimal     `json:"total_amount"` // HITO ORDER-PRICE
+	Tax         TaxSummaryResponse  `json:"tax"`          // HITO TAX-IVA
+
+	Customer     *CustomerResponse          `json:"customer,omitempty"`     // HITO CUSTOMER
+	Cancellation *OrderCancellationResponse `json:"cancellation,omitempty"` // HITO ORDER-CANCEL
+}
+
+// OrderCancellationResponse motivo, usuario y momento de la cancelación (HITO ORDER-CANCEL)
+type OrderCancellationResponse struct {
+	Reason     string    `json:"reason"`
+	CanceledBy string    `json:"canceled_by,omitempty"`
+	CanceledAt time.Time `json:"canceled_at"`
 }
 
 // OrderItemResponse representa un item dentro de la orden
@@ -18,4 +37,22 @@ type OrderItemResponse struct {
 	Quantity        int             `json:"quantity"`
 	ProductSnapshot json.RawMessage `json:"product_snapshot,omitempty"`
 	VariantSnapshot json.RawMessage `json:"variant_snapshot,omitempty"`
+
+	// HITO ORDER-CANCEL - Unidades canceladas; precio e IVA son de las vigentes
+	CanceledQuantity int `json:"canceled_quantity"`
+
+	// HITO ORDER-FULFILLMENT - Unidades despachadas en envíos
+	ShippedQuantity int `json:"shipped_quantity"`
+
+	// HITO ORDER-PRICE - Precio y subtotal de la línea
+	UnitPrice       decimal.Decimal `json:"unit_price"`
+	Subtotal        decimal.Decimal `json:"subtotal"`
+	PriceOverridden bool            `json:"price_overridden"`
+
+	// HITO TAX-IVA - Desglose de la línea
+	TaxRateCode string          `json:"tax_rate_code"`
+	TaxRate     decimal.Decimal `json:"tax_rate"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	GrossAmount decimal.Decimal `json:"gross_amount"`
 }
@@ -0,0 +1,47 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// InvoiceResponse representa un comprobante electrónico
+// HITO FISCAL - Factura A/B/C con CAE
+type InvoiceResponse struct {
+	ID                   uuid.UUID       `json:"id"`
+	SourceType           string          `json:"source_type"`
+	SourceID             uuid.UUID       `json:"source_id"`
+	InvoiceType          string          `json:"invoice_type"`
+	PointOfSaleNumber    int             `json:"point_of_sale_number"`
+	InvoiceNumber        int             `json:"invoice_number,omitempty"`
+	FormattedNumber      string          `json:"formatted_number,omitempty"` // A 0001-00000042
+	IssuerCUIT           string          `json:"issuer_cuit"`
+	CustomerTaxCondition string          `json:"customer_tax_condition"`
+	CustomerTaxID        string          `json:"customer_tax_id,omitempty"`
+	CustomerName         string          `json:"customer_name,omitempty"`
+	Currency             string          `json:"currency"`
+	NetAmount            decimal.Decimal `json:"net_amount"`
+	TaxAmount            decimal.Decimal `json:"tax_amount"`
+	TotalAmount          decimal.Decimal `json:"total_amount"`
+	FiscalStatus         string          `json:"fiscal_status"`
+	CAE                  string          `json:"cae,omitempty"`
+	CAEExpiresAt         *time.Time      `json:"cae_expires_at,omitempty"`
+	RejectionReason      string          `json:"rejection_reason,omitempty"`
+	Attempts             int             `json:"attempts"`
+	NextAttemptAt        *time.Time      `json:"next_attempt_at,omitempty"` // Solo si está PENDING
+	LastError            string          `json:"last_error,omitempty"`
+	AuthorizedAt         *time.Time      `json:"authorized_at,omitempty"`
+	CreatedAt            time.Time       `json:"created_at"`
+}
+
+// FiscalIssuerResponse representa los datos fiscales del tenant
+type FiscalIssuerResponse struct {
+	TenantID          uuid.UUID `json:"tenant_id"`
+	CUIT              string    `json:"cuit"`
+	LegalName         string    `json:"legal_name"`
+	TaxCondition      string    `json:"tax_condition"`
+	PointOfSaleNumber int       `json:"point_of_sale_number"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
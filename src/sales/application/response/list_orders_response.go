@@ -1,19 +1,19 @@
 package response
 
+import "github.com/shopspring/decimal"
+
 // OrderListItem representa una orden en el listado
 type OrderListItem struct {
-	OrderID   string              `json:"order_id"`
-	TenantID  string              `json:"tenant_id"`
-	Status    string              `json:"status"`
-	CreatedAt string              `json:"created_at"`
-	Items     []OrderItemResponse `json:"items"`
-}
+	OrderID     string              `json:"order_id"`
+	OrderNumber *int                `json:"order_number,omitempty"` // HITO ORDER-SEARCH: asignado al confirmar
+	TenantID    string              `json:"tenant_id"`
+	Status      string              `json:"status"`
+	CreatedAt   string              `json:"created_at"`
+	Items       []OrderItemResponse `json:"items"`
+	Subtotal    decimal.Decimal     `json:"subtotal"`     // HITO ORDER-PRICE
+	TotalAmount decimal.Decimal     `json:"total_amount"` // HITO ORDER-PRICE
+	Tax         TaxSummaryResponse  `json:"tax"`          // HITO TAX-IVA
 
-// ListOrdersResponse representa la respuesta paginada de órdenes
-type ListOrdersResponse struct {
-	Items      []OrderListItem `json:"items"`
-	TotalCount int             `json:"total_count"`
-	Page       int             `json:"page"`
-	PageSize   int             `json:"page_size"`
-	TotalPages int             `json:"total_pages"`
+	Customer     *CustomerResponse          `json:"customer,omitempty"`     // HITO CUSTOMER
+	Cancellation *OrderCancellationResponse `json:"cancellation,omitempty"` // HITO ORDER-CANCEL
 }
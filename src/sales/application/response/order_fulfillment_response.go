@@ -0,0 +1,54 @@
+package response
+
+import "time"
+
+// OrderStatusChangeResponse transición del historial de estados (HITO ORDER-FULFILLMENT)
+type OrderStatusChangeResponse struct {
+	ID         string    `json:"id"`
+	OrderID    string    `json:"order_id"`
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status"`
+	Actor      string    `json:"actor,omitempty"` // Vacío = sistema (vencimiento de reserva)
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// OrderStatusHistoryResponse historial de estados de una orden
+type OrderStatusHistoryResponse struct {
+	OrderID string                      `json:"order_id"`
+	Status  string                      `json:"status"`
+	History []OrderStatusChangeResponse `json:"history"`
+}
+
+// ShipmentResponse envío de una orden (HITO ORDER-FULFILLMENT)
+type ShipmentResponse struct {
+	ID             string                 `json:"id"`
+	OrderID        string                 `json:"order_id"`
+	Carrier        string                 `json:"carrier"`
+	TrackingNumber string                 `json:"tracking_number,omitempty"`
+	ShippedBy      string                 `json:"shipped_by,omitempty"`
+	ShippedAt      time.Time              `json:"shipped_at"`
+	Items          []ShipmentItemResponse `json:"items"`
+}
+
+// ShipmentItemResponse unidades de una línea despachadas en el envío
+type ShipmentItemResponse struct {
+	OrderItemID string `json:"order_item_id"`
+	SKU         string `json:"sku"`
+	Quantity    int    `json:"quantity"`
+}
+
+// CreateShipmentResponse envío registrado y estado resultante de la orden
+type CreateShipmentResponse struct {
+	Shipment     ShipmentResponse `json:"shipment"`
+	OrderStatus  string           `json:"order_status"`
+	FullyShipped bool             `json:"fully_shipped"`
+}
+
+// ListShipmentsResponse envíos de una orden
+type ListShipmentsResponse struct {
+	OrderID      string             `json:"order_id"`
+	OrderStatus  string             `json:"order_status"`
+	FullyShipped bool               `json:"fully_shipped"`
+	Shipments    []ShipmentResponse `json:"shipments"`
+}
@@ -11,6 +11,7 @@ import (
 // HITO B - Actualizado para multi-item
 type PosSaleListItem struct {
 	ID              uuid.UUID       `json:"id"`
+	SaleNumber      string          `json:"sale_number"`      // HITO POS-NUMBER: ticket PPPP-NNNNNNNN
 	CustomerID      *uuid.UUID      `json:"customer_id,omitempty"`
 	PaymentMethodID uuid.UUID       `json:"payment_method_id"`
 	TotalAmount     decimal.Decimal `json:"total_amount"`     // Suma de subtotales
@@ -19,4 +20,36 @@ type PosSaleListItem struct {
 	Currency        string          `json:"currency"`
 	TotalItems      int             `json:"total_items"`      // Cantidad de items
 	CreatedAt       time.Time       `json:"created_at"`
+
+	// HITO POS-REFUND - Estado tras anulaciones / devoluciones
+	Status         string          `json:"status"`
+	RefundedAmount decimal.Decimal `json:"refunded_amount"`
+
+	// HITO TAX-IVA - Neto gravado e IVA (final_amount = bruto)
+	NetAmount decimal.Decimal `json:"net_amount"`
+	TaxAmount decimal.Decimal `json:"tax_amount"`
+
+	// HITO CUSTOMER - Comprador (ausente en ventas previas al snapshot)
+	Customer *CustomerResponse `json:"customer,omitempty"`
+}
+
+// PosSaleListResponse página de GET /pos/sales (HITO POS-SEARCH)
+// next_cursor / prev_cursor ausentes = no hay más ventas en ese sentido con esos filtros
+type PosSaleListResponse struct {
+	Items      []*PosSaleListItem      `json:"items"`
+	PageSize   int                     `json:"page_size"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+	PrevCursor string                  `json:"prev_cursor,omitempty"`
+	Summary    *PosSaleSummaryResponse `json:"summary,omitempty"`
+}
+
+// PosSaleSummaryResponse totales del conjunto filtrado completo (summary=true)
+type PosSaleSummaryResponse struct {
+	SalesCount     int             `json:"sales_count"`
+	TotalAmount    decimal.Decimal `json:"total_amount"`    // Suma de subtotales
+	DiscountAmount decimal.Decimal `json:"discount_amount"` // Descuentos aplicados
+	NetAmount      decimal.Decimal `json:"net_amount"`      // Neto gravado
+	TaxAmount      decimal.Decimal `json:"tax_amount"`      // IVA
+	FinalAmount    decimal.Decimal `json:"final_amount"`    // Bruto cobrado
+	RefundedAmount decimal.Decimal `json:"refunded_amount"` // Devuelto / anulado
 }
@@ -0,0 +1,39 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PosSaleRefundItemResponse representa una línea devuelta
+type PosSaleRefundItemResponse struct {
+	PosSaleItemID    uuid.UUID       `json:"pos_sale_item_id"`
+	SKU              string          `json:"sku"`
+	Quantity         int             `json:"quantity"`
+	Subtotal         decimal.Decimal `json:"subtotal"`
+	RefundAmount     decimal.Decimal `json:"refund_amount"`
+	StockEntryID     uuid.UUID       `json:"stock_entry_id"`
+	StockCompensated bool            `json:"stock_compensated"` // false = pendiente: la saga POS_REFUND la reintenta
+}
+
+// PosSaleRefundResponse respuesta de anulación / devolución de venta POS
+// HITO POS-REFUND
+type PosSaleRefundResponse struct {
+	RefundID           uuid.UUID                   `json:"refund_id"`
+	PosSaleID          uuid.UUID                   `json:"pos_sale_id"`
+	RefundType         string                      `json:"refund_type"` // VOID | REFUND
+	Reason             string                      `json:"reason"`
+	RefundAmount       decimal.Decimal             `json:"refund_amount"`
+	PaymentMethodID    uuid.UUID                   `json:"payment_method_id"`
+	PaymentMethodName  string                      `json:"payment_method_name"`
+	Currency           string                      `json:"currency"`
+	CashSessionID      *uuid.UUID                  `json:"cash_session_id,omitempty"`
+	RefundedBy         string                      `json:"refunded_by,omitempty"`
+	Items              []PosSaleRefundItemResponse `json:"items"`
+	SaleStatus         string                      `json:"sale_status"`           // Estado de la venta tras la devolución
+	SaleRefundedAmount decimal.Decimal             `json:"sale_refunded_amount"`  // Total devuelto acumulado
+	CreditNote         *CreditNoteResponse         `json:"credit_note,omitempty"` // HITO CREDIT-NOTE - Solo si la venta estaba facturada
+	CreatedAt          time.Time                   `json:"created_at"`
+}
@@ -10,31 +10,60 @@ import (
 // POSSaleItemResponse representa un item en la respuesta de venta POS
 // HITO B - Multi-item support
 type POSSaleItemResponse struct {
-	ItemID         uuid.UUID       `json:"item_id"`
-	SKU            string          `json:"sku"`
-	ProductName    string          `json:"product_name"`
-	Quantity       int             `json:"quantity"`
-	UnitPrice      decimal.Decimal `json:"unit_price"`
-	Subtotal       decimal.Decimal `json:"subtotal"`
-	StockEntryID   uuid.UUID       `json:"stock_entry_id"`
+	ItemID       uuid.UUID       `json:"item_id"`
+	SKU          string          `json:"sku"`
+	ProductName  string          `json:"product_name"`
+	Quantity     int             `json:"quantity"`
+	UnitPrice    decimal.Decimal `json:"unit_price"`
+	Subtotal     decimal.Decimal `json:"subtotal"`
+	StockEntryID uuid.UUID       `json:"stock_entry_id"`
+
+	// HITO POS-PRICE - Precio de catálogo y marca de override
+	ListPrice       decimal.Decimal `json:"list_price"`
+	PriceOverridden bool            `json:"price_overridden"`
+
+	// HITO TAX-IVA - Desglose de la línea (con el descuento prorrateado)
+	TaxRateCode string          `json:"tax_rate_code"`
+	TaxRate     decimal.Decimal `json:"tax_rate"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	GrossAmount decimal.Decimal `json:"gross_amount"`
+}
+
+// POSSalePaymentResponse representa un pago en la respuesta de venta POS
+// HITO POS-SPLIT - Pagos combinados
+type POSSalePaymentResponse struct {
+	PaymentMethodID   uuid.UUID       `json:"payment_method_id"`
+	PaymentMethodName string          `json:"payment_method_name"`
+	Amount            decimal.Decimal `json:"amount"`
+	ChangeAmount      decimal.Decimal `json:"change_amount"`
+	Reference         *string         `json:"reference,omitempty"`
+	IsCash            bool            `json:"is_cash"`
 }
 
 // POSSaleResponse respuesta de venta directa POS multi-item
 // HITO B - Refactorizado para multi-item + descuentos
 // HITO: POST /pos/sale devuelve DTO listo para imprimir
 type POSSaleResponse struct {
-	PosSaleID         uuid.UUID              `json:"pos_sale_id"`
-	SaleNumber        string                 `json:"sale_number"`       // UUID como número de venta
-	Items             []POSSaleItemResponse  `json:"items"`
-	TotalItems        int                    `json:"total_items"`
-	SubtotalAmount    decimal.Decimal        `json:"subtotal_amount"`   // Suma de subtotales (antes: total_amount)
-	DiscountAmount    decimal.Decimal        `json:"discount_amount"`   // Descuento aplicado
-	FinalAmount       decimal.Decimal        `json:"final_amount"`      // Total - descuento
-	PaymentMethodID   uuid.UUID              `json:"payment_method_id"`
-	PaymentMethodName string                 `json:"payment_method_name"` // Nombre legible del método
-	AmountPaid        decimal.Decimal        `json:"amount_paid"`       // Monto pagado
-	Change            decimal.Decimal        `json:"change"`            // Vuelto
-	Currency          string                 `json:"currency"`
-	CustomerID        *uuid.UUID             `json:"customer_id,omitempty"`
-	CreatedAt         time.Time              `json:"created_at"`
+	PosSaleID         uuid.UUID                `json:"pos_sale_id"`
+	SaleNumber        string                   `json:"sale_number"`          // HITO POS-NUMBER: ticket PPPP-NNNNNNNN
+	PointOfSaleNumber int                      `json:"point_of_sale_number"` // Prefijo del ticket
+	PosNumber         int                      `json:"pos_number"`           // Correlativo del punto de venta
+	Items             []POSSaleItemResponse    `json:"items"`
+	TotalItems        int                      `json:"total_items"`
+	SubtotalAmount    decimal.Decimal          `json:"subtotal_amount"`     // Suma de subtotales (antes: total_amount)
+	DiscountAmount    decimal.Decimal          `json:"discount_amount"`     // Descuento aplicado
+	FinalAmount       decimal.Decimal          `json:"final_amount"`        // Total - descuento (+ IVA si los precios no lo incluyen)
+	Tax               TaxSummaryResponse       `json:"tax"`                 // HITO TAX-IVA: neto / IVA por alícuota
+	PaymentMethodID   uuid.UUID                `json:"payment_method_id"`   // Método principal (primer pago)
+	PaymentMethodName string                   `json:"payment_method_name"` // Nombre legible del método
+	Payments          []POSSalePaymentResponse `json:"payments"`            // HITO POS-SPLIT
+	AmountPaid        decimal.Decimal          `json:"amount_paid"`         // Monto pagado
+	Change            decimal.Decimal          `json:"change"`              // Vuelto
+	Currency          string                   `json:"currency"`
+	CustomerID        *uuid.UUID               `json:"customer_id,omitempty"`
+	Customer          *CustomerResponse        `json:"customer,omitempty"`         // HITO CUSTOMER: snapshot del comprador
+	PointOfSaleID     *uuid.UUID               `json:"point_of_sale_id,omitempty"` // HITO POS-CASH
+	CashSessionID     *uuid.UUID               `json:"cash_session_id,omitempty"`
+	CreatedAt         time.Time                `json:"created_at"`
 }
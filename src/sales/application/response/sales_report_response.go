@@ -0,0 +1,47 @@
+package response
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// SalesReportAmounts importes y cantidad de transacciones de un bucket
+// HITO SALES-REPORT - Mismo criterio que el resumen de GET /pos/sales
+type SalesReportAmounts struct {
+	TransactionCount int             `json:"transaction_count"`
+	GrossAmount      decimal.Decimal `json:"gross_amount"`    // Total con IVA (POS final_amount, orden total_amount)
+	DiscountAmount   decimal.Decimal `json:"discount_amount"` // Descuentos (las órdenes no tienen)
+	NetAmount        decimal.Decimal `json:"net_amount"`      // Neto gravado
+	TaxAmount        decimal.Decimal `json:"tax_amount"`      // IVA
+}
+
+// Add suma los importes de otro bucket
+func (a SalesReportAmounts) Add(other SalesReportAmounts) SalesReportAmounts {
+	return SalesReportAmounts{
+		TransactionCount: a.TransactionCount + other.TransactionCount,
+		GrossAmount:      a.GrossAmount.Add(other.GrossAmount),
+		DiscountAmount:   a.DiscountAmount.Add(other.DiscountAmount),
+		NetAmount:        a.NetAmount.Add(other.NetAmount),
+		TaxAmount:        a.TaxAmount.Add(other.TaxAmount),
+	}
+}
+
+// SalesReportBucket totales de un grupo del reporte
+// Key según group_by: YYYY-MM-DD (day, week = lunes), YYYY-MM (month), 00-23 (hour),
+// payment_method_id o point_of_sale_id ("" = sin asignar, ej. órdenes)
+type SalesReportBucket struct {
+	Key    string             `json:"key"`
+	Label  string             `json:"label,omitempty"` // Nombre del método de pago o "Unassigned"
+	Pos    SalesReportAmounts `json:"pos"`
+	Orders SalesReportAmounts `json:"orders"`
+	Total  SalesReportAmounts `json:"total"` // pos + orders
+}
+
+// SalesReportResponse representa el reporte de ventas de un rango de fechas
+// HITO SALES-REPORT - Ventas POS + órdenes agrupadas por fecha, hora, método de pago o punto de venta
+type SalesReportResponse struct {
+	From    string              `json:"from"` // YYYY-MM-DD
+	To      string              `json:"to"`   // YYYY-MM-DD (incluido)
+	GroupBy string              `json:"group_by"`
+	Buckets []SalesReportBucket `json:"buckets"`
+	Totals  SalesReportBucket   `json:"totals"` // Del rango completo (una venta con pago combinado cuenta una vez)
+}
@@ -0,0 +1,24 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SequenceResponse representa una secuencia de numeración
+// HITO SEQ-TX - Administración de secuencias
+type SequenceResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	DocumentType  string     `json:"document_type"`
+	ScopeID       *uuid.UUID `json:"scope_id,omitempty"`
+	CurrentNumber int        `json:"current_number"` // Último número asignado
+	NextNumber    int        `json:"next_number"`    // Próximo número a emitir
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ListSequencesResponse respuesta del listado de secuencias de un tenant
+type ListSequencesResponse struct {
+	TenantID  uuid.UUID          `json:"tenant_id"`
+	Sequences []SequenceResponse `json:"sequences"`
+}
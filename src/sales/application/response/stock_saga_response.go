@@ -0,0 +1,43 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockSagaStepResponse representa un descuento de stock de la saga
+// HITO STOCK-SAGA - Sagas de stock trabadas
+type StockSagaStepResponse struct {
+	Sequence      int        `json:"sequence"`
+	StepType      string     `json:"step_type"` // SALE | RESERVATION
+	SKU           string     `json:"sku"`
+	Quantity      float64    `json:"quantity"`
+	StockEntryID  string     `json:"stock_entry_id"`
+	Status        string     `json:"status"` // APPLIED | COMPENSATED
+	LastError     string     `json:"last_error,omitempty"`
+	CompensatedAt *time.Time `json:"compensated_at,omitempty"`
+}
+
+// StockSagaResponse representa una saga de stock sin terminar
+type StockSagaResponse struct {
+	ID                 uuid.UUID               `json:"id"`
+	TenantID           string                  `json:"tenant_id"`
+	SagaType           string                  `json:"saga_type"`
+	Reference          string                  `json:"reference"`
+	Status             string                  `json:"status"`
+	CompensationReason string                  `json:"compensation_reason,omitempty"`
+	Attempts           int                     `json:"attempts"`
+	NextAttemptAt      time.Time               `json:"next_attempt_at"`
+	LastError          string                  `json:"last_error,omitempty"`
+	PendingSteps       int                     `json:"pending_steps"`
+	CreatedAt          time.Time               `json:"created_at"`
+	UpdatedAt          time.Time               `json:"updated_at"`
+	Steps              []StockSagaStepResponse `json:"steps"`
+}
+
+// ListStockSagasResponse respuesta del listado de sagas trabadas
+type ListStockSagasResponse struct {
+	Sagas []StockSagaResponse `json:"sagas"`
+	Total int                 `json:"total"`
+}
@@ -0,0 +1,52 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TaxBreakdownResponse desglose neto / IVA / bruto de una alícuota
+// HITO TAX-IVA
+type TaxBreakdownResponse struct {
+	RateCode    string          `json:"rate_code"` // IVA_21 | IVA_10_5 | IVA_27 | EXENTO
+	Rate        decimal.Decimal `json:"rate"`      // Porcentaje (21, 10.5, 27, 0)
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	GrossAmount decimal.Decimal `json:"gross_amount"`
+}
+
+// TaxSummaryResponse totales impositivos de una venta
+// HITO TAX-IVA - gross_amount coincide con el total cobrado
+type TaxSummaryResponse struct {
+	PriceMode   string                 `json:"price_mode"` // INCLUDED | EXCLUDED
+	NetAmount   decimal.Decimal        `json:"net_amount"`
+	TaxAmount   decimal.Decimal        `json:"tax_amount"`
+	GrossAmount decimal.Decimal        `json:"gross_amount"`
+	ByRate      []TaxBreakdownResponse `json:"by_rate"`
+}
+
+// TaxSettingsResponse configuración de IVA del tenant
+type TaxSettingsResponse struct {
+	TenantID        uuid.UUID  `json:"tenant_id"`
+	PriceMode       string     `json:"price_mode"`
+	DefaultRateCode string     `json:"default_rate_code"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"` // Vacío = configuración por defecto
+}
+
+// TaxRuleResponse regla de alícuota por SKU o categoría
+type TaxRuleResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Scope      string    `json:"scope"`
+	ScopeValue string    `json:"scope_value"`
+	RateCode   string    `json:"rate_code"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ListTaxRulesResponse respuesta del listado de reglas de un tenant
+type ListTaxRulesResponse struct {
+	TenantID uuid.UUID         `json:"tenant_id"`
+	Rules    []TaxRuleResponse `json:"rules"`
+}
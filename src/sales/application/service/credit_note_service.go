@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CreditNoteService emite notas de crédito contra la factura de una venta
+// HITO CREDIT-NOTE - Compartido por la cancelación de órdenes y la devolución POS
+// La nota se numera, persiste y registra sales.credit_note.issued (HITO OUTBOX) dentro
+// de la transacción de la reversión: o se confirman todas o ninguna
+type CreditNoteService struct {
+	invoiceRepo     port.InvoiceRepository
+	creditNoteRepo  port.CreditNoteRepository
+	sequenceService *SequenceService
+	outbox          *OutboxService
+}
+
+// NewCreditNoteService crea una nueva instancia del servicio
+func NewCreditNoteService(
+	invoiceRepo port.InvoiceRepository,
+	creditNoteRepo port.CreditNoteRepository,
+	sequenceService *SequenceService,
+	outbox *OutboxService,
+) *CreditNoteService {
+	return &CreditNoteService{
+		invoiceRepo:     invoiceRepo,
+		creditNoteRepo:  creditNoteRepo,
+		sequenceService: sequenceService,
+		outbox:          outbox,
+	}
+}
+
+// IssueTx emite la nota de crédito de una reversión dentro de la transacción del llamador
+// Retorna nil sin error si la venta no fue facturada o su factura fue rechazada
+// buildItems arma las líneas revertidas a partir de la factura original
+func (s *CreditNoteService) IssueTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	tenantID uuid.UUID,
+	sourceType entity.InvoiceSourceType,
+	sourceID uuid.UUID,
+	reversalID *uuid.UUID,
+	reason, issuedBy string,
+	buildItems func(invoice *entity.Invoice) []entity.CreditNoteItem,
+) (*entity.CreditNote, error) {
+	invoice, err := s.invoiceRepo.FindBySource(ctx, tenantID, sourceType, sourceID)
+	if err == entity.ErrInvoiceNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !invoice.IsCreditable() {
+		log.Printf("⚠️ Invoice %s is REJECTED, no credit note for %s %s", invoice.ID, sourceType, sourceID)
+		return nil, nil
+	}
+
+	note, err := entity.NewCreditNote(invoice, reversalID, reason, issuedBy, buildItems(invoice))
+	if err != nil {
+		return nil, err
+	}
+
+	number, err := s.sequenceService.NextNumberTx(ctx, tx, tenantID.String(), entity.DocumentTypeCreditNote)
+	if err != nil {
+		return nil, fmt.Errorf("error getting credit note number: %w", err)
+	}
+	note.AssignNumber(number)
+
+	if err := s.creditNoteRepo.Create(ctx, note); err != nil {
+		return nil, err
+	}
+
+	if err := s.enqueueIssued(ctx, note); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Credit note %s issued against invoice %s: total=%s items=%d",
+		note.FormattedNumber(), invoice.ID, note.TotalAmount, len(note.Items))
+
+	return note, nil
+}
+
+// enqueueIssued registra sales.credit_note.issued en el outbox (tx de la reversión)
+func (s *CreditNoteService) enqueueIssued(ctx context.Context, note *entity.CreditNote) error {
+	if s.outbox == nil {
+		return nil
+	}
+
+	items := make([]map[string]interface{}, 0, len(note.Items))
+	for _, item := range note.Items {
+		line := map[string]interface{}{
+			"sku":      item.SKU,
+			"quantity": item.Quantity,
+			"amount":   item.Amount.InexactFloat64(),
+		}
+		if item.SourceItemID != nil {
+			line["source_item_id"] = item.SourceItemID.String()
+		}
+		items = append(items, line)
+	}
+
+	payload := map[string]interface{}{
+		"credit_note_id":       note.ID.String(),
+		"credit_note_number":   note.CreditNoteNumber,
+		"formatted_number":     note.FormattedNumber(),
+		"credit_note_type":     string(note.CreditNoteType),
+		"point_of_sale_number": note.PointOfSaleNumber,
+		"invoice_id":           note.InvoiceID.String(),
+		"source_type":          string(note.SourceType),
+		"source_id":            note.SourceID.String(),
+		"reason":               note.Reason,
+		"currency":             note.Currency,
+		"net_amount":           note.NetAmount.InexactFloat64(),
+		"tax_amount":           note.TaxAmount.InexactFloat64(),
+		"total_amount":         note.TotalAmount.InexactFloat64(),
+		"items":                items,
+		"issued_by":            note.IssuedBy,
+	}
+	if note.ReversalID != nil {
+		payload["reversal_id"] = note.ReversalID.String()
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sales.credit_note.issued: %w", err)
+	}
+
+	return s.outbox.Enqueue(
+		ctx,
+		note.ID.String(),           // aggregateID
+		"credit_note",              // aggregateType
+		"sales.credit_note.issued", // eventType
+		payloadBytes,               // payload (solo datos de negocio)
+	)
+}
+
+// CreditNoteItemsFromPosRefund líneas de la nota a partir de una devolución POS
+// Cada línea revierte el monto efectivamente devuelto (con descuento prorrateado)
+func CreditNoteItemsFromPosRefund(refund *entity.PosSaleRefund) []entity.CreditNoteItem {
+	items := make([]entity.CreditNoteItem, 0, len(refund.Items))
+	for _, refunded := range refund.Items {
+		sourceItemID := refunded.PosSaleItemID
+		items = append(items, entity.CreditNoteItem{
+			SourceItemID: &sourceItemID,
+			SKU:          refunded.SKU,
+			Quantity:     refunded.Quantity,
+			Amount:       refunded.RefundAmount,
+		})
+	}
+	return items
+}
+
+// CreditNoteItemsFromOrder líneas de la nota a partir de una orden cancelada
+// La cancelación revierte lo que queda de la factura: el total se reparte según
+// el bruto de cada línea (HITO ORDER-PRICE). Órdenes previas sin precio por línea
+// se reparten por cantidad. La última línea absorbe el redondeo
+// HITO ORDER-CANCEL - Solo las unidades vigentes: las canceladas ya se acreditaron
+func CreditNoteItemsFromOrder(order *entity.Order, invoiceTotal decimal.Decimal) []entity.CreditNoteItem {
+	var active []entity.OrderItem
+	for _, item := range order.Items {
+		if item.ActiveQuantity() > 0 {
+			active = append(active, item)
+		}
+	}
+
+	weights := make([]decimal.Decimal, len(active))
+	totalWeight := decimal.Zero
+	for i, item := range active {
+		weights[i] = item.GrossAmount
+		totalWeight = totalWeight.Add(item.GrossAmount)
+	}
+	if !totalWeight.IsPositive() {
+		totalWeight = decimal.Zero
+		for i, item := range active {
+			weights[i] = decimal.NewFromInt(int64(item.ActiveQuantity()))
+			totalWeight = totalWeight.Add(weights[i])
+		}
+	}
+	if !totalWeight.IsPositive() {
+		return nil
+	}
+
+	items := make([]entity.CreditNoteItem, 0, len(active))
+	allocated := decimal.Zero
+	for i, item := range active {
+		amount := invoiceTotal.Mul(weights[i]).Div(totalWeight).Round(2)
+		if i == len(active)-1 {
+			amount = invoiceTotal.Sub(allocated)
+		}
+		allocated = allocated.Add(amount)
+
+		line := entity.CreditNoteItem{
+			SKU:      item.SKU,
+			Quantity: item.ActiveQuantity(),
+			Amount:   amount,
+		}
+		if sourceItemID, err := uuid.Parse(item.ItemID); err == nil {
+			line.SourceItemID = &sourceItemID
+		}
+		items = append(items, line)
+	}
+	return items
+}
+
+// CreditNoteItemsFromOrderLineCancellation líneas de la nota a partir de una cancelación
+// parcial de la orden (HITO ORDER-CANCEL): cada línea acredita el bruto que se canceló
+func CreditNoteItemsFromOrderLineCancellation(cancellation *entity.OrderLineCancellation) []entity.CreditNoteItem {
+	items := make([]entity.CreditNoteItem, 0, len(cancellation.Items))
+	for _, canceled := range cancellation.Items {
+		line := entity.CreditNoteItem{
+			SKU:      canceled.SKU,
+			Quantity: canceled.Quantity,
+			Amount:   canceled.GrossAmount,
+		}
+		if sourceItemID, err := uuid.Parse(canceled.ItemID); err == nil {
+			line.SourceItemID = &sourceItemID
+		}
+		items = append(items, line)
+	}
+	return items
+}
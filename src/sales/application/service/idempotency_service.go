@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+const (
+	// idempotencyWaitTimeout tiempo máximo que un duplicado espera al request en curso
+	idempotencyWaitTimeout = 30 * time.Second
+	idempotencyPollMin     = 100 * time.Millisecond
+	idempotencyPollMax     = time.Second
+)
+
+// IdempotencyService administra las claves Idempotency-Key de los endpoints de venta
+// HITO IDEMPOTENCY - Un reintento del terminal no vuelve a descontar stock ni duplica
+// la venta: recibe la respuesta original. Los duplicados concurrentes se serializan:
+// esperan a que termine el primero y reproducen su respuesta
+type IdempotencyService struct {
+	idempotencyRepo port.IdempotencyRepository
+}
+
+// NewIdempotencyService crea una nueva instancia del servicio
+func NewIdempotencyService(idempotencyRepo port.IdempotencyRepository) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+	}
+}
+
+// Begin toma la clave para el request o retorna la respuesta guardada
+// acquired = true: el request es dueño de la clave y debe ejecutarse (luego Complete o Release)
+// acquired = false: record trae la respuesta COMPLETED a reproducir
+// Errores: ErrInvalidIdempotencyKey, ErrIdempotencyKeyMismatch (mismo key, otro body),
+// ErrIdempotencyKeyInProgress (el primero no terminó dentro de la espera)
+func (s *IdempotencyService) Begin(ctx context.Context, tenantID, key, requestHash string) (*entity.IdempotencyRecord, bool, error) {
+	record, err := entity.NewIdempotencyRecord(tenantID, key, requestHash)
+	if err != nil {
+		return nil, false, err
+	}
+
+	deadline := time.Now().Add(idempotencyWaitTimeout)
+	poll := idempotencyPollMin
+	for {
+		acquired, err := s.idempotencyRepo.Acquire(ctx, record)
+		if err != nil {
+			return nil, false, err
+		}
+		if acquired {
+			return record, true, nil
+		}
+
+		existing, err := s.idempotencyRepo.Find(ctx, tenantID, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing == nil {
+			// Liberada o vencida entre las dos sentencias: volver a intentar tomarla
+			continue
+		}
+		if !existing.Matches(requestHash) {
+			return nil, false, entity.ErrIdempotencyKeyMismatch
+		}
+		if existing.Status == entity.IdempotencyStatusCompleted {
+			return existing, false, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, false, entity.ErrIdempotencyKeyInProgress
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-time.After(poll):
+		}
+		if poll *= 2; poll > idempotencyPollMax {
+			poll = idempotencyPollMax
+		}
+	}
+}
+
+// Complete guarda la respuesta final para reproducirla en los reintentos
+func (s *IdempotencyService) Complete(ctx context.Context, record *entity.IdempotencyRecord, status int, body []byte) error {
+	record.Complete(status, body)
+	return s.idempotencyRepo.Complete(ctx, record)
+}
+
+// Release libera la clave sin respuesta (error transitorio: el cliente puede reintentar)
+func (s *IdempotencyService) Release(ctx context.Context, record *entity.IdempotencyRecord) error {
+	return s.idempotencyRepo.Release(ctx, record)
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+// OutboxPublisher identifica a este servicio como emisor de los eventos
+const OutboxPublisher = "order-service"
+
+// OutboxService registra eventos de negocio en el outbox transaccional
+// HITO OUTBOX - Reemplaza la publicación best-effort al eventbus: el evento se escribe
+// en la transacción del contexto (junto con la venta) y el relay lo reenvía después
+type OutboxService struct {
+	outboxRepo port.OutboxRepository
+}
+
+// NewOutboxService crea una nueva instancia del servicio
+func NewOutboxService(outboxRepo port.OutboxRepository) *OutboxService {
+	return &OutboxService{
+		outboxRepo: outboxRepo,
+	}
+}
+
+// Enqueue agrega un evento al outbox dentro de la transacción del contexto
+// Un error debe abortar la transacción: sin evento no hay cambio de negocio
+func (s *OutboxService) Enqueue(ctx context.Context, aggregateID, aggregateType, eventType string, payload []byte) error {
+	event, err := entity.NewOutboxEvent(aggregateID, aggregateType, eventType, payload, OutboxPublisher)
+	if err != nil {
+		return fmt.Errorf("invalid %s event: %w", eventType, err)
+	}
+
+	return s.outboxRepo.Append(ctx, event)
+}
@@ -6,9 +6,15 @@ import (
 	"fmt"
 	"log"
 	"time"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/shared/infrastructure/database"
+
+	"github.com/google/uuid"
 )
 
-// SequenceService gestiona numeración secuencial con optimistic locking
+// SequenceService gestiona numeración secuencial por tenant y tipo de documento
+// HITO SEQ-TX - NextNumberTx numera dentro de la transacción del documento (sin huecos)
 type SequenceService struct {
 	db *sql.DB
 }
@@ -22,22 +28,30 @@ func NewSequenceService(db *sql.DB) *SequenceService {
 
 // NextNumber obtiene el siguiente número de secuencia para un tipo de documento
 // Implementa optimistic locking con retry automático
+//
+// Deprecated: el número se confirma fuera de la transacción del documento y se pierde
+// si ésta falla (deja huecos). Usar NextNumberTx.
 func (s *SequenceService) NextNumber(ctx context.Context, tenantID, documentType string) (int, error) {
 	const maxRetries = 5
-	
+
+	// HITO SEQ-TX: Crear la secuencia si el tenant todavía no la tiene
+	if err := s.ensureSequence(ctx, s.db, tenantID, documentType, nil); err != nil {
+		return 0, err
+	}
+
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		number, err := s.tryGetNextNumber(ctx, tenantID, documentType)
-		
+
 		if err == nil {
 			// Éxito
 			return number, nil
 		}
-		
+
 		// Si no es error de concurrencia, fallar inmediatamente
 		if err != ErrConcurrentUpdate {
 			return 0, err
 		}
-		
+
 		// Retry con backoff exponencial
 		if attempt < maxRetries-1 {
 			backoff := time.Duration(attempt+1) * 10 * time.Millisecond
@@ -45,7 +59,7 @@ func (s *SequenceService) NextNumber(ctx context.Context, tenantID, documentType
 			time.Sleep(backoff)
 		}
 	}
-	
+
 	return 0, fmt.Errorf("failed to get next number after %d retries", maxRetries)
 }
 
@@ -55,33 +69,33 @@ func (s *SequenceService) tryGetNextNumber(ctx context.Context, tenantID, docume
 	querySel := `
 		SELECT current_number, version
 		FROM document_sequences
-		WHERE tenant_id = $1 AND document_type = $2
+		WHERE tenant_id = $1 AND document_type = $2 AND scope_id IS NULL
 	`
-	
+
 	var currentNumber int
 	var version int
-	
+
 	err := s.db.QueryRowContext(ctx, querySel, tenantID, documentType).Scan(&currentNumber, &version)
-	
+
 	if err == sql.ErrNoRows {
 		return 0, fmt.Errorf("sequence not found for tenant %s, document_type %s", tenantID, documentType)
 	}
-	
+
 	if err != nil {
 		return 0, fmt.Errorf("error reading sequence: %w", err)
 	}
-	
+
 	// 2. Calcular nuevo número
 	newNumber := currentNumber + 1
 	newVersion := version + 1
-	
+
 	// 3. Actualizar con optimistic locking (WHERE version = oldVersion)
 	queryUpd := `
 		UPDATE document_sequences
 		SET current_number = $1, version = $2, updated_at = $3
-		WHERE tenant_id = $4 AND document_type = $5 AND version = $6
+		WHERE tenant_id = $4 AND document_type = $5 AND scope_id IS NULL AND version = $6
 	`
-	
+
 	result, err := s.db.ExecContext(
 		ctx,
 		queryUpd,
@@ -92,26 +106,243 @@ func (s *SequenceService) tryGetNextNumber(ctx context.Context, tenantID, docume
 		documentType,
 		version, // WHERE version = oldVersion
 	)
-	
+
 	if err != nil {
 		return 0, fmt.Errorf("error updating sequence: %w", err)
 	}
-	
+
 	// 4. Verificar si se actualizó (optimistic locking check)
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return 0, fmt.Errorf("error checking rows affected: %w", err)
 	}
-	
+
 	if rowsAffected == 0 {
 		// Otro proceso actualizó la secuencia (version cambió)
 		return 0, ErrConcurrentUpdate
 	}
-	
+
 	log.Printf("✅ Sequence assigned: tenant=%s, type=%s, number=%d, version=%d", tenantID, documentType, newNumber, newVersion)
-	
+
+	return newNumber, nil
+}
+
+// NextNumberTx obtiene el siguiente número de secuencia dentro de la transacción del llamador
+// HITO SEQ-TX - Sin huecos:
+// - SELECT ... FOR UPDATE bloquea la fila hasta el commit / rollback del documento
+// - si la tx hace rollback el número vuelve atrás y lo toma el siguiente documento
+// - la fila de la secuencia se crea en 0 si no existe (tenant nuevo)
+func (s *SequenceService) NextNumberTx(ctx context.Context, tx *sql.Tx, tenantID, documentType string) (int, error) {
+	return s.nextNumberTx(ctx, tx, tenantID, documentType, nil)
+}
+
+// NextScopedNumberTx igual que NextNumberTx para una secuencia con scope
+// HITO POS-NUMBER - Ej: POS_SALE por punto de venta
+func (s *SequenceService) NextScopedNumberTx(ctx context.Context, tx *sql.Tx, tenantID, documentType string, scopeID uuid.UUID) (int, error) {
+	return s.nextNumberTx(ctx, tx, tenantID, documentType, &scopeID)
+}
+
+// nextNumberTx incrementa la secuencia (scopeID nil = secuencia única del tenant)
+func (s *SequenceService) nextNumberTx(ctx context.Context, tx *sql.Tx, tenantID, documentType string, scopeID *uuid.UUID) (int, error) {
+	if err := s.ensureSequence(ctx, tx, tenantID, documentType, scopeID); err != nil {
+		return 0, err
+	}
+
+	querySel := `
+		SELECT current_number
+		FROM document_sequences
+		WHERE tenant_id = $1 AND document_type = $2 AND scope_id IS NOT DISTINCT FROM $3
+		FOR UPDATE
+	`
+
+	var currentNumber int
+	if err := tx.QueryRowContext(ctx, querySel, tenantID, documentType, nullableScope(scopeID)).Scan(&currentNumber); err != nil {
+		return 0, fmt.Errorf("error locking sequence: %w", err)
+	}
+
+	newNumber := currentNumber + 1
+
+	queryUpd := `
+		UPDATE document_sequences
+		SET current_number = $1, version = version + 1, updated_at = NOW()
+		WHERE tenant_id = $2 AND document_type = $3 AND scope_id IS NOT DISTINCT FROM $4
+	`
+
+	if _, err := tx.ExecContext(ctx, queryUpd, newNumber, tenantID, documentType, nullableScope(scopeID)); err != nil {
+		return 0, fmt.Errorf("error updating sequence: %w", err)
+	}
+
+	log.Printf("✅ Sequence reserved (tx): tenant=%s, type=%s, scope=%s, number=%d", tenantID, documentType, scopeLabel(scopeID), newNumber)
+
 	return newNumber, nil
 }
 
+// ensureSequence crea la fila de la secuencia en 0 si no existe
+// HITO SEQ-TX - Onboarding de tenants sin INSERT manual
+func (s *SequenceService) ensureSequence(ctx context.Context, exec database.DBTX, tenantID, documentType string, scopeID *uuid.UUID) error {
+	query := `
+		INSERT INTO document_sequences (tenant_id, document_type, scope_id, current_number, version, updated_at)
+		VALUES ($1, $2, $3, 0, 1, NOW())
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := exec.ExecContext(ctx, query, tenantID, documentType, nullableScope(scopeID)); err != nil {
+		return fmt.Errorf("error provisioning sequence %s for tenant %s: %w", documentType, tenantID, err)
+	}
+
+	return nil
+}
+
+// List retorna las secuencias de un tenant (opcionalmente filtradas por tipo)
+// HITO SEQ-TX - Administración de secuencias
+func (s *SequenceService) List(ctx context.Context, tenantID uuid.UUID, documentType string) ([]entity.DocumentSequence, error) {
+	query := `
+		SELECT id, tenant_id, document_type, scope_id, current_number, version, updated_at
+		FROM document_sequences
+		WHERE tenant_id = $1 AND ($2 = '' OR document_type = $2)
+		ORDER BY document_type, scope_id NULLS FIRST
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, tenantID, documentType)
+	if err != nil {
+		return nil, fmt.Errorf("error querying document_sequences: %w", err)
+	}
+	defer rows.Close()
+
+	sequences := make([]entity.DocumentSequence, 0)
+	for rows.Next() {
+		var seq entity.DocumentSequence
+		if err := rows.Scan(
+			&seq.ID,
+			&seq.TenantID,
+			&seq.DocumentType,
+			&seq.ScopeID,
+			&seq.CurrentNumber,
+			&seq.Version,
+			&seq.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning document_sequence: %w", err)
+		}
+		sequences = append(sequences, seq)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document_sequences: %w", err)
+	}
+
+	return sequences, nil
+}
+
+// Seed crea o ajusta una secuencia fijando el último número asignado
+// HITO SEQ-TX - Ej: continuar la numeración de un sistema anterior
+// No permite retroceder (volvería a emitir números ya usados)
+func (s *SequenceService) Seed(ctx context.Context, tenantID uuid.UUID, documentType string, scopeID *uuid.UUID, currentNumber int) (*entity.DocumentSequence, error) {
+	if !entity.IsValidDocumentType(documentType) {
+		return nil, entity.ErrInvalidDocumentType
+	}
+	if currentNumber < 0 {
+		return nil, entity.ErrInvalidSequenceNumber
+	}
+
+	var seq entity.DocumentSequence
+	err := database.RunInTx(ctx, s.db, func(ctx context.Context, tx *sql.Tx) error {
+		if err := s.ensureSequence(ctx, tx, tenantID.String(), documentType, scopeID); err != nil {
+			return err
+		}
+
+		var lastNumber int
+		querySel := `
+			SELECT current_number
+			FROM document_sequences
+			WHERE tenant_id = $1 AND document_type = $2 AND scope_id IS NOT DISTINCT FROM $3
+			FOR UPDATE
+		`
+		if err := tx.QueryRowContext(ctx, querySel, tenantID, documentType, nullableScope(scopeID)).Scan(&lastNumber); err != nil {
+			return fmt.Errorf("error locking sequence: %w", err)
+		}
+
+		if currentNumber < lastNumber {
+			return entity.ErrSequenceRewind
+		}
+
+		queryUpd := `
+			UPDATE document_sequences
+			SET current_number = $1, version = version + 1, updated_at = NOW()
+			WHERE tenant_id = $2 AND document_type = $3 AND scope_id IS NOT DISTINCT FROM $4
+			RETURNING id, tenant_id, document_type, scope_id, current_number, version, updated_at
+		`
+		return tx.QueryRowContext(ctx, queryUpd, currentNumber, tenantID, documentType, nullableScope(scopeID)).Scan(
+			&seq.ID,
+			&seq.TenantID,
+			&seq.DocumentType,
+			&seq.ScopeID,
+			&seq.CurrentNumber,
+			&seq.Version,
+			&seq.UpdatedAt,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Sequence seeded: tenant=%s, type=%s, scope=%s, current_number=%d", tenantID, documentType, scopeLabel(scopeID), currentNumber)
+
+	return &seq, nil
+}
+
+// scopeLabel representa el scope para logs
+func scopeLabel(scopeID *uuid.UUID) string {
+	if scopeID == nil {
+		return "-"
+	}
+	return scopeID.String()
+}
+
+// nullableScope convierte el scope opcional en parámetro SQL (NULL = sin scope)
+func nullableScope(scopeID *uuid.UUID) uuid.NullUUID {
+	if scopeID == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *scopeID, Valid: true}
+}
+
+// PointOfSaleNumberTx retorna el número (prefijo de 4 dígitos) del punto de venta
+// HITO POS-NUMBER - Si el terminal no está registrado se le asigna el siguiente número libre del tenant
+func (s *SequenceService) PointOfSaleNumberTx(ctx context.Context, tx *sql.Tx, tenantID string, pointOfSaleID uuid.UUID) (int, error) {
+	const maxAttempts = 3
+
+	querySel := `
+		SELECT number
+		FROM points_of_sale
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	// ON CONFLICT DO NOTHING: si otro terminal tomó el mismo número en paralelo, se reintenta
+	queryIns := `
+		INSERT INTO points_of_sale (id, tenant_id, number, created_at)
+		SELECT $1, $2, COALESCE(MAX(number), 0) + 1, NOW()
+		FROM points_of_sale
+		WHERE tenant_id = $2
+		ON CONFLICT DO NOTHING
+	`
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		var number int
+		err := tx.QueryRowContext(ctx, querySel, pointOfSaleID, tenantID).Scan(&number)
+		if err == nil {
+			return number, nil
+		}
+		if err != sql.ErrNoRows {
+			return 0, fmt.Errorf("error reading point of sale: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, queryIns, pointOfSaleID, tenantID); err != nil {
+			return 0, fmt.Errorf("error registering point of sale: %w", err)
+		}
+	}
+
+	return 0, fmt.Errorf("could not register point of sale %s for tenant %s", pointOfSaleID, tenantID)
+}
+
 // ErrConcurrentUpdate indica que hubo una actualización concurrente
 var ErrConcurrentUpdate = fmt.Errorf("concurrent update detected")
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+// StockSagaService registra las sagas de stock y ejecuta sus compensaciones
+// HITO STOCK-SAGA - Reemplaza la compensación best-effort (solo log CRITICAL):
+// cada descuento queda registrado y una compensación fallida queda pendiente
+// para el worker de reintentos en lugar de perderse
+// Sin repositorio (desarrollo sin DB) compensa en memoria, sin reintentos
+type StockSagaService struct {
+	sagaRepo     port.StockSagaRepository
+	stockClient  port.StockGateway
+	handlersMu   sync.RWMutex
+	stepHandlers map[entity.StockSagaType]StockSagaStepHandler
+}
+
+// reversalLease margen para que el request compense una reversión antes que el worker
+const reversalLease = 2 * time.Minute
+
+// StockSagaStepHandler reacciona a la compensación de un paso (ej. marcar la línea devuelta)
+type StockSagaStepHandler func(ctx context.Context, saga *entity.StockSaga, step *entity.StockSagaStep) error
+
+// NewStockSagaService crea una nueva instancia del servicio
+func NewStockSagaService(sagaRepo port.StockSagaRepository, stockClient port.StockGateway) *StockSagaService {
+	return &StockSagaService{
+		sagaRepo:     sagaRepo,
+		stockClient:  stockClient,
+		stepHandlers: make(map[entity.StockSagaType]StockSagaStepHandler),
+	}
+}
+
+// OnStepCompensated registra el handler de los pasos compensados de un tipo de saga
+// Corre también desde el worker de reintentos; un error solo se loguea
+func (s *StockSagaService) OnStepCompensated(sagaType entity.StockSagaType, handler StockSagaStepHandler) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+	s.stepHandlers[sagaType] = handler
+}
+
+// Begin registra una saga STARTED antes del primer descuento de stock
+// Un error aborta la operación: sin log no se descuenta stock
+func (s *StockSagaService) Begin(ctx context.Context, tenantID string, sagaType entity.StockSagaType, reference string) (*entity.StockSaga, error) {
+	saga := entity.NewStockSaga(tenantID, sagaType, reference)
+	if s.sagaRepo == nil {
+		return saga, nil
+	}
+	if err := s.sagaRepo.Create(ctx, saga); err != nil {
+		return nil, fmt.Errorf("error starting stock saga: %w", err)
+	}
+	return saga, nil
+}
+
+// RecordStep registra un descuento aplicado por stock-service
+// Un error solo se loguea: el paso queda en memoria y se persiste con el próximo Update
+func (s *StockSagaService) RecordStep(ctx context.Context, saga *entity.StockSaga, sku string, quantity float64, stockEntryID string) {
+	step := saga.AddStep(sku, quantity, stockEntryID)
+	if s.sagaRepo == nil {
+		return
+	}
+	if err := s.sagaRepo.AppendStep(ctx, step); err != nil {
+		log.Printf("⚠️ Stock saga %s: error recording step %s: %v", saga.ID, stockEntryID, err)
+	}
+}
+
+// RecordReservation registra una reserva aplicada por stock-service (HITO ORDER-RESERVATION)
+// Si la orden no se persiste, la compensación libera la reserva por su referencia
+func (s *StockSagaService) RecordReservation(ctx context.Context, saga *entity.StockSaga, sku string, quantity float64, reference string) {
+	step := saga.AddReservationStep(sku, quantity, reference)
+	if s.sagaRepo == nil {
+		return
+	}
+	if err := s.sagaRepo.AppendStep(ctx, step); err != nil {
+		log.Printf("⚠️ Stock saga %s: error recording reservation %s: %v", saga.ID, reference, err)
+	}
+}
+
+// Complete cierra la saga dentro de la transacción del contexto
+// Debe ir en la misma tx que persiste la venta: una saga STARTED abandonada
+// significa que la venta no se persistió y su stock debe compensarse
+func (s *StockSagaService) Complete(ctx context.Context, saga *entity.StockSaga) error {
+	if err := saga.Complete(); err != nil {
+		return err
+	}
+	if s.sagaRepo == nil {
+		return nil
+	}
+	return s.sagaRepo.Update(ctx, saga)
+}
+
+// Compensate revierte los descuentos de una saga que no se completó
+// Lo que no se pueda revertir queda COMPENSATING para el worker de reintentos
+// No hereda la cancelación del request: un cliente que corta no debe dejar la saga a medias
+func (s *StockSagaService) Compensate(ctx context.Context, saga *entity.StockSaga, authToken, reason string) {
+	ctx = context.WithoutCancel(ctx)
+	if err := saga.BeginCompensation(reason); err != nil {
+		log.Printf("❌ CRITICAL: Stock saga %s cannot be compensated from %s: %v", saga.ID, saga.Status, err)
+		return
+	}
+
+	log.Printf("🔄 Compensating %d stock entries (saga %s). Reason: %s", saga.PendingSteps(), saga.ID, reason)
+	if err := s.CompensatePending(ctx, saga, authToken); err != nil {
+		log.Printf("❌ CRITICAL: Stock saga %s: %v", saga.ID, err)
+	}
+}
+
+// BeginReversal registra una saga que revierte descuentos ya confirmados (HITO POS-REFUND)
+// La saga nace COMPENSATING con un paso por stock_entry_id y se persiste en la tx del
+// contexto, junto con el documento que la origina. Quien la registra compensa después
+// del commit con CompensatePending; si el proceso cae antes, el worker la toma vencido
+// reversalLease
+func (s *StockSagaService) BeginReversal(ctx context.Context, saga *entity.StockSaga, reason string) error {
+	if err := saga.BeginCompensation(reason); err != nil {
+		return err
+	}
+	saga.DeferNextAttempt(reversalLease)
+	if s.sagaRepo == nil {
+		return nil
+	}
+	if err := s.sagaRepo.Create(ctx, saga); err != nil {
+		return fmt.Errorf("error starting stock reversal saga: %w", err)
+	}
+	// Update persiste motivo y pasos
+	return s.sagaRepo.Update(ctx, saga)
+}
+
+// CompensateCompleted revierte la saga COMPLETED de una operación que se anula después
+// de persistida (HITO ORDER-CANCEL - orden previa a las reservas cancelada en CREATED)
+// Sin saga registrada no hay qué revertir: retorna ErrOrderStockNotCompensable
+func (s *StockSagaService) CompensateCompleted(ctx context.Context, tenantID string, sagaType entity.StockSagaType, reference, authToken, reason string) error {
+	if s.sagaRepo == nil {
+		return entity.ErrOrderStockNotCompensable
+	}
+	saga, err := s.sagaRepo.FindCompletedByReference(ctx, tenantID, sagaType, reference)
+	if err != nil {
+		return err
+	}
+	if saga == nil {
+		return entity.ErrOrderStockNotCompensable
+	}
+
+	s.Compensate(ctx, saga, authToken, reason)
+	return nil
+}
+
+// CompensatePending intenta revertir los pasos aún aplicados de una saga COMPENSATING
+// y persiste el resultado del intento (COMPENSATED, reintento con backoff o ESCALATED)
+func (s *StockSagaService) CompensatePending(ctx context.Context, saga *entity.StockSaga, authToken string) error {
+	var lastErr error
+	for i := range saga.Steps {
+		step := &saga.Steps[i]
+		if step.Status != entity.StockSagaStepApplied {
+			continue
+		}
+
+		if err := s.compensateStep(ctx, saga, step, authToken); err != nil {
+			log.Printf("❌ Stock saga %s: failed to compensate stock entry %s: %v", saga.ID, step.StockEntryID, err)
+			step.LastError = err.Error()
+			lastErr = err
+			continue
+		}
+
+		step.MarkCompensated()
+		log.Printf("✅ Compensated stock entry: %s", step.StockEntryID)
+
+		s.handlersMu.RLock()
+		handler, ok := s.stepHandlers[saga.SagaType]
+		s.handlersMu.RUnlock()
+		if ok {
+			if err := handler(ctx, saga, step); err != nil {
+				log.Printf("⚠️ Stock saga %s: stock entry %s compensated but handler failed: %v", saga.ID, step.StockEntryID, err)
+			}
+		}
+	}
+
+	if err := saga.FinishCompensationAttempt(lastErr); err != nil {
+		return err
+	}
+
+	switch saga.Status {
+	case entity.StockSagaStatusCompensating:
+		log.Printf("⚠️ Stock saga %s: %d steps pending, retry at %s (attempt %d/%d)",
+			saga.ID, saga.PendingSteps(), saga.NextAttemptAt.Format("15:04:05"), saga.Attempts, entity.MaxStockSagaAttempts)
+	case entity.StockSagaStatusEscalated:
+		log.Printf("🚨 CRITICAL: Stock saga %s ESCALATED, manual intervention required: %s", saga.ID, saga.LastError)
+	}
+
+	if s.sagaRepo == nil {
+		return nil
+	}
+	return s.sagaRepo.Update(ctx, saga)
+}
+
+// compensateStep revierte un paso según la operación que lo aplicó
+func (s *StockSagaService) compensateStep(ctx context.Context, saga *entity.StockSaga, step *entity.StockSagaStep, authToken string) error {
+	if step.StepType == entity.StockSagaStepReservation {
+		_, err := s.stockClient.ReleaseStock(ctx, saga.TenantID, authToken, step.SKU, int(step.Quantity), step.StockEntryID)
+		return err
+	}
+	return s.stockClient.CompensateSale(ctx, saga.TenantID, authToken, step.StockEntryID, saga.CompensationReason)
+}
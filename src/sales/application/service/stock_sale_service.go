@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+// DefaultStockSaleParallelism llamadas por ítem simultáneas cuando no hay venta en lote
+const DefaultStockSaleParallelism = 8
+
+// StockSaleRejectedError rechazo de negocio de una línea (stock insuficiente, SKU sin inicializar)
+type StockSaleRejectedError struct {
+	SKU     string
+	Message string
+}
+
+func (e *StockSaleRejectedError) Error() string {
+	return fmt.Sprintf("stock rejected for SKU %s: %s", e.SKU, e.Message)
+}
+
+// StockSaleService descuenta el stock de todas las líneas de una venta POS y reserva
+// el de las órdenes al crearse (ReserveLines, HITO ORDER-RESERVATION)
+// HITO STOCK-BATCH - Si stock-service soporta venta en lote (probe), una sola llamada
+// atómica; si no, ProcessSaleAtomic por línea con paralelismo acotado. En ambos casos
+// cada descuento queda como paso de la saga y la compensación es responsabilidad del caller
+type StockSaleService struct {
+	stockClient port.StockGateway
+	sagaService *StockSagaService
+	parallelism int
+}
+
+// NewStockSaleService crea una nueva instancia del servicio
+// parallelism <= 0 usa DefaultStockSaleParallelism
+func NewStockSaleService(stockClient port.StockGateway, sagaService *StockSagaService, parallelism int) *StockSaleService {
+	if parallelism <= 0 {
+		parallelism = DefaultStockSaleParallelism
+	}
+	return &StockSaleService{
+		stockClient: stockClient,
+		sagaService: sagaService,
+		parallelism: parallelism,
+	}
+}
+
+// ProcessSale descuenta todas las líneas y retorna el resultado de cada una (mismo orden)
+// Error *StockSaleRejectedError = rechazo de negocio; cualquier otro error es técnico.
+// Con error, los pasos ya aplicados quedan en la saga para que el caller compense
+func (s *StockSaleService) ProcessSale(
+	ctx context.Context,
+	saga *entity.StockSaga,
+	authToken string,
+	lines []port.StockSaleLine,
+) ([]port.StockSaleResult, error) {
+	if len(lines) > 1 && s.stockClient.SupportsBatchSale(ctx, saga.TenantID, authToken) {
+		results, err := s.processBatch(ctx, saga, authToken, lines)
+		if !errors.Is(err, entity.ErrStockBatchNotSupported) {
+			return results, err
+		}
+		log.Printf("⚠️ stock-service rejected batch sale, falling back to per-item calls (saga %s)", saga.ID)
+	}
+	return s.processPerItem(ctx, saga, authToken, lines)
+}
+
+// processBatch una sola llamada atómica para todas las líneas
+func (s *StockSaleService) processBatch(
+	ctx context.Context,
+	saga *entity.StockSaga,
+	authToken string,
+	lines []port.StockSaleLine,
+) ([]port.StockSaleResult, error) {
+	log.Printf("📦 ProcessSaleBatch: %d lines (saga %s)", len(lines), saga.ID)
+
+	result, err := s.stockClient.ProcessSaleBatch(ctx, saga.TenantID, authToken, lines, saga.Reference)
+	if err != nil {
+		if errors.Is(err, entity.ErrStockBatchNotSupported) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error processing stock batch: %w", err)
+	}
+
+	if !result.Success {
+		for _, line := range result.Lines {
+			if !line.Success {
+				return nil, &StockSaleRejectedError{SKU: line.VariantSKU, Message: line.Message}
+			}
+		}
+		return nil, &StockSaleRejectedError{Message: result.Message}
+	}
+
+	// Registrar los pasos antes de validar la respuesta: lo descontado debe poder compensarse
+	for i, line := range result.Lines {
+		if line.StockEntryID == "" {
+			continue
+		}
+		quantity := line.QuantitySold
+		if i < len(lines) {
+			quantity = lines[i].Quantity
+		}
+		s.sagaService.RecordStep(ctx, saga, line.VariantSKU, quantity, line.StockEntryID)
+	}
+	if len(result.Lines) != len(lines) {
+		return nil, fmt.Errorf("stock-service batch returned %d lines for %d requested", len(result.Lines), len(lines))
+	}
+	for i, line := range result.Lines {
+		if line.StockEntryID == "" || line.VariantSKU != lines[i].VariantSKU {
+			return nil, fmt.Errorf("stock-service batch returned an invalid result for line %d (SKU %s)", i+1, lines[i].VariantSKU)
+		}
+	}
+
+	return result.Lines, nil
+}
+
+// processPerItem ProcessSaleAtomic por línea, con a lo sumo parallelism llamadas en vuelo
+func (s *StockSaleService) processPerItem(
+	ctx context.Context,
+	saga *entity.StockSaga,
+	authToken string,
+	lines []port.StockSaleLine,
+) ([]port.StockSaleResult, error) {
+	results := make([]port.StockSaleResult, len(lines))
+	var sagaMu sync.Mutex // AddStep no es seguro para uso concurrente
+
+	err := s.forEachLine(lines, func(i int, line port.StockSaleLine) error {
+		log.Printf("📦 ProcessSaleAtomic for item %d: SKU=%s, Qty=%.2f", i+1, line.VariantSKU, line.Quantity)
+		result, err := s.stockClient.ProcessSaleAtomic(ctx, saga.TenantID, authToken, line.VariantSKU, line.Quantity, line.Reference)
+		if err != nil {
+			log.Printf("❌ Stock service error for SKU %s: %v", line.VariantSKU, err)
+			return fmt.Errorf("error processing stock for SKU %s: %w", line.VariantSKU, err)
+		}
+		if !result.Success {
+			log.Printf("❌ Stock rejected for SKU %s: %s", line.VariantSKU, result.Message)
+			return &StockSaleRejectedError{SKU: line.VariantSKU, Message: result.Message}
+		}
+
+		results[i] = *result
+		sagaMu.Lock()
+		s.sagaService.RecordStep(ctx, saga, line.VariantSKU, line.Quantity, result.StockEntryID)
+		sagaMu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ReserveLines reserva el stock de todas las líneas de una orden (HITO ORDER-RESERVATION)
+// stock-service no tiene reserva en lote: ReserveStock por línea con el mismo paralelismo
+// acotado que la venta por ítem. Cada reserva queda como paso de la saga y con error el
+// caller compensa. Stock insuficiente → error que envuelve entity.ErrInsufficientStock
+func (s *StockSaleService) ReserveLines(
+	ctx context.Context,
+	saga *entity.StockSaga,
+	authToken string,
+	lines []port.StockSaleLine,
+) error {
+	var sagaMu sync.Mutex // AddReservationStep no es seguro para uso concurrente
+
+	return s.forEachLine(lines, func(i int, line port.StockSaleLine) error {
+		log.Printf("📦 ReserveStock for item %d: SKU=%s, Qty=%.0f", i+1, line.VariantSKU, line.Quantity)
+		_, err := s.stockClient.ReserveStock(ctx, saga.TenantID, authToken, line.VariantSKU, int(line.Quantity), line.Reference)
+		if err != nil {
+			log.Printf("❌ Stock reservation failed for SKU %s: %v", line.VariantSKU, err)
+			return fmt.Errorf("error reserving stock for SKU %s: %w", line.VariantSKU, err)
+		}
+
+		sagaMu.Lock()
+		s.sagaService.RecordReservation(ctx, saga, line.VariantSKU, line.Quantity, line.Reference)
+		sagaMu.Unlock()
+		return nil
+	})
+}
+
+// forEachLine ejecuta fn por línea con a lo sumo parallelism llamadas en vuelo y retorna
+// el error de la primera línea (en orden) que falló
+// Tras la primera falla no se inician más líneas; las que están en vuelo se esperan
+// (cancelarlas podría dejar un movimiento aplicado sin registrar en la saga)
+func (s *StockSaleService) forEachLine(lines []port.StockSaleLine, fn func(i int, line port.StockSaleLine) error) error {
+	errs := make([]error, len(lines))
+
+	var (
+		wg      sync.WaitGroup
+		failed  atomic.Bool
+		workers = make(chan struct{}, s.parallelism)
+	)
+
+	for i, line := range lines {
+		workers <- struct{}{}
+		if failed.Load() {
+			<-workers
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			if err := fn(i, line); err != nil {
+				errs[i] = err
+				failed.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/sales/infrastructure/stock"
+)
+
+// HITO ORDER-RESERVATION - Reservas de una orden contra FakeStockService
+
+func reservationLines(skus ...string) []port.StockSaleLine {
+	lines := make([]port.StockSaleLine, len(skus))
+	for i, sku := range skus {
+		lines[i] = port.StockSaleLine{VariantSKU: sku, Quantity: 2, Reference: fmt.Sprintf("ORDER-1-ITEM%d", i+1)}
+	}
+	return lines
+}
+
+func TestReserveLinesReservesWithBoundedParallelism(t *testing.T) {
+	fake := stock.NewFakeStockService()
+	// La latencia mantiene abiertas las llamadas para que se superpongan
+	fake.SetLatency(100 * time.Millisecond)
+	skus := []string{"SKU-A", "SKU-B", "SKU-C", "SKU-D", "SKU-E", "SKU-F"}
+	for _, sku := range skus {
+		fake.SetStock("tenant-1", sku, 10)
+	}
+
+	sagaService := NewStockSagaService(nil, fake)
+	stockSale := NewStockSaleService(fake, sagaService, 4)
+	saga := entity.NewStockSaga("tenant-1", entity.StockSagaTypeSalesOrder, "ORDER-1")
+
+	if err := stockSale.ReserveLines(context.Background(), saga, "", reservationLines(skus...)); err != nil {
+		t.Fatalf("ReserveLines: %v", err)
+	}
+	if got := fake.MaxInFlight(stock.FakeOpReserve); got != 4 {
+		t.Errorf("max reservations in flight = %d, want 4 (the parallelism limit)", got)
+	}
+
+	if got := len(saga.Steps); got != len(skus) {
+		t.Fatalf("saga steps = %d, want %d", got, len(skus))
+	}
+	for _, sku := range skus {
+		if level, _ := fake.Stock("tenant-1", sku); level.Available != 8 || level.Reserved != 2 {
+			t.Errorf("%s: available=%.0f reserved=%.0f, want 8 / 2", sku, level.Available, level.Reserved)
+		}
+	}
+}
+
+func TestReserveLinesInsufficientStockIsTypedAndCompensable(t *testing.T) {
+	fake := stock.NewFakeStockService()
+	fake.SetStock("tenant-1", "SKU-A", 10)
+	fake.SetStock("tenant-1", "SKU-B", 1)
+
+	sagaService := NewStockSagaService(nil, fake)
+	stockSale := NewStockSaleService(fake, sagaService, 1)
+	saga := entity.NewStockSaga("tenant-1", entity.StockSagaTypeSalesOrder, "ORDER-1")
+
+	err := stockSale.ReserveLines(context.Background(), saga, "", reservationLines("SKU-A", "SKU-B"))
+	if !errors.Is(err, entity.ErrInsufficientStock) {
+		t.Fatalf("ReserveLines error = %v, want entity.ErrInsufficientStock", err)
+	}
+
+	// La reserva de SKU-A quedó en la saga y la compensación la libera
+	sagaService.Compensate(context.Background(), saga, "", "insufficient_stock")
+	if saga.Status != entity.StockSagaStatusCompensated {
+		t.Errorf("saga status = %s, want %s", saga.Status, entity.StockSagaStatusCompensated)
+	}
+	if level, _ := fake.Stock("tenant-1", "SKU-A"); level.Available != 10 || level.Reserved != 0 {
+		t.Errorf("SKU-A: available=%.0f reserved=%.0f, want 10 / 0", level.Available, level.Reserved)
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// TaxService resuelve el perfil impositivo (IVA) del tenant
+// HITO TAX-IVA - Compartido por ventas POS y órdenes
+type TaxService struct {
+	taxRepo port.TaxRepository
+}
+
+// NewTaxService crea una nueva instancia del servicio
+// taxRepo nil (desarrollo sin DB) = configuración por defecto para todos los tenants
+func NewTaxService(taxRepo port.TaxRepository) *TaxService {
+	return &TaxService{
+		taxRepo: taxRepo,
+	}
+}
+
+// Profile retorna la configuración y las reglas vigentes del tenant
+func (s *TaxService) Profile(ctx context.Context, tenantID uuid.UUID) (*entity.TaxProfile, error) {
+	if s.taxRepo == nil {
+		return entity.NewTaxProfile(entity.DefaultTaxSettings(tenantID), nil), nil
+	}
+
+	settings, err := s.taxRepo.FindSettings(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading tax settings: %w", err)
+	}
+
+	rules, err := s.taxRepo.ListRules(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading tax rules: %w", err)
+	}
+
+	return entity.NewTaxProfile(settings, rules), nil
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"sales/src/sales/application/service"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/shared/infrastructure/database"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// fiscalAuthorityTimeout tiempo máximo por llamada al fisco
+	fiscalAuthorityTimeout = 30 * time.Second
+	// staleProcessingAfter comprobantes PROCESSING sin cambios se consideran abandonados
+	staleProcessingAfter = 5 * time.Minute
+)
+
+// AuthorizeInvoiceUseCase caso de uso para pedir el CAE de comprobantes
+// HITO FISCAL - PENDING → PROCESSING → APPROVED / REJECTED, reintento ante fallos transitorios
+type AuthorizeInvoiceUseCase struct {
+	invoiceRepo port.InvoiceRepository
+	authority   port.FiscalAuthority
+	outbox      *service.OutboxService // HITO OUTBOX
+	txManager   *database.TxManager
+}
+
+// NewAuthorizeInvoiceUseCase crea una nueva instancia del caso de uso
+func NewAuthorizeInvoiceUseCase(
+	invoiceRepo port.InvoiceRepository,
+	authority port.FiscalAuthority,
+	outbox *service.OutboxService,
+	txManager *database.TxManager,
+) *AuthorizeInvoiceUseCase {
+	return &AuthorizeInvoiceUseCase{
+		invoiceRepo: invoiceRepo,
+		authority:   authority,
+		outbox:      outbox,
+		txManager:   txManager,
+	}
+}
+
+// AuthorizeNow intenta autorizar un comprobante puntual (ej: recién emitido)
+// Si ya no está PENDING (lo tomó el worker) no hace nada
+func (uc *AuthorizeInvoiceUseCase) AuthorizeNow(ctx context.Context, invoiceID uuid.UUID) error {
+	invoice, err := uc.invoiceRepo.Claim(ctx, invoiceID)
+	if err != nil {
+		return err
+	}
+	if invoice == nil {
+		return nil
+	}
+	return uc.process(ctx, invoice)
+}
+
+// ProcessDue procesa hasta limit comprobantes con reintento vencido (lo invoca el worker)
+// Primero libera los PROCESSING abandonados por un proceso caído
+func (uc *AuthorizeInvoiceUseCase) ProcessDue(ctx context.Context, limit int) (int, error) {
+	released, err := uc.invoiceRepo.ReleaseStale(ctx, staleProcessingAfter)
+	if err != nil {
+		return 0, err
+	}
+	if released > 0 {
+		log.Printf("⚠️ Released %d stale PROCESSING invoices", released)
+	}
+
+	invoices, err := uc.invoiceRepo.ClaimDue(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, invoice := range invoices {
+		if err := uc.process(ctx, invoice); err != nil {
+			// Queda PROCESSING: ReleaseStale lo devuelve a PENDING
+			log.Printf("❌ Error processing invoice %s: %v", invoice.ID, err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// process pide la autorización de un comprobante PROCESSING y persiste el resultado
+func (uc *AuthorizeInvoiceUseCase) process(ctx context.Context, invoice *entity.Invoice) error {
+	result, err := uc.requestAuthorization(ctx, invoice)
+	switch {
+	case err != nil:
+		log.Printf("⚠️ Invoice %s: transient fiscal error (attempt %d/%d): %v",
+			invoice.ID, invoice.Attempts, entity.MaxInvoiceAttempts, err)
+		err = invoice.ScheduleRetry(err)
+	case !result.Approved:
+		err = invoice.Reject(result.RejectionReason)
+	default:
+		err = invoice.Approve(result.InvoiceNumber, result.CAE, result.CAEExpiresAt)
+	}
+	if err != nil {
+		return err
+	}
+
+	// HITO OUTBOX: estado del comprobante + evento (solo en estados finales) en una sola tx
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if err := uc.invoiceRepo.Update(ctx, invoice); err != nil {
+			return err
+		}
+		if invoice.FiscalStatus == entity.FiscalStatusPending {
+			return nil
+		}
+		return uc.enqueueInvoiceEvent(ctx, invoice)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("✅ Invoice %s: fiscal_status=%s number=%s attempts=%d",
+		invoice.ID, invoice.FiscalStatus, invoice.FormattedNumber(), invoice.Attempts)
+
+	return nil
+}
+
+// requestAuthorization numera el comprobante (último autorizado + 1) y pide el CAE
+func (uc *AuthorizeInvoiceUseCase) requestAuthorization(ctx context.Context, invoice *entity.Invoice) (*port.FiscalAuthorizationResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, fiscalAuthorityTimeout)
+	defer cancel()
+
+	last, err := uc.authority.LastAuthorizedNumber(ctx, invoice.IssuerCUIT, invoice.PointOfSaleNumber, invoice.InvoiceType)
+	if err != nil {
+		return nil, fmt.Errorf("error getting last authorized number: %w", err)
+	}
+
+	return uc.authority.Authorize(ctx, port.FiscalAuthorizationRequest{
+		Reference:            invoice.ID,
+		IssuerCUIT:           invoice.IssuerCUIT,
+		PointOfSaleNumber:    invoice.PointOfSaleNumber,
+		InvoiceType:          invoice.InvoiceType,
+		InvoiceNumber:        last + 1,
+		IssueDate:            time.Now(),
+		CustomerTaxCondition: invoice.CustomerTaxCondition,
+		CustomerTaxID:        invoice.CustomerTaxID,
+		Currency:             invoice.Currency,
+		NetAmount:            invoice.NetAmount,
+		TaxAmount:            invoice.TaxAmount,
+		TotalAmount:          invoice.TotalAmount,
+	})
+}
+
+// enqueueInvoiceEvent registra sales.invoice.approved / sales.invoice.rejected en el outbox
+func (uc *AuthorizeInvoiceUseCase) enqueueInvoiceEvent(ctx context.Context, invoice *entity.Invoice) error {
+	if uc.outbox == nil {
+		return nil
+	}
+
+	eventType := "sales.invoice.approved"
+	if invoice.FiscalStatus == entity.FiscalStatusRejected {
+		eventType = "sales.invoice.rejected"
+	}
+
+	payload := map[string]interface{}{
+		"invoice_id":             invoice.ID.String(),
+		"source_type":            string(invoice.SourceType),
+		"source_id":              invoice.SourceID.String(),
+		"invoice_type":           string(invoice.InvoiceType),
+		"point_of_sale_number":   invoice.PointOfSaleNumber,
+		"invoice_number":         invoice.InvoiceNumber,
+		"formatted_number":       invoice.FormattedNumber(),
+		"customer_tax_condition": string(invoice.CustomerTaxCondition),
+		"currency":               invoice.Currency,
+		"net_amount":             invoice.NetAmount.InexactFloat64(),
+		"tax_amount":             invoice.TaxAmount.InexactFloat64(),
+		"total_amount":           invoice.TotalAmount.InexactFloat64(),
+		"fiscal_status":          string(invoice.FiscalStatus),
+		"attempts":               invoice.Attempts,
+	}
+	if invoice.FiscalStatus == entity.FiscalStatusApproved {
+		payload["cae"] = invoice.CAE
+		payload["cae_expires_at"] = invoice.CAEExpiresAt
+	} else {
+		payload["rejection_reason"] = invoice.RejectionReason
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return uc.outbox.Enqueue(
+		ctx,
+		invoice.ID.String(), // aggregateID
+		"invoice",           // aggregateType
+		eventType,           // eventType
+		payloadBytes,        // payload (solo datos de negocio)
+	)
+}
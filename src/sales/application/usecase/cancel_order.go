@@ -2,55 +2,416 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"log"
+	"sales/src/sales/application/response"
+	"sales/src/sales/application/service"
 	"sales/src/sales/domain/entity"
 	"sales/src/sales/domain/port"
-	"sales/src/sales/infrastructure/client"
+	"sales/src/shared/infrastructure/database"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // CancelOrderUseCase caso de uso para cancelar una orden
+// HITO ORDER-CANCEL - Cancelación completa desde CREATED o CONFIRMED y cancelación
+// parcial de líneas de una orden confirmada
 type CancelOrderUseCase struct {
-	orderRepo   port.OrderRepository
-	stockClient *client.StockClient
+	orderRepo         port.OrderRepository
+	stockClient       port.StockGateway
+	creditNoteService *service.CreditNoteService // HITO CREDIT-NOTE
+	outbox            *service.OutboxService     // HITO OUTBOX
+	sagaService       *service.StockSagaService  // HITO ORDER-CANCEL: órdenes previas a las reservas
+	txManager         *database.TxManager
 }
 
 // NewCancelOrderUseCase crea una nueva instancia del caso de uso
-func NewCancelOrderUseCase(orderRepo port.OrderRepository, stockClient *client.StockClient) *CancelOrderUseCase {
+func NewCancelOrderUseCase(
+	orderRepo port.OrderRepository,
+	stockClient port.StockGateway,
+	creditNoteService *service.CreditNoteService,
+	outbox *service.OutboxService,
+	sagaService *service.StockSagaService,
+	txManager *database.TxManager,
+) *CancelOrderUseCase {
 	return &CancelOrderUseCase{
-		orderRepo:   orderRepo,
-		stockClient: stockClient,
+		orderRepo:         orderRepo,
+		stockClient:       stockClient,
+		creditNoteService: creditNoteService,
+		outbox:            outbox,
+		sagaService:       sagaService,
+		txManager:         txManager,
 	}
 }
 
 // Execute ejecuta la cancelación de la orden (multi-item, atómico)
-func (uc *CancelOrderUseCase) Execute(ctx context.Context, tenantID, authToken, orderID string) (*entity.Order, error) {
+// HITO CREDIT-NOTE - Si la orden estaba facturada se emite la nota de crédito en la misma tx
+// HITO ORDER-CANCEL - En CREATED libera las reservas; en CONFIRMED revierte el consumo
+func (uc *CancelOrderUseCase) Execute(ctx context.Context, tenantID, authToken, userID, orderID, reason string) (*response.CancelOrderResponse, error) {
 	// 1. Buscar orden con sus items (load aggregate)
 	order, err := uc.orderRepo.FindByID(ctx, orderID, tenantID)
 	if err != nil {
 		return nil, entity.ErrOrderNotFound
 	}
 
-	// 2. Validar que esté en estado CONFIRMED
-	if order.Status != entity.OrderStatusConfirmed {
-		return nil, entity.ErrOrderNotInConfirmedState
+	// 2. Validar estado y motivo (el aggregate queda CANCELED en memoria)
+	from := order.Status
+	if err := order.Cancel(reason, userID); err != nil {
+		return nil, err
+	}
+
+	// 3. Devolver el stock según el estado desde el que se cancela
+	switch from {
+	case entity.OrderStatusCreated:
+		err = uc.releaseCreatedStock(ctx, order, authToken)
+	default:
+		err = uc.revertConsumedStock(ctx, order, authToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Cancelar orden en DB (+ nota de crédito si estaba facturada)
+	creditNote, err := uc.cancelWithCreditNote(ctx, order, from)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🚫 Order %s canceled from %s by %q: %s", order.OrderID, from, order.CanceledBy, order.CancelReason)
+
+	resp := &response.CancelOrderResponse{
+		OrderID:      order.OrderID,
+		Status:       string(order.Status),
+		CancelReason: order.CancelReason,
+		CanceledBy:   order.CanceledBy,
+		CanceledAt:   order.CanceledAt,
+	}
+	if creditNote != nil {
+		resp.CreditNote = toCreditNoteResponse(creditNote)
+	}
+
+	return resp, nil
+}
+
+// releaseCreatedStock devuelve el stock de una orden sin confirmar
+// Con reservas (HITO ORDER-RESERVATION) se liberan; las órdenes previas descontaron
+// stock al crearse y se revierten compensando la saga de la creación
+func (uc *CancelOrderUseCase) releaseCreatedStock(ctx context.Context, order *entity.Order, authToken string) error {
+	if order.ReservationExpiresAt != nil {
+		if err := releaseOrderReservations(ctx, uc.stockClient, uc.orderRepo, order, authToken); err != nil {
+			return fmt.Errorf("error releasing stock reservations: %w", err)
+		}
+		return nil
 	}
 
-	// 3. Revertir consumo de stock para CADA item vía Kong
+	if uc.sagaService == nil {
+		return entity.ErrOrderStockNotCompensable
+	}
+	return uc.sagaService.CompensateCompleted(
+		ctx,
+		order.TenantID,
+		entity.StockSagaTypeSalesOrder,
+		order.OrderID,
+		authToken,
+		"order canceled: "+order.CancelReason,
+	)
+}
+
+// revertConsumedStock revierte el consumo de las unidades vigentes de una orden confirmada
+func (uc *CancelOrderUseCase) revertConsumedStock(ctx context.Context, order *entity.Order, authToken string) error {
 	for _, item := range order.Items {
-		_, err = uc.stockClient.RevertConsume(tenantID, authToken, item.SKU, item.Quantity, orderID)
+		quantity := item.ActiveQuantity()
+		if quantity == 0 {
+			continue // HITO ORDER-CANCEL: línea cancelada entera, su stock ya volvió
+		}
+		_, err := uc.stockClient.RevertConsume(ctx, order.TenantID, authToken, item.SKU, quantity, consumeReference(order, item))
 		if err != nil {
 			// Si falla un item, TODO el proceso falla
-			return nil, fmt.Errorf("error reverting stock for SKU %s: %w", item.SKU, err)
+			return fmt.Errorf("error reverting stock for SKU %s: %w", item.SKU, err)
+		}
+	}
+	return nil
+}
+
+// consumeReference referencia con la que se consumió el stock de la línea
+// HITO ORDER-RESERVATION: el consumo se hizo con la referencia de la reserva de la línea
+func consumeReference(order *entity.Order, item entity.OrderItem) string {
+	if item.HasReservation() {
+		return item.ReservationReference
+	}
+	return order.OrderID
+}
+
+// cancelWithCreditNote cancela la orden y emite la nota de crédito en una sola transacción
+// Sin servicio de notas de crédito solo cancela
+func (uc *CancelOrderUseCase) cancelWithCreditNote(ctx context.Context, order *entity.Order, from entity.OrderStatus) (*entity.CreditNote, error) {
+	// HITO ORDER-FULFILLMENT: la transición queda en el historial con la cancelación
+	change := entity.NewOrderStatusChange(order, from, order.CanceledBy, order.CancelReason)
+	if uc.creditNoteService == nil || uc.txManager == nil {
+		if err := uc.orderRepo.Cancel(ctx, order, from); err != nil {
+			return nil, err
+		}
+		return nil, uc.orderRepo.RecordStatusChange(ctx, change)
+	}
+
+	tenantUUID, err := uuid.Parse(order.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tenant_id: %w", err)
+	}
+	orderUUID, err := uuid.Parse(order.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order_id: %w", err)
+	}
+
+	var creditNote *entity.CreditNote
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if err := uc.orderRepo.Cancel(ctx, order, from); err != nil {
+			return err
+		}
+		if err := uc.orderRepo.RecordStatusChange(ctx, change); err != nil {
+			return err
+		}
+
+		var err error
+		creditNote, err = uc.creditNoteService.IssueTx(
+			ctx, tx,
+			tenantUUID,
+			entity.InvoiceSourceSalesOrder,
+			orderUUID,
+			nil, // cancelación: revierte lo que queda de la factura
+			"order canceled: "+order.CancelReason,
+			order.CanceledBy,
+			func(invoice *entity.Invoice) []entity.CreditNoteItem {
+				return service.CreditNoteItemsFromOrder(order, remainingInvoiceTotal(order, invoice))
+			},
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return creditNote, nil
+}
+
+// remainingInvoiceTotal importe de la factura aún no acreditado
+// HITO ORDER-CANCEL - Las cancelaciones parciales ya acreditaron su parte: queda el total vigente
+func remainingInvoiceTotal(order *entity.Order, invoice *entity.Invoice) decimal.Decimal {
+	if !order.HasCanceledLines() {
+		return invoice.TotalAmount
+	}
+	return decimal.Min(invoice.TotalAmount, order.TotalAmount)
+}
+
+// ExecuteLines cancela unidades de líneas de una orden confirmada
+// HITO ORDER-CANCEL - Revierte el consumo de las unidades canceladas, recalcula los
+// totales y, en una sola tx, persiste la cancelación, emite la nota de crédito parcial
+// (si la orden estaba facturada) y registra sales.order.line_canceled
+func (uc *CancelOrderUseCase) ExecuteLines(
+	ctx context.Context,
+	tenantID, authToken, userID, orderID string,
+	lines []entity.OrderLineCancel,
+	reason string,
+) (*response.CancelOrderLinesResponse, error) {
+	// 1. Buscar orden con sus items (load aggregate)
+	order, err := uc.orderRepo.FindByID(ctx, orderID, tenantID)
+	if err != nil {
+		return nil, entity.ErrOrderNotFound
+	}
+
+	// 2. Aplicar la cancelación al aggregate (valida y recalcula totales)
+	cancellation, err := order.CancelLines(lines, reason, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Revertir el consumo de stock de las unidades canceladas
+	itemsByID := make(map[string]entity.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		itemsByID[item.ItemID] = item
+	}
+	for _, line := range cancellation.Items {
+		item := itemsByID[line.ItemID]
+		_, err := uc.stockClient.RevertConsume(ctx, tenantID, authToken, line.SKU, line.Quantity, consumeReference(order, item))
+		if err != nil {
+			return nil, fmt.Errorf("error reverting stock for SKU %s: %w", line.SKU, err)
+		}
+	}
+
+	// 4. Persistir cancelación + nota de crédito + evento
+	creditNote, err := uc.persistLineCancellation(ctx, order, cancellation)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✂️ Order %s: %d lines canceled by %q (total=%s): %s",
+		order.OrderID, len(cancellation.Items), cancellation.CanceledBy, cancellation.TotalAmount, cancellation.Reason)
+
+	return toCancelOrderLinesResponse(order, cancellation, creditNote), nil
+}
+
+// persistLineCancellation persiste la cancelación parcial y su nota de crédito en una sola tx
+func (uc *CancelOrderUseCase) persistLineCancellation(
+	ctx context.Context,
+	order *entity.Order,
+	cancellation *entity.OrderLineCancellation,
+) (*entity.CreditNote, error) {
+	if uc.txManager == nil {
+		if err := uc.orderRepo.CancelLines(ctx, order, cancellation); err != nil {
+			return nil, err
 		}
+		return nil, uc.enqueueLineCanceled(ctx, order, cancellation, nil)
+	}
+
+	tenantUUID, err := uuid.Parse(order.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tenant_id: %w", err)
+	}
+	orderUUID, err := uuid.Parse(order.OrderID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order_id: %w", err)
 	}
 
-	// 4. Cancelar orden en DB
-	if err := uc.orderRepo.Cancel(ctx, orderID, tenantID); err != nil {
+	var creditNote *entity.CreditNote
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if err := uc.orderRepo.CancelLines(ctx, order, cancellation); err != nil {
+			return err
+		}
+
+		if uc.creditNoteService != nil {
+			var err error
+			creditNote, err = uc.creditNoteService.IssueTx(
+				ctx, tx,
+				tenantUUID,
+				entity.InvoiceSourceSalesOrder,
+				orderUUID,
+				&cancellation.ID, // cancelación parcial: la nota referencia el documento de cancelación
+				"order lines canceled: "+cancellation.Reason,
+				cancellation.CanceledBy,
+				func(invoice *entity.Invoice) []entity.CreditNoteItem {
+					return service.CreditNoteItemsFromOrderLineCancellation(cancellation)
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return uc.enqueueLineCanceled(ctx, order, cancellation, creditNote)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// 5. Actualizar entidad en memoria
-	order.Status = entity.OrderStatusCanceled
+	return creditNote, nil
+}
+
+// enqueueLineCanceled registra sales.order.line_canceled en el outbox (tx de la cancelación)
+func (uc *CancelOrderUseCase) enqueueLineCanceled(
+	ctx context.Context,
+	order *entity.Order,
+	cancellation *entity.OrderLineCancellation,
+	creditNote *entity.CreditNote,
+) error {
+	if uc.outbox == nil {
+		return nil
+	}
+
+	items := make([]map[string]interface{}, 0, len(cancellation.Items))
+	for _, item := range cancellation.Items {
+		items = append(items, map[string]interface{}{
+			"item_id":      item.ItemID,
+			"sku":          item.SKU,
+			"quantity":     item.Quantity,
+			"net_amount":   item.NetAmount.InexactFloat64(),
+			"tax_amount":   item.TaxAmount.InexactFloat64(),
+			"gross_amount": item.GrossAmount.InexactFloat64(),
+		})
+	}
+
+	payload := map[string]interface{}{
+		"cancellation_id": cancellation.ID.String(),
+		"order_id":        order.OrderID,
+		"tenant_id":       order.TenantID,
+		"reason":          cancellation.Reason,
+		"canceled_by":     cancellation.CanceledBy,
+		"canceled_amount": map[string]interface{}{
+			"net":   cancellation.NetAmount.InexactFloat64(),
+			"tax":   cancellation.TaxAmount.InexactFloat64(),
+			"total": cancellation.TotalAmount.InexactFloat64(),
+		},
+		"order_totals": map[string]interface{}{
+			"net":        order.NetAmount.InexactFloat64(),
+			"tax":        order.TaxAmount.InexactFloat64(),
+			"total":      order.TotalAmount.InexactFloat64(),
+			"price_mode": string(order.PriceMode),
+		},
+		"items":       items,
+		"occurred_at": cancellation.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if creditNote != nil {
+		payload["credit_note_id"] = creditNote.ID.String()
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
 
-	return order, nil
+	return uc.outbox.Enqueue(
+		ctx,
+		order.OrderID,               // aggregateID
+		"sales_order",               // aggregateType
+		"sales.order.line_canceled", // eventType
+		payloadBytes,                // payload (solo datos de negocio)
+	)
+}
+
+// toCancelOrderLinesResponse arma el DTO de respuesta de la cancelación parcial
+func toCancelOrderLinesResponse(
+	order *entity.Order,
+	cancellation *entity.OrderLineCancellation,
+	creditNote *entity.CreditNote,
+) *response.CancelOrderLinesResponse {
+	lines := make([]response.CanceledOrderLineResponse, 0, len(cancellation.Items))
+	for _, item := range cancellation.Items {
+		lines = append(lines, response.CanceledOrderLineResponse{
+			ItemID:      item.ItemID,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			NetAmount:   item.NetAmount,
+			TaxAmount:   item.TaxAmount,
+			GrossAmount: item.GrossAmount,
+		})
+	}
+
+	items := make([]response.OrderItemResponse, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, toOrderItemResponse(item))
+	}
+
+	resp := &response.CancelOrderLinesResponse{
+		CancellationID: cancellation.ID.String(),
+		OrderID:        order.OrderID,
+		Status:         string(order.Status),
+		Reason:         cancellation.Reason,
+		CanceledBy:     cancellation.CanceledBy,
+		CanceledLines:  lines,
+		CanceledNet:    cancellation.NetAmount,
+		CanceledTax:    cancellation.TaxAmount,
+		CanceledTotal:  cancellation.TotalAmount,
+		NetAmount:      order.NetAmount,
+		TaxAmount:      order.TaxAmount,
+		TotalAmount:    order.TotalAmount,
+		Items:          items,
+		CreatedAt:      cancellation.CreatedAt,
+	}
+	if creditNote != nil {
+		resp.CreditNote = toCreditNoteResponse(creditNote)
+	}
+	return resp
 }
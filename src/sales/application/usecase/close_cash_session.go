@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/sales/infrastructure/cache"
+	"sales/src/shared/infrastructure/database"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CloseCashSessionUseCase caso de uso para cerrar una sesión de caja con arqueo
+// HITO POS-CASH - Cierre de caja
+type CloseCashSessionUseCase struct {
+	cashSessionRepo    port.CashSessionRepository
+	paymentMethodCache *cache.PaymentMethodCache
+	txManager          *database.TxManager // nil = sin transacción (desarrollo sin DB)
+}
+
+// NewCloseCashSessionUseCase crea una nueva instancia del caso de uso
+func NewCloseCashSessionUseCase(cashSessionRepo port.CashSessionRepository, paymentMethodCache *cache.PaymentMethodCache, txManager *database.TxManager) *CloseCashSessionUseCase {
+	return &CloseCashSessionUseCase{
+		cashSessionRepo:    cashSessionRepo,
+		paymentMethodCache: paymentMethodCache,
+		txManager:          txManager,
+	}
+}
+
+// Execute cierra la sesión comparando lo contado contra lo vendido
+// 1. Bloquear sesión (debe estar OPEN)
+// 2. Agregar pos_sales de la sesión por método de pago
+// 3. Calcular esperado por método (+ fondo inicial para efectivo)
+// 4. Persistir cierre + arqueo
+// Todo en una transacción: con la fila bloqueada ninguna venta o devolución entra a la
+// sesión entre los totales y el cierre (ver PosSalePostgresRepository.create)
+func (uc *CloseCashSessionUseCase) Execute(ctx context.Context, tenantID, sessionID uuid.UUID, userID string, req *request.CloseCashSessionRequest) (*response.CashSessionResponse, error) {
+	var session *entity.CashSession
+	var totals []entity.CashSessionMethodTotal
+
+	closeSession := func(ctx context.Context) error {
+		// 1. Bloquear sesión
+		var err error
+		session, err = uc.cashSessionRepo.FindByIDForUpdate(ctx, tenantID, sessionID)
+		if err != nil {
+			return err
+		}
+		if !session.IsOpen() {
+			return entity.ErrCashSessionNotOpen
+		}
+
+		// 2. Totales vendidos por método de pago
+		totals, err = uc.cashSessionRepo.SalesTotalsByPaymentMethod(ctx, session.ID)
+		if err != nil {
+			return fmt.Errorf("error computing session totals: %w", err)
+		}
+
+		// 3. Construir arqueo
+		counts, err := uc.buildCounts(session, totals, req)
+		if err != nil {
+			return err
+		}
+
+		if err := session.Close(userID, req.Notes, counts); err != nil {
+			return err
+		}
+
+		// 4. Persistir
+		if err := uc.cashSessionRepo.Close(ctx, session); err != nil {
+			if err == entity.ErrCashSessionNotOpen {
+				return err
+			}
+			return fmt.Errorf("error closing cash session: %w", err)
+		}
+		return nil
+	}
+
+	var err error
+	if uc.txManager == nil {
+		err = closeSession(ctx)
+	} else {
+		err = uc.txManager.WithinTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+			return closeSession(ctx)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Cash session closed: ID=%s, POS=%s, Counts=%d", session.ID, session.PointOfSaleID, len(session.Counts))
+
+	return buildCashSessionResponse(session, totals, uc.paymentMethodCache), nil
+}
+
+// buildCounts arma el arqueo: una línea por método contado y una con contado = 0 por
+// cada método con movimientos en la sesión que no se contó (la diferencia no se pierde)
+func (uc *CloseCashSessionUseCase) buildCounts(session *entity.CashSession, totals []entity.CashSessionMethodTotal, req *request.CloseCashSessionRequest) ([]entity.CashCount, error) {
+	totalsByMethod := make(map[uuid.UUID]entity.CashSessionMethodTotal, len(totals))
+	for _, t := range totals {
+		totalsByMethod[t.PaymentMethodID] = t
+	}
+
+	counts := make([]entity.CashCount, 0, len(req.Counts)+len(totals))
+	seen := make(map[uuid.UUID]bool, len(req.Counts))
+	for _, countReq := range req.Counts {
+		if seen[countReq.PaymentMethodID] {
+			return nil, fmt.Errorf("duplicated count for payment_method_id %s", countReq.PaymentMethodID)
+		}
+		seen[countReq.PaymentMethodID] = true
+
+		expected := uc.expectedAmount(session, countReq.PaymentMethodID, totalsByMethod[countReq.PaymentMethodID].FinalAmount)
+		count, err := entity.NewCashCount(countReq.PaymentMethodID, countReq.CountedAmount, expected)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, *count)
+	}
+
+	for _, t := range totals {
+		if seen[t.PaymentMethodID] {
+			continue
+		}
+		expected := uc.expectedAmount(session, t.PaymentMethodID, t.FinalAmount)
+		count, err := entity.NewCashCount(t.PaymentMethodID, decimal.Zero, expected)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, *count)
+	}
+
+	return counts, nil
+}
+
+// expectedAmount calcula el monto esperado para un método de pago
+func (uc *CloseCashSessionUseCase) expectedAmount(session *entity.CashSession, paymentMethodID uuid.UUID, salesAmount decimal.Decimal) decimal.Decimal {
+	if uc.paymentMethodCache != nil && uc.paymentMethodCache.IsCash(paymentMethodID) {
+		return salesAmount.Add(session.OpeningFloat)
+	}
+	return salesAmount
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,36 +10,39 @@ import (
 	"sales/src/sales/application/service"
 	"sales/src/sales/domain/entity"
 	"sales/src/sales/domain/port"
-	"sales/src/sales/infrastructure/client"
-	
-	"github.com/mercadocercano/eventbus"
+	"sales/src/shared/infrastructure/database"
 )
 
 // ConfirmOrderUseCase caso de uso para confirmar una orden
 type ConfirmOrderUseCase struct {
 	orderRepo       port.OrderRepository
-	stockClient     *client.StockClient
-	publishUseCase  *eventbus.PublishEventUseCase
+	stockClient     port.StockGateway
+	outbox          *service.OutboxService // HITO OUTBOX
 	sequenceService *service.SequenceService
+	txManager       *database.TxManager
 }
 
 // NewConfirmOrderUseCase crea una nueva instancia del caso de uso
 func NewConfirmOrderUseCase(
 	orderRepo port.OrderRepository, 
-	stockClient *client.StockClient,
-	publishUseCase *eventbus.PublishEventUseCase,
+	stockClient port.StockGateway,
+	outbox *service.OutboxService,
 	sequenceService *service.SequenceService,
+	txManager *database.TxManager,
 ) *ConfirmOrderUseCase {
 	return &ConfirmOrderUseCase{
 		orderRepo:       orderRepo,
 		stockClient:     stockClient,
-		publishUseCase:  publishUseCase,
+		outbox:          outbox,
 		sequenceService: sequenceService,
+		txManager:       txManager,
 	}
 }
 
 // Execute ejecuta la confirmación de la orden (multi-item, atómico)
-func (uc *ConfirmOrderUseCase) Execute(ctx context.Context, tenantID, authToken, orderID, reference string) (*entity.Order, error) {
+// HITO ORDER-RESERVATION - Consume exactamente las reservas hechas al crear la orden
+// HITO ORDER-FULFILLMENT - userID queda como actor en el historial de estados
+func (uc *ConfirmOrderUseCase) Execute(ctx context.Context, tenantID, authToken, userID, orderID string) (*entity.Order, error) {
 	// 1. Buscar orden con sus items (load aggregate)
 	order, err := uc.orderRepo.FindByID(ctx, orderID, tenantID)
 	if err != nil {
@@ -50,80 +54,157 @@ func (uc *ConfirmOrderUseCase) Execute(ctx context.Context, tenantID, authToken,
 		return nil, entity.ErrOrderNotInCreatedState
 	}
 
-	// 3. Consumir stock reservado para CADA item vía Kong (ALL OR NOTHING)
+	// 3. HITO ORDER-RESERVATION: una orden vencida ya no tiene stock reservado
+	if order.ReservationExpired(time.Now()) {
+		return nil, entity.ErrOrderReservationExpired
+	}
+	// HITO ORDER-CANCEL: una cancelación en curso ya liberó reservas de la orden
 	for _, item := range order.Items {
-		_, err = uc.stockClient.ConsumeStock(tenantID, authToken, item.SKU, item.Quantity, reference)
+		if item.ReservationStatus == entity.ReservationStatusReleased {
+			return nil, entity.ErrOrderReservationReleased
+		}
+	}
+
+	// 4. Consumir la reserva de CADA item vía Kong
+	if err := uc.consumeReservations(ctx, authToken, order); err != nil {
+		return nil, err
+	}
+
+	// 5-7. HITO SEQ-TX + OUTBOX: Asignar número + confirmar + registrar sales.order.confirmed
+	// en una sola transacción. Si algo falla, el número vuelve atrás con el rollback (sin huecos)
+	if err := uc.confirmNumbered(ctx, tenantID, orderID, userID, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// consumeReservations consume la reserva de cada línea por su referencia
+// Cada consumo se persiste en la línea: si falla un item la orden sigue CREATED y un
+// reintento solo consume las reservas pendientes. Si en cambio vence, el worker de
+// reservas revierte lo consumido. Las líneas previas a las reservas no tienen nada
+// que consumir (su stock se descontó al crear la orden)
+// Antes de llamar a stock-service cada línea se toma con un UPDATE condicional
+// RESERVED → CONSUMING: de dos confirmaciones concurrentes solo una consume la línea y
+// la otra recibe ErrOrderConfirmInProgress. Una línea que quedó CONSUMING (el proceso
+// murió a mitad del consumo) la resuelve el vencimiento de la orden
+func (uc *ConfirmOrderUseCase) consumeReservations(ctx context.Context, authToken string, order *entity.Order) error {
+	for i := range order.Items {
+		item := &order.Items[i]
+		if !item.HasReservation() || item.ReservationStatus == entity.ReservationStatusConsumed {
+			continue
+		}
+		if item.ReservationStatus != entity.ReservationStatusReserved {
+			return entity.ErrOrderConfirmInProgress
+		}
+
+		claimed, err := uc.orderRepo.TransitionItemReservation(ctx, item.ItemID, entity.ReservationStatusReserved, entity.ReservationStatusConsuming)
 		if err != nil {
-			// Si falla un item, TODO el proceso falla
-			// Nota: En producción debería hacer rollback de items anteriores
+			return fmt.Errorf("error claiming reservation %s: %w", item.ReservationReference, err)
+		}
+		if !claimed {
+			return entity.ErrOrderConfirmInProgress
+		}
+		item.ReservationStatus = entity.ReservationStatusConsuming
+
+		if _, err := uc.stockClient.ConsumeStock(ctx, order.TenantID, authToken, item.SKU, item.Quantity, item.ReservationReference); err != nil {
+			// La reserva sigue intacta: la línea vuelve a RESERVED para un reintento
+			if _, revertErr := uc.orderRepo.TransitionItemReservation(context.WithoutCancel(ctx), item.ItemID, entity.ReservationStatusConsuming, entity.ReservationStatusReserved); revertErr != nil {
+				log.Printf("❌ Error releasing claim on reservation %s: %v", item.ReservationReference, revertErr)
+			}
+			item.ReservationStatus = entity.ReservationStatusReserved
 			if contains(err.Error(), "insufficient reserved stock") {
-				return nil, fmt.Errorf("insufficient_reserved_stock for SKU %s: %w", item.SKU, err)
+				return fmt.Errorf("insufficient_reserved_stock for SKU %s: %w", item.SKU, err)
 			}
-			return nil, fmt.Errorf("error consuming stock for SKU %s: %w", item.SKU, err)
+			return fmt.Errorf("error consuming stock for SKU %s: %w", item.SKU, err)
 		}
-	}
 
-	// 4. HITO v0.4: Asignar número de orden (con optimistic locking)
-	if uc.sequenceService != nil {
-		orderNumber, err := uc.sequenceService.NextNumber(ctx, tenantID, "SALES_ORDER")
+		// El consumo ya se aplicó: un cliente que corta no debe impedir registrarlo
+		consumed, err := uc.orderRepo.TransitionItemReservation(context.WithoutCancel(ctx), item.ItemID, entity.ReservationStatusConsuming, entity.ReservationStatusConsumed)
 		if err != nil {
-			return nil, fmt.Errorf("error assigning order number: %w", err)
+			return fmt.Errorf("error recording consumed reservation %s: %w", item.ReservationReference, err)
 		}
-		order.AssignOrderNumber(orderNumber)
-		log.Printf("✅ Order number assigned: %d", orderNumber)
+		if !consumed {
+			// El vencimiento o la cancelación liberaron la línea mientras se consumía
+			return entity.ErrOrderReservationReleased
+		}
+		item.ReservationStatus = entity.ReservationStatusConsumed
 	}
+	return nil
+}
 
-	// 5. Confirmar orden en DB
-	if err := uc.orderRepo.Confirm(ctx, orderID, tenantID); err != nil {
-		return nil, fmt.Errorf("error confirming order: %w", err)
+// confirmNumbered asigna order_number, confirma la orden y registra el evento en el outbox
+// dentro de la misma transacción
+// Sin SequenceService / TxManager (desarrollo sin DB) confirma sin numerar
+func (uc *ConfirmOrderUseCase) confirmNumbered(ctx context.Context, tenantID, orderID, userID string, order *entity.Order) error {
+	if uc.sequenceService == nil || uc.txManager == nil {
+		if err := uc.orderRepo.Confirm(ctx, orderID, tenantID); err != nil {
+			return fmt.Errorf("error confirming order: %w", err)
+		}
+		order.Status = entity.OrderStatusConfirmed
+		if err := uc.orderRepo.RecordStatusChange(ctx, entity.NewOrderStatusChange(order, entity.OrderStatusCreated, userID, "")); err != nil {
+			return err
+		}
+		return uc.enqueueSalesOrderConfirmedEvent(ctx, order, tenantID)
 	}
 
-	// 5b. HITO v0.4: Persistir order_number si fue asignado
-	if order.OrderNumber != nil {
-		if err := uc.orderRepo.UpdateOrderNumber(ctx, orderID, tenantID, *order.OrderNumber); err != nil {
-			log.Printf("WARNING: Failed to persist order_number: %v", err)
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		orderNumber, err := uc.sequenceService.NextNumberTx(ctx, tx, tenantID, entity.DocumentTypeSalesOrder)
+		if err != nil {
+			return fmt.Errorf("error assigning order number: %w", err)
 		}
-	}
 
-	// 6. Actualizar entidad en memoria
-	order.Status = entity.OrderStatusConfirmed
+		if err := uc.orderRepo.Confirm(ctx, orderID, tenantID); err != nil {
+			return fmt.Errorf("error confirming order: %w", err)
+		}
 
-	// 7. HITO v0.1: Publicar evento sales.order.confirmed
-	if uc.publishUseCase != nil {
-		if err := uc.publishSalesOrderConfirmedEvent(ctx, order, tenantID); err != nil {
-			// Log error pero NO fallar la operación (orden ya confirmada)
-			log.Printf("WARNING: Failed to publish sales.order.confirmed: %v", err)
+		if err := uc.orderRepo.UpdateOrderNumber(ctx, orderID, tenantID, orderNumber); err != nil {
+			return fmt.Errorf("error persisting order number: %w", err)
 		}
-	}
 
-	return order, nil
+		order.AssignOrderNumber(orderNumber)
+		order.Status = entity.OrderStatusConfirmed
+		log.Printf("✅ Order number assigned: %d", orderNumber)
+
+		// HITO ORDER-FULFILLMENT: la transición queda en el historial con la confirmación
+		if err := uc.orderRepo.RecordStatusChange(ctx, entity.NewOrderStatusChange(order, entity.OrderStatusCreated, userID, "")); err != nil {
+			return err
+		}
+
+		// HITO OUTBOX: el evento se confirma junto con la orden (lo reenvía el relay)
+		return uc.enqueueSalesOrderConfirmedEvent(ctx, order, tenantID)
+	})
 }
 
-// publishSalesOrderConfirmedEvent publica el evento sales.order.confirmed
-func (uc *ConfirmOrderUseCase) publishSalesOrderConfirmedEvent(
+// enqueueSalesOrderConfirmedEvent registra el evento sales.order.confirmed en el outbox
+func (uc *ConfirmOrderUseCase) enqueueSalesOrderConfirmedEvent(
 	ctx context.Context,
 	order *entity.Order,
 	tenantID string,
 ) error {
-	// HITO v0.1: Total hardcoded para testing (sin productos reales)
-	totalAmount := 250.00 // Monto fijo para validación E2E
+	// HITO v0.4: order_number se asigna en la misma tx (confirmNumbered); sin numeración
+	// (desarrollo sin DB) viaja null
+	var orderNumber interface{}
+	if order.OrderNumber != nil {
+		orderNumber = *order.OrderNumber
+	}
 
 	// Construir payload de negocio según contrato v1
 	businessPayload := map[string]interface{}{
-		"order_number": 0, // TODO: Implementar numeración secuencial
-		"customer": map[string]interface{}{
-			"customer_id":   "00000000-0000-0000-0000-000000000001", // TODO: Obtener customer_id real
-			"customer_name": "Cliente Genérico",
-			"tax_condition": "CONSUMIDOR_FINAL",
-		},
+		"order_number":  orderNumber,
+		"customer":      buildCustomerPayload(order.Customer), // HITO CUSTOMER: comprador del snapshot
 		"currency":      "ARS",
 		"exchange_rate": 1.0,
 		"totals": map[string]interface{}{
-			"subtotal": totalAmount,
-			"discount": 0.0,
-			"tax":      0.0,
-			"total":    totalAmount,
+			"subtotal":   order.Subtotal().InexactFloat64(), // HITO ORDER-PRICE: precios del snapshot de PIM
+			"discount":   0.0,
+			"net":        order.NetAmount.InexactFloat64(),
+			"tax":        order.TaxAmount.InexactFloat64(), // HITO TAX-IVA
+			"total":      order.TotalAmount.InexactFloat64(),
+			"price_mode": string(order.PriceMode),
 		},
+		"taxes": buildTaxesPayload(order.TaxSummary()), // HITO TAX-IVA: desglose por alícuota
+		"items": buildOrderItemsPayload(order),         // HITO ORDER-PRICE
 		"payment_terms": map[string]interface{}{
 			"type":     "CUENTA_CORRIENTE",
 			"due_date": time.Now().Add(30 * 24 * time.Hour).Format(time.RFC3339),
@@ -148,13 +229,37 @@ func (uc *ConfirmOrderUseCase) publishSalesOrderConfirmedEvent(
 		return fmt.Errorf("failed to marshal envelope: %w", err)
 	}
 
-	// Publicar usando eventbus
-	return uc.publishUseCase.Execute(
+	// HITO OUTBOX: se suma a la transacción del contexto
+	if uc.outbox == nil {
+		return nil
+	}
+	return uc.outbox.Enqueue(
 		ctx,
-		order.OrderID,            // aggregateID
-		"sales_order",            // aggregateType
-		"sales.order.confirmed",  // eventType
-		envelopeBytes,            // payload (envelope completo)
-		"order-service",          // publishedBy
+		order.OrderID,           // aggregateID
+		"sales_order",           // aggregateType
+		"sales.order.confirmed", // eventType
+		envelopeBytes,           // payload (envelope completo)
 	)
 }
+
+// buildOrderItemsPayload arma las líneas de la orden para sales.order.confirmed
+// HITO ORDER-PRICE - Precio unitario, subtotal y desglose impositivo por línea
+func buildOrderItemsPayload(order *entity.Order) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, map[string]interface{}{
+			"item_id":          item.ItemID,
+			"sku":              item.SKU,
+			"quantity":         item.Quantity,
+			"unit_price":       item.UnitPrice.InexactFloat64(),
+			"price_overridden": item.PriceOverridden,
+			"subtotal":         item.Subtotal.InexactFloat64(),
+			"tax_rate_code":    string(item.TaxRateCode),
+			"tax_rate":         item.TaxRate.InexactFloat64(),
+			"net_amount":       item.NetAmount.InexactFloat64(),
+			"tax_amount":       item.TaxAmount.InexactFloat64(),
+			"gross_amount":     item.GrossAmount.InexactFloat64(),
+		})
+	}
+	return items
+}
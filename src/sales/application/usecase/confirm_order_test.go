@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"sales/src/sales/application/service"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/sales/infrastructure/stock"
+)
+
+// HITO ORDER-RESERVATION - Dos confirmaciones concurrentes consumen cada reserva una sola vez
+
+// confirmOrderRepo guarda el estado de la orden como la DB: cada FindByID retorna una copia
+// y las transiciones de reserva son condicionales
+type confirmOrderRepo struct {
+	port.OrderRepository
+	mu    sync.Mutex
+	order entity.Order
+}
+
+func (r *confirmOrderRepo) FindByID(ctx context.Context, orderID, tenantID string) (*entity.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	order := r.order
+	order.Items = append([]entity.OrderItem(nil), r.order.Items...)
+	return &order, nil
+}
+
+func (r *confirmOrderRepo) TransitionItemReservation(ctx context.Context, itemID string, from, to entity.ReservationStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.order.Items {
+		item := &r.order.Items[i]
+		if item.ItemID == itemID && item.ReservationStatus == from {
+			item.ReservationStatus = to
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *confirmOrderRepo) Confirm(ctx context.Context, orderID, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.order.Status != entity.OrderStatusCreated {
+		return entity.ErrOrderNotInCreatedState
+	}
+	r.order.Status = entity.OrderStatusConfirmed
+	return nil
+}
+
+func (r *confirmOrderRepo) RecordStatusChange(ctx context.Context, change *entity.OrderStatusChange) error {
+	return nil
+}
+
+func TestConcurrentConfirmsConsumeEachReservationOnce(t *testing.T) {
+	fake := stock.NewFakeStockService()
+	// La latencia superpone las dos confirmaciones sobre stock-service
+	fake.SetLatency(50 * time.Millisecond)
+	expiresAt := time.Now().Add(time.Hour)
+	repo := &confirmOrderRepo{order: entity.Order{
+		OrderID:              "ORDER-1",
+		TenantID:             "tenant-1",
+		Status:               entity.OrderStatusCreated,
+		ReservationExpiresAt: &expiresAt,
+	}}
+	for _, sku := range []string{"SKU-A", "SKU-B"} {
+		fake.SetStock("tenant-1", sku, 10)
+		if _, err := fake.ReserveStock(context.Background(), "tenant-1", "", sku, 2, "ORDER-1-"+sku); err != nil {
+			t.Fatalf("ReserveStock(%s): %v", sku, err)
+		}
+		repo.order.Items = append(repo.order.Items, entity.OrderItem{
+			ItemID:               "ITEM-" + sku,
+			OrderID:              "ORDER-1",
+			SKU:                  sku,
+			Quantity:             2,
+			ReservationReference: "ORDER-1-" + sku,
+			ReservationStatus:    entity.ReservationStatusReserved,
+		})
+	}
+	uc := NewConfirmOrderUseCase(repo, fake, service.NewOutboxService(&memoryOutboxRepo{}), nil, nil)
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := uc.Execute(context.Background(), "tenant-1", "", "user-1", "ORDER-1")
+			errs <- err
+		}()
+	}
+
+	confirmed := 0
+	for i := 0; i < 2; i++ {
+		err := <-errs
+		switch {
+		case err == nil:
+			confirmed++
+		case errors.Is(err, entity.ErrOrderConfirmInProgress), errors.Is(err, entity.ErrOrderNotInCreatedState):
+		default:
+			t.Errorf("Execute error = %v, want ErrOrderConfirmInProgress or ErrOrderNotInCreatedState", err)
+		}
+	}
+	if confirmed != 1 {
+		t.Fatalf("confirmations = %d, want 1", confirmed)
+	}
+
+	if got := fake.Calls(stock.FakeOpConsume); got != 2 {
+		t.Errorf("consume calls = %d, want 2 (one per line)", got)
+	}
+	for _, item := range repo.order.Items {
+		if item.ReservationStatus != entity.ReservationStatusConsumed {
+			t.Errorf("%s reservation = %s, want %s", item.SKU, item.ReservationStatus, entity.ReservationStatusConsumed)
+		}
+		if level, _ := fake.Stock("tenant-1", item.SKU); level.Total != 8 || level.Reserved != 0 {
+			t.Errorf("%s: total=%.0f reserved=%.0f, want 8 / 0", item.SKU, level.Total, level.Reserved)
+		}
+	}
+}
+
+func TestConfirmReturnsLineToReservedWhenConsumeFails(t *testing.T) {
+	fake := stock.NewFakeStockService()
+	fake.SetStock("tenant-1", "SKU-A", 10)
+	if _, err := fake.ReserveStock(context.Background(), "tenant-1", "", "SKU-A", 2, "ORDER-1-SKU-A"); err != nil {
+		t.Fatalf("ReserveStock: %v", err)
+	}
+	repo := &confirmOrderRepo{order: entity.Order{
+		OrderID:  "ORDER-1",
+		TenantID: "tenant-1",
+		Status:   entity.OrderStatusCreated,
+		Items: []entity.OrderItem{{
+			ItemID:               "ITEM-A",
+			SKU:                  "SKU-A",
+			Quantity:             2,
+			ReservationReference: "ORDER-1-SKU-A",
+			ReservationStatus:    entity.ReservationStatusReserved,
+		}},
+	}}
+	uc := NewConfirmOrderUseCase(repo, fake, service.NewOutboxService(&memoryOutboxRepo{}), nil, nil)
+
+	// Falla transitoria: la línea vuelve a RESERVED y el reintento confirma
+	fake.FailNext(stock.FakeOpConsume, 1)
+	if _, err := uc.Execute(context.Background(), "tenant-1", "", "user-1", "ORDER-1"); err == nil {
+		t.Fatal("Execute with failing stock-service succeeded")
+	}
+	if got := repo.order.Items[0].ReservationStatus; got != entity.ReservationStatusReserved {
+		t.Fatalf("reservation after failure = %s, want %s", got, entity.ReservationStatusReserved)
+	}
+	if _, err := uc.Execute(context.Background(), "tenant-1", "", "user-1", "ORDER-1"); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if repo.order.Status != entity.OrderStatusConfirmed {
+		t.Errorf("order status = %s, want %s", repo.order.Status, entity.OrderStatusConfirmed)
+	}
+}
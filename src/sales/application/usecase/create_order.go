@@ -2,50 +2,103 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"sales/src/sales/application/request"
 	"sales/src/sales/application/response"
+	"sales/src/sales/application/service"
 	"sales/src/sales/domain/entity"
 	"sales/src/sales/domain/port"
 	"sales/src/sales/infrastructure/client"
+	"sales/src/shared/infrastructure/database"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
+// DefaultOrderReservationTTL vigencia de la reserva de stock de una orden sin confirmar
+const DefaultOrderReservationTTL = 30 * time.Minute
+
 // CreateOrderUseCase caso de uso para crear una orden
 type CreateOrderUseCase struct {
-	orderRepo   port.OrderRepository
-	pimClient   *client.PIMClient
-	stockClient *client.StockClient
+	orderRepo      port.OrderRepository
+	pimClient      *client.PIMClient
+	stockSale      *service.StockSaleService // HITO ORDER-RESERVATION: reservas con paralelismo acotado
+	taxService     *service.TaxService       // HITO TAX-IVA
+	sagaService    *service.StockSagaService // HITO STOCK-SAGA
+	txManager      *database.TxManager
+	reservationTTL time.Duration        // HITO ORDER-RESERVATION
+	customers      port.CustomerGateway // HITO CUSTOMER
 }
 
 // NewCreateOrderUseCase crea una nueva instancia del caso de uso
-func NewCreateOrderUseCase(orderRepo port.OrderRepository, pimClient *client.PIMClient, stockClient *client.StockClient) *CreateOrderUseCase {
+// reservationTTL <= 0 usa DefaultOrderReservationTTL
+func NewCreateOrderUseCase(
+	orderRepo port.OrderRepository,
+	pimClient *client.PIMClient,
+	stockSale *service.StockSaleService,
+	taxService *service.TaxService,
+	sagaService *service.StockSagaService,
+	txManager *database.TxManager,
+	reservationTTL time.Duration,
+	customers port.CustomerGateway,
+) *CreateOrderUseCase {
+	if reservationTTL <= 0 {
+		reservationTTL = DefaultOrderReservationTTL
+	}
 	return &CreateOrderUseCase{
-		orderRepo:   orderRepo,
-		pimClient:   pimClient,
-		stockClient: stockClient,
+		orderRepo:      orderRepo,
+		pimClient:      pimClient,
+		stockSale:      stockSale,
+		taxService:     taxService,
+		sagaService:    sagaService,
+		txManager:      txManager,
+		reservationTTL: reservationTTL,
+		customers:      customers,
 	}
 }
 
-// Execute ejecuta la creación de la orden con operación atómica y compensación
+// Execute ejecuta la creación de la orden con reserva de stock y compensación
 // HITO D - Flujo transaccional robusto:
-// 1. Obtener snapshots de PIM para todos los items
-// 2. Crear aggregate Order (en memoria)
-// 3. Ejecutar ProcessSaleAtomic para cada item (validación + descuento atómico)
-// 4. Si falla un item → compensar todos los anteriores
-// 5. Persistir orden
-// 6. Si falla persistencia → compensar todo el stock descontado
+// 0. Resolver el comprador en customer-service (HITO CUSTOMER)
+// 1. Obtener snapshots de PIM para todos los items (precio de la variante u override)
+// 2. Crear aggregate Order (en memoria) con subtotales y desglose de IVA
+// 3. Reservar stock de cada item con su propia referencia (HITO ORDER-RESERVATION)
+// 4. Si falla un item → liberar todas las reservas ya hechas
+// 5. Persistir orden con las referencias y el vencimiento de la reserva
+// 6. Si falla persistencia → liberar todas las reservas
+// La confirmación consume esas reservas; si la orden vence sin confirmar, el
+// worker de reservas las libera
 func (uc *CreateOrderUseCase) Execute(ctx context.Context, tenantID, authToken string, req *request.CreateOrderRequest) (*response.CreateOrderResponse, error) {
 	if len(req.Items) == 0 {
 		return nil, fmt.Errorf("order must contain at least one item")
 	}
 
+	// HITO TAX-IVA: Configuración de IVA del tenant (alícuotas por SKU / categoría)
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tenant_id format: %w", err)
+	}
+	taxProfile, err := uc.taxProfile(ctx, tenantUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// HITO CUSTOMER: snapshot inmutable del comprador (sin customer_id = consumidor final)
+	customer, err := resolveCustomer(ctx, uc.customers, tenantID, authToken, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
 	// ========================================================================
 	// PASO 1: Obtener snapshots inmutables de PIM para todos los items
 	// ========================================================================
 	var items []entity.OrderItem
 	for _, itemReq := range req.Items {
 		// Obtener snapshots inmutables de PIM al momento de crear la orden
-		productSnapshot, variantSnapshot, err := uc.pimClient.GetSnapshotForSKU(tenantID, authToken, itemReq.SKU)
+		productSnapshot, variantSnapshot, err := uc.pimClient.GetSnapshotForSKU(ctx, tenantID, authToken, itemReq.SKU)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching snapshot for SKU %s: %w", itemReq.SKU, err)
 		}
@@ -53,8 +106,16 @@ func (uc *CreateOrderUseCase) Execute(ctx context.Context, tenantID, authToken s
 		// Crear item con snapshots
 		item, err := entity.NewOrderItemWithSnapshots("", itemReq.SKU, itemReq.Quantity, productSnapshot, variantSnapshot)
 		if err != nil {
-			return nil, fmt.Errorf("error creating order item: %w", itemReq.SKU, err)
+			return nil, fmt.Errorf("error creating order item %s: %w", itemReq.SKU, err)
 		}
+
+		// HITO ORDER-PRICE: Precio de la variante en PIM salvo override de la línea
+		if err := applyItemPrice(item, itemReq.UnitPrice); err != nil {
+			return nil, fmt.Errorf("error pricing order item %s: %w", itemReq.SKU, err)
+		}
+
+		// HITO TAX-IVA: Alícuota por SKU o por la categoría del snapshot
+		item.TaxRateCode = taxProfile.ResolveRate(item.SKU, item.CategoryID())
 		items = append(items, *item)
 	}
 
@@ -65,45 +126,40 @@ func (uc *CreateOrderUseCase) Execute(ctx context.Context, tenantID, authToken s
 	if err != nil {
 		return nil, fmt.Errorf("error creating order entity: %w", err)
 	}
+	if err := order.ApplyTax(taxProfile.Settings.PriceMode); err != nil {
+		return nil, fmt.Errorf("error calculating order taxes: %w", err)
+	}
+	order.Customer = customer
 
 	// ========================================================================
-	// PASO 3: Ejecutar ProcessSaleAtomic para cada item
-	// HITO D: Operación atómica (SELECT FOR UPDATE) elimina race condition
+	// PASO 3: Reservar stock de todos los items (HITO ORDER-RESERVATION)
 	// ========================================================================
-	processedStockEntries := make([]string, 0, len(order.Items))
-
-	for _, item := range order.Items {
-		saleResp, err := uc.stockClient.ProcessSaleAtomic(
-			tenantID,
-			authToken,
-			item.SKU,
-			float64(item.Quantity),
-			order.OrderID, // Reference para trazabilidad
-		)
-
-		if err != nil {
-			// Error técnico (HTTP, network, etc.)
-			uc.compensateProcessedStock(ctx, tenantID, authToken, processedStockEntries, "order_creation_failed")
-			return nil, fmt.Errorf("error processing stock for SKU %s: %w", item.SKU, err)
-		}
+	// HITO STOCK-SAGA: cada reserva queda registrada para poder liberarla
+	saga, err := uc.sagaService.Begin(ctx, tenantID, entity.StockSagaTypeSalesOrder, order.OrderID)
+	if err != nil {
+		return nil, err
+	}
 
-		if !saleResp.Success {
-			// Error de negocio (stock insuficiente, no inicializado, etc.)
-			uc.compensateProcessedStock(ctx, tenantID, authToken, processedStockEntries, "insufficient_stock")
-			return nil, fmt.Errorf("stock rejected for SKU %s: %s", item.SKU, saleResp.Message)
+	order.ReserveItems(time.Now().Add(uc.reservationTTL))
+	if err := uc.reserveItems(ctx, saga, authToken, order); err != nil {
+		if errors.Is(err, entity.ErrInsufficientStock) {
+			// Error de negocio (stock insuficiente)
+			uc.compensateProcessedStock(ctx, saga, authToken, "insufficient_stock")
+			return nil, err
 		}
-
-		// Guardar stock_entry_id para posible compensación
-		processedStockEntries = append(processedStockEntries, saleResp.StockEntryID)
+		// Error técnico (HTTP, network, etc.)
+		uc.compensateProcessedStock(ctx, saga, authToken, "order_creation_failed")
+		return nil, err
 	}
 
 	// ========================================================================
-	// PASO 4: Persistir orden SOLO si todo el stock salió correctamente
+	// PASO 4: Persistir orden SOLO si todas las reservas salieron correctamente
 	// ========================================================================
-	if err := uc.orderRepo.Save(ctx, order); err != nil {
-		// CRÍTICO: Stock ya fue descontado, debemos revertirlo
-		uc.compensateProcessedStock(ctx, tenantID, authToken, processedStockEntries, "order_persistence_failed")
-		return nil, fmt.Errorf("error saving order (stock compensated): %w", err)
+	// HITO RESILIENCE: con el stock ya reservado, un cliente que corta no debe tirar abajo la orden
+	if err := uc.persist(context.WithoutCancel(ctx), order, saga); err != nil {
+		// CRÍTICO: Stock ya fue reservado, debemos liberarlo
+		uc.compensateProcessedStock(ctx, saga, authToken, "order_persistence_failed")
+		return nil, fmt.Errorf("error saving order (stock reservations released): %w", err)
 	}
 
 	// ========================================================================
@@ -112,35 +168,90 @@ func (uc *CreateOrderUseCase) Execute(ctx context.Context, tenantID, authToken s
 	var itemsResp []response.CreateOrderItemResponse
 	for _, item := range order.Items {
 		itemsResp = append(itemsResp, response.CreateOrderItemResponse{
-			ItemID:   item.ItemID,
-			SKU:      item.SKU,
-			Quantity: item.Quantity,
+			ItemID:               item.ItemID,
+			SKU:                  item.SKU,
+			Quantity:             item.Quantity,
+			UnitPrice:            item.UnitPrice,
+			Subtotal:             item.Subtotal,
+			PriceOverridden:      item.PriceOverridden,
+			TaxRateCode:          string(item.TaxRateCode),
+			NetAmount:            item.NetAmount,
+			TaxAmount:            item.TaxAmount,
+			GrossAmount:          item.GrossAmount,
+			ReservationReference: item.ReservationReference,
 		})
 	}
 
 	return &response.CreateOrderResponse{
-		OrderID:    order.OrderID,
-		Items:      itemsResp,
-		TotalItems: len(order.Items),
-		Status:     string(order.Status),
+		OrderID:     order.OrderID,
+		Items:       itemsResp,
+		TotalItems:  len(order.Items),
+		Status:      string(order.Status),
+		Subtotal:    order.Subtotal(),
+		TotalAmount: order.TotalAmount,
+		Tax:         toTaxSummaryResponse(order.TaxSummary()),
+
+		ReservationExpiresAt: order.ReservationExpiresAt,
+		Customer:             toCustomerResponse(order.Customer),
 	}, nil
 }
 
-// compensateProcessedStock revierte todas las ventas procesadas
-// HITO D: Función crítica para garantizar consistencia transaccional
-func (uc *CreateOrderUseCase) compensateProcessedStock(
-	ctx context.Context,
-	tenantID, authToken string,
-	stockEntryIDs []string,
-	reason string,
-) {
-	for _, entryID := range stockEntryIDs {
-		err := uc.stockClient.CompensateSale(tenantID, authToken, entryID, reason)
-		if err != nil {
-			// CRÍTICO: Si falla compensación, log para auditoría manual
-			// No hacer panic ni detener el flujo
-			fmt.Printf("CRITICAL ERROR: Failed to compensate stock entry %s: %v\n", entryID, err)
-			// TODO: Enviar alerta a sistema de monitoreo
+// reserveItems reserva el stock de todas las líneas con su referencia (en paralelo acotado)
+// Cada reserva exitosa queda como paso de la saga; con error, el caller compensa
+func (uc *CreateOrderUseCase) reserveItems(ctx context.Context, saga *entity.StockSaga, authToken string, order *entity.Order) error {
+	lines := make([]port.StockSaleLine, len(order.Items))
+	for i, item := range order.Items {
+		lines[i] = port.StockSaleLine{
+			VariantSKU: item.SKU,
+			Quantity:   float64(item.Quantity),
+			Reference:  item.ReservationReference,
 		}
 	}
+	return uc.stockSale.ReserveLines(ctx, saga, authToken, lines)
+}
+
+// taxProfile obtiene la configuración de IVA del tenant
+// Sin TaxService (desarrollo sin DB) se usa la configuración por defecto
+func (uc *CreateOrderUseCase) taxProfile(ctx context.Context, tenantID uuid.UUID) (*entity.TaxProfile, error) {
+	if uc.taxService == nil {
+		return entity.NewTaxProfile(entity.DefaultTaxSettings(tenantID), nil), nil
+	}
+	return uc.taxService.Profile(ctx, tenantID)
+}
+
+// applyItemPrice fija el precio de la línea: override del request o precio del snapshot de PIM
+func applyItemPrice(item *entity.OrderItem, override *decimal.Decimal) error {
+	if override != nil {
+		return item.ApplyPrice(*override, true)
+	}
+
+	unitPrice, err := item.SnapshotPrice()
+	if err != nil {
+		return err
+	}
+	return item.ApplyPrice(unitPrice, false)
+}
+
+// persist guarda la orden y cierra la saga en una única transacción
+// HITO STOCK-SAGA - Si la tx no confirma la saga queda STARTED y el worker la compensa
+func (uc *CreateOrderUseCase) persist(ctx context.Context, order *entity.Order, saga *entity.StockSaga) error {
+	save := func(ctx context.Context) error {
+		if err := uc.orderRepo.Save(ctx, order); err != nil {
+			return err
+		}
+		return uc.sagaService.Complete(ctx, saga)
+	}
+	if uc.txManager == nil {
+		return save(ctx)
+	}
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+		return save(ctx)
+	})
+}
+
+// compensateProcessedStock libera todas las reservas hechas
+// HITO D: Función crítica para garantizar consistencia transaccional
+// HITO STOCK-SAGA: lo que no se pueda revertir queda pendiente para el worker de reintentos
+func (uc *CreateOrderUseCase) compensateProcessedStock(ctx context.Context, saga *entity.StockSaga, authToken, reason string) {
+	uc.sagaService.Compensate(ctx, saga, authToken, reason)
 }
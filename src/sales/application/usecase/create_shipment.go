@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+// CreateShipmentUseCase registra un envío de la orden
+// HITO ORDER-FULFILLMENT - Transportista, seguimiento y unidades por línea. El primer
+// envío de una orden PACKED la pasa a SHIPPED (queda en el historial de estados)
+type CreateShipmentUseCase struct {
+	orderRepo port.OrderRepository
+}
+
+// NewCreateShipmentUseCase crea una nueva instancia del caso de uso
+func NewCreateShipmentUseCase(orderRepo port.OrderRepository) *CreateShipmentUseCase {
+	return &CreateShipmentUseCase{
+		orderRepo: orderRepo,
+	}
+}
+
+// Execute registra el envío
+func (uc *CreateShipmentUseCase) Execute(
+	ctx context.Context,
+	tenantID, userID, orderID string,
+	req *request.CreateShipmentRequest,
+) (*response.CreateShipmentResponse, error) {
+	order, err := uc.orderRepo.FindByID(ctx, orderID, tenantID)
+	if err != nil {
+		return nil, entity.ErrOrderNotFound
+	}
+
+	lines := make([]entity.ShipmentLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, entity.ShipmentLine{ItemID: line.ItemID, Quantity: line.Quantity})
+	}
+
+	from := order.Status
+	shipment, err := order.Ship(lines, req.Carrier, req.TrackingNumber, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var change *entity.OrderStatusChange
+	if order.Status != from {
+		change = entity.NewOrderStatusChange(order, from, userID, "shipment "+shipment.ID.String())
+	}
+
+	if err := uc.orderRepo.SaveShipment(ctx, shipment, change); err != nil {
+		if errors.Is(err, entity.ErrInvalidShipmentQuantity) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error saving shipment: %w", err)
+	}
+
+	log.Printf("🚚 Order %s: shipment %s via %s (%d lines, tracking %q)",
+		order.OrderID, shipment.ID, shipment.Carrier, len(shipment.Items), shipment.TrackingNumber)
+
+	return &response.CreateShipmentResponse{
+		Shipment:     toShipmentResponse(shipment),
+		OrderStatus:  string(order.Status),
+		FullyShipped: order.FullyShipped(),
+	}, nil
+}
+
+// toShipmentResponse convierte un envío con sus líneas
+func toShipmentResponse(shipment *entity.Shipment) response.ShipmentResponse {
+	items := make([]response.ShipmentItemResponse, 0, len(shipment.Items))
+	for _, item := range shipment.Items {
+		items = append(items, response.ShipmentItemResponse{
+			OrderItemID: item.OrderItemID,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	return response.ShipmentResponse{
+		ID:             shipment.ID.String(),
+		OrderID:        shipment.OrderID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		ShippedBy:      shipment.ShippedBy,
+		ShippedAt:      shipment.ShippedAt,
+		Items:          items,
+	}
+}
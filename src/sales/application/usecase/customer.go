@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// resolveCustomer obtiene el snapshot del comprador (HITO CUSTOMER)
+// Sin customer_id la venta es a consumidor final y no se consulta customer-service
+func resolveCustomer(ctx context.Context, customers port.CustomerGateway, tenantID, authToken string, customerID *uuid.UUID) (*entity.CustomerSnapshot, error) {
+	if customerID == nil {
+		return entity.FinalConsumerSnapshot(), nil
+	}
+	if *customerID == uuid.Nil {
+		return nil, entity.ErrInvalidCustomerID
+	}
+	if customers == nil {
+		return nil, fmt.Errorf("%w: customer-service not configured", entity.ErrDependencyUnavailable)
+	}
+
+	customer, err := customers.GetCustomerSnapshot(ctx, tenantID, authToken, *customerID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching customer %s: %w", customerID, err)
+	}
+	return customer, nil
+}
+
+// buildCustomerPayload bloque "customer" de los eventos de venta
+// El consumidor final (o una venta previa al snapshot) sale con el cliente genérico
+func buildCustomerPayload(customer *entity.CustomerSnapshot) map[string]interface{} {
+	customer = customer.OrFinalConsumer()
+
+	payload := map[string]interface{}{
+		"customer_id":   customer.LedgerCustomerID(),
+		"customer_name": customer.Name,
+		"tax_condition": string(customer.TaxCondition),
+	}
+	if customer.DocumentNumber != "" {
+		payload["document_type"] = customer.DocumentType
+		payload["document_number"] = customer.DocumentNumber
+	}
+	if customer.Email != "" {
+		payload["email"] = customer.Email
+	}
+	if customer.Address != nil {
+		payload["address"] = map[string]interface{}{
+			"street":      customer.Address.Street,
+			"city":        customer.Address.City,
+			"state":       customer.Address.State,
+			"postal_code": customer.Address.PostalCode,
+			"country":     customer.Address.Country,
+		}
+	}
+	return payload
+}
+
+// toCustomerResponse expone el snapshot del comprador (nil = venta previa al snapshot)
+func toCustomerResponse(customer *entity.CustomerSnapshot) *response.CustomerResponse {
+	if customer == nil {
+		return nil
+	}
+
+	resp := &response.CustomerResponse{
+		Name:           customer.Name,
+		DocumentType:   customer.DocumentType,
+		DocumentNumber: customer.DocumentNumber,
+		TaxCondition:   string(customer.TaxCondition),
+		Email:          customer.Email,
+	}
+	if customer.CustomerID != nil {
+		resp.CustomerID = customer.CustomerID.String()
+	}
+	if customer.Address != nil {
+		resp.Address = &response.CustomerAddressResponse{
+			Street:     customer.Address.Street,
+			City:       customer.Address.City,
+			State:      customer.Address.State,
+			PostalCode: customer.Address.PostalCode,
+			Country:    customer.Address.Country,
+		}
+	}
+	return resp
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"sales/src/sales/application/response"
+	"sales/src/sales/infrastructure/cache"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -15,13 +16,15 @@ import (
 // DailyReportUseCase caso de uso para reporte diario de ventas
 // HITO C - Reportes Diarios
 type DailyReportUseCase struct {
-	db *sql.DB
+	db                 *sql.DB
+	paymentMethodCache *cache.PaymentMethodCache
 }
 
 // NewDailyReportUseCase crea una nueva instancia del caso de uso
-func NewDailyReportUseCase(db *sql.DB) *DailyReportUseCase {
+func NewDailyReportUseCase(db *sql.DB, paymentMethodCache *cache.PaymentMethodCache) *DailyReportUseCase {
 	return &DailyReportUseCase{
-		db: db,
+		db:                 db,
+		paymentMethodCache: paymentMethodCache,
 	}
 }
 
@@ -52,6 +55,8 @@ func (uc *DailyReportUseCase) Execute(ctx context.Context, tenantID uuid.UUID, d
 			COALESCE(SUM(total_amount), 0) as gross_total,
 			COALESCE(SUM(discount_amount), 0) as total_discounts,
 			COALESCE(SUM(final_amount), 0) as net_total,
+			COALESCE(SUM(net_amount), 0) as taxable_net,
+			COALESCE(SUM(tax_amount), 0) as tax_total,
 			MIN(created_at) as first_sale,
 			MAX(created_at) as last_sale
 		FROM pos_sales
@@ -61,7 +66,7 @@ func (uc *DailyReportUseCase) Execute(ctx context.Context, tenantID uuid.UUID, d
 	`
 
 	var posSalesCount int
-	var grossTotal, totalDiscounts, netTotal decimal.Decimal
+	var grossTotal, totalDiscounts, netTotal, taxableNet, taxTotal decimal.Decimal
 	var firstSale, lastSale sql.NullTime
 
 	err = uc.db.QueryRowContext(ctx, queryPOS, tenantID, from, to).Scan(
@@ -69,6 +74,8 @@ func (uc *DailyReportUseCase) Execute(ctx context.Context, tenantID uuid.UUID, d
 		&grossTotal,
 		&totalDiscounts,
 		&netTotal,
+		&taxableNet,
+		&taxTotal,
 		&firstSale,
 		&lastSale,
 	)
@@ -78,10 +85,11 @@ func (uc *DailyReportUseCase) Execute(ctx context.Context, tenantID uuid.UUID, d
 
 	// ========================================================================
 	// PASO 4: QUERY ORDERS (Solo count, sin amounts)
+	// HITO TAX-IVA: sales_orders (la tabla orders se eliminó en la migración 010)
 	// ========================================================================
 	queryOrders := `
 		SELECT COUNT(*)
-		FROM orders
+		FROM sales_orders
 		WHERE tenant_id = $1
 			AND created_at >= $2
 			AND created_at < $3
@@ -93,6 +101,43 @@ func (uc *DailyReportUseCase) Execute(ctx context.Context, tenantID uuid.UUID, d
 		return nil, fmt.Errorf("error querying orders: %w", err)
 	}
 
+	// ========================================================================
+	// PASO 4a: QUERY DEVOLUCIONES POS (HITO POS-REFUND)
+	// Se imputan al día en que se emite la devolución, no al de la venta
+	// ========================================================================
+	queryRefunds := `
+		SELECT
+			COUNT(*) as refunds_count,
+			COALESCE(SUM(refund_amount), 0) as refunds_total
+		FROM pos_sale_refunds
+		WHERE tenant_id = $1
+			AND created_at >= $2
+			AND created_at < $3
+	`
+
+	var refundsCount int
+	var refundsTotal decimal.Decimal
+	err = uc.db.QueryRowContext(ctx, queryRefunds, tenantID, from, to).Scan(&refundsCount, &refundsTotal)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pos_sale_refunds: %w", err)
+	}
+
+	// ========================================================================
+	// PASO 4b: QUERY PAGOS POS POR MÉTODO (HITO POS-SPLIT)
+	// ========================================================================
+	paymentMethods, err := uc.queryPaymentBreakdown(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// ========================================================================
+	// PASO 4c: DESGLOSE DE IVA POR ALÍCUOTA (HITO TAX-IVA)
+	// ========================================================================
+	taxBreakdown, err := uc.queryTaxBreakdown(ctx, tenantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
 	// ========================================================================
 	// PASO 5: CONSTRUIR RESPONSE (Combinación en memoria)
 	// ========================================================================
@@ -103,7 +148,13 @@ func (uc *DailyReportUseCase) Execute(ctx context.Context, tenantID uuid.UUID, d
 		TotalTransactions: posSalesCount + ordersCount,
 		PosGrossTotal:     grossTotal,
 		PosDiscounts:      totalDiscounts,
-		PosNetTotal:       netTotal,
+		PosNetTotal:       netTotal.Sub(refundsTotal),
+		PosRefundsCount:   refundsCount,
+		PosRefundsTotal:   refundsTotal,
+		PosPaymentMethods: paymentMethods,
+		PosTaxableNet:     taxableNet,
+		PosTaxTotal:       taxTotal,
+		TaxBreakdown:      taxBreakdown,
 	}
 
 	// Agregar timestamps solo si existen ventas
@@ -116,3 +167,139 @@ func (uc *DailyReportUseCase) Execute(ctx context.Context, tenantID uuid.UUID, d
 
 	return resp, nil
 }
+
+// queryPaymentBreakdown agrega los pagos POS del rango por método de pago
+// HITO POS-SPLIT - Una venta con pago combinado aporta a varios métodos
+// HITO POS-REFUND - Las devoluciones del rango se restan del método por el que se devolvió
+func (uc *DailyReportUseCase) queryPaymentBreakdown(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]response.PaymentMethodBreakdown, error) {
+	query := `
+		SELECT
+			movements.payment_method_id,
+			COUNT(DISTINCT movements.pos_sale_id) as sales_count,
+			COALESCE(SUM(movements.amount), 0) as amount_received,
+			COALESCE(SUM(movements.change_amount), 0) as change_given,
+			COALESCE(SUM(movements.refunded_amount), 0) as refunded_amount
+		FROM (
+			SELECT
+				p.payment_method_id,
+				p.pos_sale_id,
+				p.amount,
+				p.change_amount,
+				0 as refunded_amount
+			FROM pos_sale_payments p
+			JOIN pos_sales ps ON ps.id = p.pos_sale_id
+			WHERE ps.tenant_id = $1
+				AND ps.created_at >= $2
+				AND ps.created_at < $3
+
+			UNION ALL
+
+			SELECT
+				r.payment_method_id,
+				NULL as pos_sale_id,
+				0 as amount,
+				0 as change_amount,
+				r.refund_amount as refunded_amount
+			FROM pos_sale_refunds r
+			WHERE r.tenant_id = $1
+				AND r.created_at >= $2
+				AND r.created_at < $3
+		) movements
+		GROUP BY movements.payment_method_id
+		ORDER BY amount_received DESC
+	`
+
+	rows, err := uc.db.QueryContext(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pos_sale_payments: %w", err)
+	}
+	defer rows.Close()
+
+	breakdown := make([]response.PaymentMethodBreakdown, 0)
+	for rows.Next() {
+		var line response.PaymentMethodBreakdown
+		if err := rows.Scan(
+			&line.PaymentMethodID,
+			&line.SalesCount,
+			&line.AmountReceived,
+			&line.ChangeGiven,
+			&line.RefundedAmount,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning payment breakdown: %w", err)
+		}
+
+		line.NetAmount = line.AmountReceived.Sub(line.ChangeGiven).Sub(line.RefundedAmount)
+		line.PaymentMethodName = "Unknown"
+		if uc.paymentMethodCache != nil {
+			line.PaymentMethodName = uc.paymentMethodCache.GetName(line.PaymentMethodID)
+		}
+		breakdown = append(breakdown, line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payment breakdown: %w", err)
+	}
+
+	return breakdown, nil
+}
+
+// queryTaxBreakdown agrega neto / IVA / bruto del rango por alícuota
+// HITO TAX-IVA - Líneas de ventas POS + líneas de órdenes no canceladas
+// Las devoluciones se informan aparte (pos_refunds_*); su IVA se revierte con nota de crédito
+func (uc *DailyReportUseCase) queryTaxBreakdown(ctx context.Context, tenantID uuid.UUID, from, to time.Time) ([]response.TaxBreakdownResponse, error) {
+	query := `
+		SELECT
+			lines.tax_rate_code,
+			lines.tax_rate,
+			COALESCE(SUM(lines.net_amount), 0) as net_amount,
+			COALESCE(SUM(lines.tax_amount), 0) as tax_amount,
+			COALESCE(SUM(lines.gross_amount), 0) as gross_amount
+		FROM (
+			SELECT i.tax_rate_code, i.tax_rate, i.net_amount, i.tax_amount, i.gross_amount
+			FROM pos_sale_items i
+			JOIN pos_sales ps ON ps.id = i.pos_sale_id
+			WHERE ps.tenant_id = $1
+				AND ps.created_at >= $2
+				AND ps.created_at < $3
+
+			UNION ALL
+
+			SELECT oi.tax_rate_code, oi.tax_rate, oi.net_amount, oi.tax_amount, oi.gross_amount
+			FROM sales_order_items oi
+			JOIN sales_orders o ON o.id = oi.sales_order_id
+			WHERE o.tenant_id = $1
+				AND o.created_at >= $2
+				AND o.created_at < $3
+				AND o.status <> 'CANCELED'
+		) lines
+		GROUP BY lines.tax_rate_code, lines.tax_rate
+		ORDER BY lines.tax_rate DESC
+	`
+
+	rows, err := uc.db.QueryContext(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tax breakdown: %w", err)
+	}
+	defer rows.Close()
+
+	breakdown := make([]response.TaxBreakdownResponse, 0)
+	for rows.Next() {
+		var line response.TaxBreakdownResponse
+		if err := rows.Scan(
+			&line.RateCode,
+			&line.Rate,
+			&line.NetAmount,
+			&line.TaxAmount,
+			&line.GrossAmount,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning tax breakdown: %w", err)
+		}
+		breakdown = append(breakdown, line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tax breakdown: %w", err)
+	}
+
+	return breakdown, nil
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+const (
+	// orderReservationGrace margen tras el vencimiento para las confirmaciones en curso
+	// (la confirmación rechaza órdenes vencidas, pero una que empezó antes puede estar consumiendo)
+	orderReservationGrace = 2 * time.Minute
+	// orderReservationLease tiempo que una orden vencida tomada queda fuera de la cola
+	orderReservationLease = 2 * time.Minute
+)
+
+// ExpireOrderReservationsUseCase vence las órdenes sin confirmar y libera su stock reservado
+// HITO ORDER-RESERVATION - Un carrito abandonado no bloquea stock más allá del TTL:
+// la orden pasa a EXPIRED y cada reserva se libera en stock-service. Lo que falle se
+// reintenta al vencer el lease
+type ExpireOrderReservationsUseCase struct {
+	orderRepo    port.OrderRepository
+	stockClient  port.StockGateway
+	serviceToken string // Authorization para stock-service fuera de un request (puede ser vacío)
+}
+
+// NewExpireOrderReservationsUseCase crea una nueva instancia del caso de uso
+func NewExpireOrderReservationsUseCase(
+	orderRepo port.OrderRepository,
+	stockClient port.StockGateway,
+	serviceToken string,
+) *ExpireOrderReservationsUseCase {
+	return &ExpireOrderReservationsUseCase{
+		orderRepo:    orderRepo,
+		stockClient:  stockClient,
+		serviceToken: serviceToken,
+	}
+}
+
+// ProcessDue vence hasta limit órdenes y libera sus reservas (lo invoca el worker)
+func (uc *ExpireOrderReservationsUseCase) ProcessDue(ctx context.Context, limit int) (int, error) {
+	orders, err := uc.orderRepo.ClaimExpiredReservations(ctx, time.Now().Add(-orderReservationGrace), limit, orderReservationLease)
+	if err != nil {
+		if len(orders) == 0 {
+			return 0, err
+		}
+		log.Printf("⚠️ Order reservations: %v", err)
+	}
+
+	processed := 0
+	for _, order := range orders {
+		if err := releaseOrderReservations(ctx, uc.stockClient, uc.orderRepo, order, uc.serviceToken); err != nil {
+			// Sigue EXPIRED con reservas pendientes: se retoma al vencer el lease
+			log.Printf("❌ Error releasing reservations of expired order %s: %v", order.OrderID, err)
+			continue
+		}
+		if err := uc.orderRepo.MarkReservationsReleased(ctx, order.OrderID); err != nil {
+			log.Printf("❌ Error closing expired order %s: %v", order.OrderID, err)
+			continue
+		}
+		log.Printf("⌛ Order %s expired, stock reservations released", order.OrderID)
+		processed++
+	}
+
+	return processed, nil
+}
+
+// releaseOrderReservations libera cada reserva pendiente de la orden
+// Las consumidas por una confirmación que no terminó se revierten
+// Compartido por el vencimiento y la cancelación en CREATED (HITO ORDER-CANCEL)
+func releaseOrderReservations(
+	ctx context.Context,
+	stockClient port.StockGateway,
+	orderRepo port.OrderRepository,
+	order *entity.Order,
+	authToken string,
+) error {
+	var lastErr error
+	for i := range order.Items {
+		item := &order.Items[i]
+		if !item.HasReservation() || item.ReservationStatus == entity.ReservationStatusReleased {
+			continue
+		}
+
+		var err error
+		switch item.ReservationStatus {
+		case entity.ReservationStatusConsumed:
+			_, err = stockClient.RevertConsume(ctx, order.TenantID, authToken, item.SKU, item.Quantity, item.ReservationReference)
+		case entity.ReservationStatusConsuming:
+			// Consumo en duda (confirmación interrumpida): si la reserva ya no se puede
+			// liberar es porque se consumió, y se revierte el consumo
+			_, err = stockClient.ReleaseStock(ctx, order.TenantID, authToken, item.SKU, item.Quantity, item.ReservationReference)
+			if err != nil {
+				_, err = stockClient.RevertConsume(ctx, order.TenantID, authToken, item.SKU, item.Quantity, item.ReservationReference)
+			}
+		default:
+			_, err = stockClient.ReleaseStock(ctx, order.TenantID, authToken, item.SKU, item.Quantity, item.ReservationReference)
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("error releasing reservation %s: %w", item.ReservationReference, err)
+			continue
+		}
+
+		item.ReservationStatus = entity.ReservationStatusReleased
+		if err := orderRepo.UpdateItemReservation(ctx, item.ItemID, item.ReservationStatus); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// FiscalIssuerUseCase caso de uso para consultar / cargar los datos fiscales del tenant
+// HITO FISCAL - Emisor de comprobantes (CUIT, condición frente al IVA, punto de venta)
+type FiscalIssuerUseCase struct {
+	fiscalIssuerRepo port.FiscalIssuerRepository
+}
+
+// NewFiscalIssuerUseCase crea una nueva instancia del caso de uso
+func NewFiscalIssuerUseCase(fiscalIssuerRepo port.FiscalIssuerRepository) *FiscalIssuerUseCase {
+	return &FiscalIssuerUseCase{
+		fiscalIssuerRepo: fiscalIssuerRepo,
+	}
+}
+
+// Get retorna el emisor del tenant
+func (uc *FiscalIssuerUseCase) Get(ctx context.Context, tenantID uuid.UUID) (*response.FiscalIssuerResponse, error) {
+	issuer, err := uc.fiscalIssuerRepo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toFiscalIssuerResponse(issuer), nil
+}
+
+// Save crea o reemplaza el emisor del tenant
+// Los comprobantes ya emitidos no cambian (guardan su propia copia del emisor)
+func (uc *FiscalIssuerUseCase) Save(ctx context.Context, tenantID uuid.UUID, req *request.SaveFiscalIssuerRequest) (*response.FiscalIssuerResponse, error) {
+	issuer, err := entity.NewFiscalIssuer(
+		tenantID,
+		req.CUIT,
+		req.LegalName,
+		entity.TaxCondition(req.TaxCondition),
+		req.PointOfSaleNumber,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.fiscalIssuerRepo.Save(ctx, issuer); err != nil {
+		return nil, err
+	}
+
+	return toFiscalIssuerResponse(issuer), nil
+}
+
+// toFiscalIssuerResponse mapea el emisor al DTO de respuesta
+func toFiscalIssuerResponse(issuer *entity.FiscalIssuer) *response.FiscalIssuerResponse {
+	return &response.FiscalIssuerResponse{
+		TenantID:          issuer.TenantID,
+		CUIT:              issuer.CUIT,
+		LegalName:         issuer.LegalName,
+		TaxCondition:      string(issuer.TaxCondition),
+		PointOfSaleNumber: issuer.PointOfSaleNumber,
+		UpdatedAt:         issuer.UpdatedAt,
+	}
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/sales/infrastructure/cache"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// GetCashSessionUseCase caso de uso para consultar una sesión de caja con su arqueo
+// HITO POS-CASH - Permite ver el arqueo parcial de una sesión abierta
+type GetCashSessionUseCase struct {
+	cashSessionRepo    port.CashSessionRepository
+	paymentMethodCache *cache.PaymentMethodCache
+}
+
+// NewGetCashSessionUseCase crea una nueva instancia del caso de uso
+func NewGetCashSessionUseCase(cashSessionRepo port.CashSessionRepository, paymentMethodCache *cache.PaymentMethodCache) *GetCashSessionUseCase {
+	return &GetCashSessionUseCase{
+		cashSessionRepo:    cashSessionRepo,
+		paymentMethodCache: paymentMethodCache,
+	}
+}
+
+// Execute obtiene una sesión por ID
+func (uc *GetCashSessionUseCase) Execute(ctx context.Context, tenantID, sessionID uuid.UUID) (*response.CashSessionResponse, error) {
+	session, err := uc.cashSessionRepo.FindByID(ctx, tenantID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.withTotals(ctx, session)
+}
+
+// ExecuteCurrent obtiene la sesión abierta de un punto de venta
+func (uc *GetCashSessionUseCase) ExecuteCurrent(ctx context.Context, tenantID, pointOfSaleID uuid.UUID) (*response.CashSessionResponse, error) {
+	session, err := uc.cashSessionRepo.FindOpenByPointOfSale(ctx, tenantID, pointOfSaleID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.withTotals(ctx, session)
+}
+
+func (uc *GetCashSessionUseCase) withTotals(ctx context.Context, session *entity.CashSession) (*response.CashSessionResponse, error) {
+	totals, err := uc.cashSessionRepo.SalesTotalsByPaymentMethod(ctx, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error computing session totals: %w", err)
+	}
+	return buildCashSessionResponse(session, totals, uc.paymentMethodCache), nil
+}
+
+// buildCashSessionResponse arma el arqueo combinando totales vendidos y montos contados
+// Para sesiones abiertas el esperado se calcula al vuelo; para cerradas se usa lo persistido
+func buildCashSessionResponse(
+	session *entity.CashSession,
+	totals []entity.CashSessionMethodTotal,
+	paymentMethodCache *cache.PaymentMethodCache,
+) *response.CashSessionResponse {
+	resp := &response.CashSessionResponse{
+		ID:              session.ID,
+		PointOfSaleID:   session.PointOfSaleID,
+		Status:          string(session.Status),
+		OpeningFloat:    session.OpeningFloat,
+		OpenedBy:        session.OpenedBy,
+		OpenedAt:        session.OpenedAt,
+		ClosedBy:        session.ClosedBy,
+		ClosedAt:        session.ClosedAt,
+		Notes:           session.Notes,
+		SalesAmount:     decimal.Zero,
+		ChangeGiven:     decimal.Zero,
+		TotalDifference: decimal.Zero,
+		Reconciliation:  []response.CashReconciliationLine{},
+	}
+
+	lines := make(map[uuid.UUID]*response.CashReconciliationLine)
+	var order []uuid.UUID
+
+	lineFor := func(paymentMethodID uuid.UUID) *response.CashReconciliationLine {
+		if line, ok := lines[paymentMethodID]; ok {
+			return line
+		}
+		line := &response.CashReconciliationLine{
+			PaymentMethodID:   paymentMethodID,
+			PaymentMethodName: "Unknown",
+			SalesAmount:       decimal.Zero,
+			ChangeGiven:       decimal.Zero,
+			ExpectedAmount:    decimal.Zero,
+		}
+		if paymentMethodCache != nil {
+			line.PaymentMethodName = paymentMethodCache.GetName(paymentMethodID)
+			line.IsCash = paymentMethodCache.IsCash(paymentMethodID)
+		}
+		if line.IsCash {
+			line.ExpectedAmount = session.OpeningFloat
+		}
+		lines[paymentMethodID] = line
+		order = append(order, paymentMethodID)
+		return line
+	}
+
+	// 1. Totales vendidos
+	for _, t := range totals {
+		line := lineFor(t.PaymentMethodID)
+		line.SalesCount = t.SalesCount
+		line.SalesAmount = t.FinalAmount
+		line.ChangeGiven = t.ChangeGiven
+		line.ExpectedAmount = line.ExpectedAmount.Add(t.FinalAmount)
+
+		resp.SalesCount += t.SalesCount
+		resp.SalesAmount = resp.SalesAmount.Add(t.FinalAmount)
+		resp.ChangeGiven = resp.ChangeGiven.Add(t.ChangeGiven)
+	}
+
+	// 2. Montos contados (solo sesiones cerradas)
+	for _, count := range session.Counts {
+		line := lineFor(count.PaymentMethodID)
+		counted := count.CountedAmount
+		difference := count.Difference
+		line.ExpectedAmount = count.ExpectedAmount
+		line.CountedAmount = &counted
+		line.Difference = &difference
+
+		resp.TotalDifference = resp.TotalDifference.Add(count.Difference)
+	}
+
+	for _, id := range order {
+		resp.Reconciliation = append(resp.Reconciliation, *lines[id])
+	}
+
+	return resp
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// GetInvoiceUseCase caso de uso para consultar un comprobante
+// HITO FISCAL - Estado fiscal, número y CAE
+type GetInvoiceUseCase struct {
+	invoiceRepo port.InvoiceRepository
+}
+
+// NewGetInvoiceUseCase crea una nueva instancia del caso de uso
+func NewGetInvoiceUseCase(invoiceRepo port.InvoiceRepository) *GetInvoiceUseCase {
+	return &GetInvoiceUseCase{
+		invoiceRepo: invoiceRepo,
+	}
+}
+
+// Execute retorna el comprobante del tenant
+func (uc *GetInvoiceUseCase) Execute(ctx context.Context, tenantID, invoiceID uuid.UUID) (*response.InvoiceResponse, error) {
+	invoice, err := uc.invoiceRepo.FindByID(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toInvoiceResponse(invoice), nil
+}
+
+// toInvoiceResponse mapea el comprobante al DTO de respuesta
+func toInvoiceResponse(invoice *entity.Invoice) *response.InvoiceResponse {
+	resp := &response.InvoiceResponse{
+		ID:                   invoice.ID,
+		SourceType:           string(invoice.SourceType),
+		SourceID:             invoice.SourceID,
+		InvoiceType:          string(invoice.InvoiceType),
+		PointOfSaleNumber:    invoice.PointOfSaleNumber,
+		InvoiceNumber:        invoice.InvoiceNumber,
+		FormattedNumber:      invoice.FormattedNumber(),
+		IssuerCUIT:           invoice.IssuerCUIT,
+		CustomerTaxCondition: string(invoice.CustomerTaxCondition),
+		CustomerTaxID:        invoice.CustomerTaxID,
+		CustomerName:         invoice.CustomerName,
+		Currency:             invoice.Currency,
+		NetAmount:            invoice.NetAmount,
+		TaxAmount:            invoice.TaxAmount,
+		TotalAmount:          invoice.TotalAmount,
+		FiscalStatus:         string(invoice.FiscalStatus),
+		CAE:                  invoice.CAE,
+		CAEExpiresAt:         invoice.CAEExpiresAt,
+		RejectionReason:      invoice.RejectionReason,
+		Attempts:             invoice.Attempts,
+		LastError:            invoice.LastError,
+		AuthorizedAt:         invoice.AuthorizedAt,
+		CreatedAt:            invoice.CreatedAt,
+	}
+	if invoice.FiscalStatus == entity.FiscalStatusPending {
+		nextAttemptAt := invoice.NextAttemptAt
+		resp.NextAttemptAt = &nextAttemptAt
+	}
+
+	return resp
+}
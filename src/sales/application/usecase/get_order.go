@@ -33,20 +33,53 @@ func (uc *GetOrderUseCase) Execute(ctx context.Context, tenantID, orderID string
 	// Convertir items con snapshots
 	var items []response.OrderItemResponse
 	for _, item := range order.Items {
-		items = append(items, response.OrderItemResponse{
-			ItemID:          item.ItemID,
-			SKU:             item.SKU,
-			Quantity:        item.Quantity,
-			ProductSnapshot: item.ProductSnapshot,
-			VariantSnapshot: item.VariantSnapshot,
-		})
+		items = append(items, toOrderItemResponse(item))
 	}
 
 	return &response.GetOrderResponse{
-		OrderID:   order.OrderID,
-		TenantID:  order.TenantID,
-		Status:    string(order.Status),
-		CreatedAt: order.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		Items:     items,
+		OrderID:      order.OrderID,
+		OrderNumber:  order.OrderNumber,
+		TenantID:     order.TenantID,
+		Status:       string(order.Status),
+		CreatedAt:    order.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Subtotal:     order.Subtotal(),
+		TotalAmount:  order.TotalAmount,
+		Items:        items,
+		Tax:          toTaxSummaryResponse(order.TaxSummary()),
+		Customer:     toCustomerResponse(order.Customer),
+		Cancellation: toOrderCancellationResponse(order),
 	}, nil
 }
+
+// toOrderItemResponse convierte una línea de la orden (compartido por get y list)
+func toOrderItemResponse(item entity.OrderItem) response.OrderItemResponse {
+	return response.OrderItemResponse{
+		ItemID:           item.ItemID,
+		SKU:              item.SKU,
+		Quantity:         item.Quantity,
+		CanceledQuantity: item.CanceledQuantity, // HITO ORDER-CANCEL
+		ShippedQuantity:  item.ShippedQuantity,  // HITO ORDER-FULFILLMENT
+		ProductSnapshot:  item.ProductSnapshot,
+		VariantSnapshot:  item.VariantSnapshot,
+		UnitPrice:        item.UnitPrice,
+		Subtotal:         item.Subtotal,
+		PriceOverridden:  item.PriceOverridden,
+		TaxRateCode:      string(item.TaxRateCode),
+		TaxRate:          item.TaxRate,
+		NetAmount:        item.NetAmount,
+		TaxAmount:        item.TaxAmount,
+		GrossAmount:      item.GrossAmount,
+	}
+}
+
+// toOrderCancellationResponse datos de la cancelación o nil si la orden no fue cancelada
+func toOrderCancellationResponse(order *entity.Order) *response.OrderCancellationResponse {
+	if order.Status != entity.OrderStatusCanceled || order.CanceledAt == nil {
+		return nil
+	}
+	return &response.OrderCancellationResponse{
+		Reason:     order.CancelReason,
+		CanceledBy: order.CanceledBy,
+		CanceledAt: *order.CanceledAt,
+	}
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+// GetOrderStatusHistoryUseCase retorna el historial de estados de una orden
+// HITO ORDER-FULFILLMENT - Quién movió la orden a cada estado y cuándo
+type GetOrderStatusHistoryUseCase struct {
+	orderRepo port.OrderRepository
+}
+
+// NewGetOrderStatusHistoryUseCase crea una nueva instancia del caso de uso
+func NewGetOrderStatusHistoryUseCase(orderRepo port.OrderRepository) *GetOrderStatusHistoryUseCase {
+	return &GetOrderStatusHistoryUseCase{
+		orderRepo: orderRepo,
+	}
+}
+
+// Execute retorna las transiciones de la orden en orden cronológico
+func (uc *GetOrderStatusHistoryUseCase) Execute(ctx context.Context, tenantID, orderID string) (*response.OrderStatusHistoryResponse, error) {
+	order, err := uc.orderRepo.FindByID(ctx, orderID, tenantID)
+	if err != nil {
+		return nil, entity.ErrOrderNotFound
+	}
+
+	history, err := uc.orderRepo.ListStatusHistory(ctx, orderID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]response.OrderStatusChangeResponse, 0, len(history))
+	for _, change := range history {
+		changes = append(changes, toOrderStatusChangeResponse(change))
+	}
+
+	return &response.OrderStatusHistoryResponse{
+		OrderID: order.OrderID,
+		Status:  string(order.Status),
+		History: changes,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// IssueInvoiceUseCase caso de uso para facturar una orden confirmada o una venta POS
+// HITO FISCAL - Genera el comprobante PENDING e intenta autorizarlo en línea
+type IssueInvoiceUseCase struct {
+	invoiceRepo      port.InvoiceRepository
+	fiscalIssuerRepo port.FiscalIssuerRepository
+	authorizeUC      *AuthorizeInvoiceUseCase
+}
+
+// NewIssueInvoiceUseCase crea una nueva instancia del caso de uso
+func NewIssueInvoiceUseCase(
+	invoiceRepo port.InvoiceRepository,
+	fiscalIssuerRepo port.FiscalIssuerRepository,
+	authorizeUC *AuthorizeInvoiceUseCase,
+) *IssueInvoiceUseCase {
+	return &IssueInvoiceUseCase{
+		invoiceRepo:      invoiceRepo,
+		fiscalIssuerRepo: fiscalIssuerRepo,
+		authorizeUC:      authorizeUC,
+	}
+}
+
+// Execute emite el comprobante:
+// 1. Cargar emisor del tenant y venta a facturar
+// 2. Determinar letra (A/B/C) y discriminar IVA
+// 3. Persistir PENDING + vincular a la venta (invoice_id / fiscal_status)
+// 4. Intentar autorizar en línea; si el fisco no responde queda PENDING para el worker
+func (uc *IssueInvoiceUseCase) Execute(ctx context.Context, tenantID uuid.UUID, req *request.IssueInvoiceRequest) (*response.InvoiceResponse, error) {
+	sourceType := entity.InvoiceSourceType(req.SourceType)
+	if !sourceType.IsValid() {
+		return nil, entity.ErrInvalidInvoiceSourceType
+	}
+
+	// 1. Emisor y venta
+	issuer, err := uc.fiscalIssuerRepo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := uc.invoiceRepo.FindSource(ctx, tenantID, sourceType, req.SourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Comprobante (reglas fiscales en el aggregate)
+	taxCondition, taxID, name := invoiceCustomer(req, source.Customer)
+	invoice, err := entity.NewInvoice(
+		issuer,
+		source,
+		taxCondition,
+		taxID,
+		name,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Persistir
+	if err := uc.invoiceRepo.Create(ctx, invoice); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Invoice %s created: type=%s source=%s/%s total=%s",
+		invoice.ID, invoice.InvoiceType, invoice.SourceType, invoice.SourceID, invoice.TotalAmount)
+
+	// 4. Autorización en línea (un fallo no invalida la emisión, reintenta el worker)
+	if uc.authorizeUC != nil {
+		if err := uc.authorizeUC.AuthorizeNow(ctx, invoice.ID); err != nil {
+			log.Printf("⚠️ Invoice %s left for background authorization: %v", invoice.ID, err)
+		}
+	}
+
+	current, err := uc.invoiceRepo.FindByID(ctx, tenantID, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toInvoiceResponse(current), nil
+}
+
+// invoiceCustomer receptor del comprobante: lo informado en el request o, si falta,
+// el comprador guardado en la venta (HITO CUSTOMER)
+func invoiceCustomer(req *request.IssueInvoiceRequest, customer *entity.CustomerSnapshot) (entity.TaxCondition, string, string) {
+	taxCondition := entity.TaxCondition(req.CustomerTaxCondition)
+	taxID := req.CustomerTaxID
+	name := req.CustomerName
+	if customer.IsFinalConsumer() {
+		return taxCondition, taxID, name
+	}
+
+	if taxCondition == "" {
+		taxCondition = customer.TaxCondition
+	}
+	if taxID == "" {
+		taxID = customer.TaxID()
+	}
+	if name == "" {
+		name = customer.Name
+	}
+	return taxCondition, taxID, name
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// ListCreditNotesUseCase caso de uso para listar las notas de crédito de una factura
+// HITO CREDIT-NOTE
+type ListCreditNotesUseCase struct {
+	invoiceRepo    port.InvoiceRepository
+	creditNoteRepo port.CreditNoteRepository
+}
+
+// NewListCreditNotesUseCase crea una nueva instancia del caso de uso
+func NewListCreditNotesUseCase(invoiceRepo port.InvoiceRepository, creditNoteRepo port.CreditNoteRepository) *ListCreditNotesUseCase {
+	return &ListCreditNotesUseCase{
+		invoiceRepo:    invoiceRepo,
+		creditNoteRepo: creditNoteRepo,
+	}
+}
+
+// Execute retorna las notas de crédito emitidas contra la factura
+func (uc *ListCreditNotesUseCase) Execute(ctx context.Context, tenantID, invoiceID uuid.UUID) (*response.ListCreditNotesResponse, error) {
+	// Validar que la factura exista para el tenant (404 en lugar de lista vacía)
+	if _, err := uc.invoiceRepo.FindByID(ctx, tenantID, invoiceID); err != nil {
+		return nil, err
+	}
+
+	notes, err := uc.creditNoteRepo.ListByInvoice(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response.ListCreditNotesResponse{
+		InvoiceID:   invoiceID,
+		CreditNotes: make([]response.CreditNoteResponse, 0, len(notes)),
+	}
+	for _, note := range notes {
+		resp.CreditNotes = append(resp.CreditNotes, *toCreditNoteResponse(note))
+	}
+
+	return resp, nil
+}
+
+// toCreditNoteResponse mapea la nota de crédito al DTO de respuesta
+func toCreditNoteResponse(note *entity.CreditNote) *response.CreditNoteResponse {
+	items := make([]response.CreditNoteItemResponse, 0, len(note.Items))
+	for _, item := range note.Items {
+		items = append(items, response.CreditNoteItemResponse{
+			SourceItemID: item.SourceItemID,
+			SKU:          item.SKU,
+			Quantity:     item.Quantity,
+			Amount:       item.Amount,
+		})
+	}
+
+	return &response.CreditNoteResponse{
+		ID:               note.ID,
+		InvoiceID:        note.InvoiceID,
+		SourceType:       string(note.SourceType),
+		SourceID:         note.SourceID,
+		ReversalID:       note.ReversalID,
+		CreditNoteType:   string(note.CreditNoteType),
+		CreditNoteNumber: note.CreditNoteNumber,
+		FormattedNumber:  note.FormattedNumber(),
+		Reason:           note.Reason,
+		Currency:         note.Currency,
+		NetAmount:        note.NetAmount,
+		TaxAmount:        note.TaxAmount,
+		TotalAmount:      note.TotalAmount,
+		IssuedBy:         note.IssuedBy,
+		Items:            items,
+		CreatedAt:        note.CreatedAt,
+	}
+}
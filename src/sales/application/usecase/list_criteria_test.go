@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/shared/domain/criteria"
+	sqlCriteria "sales/src/shared/infrastructure/criteria"
+
+	"github.com/google/uuid"
+)
+
+// HITO ORDER-SEARCH / POS-SEARCH - Los grupos filter=and/or/not llegan al SQL junto al tenant
+
+// listCriteriaFields mapeo mínimo para convertir el criteria que recibe el repositorio
+var listCriteriaFields = sqlCriteria.FieldMap{
+	"tenant_id":  "tenant_id",
+	"status":     "status",
+	"created_at": "created_at",
+}
+
+// capturingOrderRepo guarda el criteria de cada búsqueda
+type capturingOrderRepo struct {
+	port.OrderRepository
+	criteria []criteria.Criteria
+}
+
+func (r *capturingOrderRepo) SearchByCriteria(ctx context.Context, c criteria.Criteria) ([]*entity.Order, error) {
+	r.criteria = append(r.criteria, c)
+	return []*entity.Order{}, nil
+}
+
+func (r *capturingOrderRepo) CountByCriteria(ctx context.Context, c criteria.Criteria) (int, error) {
+	r.criteria = append(r.criteria, c)
+	return 0, nil
+}
+
+// capturingPosSaleRepo guarda el criteria de cada búsqueda
+type capturingPosSaleRepo struct {
+	port.PosSaleRepository
+	criteria []criteria.Criteria
+}
+
+func (r *capturingPosSaleRepo) SearchPage(ctx context.Context, c criteria.Criteria) ([]*entity.PosSale, error) {
+	r.criteria = append(r.criteria, c)
+	return []*entity.PosSale{}, nil
+}
+
+func (r *capturingPosSaleRepo) Summarize(ctx context.Context, c criteria.Criteria) (*port.PosSaleSummary, error) {
+	r.criteria = append(r.criteria, c)
+	return &port.PosSaleSummary{}, nil
+}
+
+func statusOrGroupCriteria(t *testing.T) criteria.Criteria {
+	t.Helper()
+	builder := criteria.NewCriteriaBuilder()
+	if err := builder.AddFilterExpression("or(status:eq:CONFIRMED,status:eq:SHIPPED)", []string{"status"}); err != nil {
+		t.Fatalf("AddFilterExpression: %v", err)
+	}
+	return builder.Build()
+}
+
+func assertTenantAndGroupInSQL(t *testing.T, c criteria.Criteria, tenantID string) {
+	t.Helper()
+	where, args, err := sqlCriteria.NewMappedSQLCriteriaConverter(listCriteriaFields).ToSQL(c)
+	if err != nil {
+		t.Fatalf("ToSQL: %v", err)
+	}
+	if !strings.Contains(where, "tenant_id = $1") || !strings.Contains(where, "(status = $2 OR status = $3)") {
+		t.Errorf("WHERE = %q, want the tenant filter AND the or(...) group", where)
+	}
+	if len(args) != 3 || args[0] != tenantID {
+		t.Errorf("args = %v, want [%s CONFIRMED SHIPPED]", args, tenantID)
+	}
+}
+
+func TestListOrdersKeepsFilterGroups(t *testing.T) {
+	repo := &capturingOrderRepo{}
+	tenantID := uuid.New().String()
+
+	if _, err := NewListOrdersUseCase(repo).Execute(context.Background(), tenantID, statusOrGroupCriteria(t)); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	if len(repo.criteria) == 0 {
+		t.Fatal("repository never received a criteria")
+	}
+	for _, c := range repo.criteria {
+		assertTenantAndGroupInSQL(t, c, tenantID)
+	}
+}
+
+func TestListPosSalesKeepsFilterGroups(t *testing.T) {
+	repo := &capturingPosSaleRepo{}
+	tenantID := uuid.New()
+
+	if _, err := NewListPosSalesUseCase(repo).Execute(context.Background(), tenantID, statusOrGroupCriteria(t), true); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	// Página y resumen filtran el mismo conjunto
+	if len(repo.criteria) != 2 {
+		t.Fatalf("repository calls = %d, want 2 (page + summary)", len(repo.criteria))
+	}
+	for _, c := range repo.criteria {
+		assertTenantAndGroupInSQL(t, c, tenantID.String())
+	}
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+// ListOrderShipmentsUseCase lista los envíos de una orden (HITO ORDER-FULFILLMENT)
+type ListOrderShipmentsUseCase struct {
+	orderRepo port.OrderRepository
+}
+
+// NewListOrderShipmentsUseCase crea una nueva instancia del caso de uso
+func NewListOrderShipmentsUseCase(orderRepo port.OrderRepository) *ListOrderShipmentsUseCase {
+	return &ListOrderShipmentsUseCase{
+		orderRepo: orderRepo,
+	}
+}
+
+// Execute retorna los envíos de la orden y si quedan unidades por despachar
+func (uc *ListOrderShipmentsUseCase) Execute(ctx context.Context, tenantID, orderID string) (*response.ListShipmentsResponse, error) {
+	order, err := uc.orderRepo.FindByID(ctx, orderID, tenantID)
+	if err != nil {
+		return nil, entity.ErrOrderNotFound
+	}
+
+	shipments, err := uc.orderRepo.ListShipments(ctx, orderID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]response.ShipmentResponse, 0, len(shipments))
+	for _, shipment := range shipments {
+		items = append(items, toShipmentResponse(shipment))
+	}
+
+	return &response.ListShipmentsResponse{
+		OrderID:      order.OrderID,
+		OrderStatus:  string(order.Status),
+		FullyShipped: order.FullyShipped(),
+		Shipments:    items,
+	}, nil
+}
@@ -2,12 +2,14 @@ package usecase
 
 import (
 	"context"
-	"math"
 	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
 	"sales/src/sales/domain/port"
+	"sales/src/shared/domain/criteria"
 )
 
-// ListOrdersUseCase caso de uso para listar órdenes con paginación
+// ListOrdersUseCase caso de uso para listar órdenes con filtros y paginación
+// HITO ORDER-SEARCH - Criteria con filtros por estado, número, fecha, SKU y cliente
 type ListOrdersUseCase struct {
 	orderRepo port.OrderRepository
 }
@@ -20,51 +22,46 @@ func NewListOrdersUseCase(orderRepo port.OrderRepository) *ListOrdersUseCase {
 }
 
 // Execute ejecuta el listado de órdenes
-func (uc *ListOrdersUseCase) Execute(ctx context.Context, tenantID string, page, pageSize int) (*response.ListOrdersResponse, error) {
-	// Valores por defecto
-	if page < 1 {
-		page = 1
+// El criteria llega validado por el controller; el filtro por tenant se agrega siempre
+func (uc *ListOrdersUseCase) Execute(ctx context.Context, tenantID string, c criteria.Criteria) (*criteria.ListResponse[response.OrderListItem], error) {
+	filters := criteria.NewFilters(criteria.NewFilter("tenant_id", criteria.OpEqual, tenantID))
+	for _, filter := range c.Filters.Items {
+		filters.Add(filter)
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	// Los grupos and / or / not se combinan con AND junto al tenant
+	for _, group := range c.Filters.Groups {
+		filters.AddGroup(group)
 	}
+	c = criteria.NewCriteria(filters, c.Order, c.Limit, c.Offset)
 
-	// Obtener órdenes del repositorio
-	orders, totalCount, err := uc.orderRepo.List(ctx, tenantID, page, pageSize)
+	// Órdenes + total en una sola respuesta paginada
+	list, err := criteria.NewBaseListRepository[entity.Order](uc.orderRepo).ListByCriteria(ctx, c)
 	if err != nil {
 		return nil, err
 	}
 
 	// Convertir a respuesta con snapshots
-	var items []response.OrderListItem
-	for _, order := range orders {
+	items := make([]*response.OrderListItem, 0, len(list.Items))
+	for _, order := range list.Items {
 		var orderItems []response.OrderItemResponse
 		for _, item := range order.Items {
-			orderItems = append(orderItems, response.OrderItemResponse{
-				ItemID:          item.ItemID,
-				SKU:             item.SKU,
-				Quantity:        item.Quantity,
-				ProductSnapshot: item.ProductSnapshot,
-				VariantSnapshot: item.VariantSnapshot,
-			})
+			orderItems = append(orderItems, toOrderItemResponse(item))
 		}
 
-		items = append(items, response.OrderListItem{
-			OrderID:   order.OrderID,
-			TenantID:  order.TenantID,
-			Status:    string(order.Status),
-			CreatedAt: order.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			Items:     orderItems,
+		items = append(items, &response.OrderListItem{
+			OrderID:      order.OrderID,
+			OrderNumber:  order.OrderNumber,
+			TenantID:     order.TenantID,
+			Status:       string(order.Status),
+			CreatedAt:    order.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Subtotal:     order.Subtotal(),
+			TotalAmount:  order.TotalAmount,
+			Items:        orderItems,
+			Tax:          toTaxSummaryResponse(order.TaxSummary()),
+			Customer:     toCustomerResponse(order.Customer),
+			Cancellation: toOrderCancellationResponse(order),
 		})
 	}
 
-	totalPages := int(math.Ceil(float64(totalCount) / float64(pageSize)))
-
-	return &response.ListOrdersResponse{
-		Items:      items,
-		TotalCount: totalCount,
-		Page:       page,
-		PageSize:   pageSize,
-		TotalPages: totalPages,
-	}, nil
+	return criteria.NewListResponse(items, list.TotalCount, c), nil
 }
@@ -5,12 +5,17 @@ import (
 	"sales/src/sales/application/response"
 	"sales/src/sales/domain/entity"
 	"sales/src/sales/domain/port"
+	"sales/src/shared/domain/criteria"
 
 	"github.com/google/uuid"
 )
 
+// defaultPosSalePageSize tamaño de página si el criteria no trae límite
+const defaultPosSalePageSize = 10
+
 // ListPosSalesUseCase caso de uso para listar ventas POS
 // Hito: POS-SALE-02 - Reporte
+// HITO POS-SEARCH - Filtros + paginación por cursor (criteria.Cursor) + totales opcionales
 type ListPosSalesUseCase struct {
 	posSaleRepo port.PosSaleRepository
 }
@@ -20,13 +25,64 @@ func NewListPosSalesUseCase(posSaleRepo port.PosSaleRepository) *ListPosSalesUse
 	return &ListPosSalesUseCase{posSaleRepo: posSaleRepo}
 }
 
-// Execute lista las ventas POS del tenant
-func (uc *ListPosSalesUseCase) Execute(ctx context.Context, tenantID uuid.UUID) ([]*response.PosSaleListItem, error) {
-	sales, err := uc.posSaleRepo.ListByTenant(ctx, tenantID)
+// Execute lista una página de ventas POS del tenant, de la más nueva a la más vieja
+// c.Cursor = posición pedida (nil = primera página); withSummary agrega los totales
+// del conjunto filtrado
+func (uc *ListPosSalesUseCase) Execute(ctx context.Context, tenantID uuid.UUID, c criteria.Criteria, withSummary bool) (*response.PosSaleListResponse, error) {
+	filters := criteria.NewFilters(criteria.NewFilter("tenant_id", criteria.OpEqual, tenantID.String()))
+	for _, filter := range c.Filters.Items {
+		filters.Add(filter)
+	}
+	// Los grupos and / or / not se combinan con AND junto al tenant
+	for _, group := range c.Filters.Groups {
+		filters.AddGroup(group)
+	}
+
+	limit := defaultPosSalePageSize
+	if c.Limit != nil && *c.Limit > 0 {
+		limit = *c.Limit
+	}
+
+	// Orden fijo del listado: la más nueva primero
+	order := criteria.NewOrder("created_at", criteria.DESC)
+	cursor := c.Cursor
+	if cursor == nil {
+		cursor = criteria.NewFirstPageCursor(order)
+	}
+	pageCriteria := criteria.NewCriteria(filters, order, &limit, nil).WithCursor(cursor)
+
+	sales, err := uc.posSaleRepo.SearchPage(ctx, pageCriteria)
 	if err != nil {
 		return nil, err
 	}
-	return toListItems(sales), nil
+	page := criteria.NewCursorListResponse(sales, 0, pageCriteria, func(sale *entity.PosSale) []interface{} {
+		return []interface{}{sale.CreatedAt, sale.ID}
+	})
+
+	resp := &response.PosSaleListResponse{
+		Items:      toListItems(page.Items),
+		PageSize:   page.PageSize,
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+
+	if withSummary {
+		summary, err := uc.posSaleRepo.Summarize(ctx, criteria.NewCriteria(filters, criteria.Order{}, nil, nil))
+		if err != nil {
+			return nil, err
+		}
+		resp.Summary = &response.PosSaleSummaryResponse{
+			SalesCount:     summary.SalesCount,
+			TotalAmount:    summary.Subtotal,
+			DiscountAmount: summary.Discount,
+			NetAmount:      summary.Net,
+			TaxAmount:      summary.Tax,
+			FinalAmount:    summary.Gross,
+			RefundedAmount: summary.Refunded,
+		}
+	}
+
+	return resp, nil
 }
 
 func toListItems(sales []*entity.PosSale) []*response.PosSaleListItem {
@@ -34,6 +90,7 @@ func toListItems(sales []*entity.PosSale) []*response.PosSaleListItem {
 	for _, s := range sales {
 		items = append(items, &response.PosSaleListItem{
 			ID:              s.ID,
+			SaleNumber:      s.TicketNumber(),
 			CustomerID:      s.CustomerID,
 			PaymentMethodID: s.PaymentMethodID,
 			TotalAmount:     s.TotalAmount,
@@ -42,6 +99,11 @@ func toListItems(sales []*entity.PosSale) []*response.PosSaleListItem {
 			Currency:        s.Currency,
 			TotalItems:      s.TotalItems(),
 			CreatedAt:       s.CreatedAt,
+			Status:          string(s.Status),
+			RefundedAmount:  s.RefundedAmount,
+			NetAmount:       s.NetAmount,
+			TaxAmount:       s.TaxAmount,
+			Customer:        toCustomerResponse(s.Customer),
 		})
 	}
 	return items
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+
+	"sales/src/sales/application/response"
+	"sales/src/sales/application/service"
+	"sales/src/sales/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// ListSequencesUseCase caso de uso para inspeccionar las secuencias de un tenant
+// HITO SEQ-TX - Administración de secuencias
+type ListSequencesUseCase struct {
+	sequenceService *service.SequenceService
+}
+
+// NewListSequencesUseCase crea una nueva instancia del caso de uso
+func NewListSequencesUseCase(sequenceService *service.SequenceService) *ListSequencesUseCase {
+	return &ListSequencesUseCase{
+		sequenceService: sequenceService,
+	}
+}
+
+// Execute lista las secuencias del tenant (documentType vacío = todas)
+func (uc *ListSequencesUseCase) Execute(ctx context.Context, tenantID uuid.UUID, documentType string) (*response.ListSequencesResponse, error) {
+	if documentType != "" && !entity.IsValidDocumentType(documentType) {
+		return nil, entity.ErrInvalidDocumentType
+	}
+
+	sequences, err := uc.sequenceService.List(ctx, tenantID, documentType)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response.ListSequencesResponse{
+		TenantID:  tenantID,
+		Sequences: make([]response.SequenceResponse, 0, len(sequences)),
+	}
+	for _, seq := range sequences {
+		resp.Sequences = append(resp.Sequences, toSequenceResponse(seq))
+	}
+
+	return resp, nil
+}
+
+// toSequenceResponse convierte la entidad en DTO
+func toSequenceResponse(seq entity.DocumentSequence) response.SequenceResponse {
+	return response.SequenceResponse{
+		ID:            seq.ID,
+		DocumentType:  seq.DocumentType,
+		ScopeID:       seq.ScopeID,
+		CurrentNumber: seq.CurrentNumber,
+		NextNumber:    seq.CurrentNumber + 1,
+		UpdatedAt:     seq.UpdatedAt,
+	}
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+const (
+	// stuckSagaAfter una saga STARTED sin cambios por más de este tiempo se considera trabada
+	stuckSagaAfter = 5 * time.Minute
+	// maxStuckSagas límite del listado de administración
+	maxStuckSagas = 200
+)
+
+// ListStuckSagasUseCase caso de uso para inspeccionar sagas de stock sin terminar
+// HITO STOCK-SAGA - ESCALATED requiere intervención manual; COMPENSATING y STARTED
+// viejas indican compensaciones demoradas o procesos caídos
+type ListStuckSagasUseCase struct {
+	sagaRepo port.StockSagaRepository
+}
+
+// NewListStuckSagasUseCase crea una nueva instancia del caso de uso
+func NewListStuckSagasUseCase(sagaRepo port.StockSagaRepository) *ListStuckSagasUseCase {
+	return &ListStuckSagasUseCase{
+		sagaRepo: sagaRepo,
+	}
+}
+
+// Execute lista las sagas trabadas (status y tenantID vacíos = todas)
+func (uc *ListStuckSagasUseCase) Execute(ctx context.Context, tenantID, status string) (*response.ListStockSagasResponse, error) {
+	sagaStatus := entity.StockSagaStatus(status)
+	switch sagaStatus {
+	case "", entity.StockSagaStatusStarted, entity.StockSagaStatusCompensating, entity.StockSagaStatusEscalated:
+	default:
+		return nil, entity.ErrInvalidStockSagaStatus
+	}
+
+	sagas, err := uc.sagaRepo.ListStuck(ctx, tenantID, sagaStatus, stuckSagaAfter, maxStuckSagas)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response.ListStockSagasResponse{
+		Sagas: make([]response.StockSagaResponse, 0, len(sagas)),
+		Total: len(sagas),
+	}
+	for _, saga := range sagas {
+		resp.Sagas = append(resp.Sagas, toStockSagaResponse(saga))
+	}
+
+	return resp, nil
+}
+
+// toStockSagaResponse convierte la entidad en DTO
+func toStockSagaResponse(saga *entity.StockSaga) response.StockSagaResponse {
+	steps := make([]response.StockSagaStepResponse, 0, len(saga.Steps))
+	for _, step := range saga.Steps {
+		steps = append(steps, response.StockSagaStepResponse{
+			Sequence:      step.Sequence,
+			StepType:      string(step.StepType),
+			SKU:           step.SKU,
+			Quantity:      step.Quantity,
+			StockEntryID:  step.StockEntryID,
+			Status:        string(step.Status),
+			LastError:     step.LastError,
+			CompensatedAt: step.CompensatedAt,
+		})
+	}
+
+	return response.StockSagaResponse{
+		ID:                 saga.ID,
+		TenantID:           saga.TenantID,
+		SagaType:           string(saga.SagaType),
+		Reference:          saga.Reference,
+		Status:             string(saga.Status),
+		CompensationReason: saga.CompensationReason,
+		Attempts:           saga.Attempts,
+		NextAttemptAt:      saga.NextAttemptAt,
+		LastError:          saga.LastError,
+		PendingSteps:       saga.PendingSteps(),
+		CreatedAt:          saga.CreatedAt,
+		UpdatedAt:          saga.UpdatedAt,
+		Steps:              steps,
+	}
+}
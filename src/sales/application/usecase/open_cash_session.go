@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/sales/infrastructure/cache"
+
+	"github.com/google/uuid"
+)
+
+// OpenCashSessionUseCase caso de uso para abrir una sesión de caja
+// HITO POS-CASH - Apertura de caja con fondo inicial
+type OpenCashSessionUseCase struct {
+	cashSessionRepo    port.CashSessionRepository
+	paymentMethodCache *cache.PaymentMethodCache
+}
+
+// NewOpenCashSessionUseCase crea una nueva instancia del caso de uso
+func NewOpenCashSessionUseCase(cashSessionRepo port.CashSessionRepository, paymentMethodCache *cache.PaymentMethodCache) *OpenCashSessionUseCase {
+	return &OpenCashSessionUseCase{
+		cashSessionRepo:    cashSessionRepo,
+		paymentMethodCache: paymentMethodCache,
+	}
+}
+
+// Execute abre una sesión de caja para el punto de venta
+// Falla con entity.ErrCashSessionAlreadyOpen si ya existe una sesión abierta
+func (uc *OpenCashSessionUseCase) Execute(ctx context.Context, tenantID uuid.UUID, userID string, req *request.OpenCashSessionRequest) (*response.CashSessionResponse, error) {
+	session, err := entity.NewCashSession(tenantID, req.PointOfSaleID, req.OpeningFloat, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.cashSessionRepo.Create(ctx, session); err != nil {
+		if err == entity.ErrCashSessionAlreadyOpen {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error opening cash session: %w", err)
+	}
+
+	log.Printf("✅ Cash session opened: ID=%s, POS=%s, Float=%s", session.ID, session.PointOfSaleID, session.OpeningFloat)
+
+	return buildCashSessionResponse(session, nil, uc.paymentMethodCache), nil
+}
@@ -2,64 +2,104 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"sales/src/sales/application/request"
 	"sales/src/sales/application/response"
+	"sales/src/sales/application/service"
 	"sales/src/sales/domain/entity"
 	"sales/src/sales/domain/port"
 	"sales/src/sales/infrastructure/cache"
 	"sales/src/sales/infrastructure/client"
+	"sales/src/shared/infrastructure/database"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
-	"github.com/mercadocercano/eventbus"
 )
 
+// POSOperator operador que registra la venta en el terminal
+// HITO POS-PRICE - Identidad y permisos informados por el gateway
+type POSOperator struct {
+	UserID           string
+	CanOverridePrice bool // Tiene PermissionPriceOverride
+}
+
+// posLinePricing precio, nombre y alícuota resueltos para una línea del request
+type posLinePricing struct {
+	catalog   *entity.CatalogItem
+	unitPrice decimal.Decimal
+	taxRate   entity.TaxRateCode
+}
+
 // POSSaleUseCase caso de uso para venta directa POS
 // Hito: POS-SALE-02.BE - Paso 3
 // HITO: POST /pos/sale devuelve DTO listo para imprimir
 type POSSaleUseCase struct {
-	stockClient        *client.StockClient
+	stockSale          *service.StockSaleService // HITO STOCK-BATCH
 	posSaleRepo        port.PosSaleRepository
+	cashSessionRepo    port.CashSessionRepository
 	paymentMethodCache *cache.PaymentMethodCache
-	publishUseCase     *eventbus.PublishEventUseCase
+	outbox             *service.OutboxService // HITO OUTBOX
+	sequenceService    *service.SequenceService
+	txManager          *database.TxManager
+	taxService         *service.TaxService       // HITO TAX-IVA
+	pimClient          *client.PIMClient         // HITO POS-PRICE: precio, nombre y categoría del catálogo
+	sagaService        *service.StockSagaService // HITO STOCK-SAGA: log de descuentos y compensaciones
+	customers          port.CustomerGateway      // HITO CUSTOMER: snapshot del comprador
 }
 
 // NewPOSSaleUseCase crea una nueva instancia del caso de uso
 func NewPOSSaleUseCase(
-	stockClient *client.StockClient,
+	stockSale *service.StockSaleService,
 	posSaleRepo port.PosSaleRepository,
+	cashSessionRepo port.CashSessionRepository,
 	paymentMethodCache *cache.PaymentMethodCache,
-	publishUseCase *eventbus.PublishEventUseCase,
+	outbox *service.OutboxService,
+	sequenceService *service.SequenceService,
+	txManager *database.TxManager,
+	taxService *service.TaxService,
+	pimClient *client.PIMClient,
+	sagaService *service.StockSagaService,
+	customers port.CustomerGateway,
 ) *POSSaleUseCase {
 	return &POSSaleUseCase{
-		stockClient:        stockClient,
+		stockSale:          stockSale,
 		posSaleRepo:        posSaleRepo,
+		cashSessionRepo:    cashSessionRepo,
 		paymentMethodCache: paymentMethodCache,
-		publishUseCase:     publishUseCase,
+		outbox:             outbox,
+		sequenceService:    sequenceService,
+		txManager:          txManager,
+		taxService:         taxService,
+		pimClient:          pimClient,
+		sagaService:        sagaService,
+		customers:          customers,
 	}
 }
 
 // Execute ejecuta una venta directa POS multi-item con operación atómica y compensación
 // HITO D - Flujo transaccional robusto:
-// 1. Validar request
-// 2. Ejecutar ProcessSaleAtomic para cada item (validación + descuento atómico)
-// 3. Si falla un item → compensar todos los anteriores
+// 1. Validar request (sesión de caja abierta, comprador, precio de catálogo y alícuotas de IVA)
+// 2. Descontar stock de todos los items (lote atómico o ProcessSaleAtomic por item, HITO STOCK-BATCH)
+// 3. Si falla un item → compensar todos los ya descontados
 // 4. Crear pos_sale aggregate
-// 5. Persistir pos_sale
+// 5. Numerar + persistir pos_sale + registrar sales.pos.confirmed en la misma transacción (HITO POS-NUMBER / OUTBOX)
 // 6. Si falla persistencia → compensar todo el stock descontado
-func (uc *POSSaleUseCase) Execute(tenantID, authToken string, req *request.POSSaleRequest) (*response.POSSaleResponse, error) {
+func (uc *POSSaleUseCase) Execute(ctx context.Context, tenantID, authToken string, operator POSOperator, req *request.POSSaleRequest) (*response.POSSaleResponse, error) {
 	log.Printf("🛒 POS Sale Multi-Item - Items: %d, Tenant: %s", len(req.Items), tenantID)
 
 	// ========================================================================
 	// PASO 1: VALIDACIONES TÉCNICAS
 	// ========================================================================
-	if req.PaymentMethodID == uuid.Nil {
-		return nil, fmt.Errorf("payment_method_id is required")
+	// HITO POS-SPLIT: pagos (o pago único legacy)
+	paymentReqs := req.NormalizedPayments()
+	if len(paymentReqs) == 0 {
+		return nil, fmt.Errorf("at least one payment is required (payments or payment_method_id)")
 	}
 	if len(req.Items) == 0 {
 		return nil, fmt.Errorf("at least one item is required")
@@ -77,9 +117,19 @@ func (uc *POSSaleUseCase) Execute(tenantID, authToken string, req *request.POSSa
 		currency = "ARS"
 	}
 
-	// HITO: Validar amount_paid
-	if req.AmountPaid.IsZero() || req.AmountPaid.LessThanOrEqual(decimal.Zero) {
-		return nil, fmt.Errorf("amount_paid must be greater than 0")
+	// HITO POS-SPLIT: Construir pagos (el vuelto solo se calcula sobre efectivo)
+	var payments []entity.PosSalePayment
+	for _, paymentReq := range paymentReqs {
+		payment, err := entity.NewPosSalePayment(
+			paymentReq.PaymentMethodID,
+			paymentReq.Amount,
+			paymentReq.Reference,
+			uc.isCash(paymentReq.PaymentMethodID),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid payment: %w", err)
+		}
+		payments = append(payments, *payment)
 	}
 
 	// Parsear tenant_id a UUID
@@ -88,8 +138,37 @@ func (uc *POSSaleUseCase) Execute(tenantID, authToken string, req *request.POSSa
 		return nil, fmt.Errorf("invalid tenant_id format: %w", err)
 	}
 
+	// HITO POS-CASH: No se vende sin sesión de caja abierta en el terminal
+	// Se valida ANTES de tocar stock para no tener que compensar
+	if uc.cashSessionRepo == nil {
+		return nil, fmt.Errorf("cash session repository not available")
+	}
+	cashSession, err := uc.cashSessionRepo.FindOpenByPointOfSale(ctx, tenantUUID, req.PointOfSaleID)
+	if err != nil {
+		if err == entity.ErrNoOpenCashSession {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error checking cash session: %w", err)
+	}
+
+	// HITO CUSTOMER: Snapshot del comprador (sin customer_id = consumidor final)
+	customer, err := resolveCustomer(ctx, uc.customers, tenantID, authToken, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// HITO POS-PRICE + TAX-IVA: Precio de catálogo y alícuota de cada línea (también antes de tocar stock)
+	taxProfile, err := uc.taxProfile(ctx, tenantUUID)
+	if err != nil {
+		return nil, err
+	}
+	pricing, err := uc.resolvePricing(ctx, tenantID, authToken, operator, taxProfile, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
 	// ========================================================================
-	// PASO 2: EJECUTAR PROCESAMIENTO ATÓMICO DE STOCK PARA CADA ITEM
+	// PASO 2: EJECUTAR PROCESAMIENTO ATÓMICO DE STOCK PARA TODOS LOS ITEMS
 	// HITO D: ProcessSaleAtomic elimina race condition
 	// ========================================================================
 	
@@ -100,64 +179,65 @@ func (uc *POSSaleUseCase) Execute(tenantID, authToken string, req *request.POSSa
 	}
 	baseReference := fmt.Sprintf("POS-%s-%d", tenantShort, time.Now().UnixNano())
 
-	processedStockEntries := make([]string, 0, len(req.Items))
-	var posSaleItems []entity.PosSaleItem
+	// HITO STOCK-SAGA: cada descuento queda registrado para poder compensarlo
+	saga, err := uc.sagaService.Begin(ctx, tenantID, entity.StockSagaTypePosSale, baseReference)
+	if err != nil {
+		return nil, err
+	}
 
+	// HITO STOCK-BATCH: una llamada en lote si stock-service la soporta; si no, por ítem en paralelo
+	stockLines := make([]port.StockSaleLine, len(req.Items))
 	for i, itemReq := range req.Items {
-		// Generar reference por item
-		itemReference := fmt.Sprintf("%s-ITEM%d", baseReference, i+1)
-
-		// OPERACIÓN ATÓMICA: validar + descontar en una sola transacción
-		log.Printf("📦 ProcessSaleAtomic for item %d: SKU=%s, Qty=%d", i+1, itemReq.SKU, itemReq.Quantity)
-		
-		saleResp, err := uc.stockClient.ProcessSaleAtomic(
-			tenantID,
-			authToken,
-			itemReq.SKU,
-			float64(itemReq.Quantity),
-			itemReference,
-		)
-
-		if err != nil {
-			// Error técnico (HTTP, network, etc.)
-			log.Printf("❌ Stock service error for SKU %s: %v", itemReq.SKU, err)
-			uc.compensateProcessedStock(tenantID, authToken, processedStockEntries, "pos_sale_creation_failed")
-			return nil, fmt.Errorf("error processing stock for SKU %s: %w", itemReq.SKU, err)
+		stockLines[i] = port.StockSaleLine{
+			VariantSKU: itemReq.SKU,
+			Quantity:   float64(itemReq.Quantity),
+			Reference:  fmt.Sprintf("%s-ITEM%d", baseReference, i+1), // Reference por item
 		}
+	}
 
-		if !saleResp.Success {
+	stockResults, err := uc.stockSale.ProcessSale(ctx, saga, authToken, stockLines)
+	if err != nil {
+		var rejected *service.StockSaleRejectedError
+		if errors.As(err, &rejected) {
 			// Error de negocio (stock insuficiente, no inicializado, etc.)
-			log.Printf("❌ Stock rejected for SKU %s: %s", itemReq.SKU, saleResp.Message)
-			uc.compensateProcessedStock(tenantID, authToken, processedStockEntries, "insufficient_stock")
-			return nil, fmt.Errorf("stock rejected for SKU %s: %s", itemReq.SKU, saleResp.Message)
+			uc.compensateProcessedStock(ctx, saga, authToken, "insufficient_stock")
+			return nil, err
 		}
+		// Error técnico (HTTP, network, etc.)
+		uc.compensateProcessedStock(ctx, saga, authToken, "pos_sale_creation_failed")
+		return nil, err
+	}
 
-		log.Printf("✅ Stock OK for item %d: EntryID=%s, QtySold=%.2f, Remaining=%.2f", 
-			i+1, saleResp.StockEntryID, saleResp.QuantitySold, saleResp.RemainingStock)
+	var posSaleItems []entity.PosSaleItem
 
-		// Guardar stock_entry_id para posible compensación
-		processedStockEntries = append(processedStockEntries, saleResp.StockEntryID)
+	for i, itemReq := range req.Items {
+		saleResp := stockResults[i]
+		log.Printf("✅ Stock OK for item %d: EntryID=%s, QtySold=%.2f, Remaining=%.2f",
+			i+1, saleResp.StockEntryID, saleResp.QuantitySold, saleResp.RemainingStock)
 
 		// Parsear stock_entry_id
 		stockEntryUUID, err := uuid.Parse(saleResp.StockEntryID)
 		if err != nil {
-			uc.compensateProcessedStock(tenantID, authToken, processedStockEntries, "invalid_stock_entry_id")
+			uc.compensateProcessedStock(ctx, saga, authToken, "invalid_stock_entry_id")
 			return nil, fmt.Errorf("invalid stock_entry_id from stock-service: %w", err)
 		}
 
 		// Crear item entity (subtotal se calcula en NewPosSaleItem)
+		// HITO POS-PRICE: nombre y precio salen del catálogo, no del terminal
 		item, err := entity.NewPosSaleItem(
 			uuid.Nil, // Se asignará en NewPosSale
 			itemReq.SKU,
-			saleResp.VariantSKU, // Usar SKU como product_name temporal (TODO: obtener de PIM)
+			pricing[i].catalog.ProductName,
 			itemReq.Quantity,
-			itemReq.UnitPrice,
+			pricing[i].unitPrice,
 			stockEntryUUID,
 		)
 		if err != nil {
-			uc.compensateProcessedStock(tenantID, authToken, processedStockEntries, "item_creation_failed")
+			uc.compensateProcessedStock(ctx, saga, authToken, "item_creation_failed")
 			return nil, fmt.Errorf("error creating pos_sale_item: %w", err)
 		}
+		item.RecordPricing(pricing[i].catalog.UnitPrice, operator.UserID)
+		item.TaxRateCode = pricing[i].taxRate
 
 		posSaleItems = append(posSaleItems, *item)
 	}
@@ -171,42 +251,41 @@ func (uc *POSSaleUseCase) Execute(tenantID, authToken string, req *request.POSSa
 		posSale, err = entity.NewPosSale(
 			tenantUUID,
 			req.CustomerID,
-			req.PaymentMethodID,
 			posSaleItems,
 			discountAmount,
-			req.AmountPaid,
+			payments,
 			currency,
+			taxProfile.Settings.PriceMode,
 		)
 		if err != nil {
-			uc.compensateProcessedStock(tenantID, authToken, processedStockEntries, "aggregate_creation_failed")
+			uc.compensateProcessedStock(ctx, saga, authToken, "aggregate_creation_failed")
 			return nil, fmt.Errorf("error creating pos_sale entity: %w", err)
 		}
+		posSale.Customer = customer
+
+		// HITO POS-CASH: Vincular venta a la sesión de caja
+		if err := posSale.AssignCashSession(cashSession); err != nil {
+			uc.compensateProcessedStock(ctx, saga, authToken, "cash_session_assignment_failed")
+			return nil, err
+		}
 
 		// ========================================================================
-		// PASO 4: PERSISTIR ATOMICALLY
+		// PASO 4: NUMERAR + PERSISTIR ATOMICALLY
 		// HITO D: Si falla persistencia → compensar todo el stock descontado
+		// HITO POS-NUMBER: Si falla el insert, el número se libera con el rollback (sin huecos)
 		// ========================================================================
-		ctx := context.Background()
-		err = uc.posSaleRepo.Create(ctx, posSale)
+		// HITO RESILIENCE: con el stock ya descontado, un cliente que corta no debe tirar abajo la venta
+		err = uc.persistNumbered(context.WithoutCancel(ctx), tenantID, posSale, saga)
 		if err != nil {
 			// CRÍTICO: Stock ya fue descontado, debemos revertirlo
 			log.Printf("⚠️ CRITICAL: Stock consumed but pos_sale persistence failed: %v", err)
-			uc.compensateProcessedStock(tenantID, authToken, processedStockEntries, "pos_sale_persistence_failed")
+			uc.compensateProcessedStock(ctx, saga, authToken, "pos_sale_persistence_failed")
 			return nil, fmt.Errorf("error saving pos_sale (stock compensated): %w", err)
 		}
 
-		log.Printf("✅ PosSale created: ID=%s, Items=%d, FinalAmount=%s", posSale.ID, posSale.TotalItems(), posSale.FinalAmount)
-		
-		// HITO v0.1: Publicar evento sales.pos.confirmed
-		if uc.publishUseCase != nil {
-			ctx := context.Background()
-			if err := uc.publishPOSSaleConfirmedEvent(ctx, posSale, tenantID); err != nil {
-				// Log error pero NO fallar la operación (venta ya registrada)
-				log.Printf("WARNING: Failed to publish sales.pos.confirmed: %v", err)
-			}
-		}
+		log.Printf("✅ PosSale created: ID=%s, Ticket=%s, Items=%d, FinalAmount=%s", posSale.ID, posSale.TicketNumber(), posSale.TotalItems(), posSale.FinalAmount)
 	} else {
-		uc.compensateProcessedStock(tenantID, authToken, processedStockEntries, "repository_not_available")
+		uc.compensateProcessedStock(ctx, saga, authToken, "repository_not_available")
 		return nil, fmt.Errorf("pos_sale repository not available")
 	}
 
@@ -216,70 +295,209 @@ func (uc *POSSaleUseCase) Execute(tenantID, authToken string, req *request.POSSa
 	var itemsResp []response.POSSaleItemResponse
 	for _, item := range posSale.Items {
 		itemsResp = append(itemsResp, response.POSSaleItemResponse{
-			ItemID:       item.ID,
-			SKU:          item.SKU,
-			ProductName:  item.ProductName,
-			Quantity:     item.Quantity,
-			UnitPrice:    item.UnitPrice,
-			Subtotal:     item.Subtotal,
-			StockEntryID: item.StockEntryID,
+			ItemID:          item.ID,
+			SKU:             item.SKU,
+			ProductName:     item.ProductName,
+			Quantity:        item.Quantity,
+			UnitPrice:       item.UnitPrice,
+			Subtotal:        item.Subtotal,
+			StockEntryID:    item.StockEntryID,
+			ListPrice:       item.ListPrice,
+			PriceOverridden: item.PriceOverridden,
+			TaxRateCode:     string(item.TaxRateCode),
+			TaxRate:         item.TaxRate,
+			NetAmount:       item.NetAmount,
+			TaxAmount:       item.TaxAmount,
+			GrossAmount:     item.GrossAmount,
 		})
 	}
 
 	// HITO: Obtener nombre del método de pago desde cache
-	paymentMethodName := "Unknown"
-	if uc.paymentMethodCache != nil {
-		paymentMethodName = uc.paymentMethodCache.GetName(posSale.PaymentMethodID)
+	paymentMethodName := uc.paymentMethodName(posSale.PaymentMethodID)
+
+	// HITO POS-SPLIT: Detalle de pagos
+	paymentsResp := make([]response.POSSalePaymentResponse, 0, len(posSale.Payments))
+	for _, payment := range posSale.Payments {
+		paymentsResp = append(paymentsResp, response.POSSalePaymentResponse{
+			PaymentMethodID:   payment.PaymentMethodID,
+			PaymentMethodName: uc.paymentMethodName(payment.PaymentMethodID),
+			Amount:            payment.Amount,
+			ChangeAmount:      payment.ChangeAmount,
+			Reference:         payment.Reference,
+			IsCash:            payment.IsCash,
+		})
 	}
 
-	// HITO: Usar UUID completo como sale_number
-	saleNumber := posSale.ID.String()
+	// HITO POS-NUMBER: Número de ticket PPPP-NNNNNNNN
+	saleNumber := posSale.TicketNumber()
 
 	return &response.POSSaleResponse{
 		PosSaleID:         posSale.ID,
 		SaleNumber:        saleNumber,
+		PointOfSaleNumber: posSale.PointOfSaleNumber,
+		PosNumber:         posSale.PosNumber,
 		Items:             itemsResp,
 		TotalItems:        posSale.TotalItems(),
 		SubtotalAmount:    posSale.TotalAmount,
 		DiscountAmount:    posSale.DiscountAmount,
 		FinalAmount:       posSale.FinalAmount,
+		Tax:               toTaxSummaryResponse(posSale.TaxSummary()),
 		PaymentMethodID:   posSale.PaymentMethodID,
 		PaymentMethodName: paymentMethodName,
+		Payments:          paymentsResp,
 		AmountPaid:        posSale.AmountPaid,
 		Change:            posSale.Change,
 		Currency:          posSale.Currency,
 		CustomerID:        posSale.CustomerID,
+		Customer:          toCustomerResponse(posSale.Customer),
+		PointOfSaleID:     posSale.PointOfSaleID,
+		CashSessionID:     posSale.CashSessionID,
 		CreatedAt:         posSale.CreatedAt,
 	}, nil
 }
 
-// publishPOSSaleConfirmedEvent publica el evento sales.pos.confirmed
-func (uc *POSSaleUseCase) publishPOSSaleConfirmedEvent(
+// taxProfile obtiene la configuración de IVA del tenant
+// Sin TaxService (desarrollo sin DB) se usa la configuración por defecto
+func (uc *POSSaleUseCase) taxProfile(ctx context.Context, tenantID uuid.UUID) (*entity.TaxProfile, error) {
+	if uc.taxService == nil {
+		return entity.NewTaxProfile(entity.DefaultTaxSettings(tenantID), nil), nil
+	}
+	return uc.taxService.Profile(ctx, tenantID)
+}
+
+// resolvePricing resuelve precio de catálogo, nombre y alícuota de cada línea del request
+// HITO POS-PRICE - Una consulta a PIM por SKU distinto. El precio del terminal solo se acepta
+// si coincide con el de catálogo o si el operador tiene permiso de override
+func (uc *POSSaleUseCase) resolvePricing(
 	ctx context.Context,
-	posSale *entity.PosSale,
-	tenantID string,
-) error {
+	tenantID, authToken string,
+	operator POSOperator,
+	profile *entity.TaxProfile,
+	items []request.POSSaleItemRequest,
+) ([]posLinePricing, error) {
+	if uc.pimClient == nil {
+		return nil, fmt.Errorf("PIM client not available for price lookup")
+	}
+
+	catalog := make(map[string]*entity.CatalogItem)
+	lines := make([]posLinePricing, len(items))
+
+	for i, item := range items {
+		catalogItem, known := catalog[item.SKU]
+		if !known {
+			productSnapshot, variantSnapshot, err := uc.pimClient.GetSnapshotForSKU(ctx, tenantID, authToken, item.SKU)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching catalog price for SKU %s: %w", item.SKU, err)
+			}
+			catalogItem, err = entity.CatalogItemFromSnapshots(item.SKU, productSnapshot, variantSnapshot)
+			if err != nil {
+				return nil, fmt.Errorf("error resolving catalog price for SKU %s: %w", item.SKU, err)
+			}
+			catalog[item.SKU] = catalogItem
+		}
+
+		unitPrice, overridden, err := catalogItem.ResolveUnitPrice(item.UnitPrice, operator.CanOverridePrice)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unit_price for SKU %s: %w", item.SKU, err)
+		}
+		if overridden {
+			log.Printf("⚠️ Price override on SKU %s: list=%s charged=%s user=%s",
+				item.SKU, catalogItem.UnitPrice, unitPrice, operator.UserID)
+		}
+
+		lines[i] = posLinePricing{
+			catalog:   catalogItem,
+			unitPrice: unitPrice,
+			taxRate:   profile.ResolveRate(item.SKU, catalogItem.CategoryID),
+		}
+	}
+
+	return lines, nil
+}
+
+// persistNumbered asigna el número de ticket, persiste la venta y registra el evento
+// en el outbox en una única transacción
+// HITO POS-NUMBER - Correlativo por tenant + punto de venta (document_sequences POS_SALE con scope)
+// Sin SequenceService / TxManager (desarrollo sin DB) la venta se persiste sin número
+func (uc *POSSaleUseCase) persistNumbered(ctx context.Context, tenantID string, posSale *entity.PosSale, saga *entity.StockSaga) error {
+	persistUnnumbered := func(ctx context.Context) error {
+		if err := uc.posSaleRepo.Create(ctx, posSale); err != nil {
+			return err
+		}
+		if err := uc.enqueuePOSSaleConfirmedEvent(ctx, posSale); err != nil {
+			return err
+		}
+		return uc.sagaService.Complete(ctx, saga)
+	}
+	if uc.txManager == nil {
+		return persistUnnumbered(ctx)
+	}
+	if uc.sequenceService == nil || posSale.PointOfSaleID == nil {
+		// HITO STOCK-SAGA: aun sin número, venta y cierre de la saga van en la misma tx
+		return uc.txManager.WithinTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+			return persistUnnumbered(ctx)
+		})
+	}
+
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		pointOfSaleNumber, err := uc.sequenceService.PointOfSaleNumberTx(ctx, tx, tenantID, *posSale.PointOfSaleID)
+		if err != nil {
+			return fmt.Errorf("error resolving point of sale number: %w", err)
+		}
+
+		posNumber, err := uc.sequenceService.NextScopedNumberTx(ctx, tx, tenantID, entity.DocumentTypePosSale, *posSale.PointOfSaleID)
+		if err != nil {
+			return fmt.Errorf("error assigning pos number: %w", err)
+		}
+
+		posSale.AssignPosNumber(pointOfSaleNumber, posNumber)
+
+		// El repositorio se suma a la transacción del contexto
+		if err := uc.posSaleRepo.Create(ctx, posSale); err != nil {
+			return err
+		}
+
+		// HITO OUTBOX: el evento se confirma junto con la venta (lo reenvía el relay)
+		if err := uc.enqueuePOSSaleConfirmedEvent(ctx, posSale); err != nil {
+			return err
+		}
+
+		// HITO STOCK-SAGA: la saga se cierra con la venta; si la tx no confirma queda
+		// STARTED y el worker la compensa como abandonada
+		return uc.sagaService.Complete(ctx, saga)
+	})
+}
+
+// enqueuePOSSaleConfirmedEvent registra el evento sales.pos.confirmed en el outbox
+func (uc *POSSaleUseCase) enqueuePOSSaleConfirmedEvent(ctx context.Context, posSale *entity.PosSale) error {
+	if uc.outbox == nil {
+		return nil
+	}
+
 	// Construir payload según contrato v1 (SOLO el payload, sin envelope)
 	payload := map[string]interface{}{
-		"pos_number": 0, // TODO: Implementar numeración secuencial
-		"customer": map[string]interface{}{
-			"customer_id":   "00000000-0000-0000-0000-000000000001", // TODO: Obtener customer_id real
-			"customer_name": "Cliente Genérico",
-			"tax_condition": "CONSUMIDOR_FINAL",
-		},
-		"currency":      posSale.Currency,
-		"exchange_rate": 1.0,
+		"pos_number":           posSale.TicketNumber(), // HITO POS-NUMBER: PPPP-NNNNNNNN
+		"point_of_sale_number": posSale.PointOfSaleNumber,
+		"pos_sequence":         posSale.PosNumber,
+		"customer":             buildCustomerPayload(posSale.Customer), // HITO CUSTOMER: comprador del snapshot
+		"currency":             posSale.Currency,
+		"exchange_rate":        1.0,
 		"totals": map[string]interface{}{
-			"subtotal": posSale.TotalAmount.InexactFloat64(),
-			"discount": posSale.DiscountAmount.InexactFloat64(),
-			"tax":      0.0,
-			"total":    posSale.FinalAmount.InexactFloat64(),
+			"subtotal":   posSale.TotalAmount.InexactFloat64(),
+			"discount":   posSale.DiscountAmount.InexactFloat64(),
+			"net":        posSale.NetAmount.InexactFloat64(),
+			"tax":        posSale.TaxAmount.InexactFloat64(),
+			"total":      posSale.FinalAmount.InexactFloat64(),
+			"price_mode": string(posSale.PriceMode),
 		},
+		"taxes": buildTaxesPayload(posSale.TaxSummary()), // HITO TAX-IVA: desglose por alícuota
+		"items": buildPosSaleItemsPayload(posSale),
 		"payment": map[string]interface{}{
 			"method":          posSale.PaymentMethodID.String(),
 			"amount_received": posSale.AmountPaid.InexactFloat64(),
 			"change_given":    posSale.Change.InexactFloat64(),
 		},
+		"payments": uc.buildPaymentsPayload(posSale),
 	}
 
 	// Serializar payload a JSON
@@ -288,35 +506,99 @@ func (uc *POSSaleUseCase) publishPOSSaleConfirmedEvent(
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	// Publicar usando eventbus (el envelope lo construye PublishEventUseCase)
-	return uc.publishUseCase.Execute(
+	// El envelope lo construye PublishEventUseCase al reenviar (relay del outbox)
+	return uc.outbox.Enqueue(
 		ctx,
-		posSale.ID.String(),      // aggregateID
-		"pos_sale",               // aggregateType
-		"sales.pos.confirmed",    // eventType
-		payloadBytes,             // payload (solo datos de negocio)
-		"order-service",          // publishedBy
+		posSale.ID.String(),   // aggregateID
+		"pos_sale",            // aggregateType
+		"sales.pos.confirmed", // eventType
+		payloadBytes,          // payload (solo datos de negocio)
 	)
 }
 
-// compensateProcessedStock revierte todas las ventas procesadas
-// HITO D: Función crítica para garantizar consistencia transaccional en POS
-func (uc *POSSaleUseCase) compensateProcessedStock(
-	tenantID, authToken string,
-	stockEntryIDs []string,
-	reason string,
-) {
-	log.Printf("🔄 Compensating %d stock entries. Reason: %s", len(stockEntryIDs), reason)
-	
-	for _, entryID := range stockEntryIDs {
-		err := uc.stockClient.CompensateSale(tenantID, authToken, entryID, reason)
-		if err != nil {
-			// CRÍTICO: Si falla compensación, log para auditoría manual
-			// No hacer panic ni detener el flujo de compensación
-			log.Printf("❌ CRITICAL ERROR: Failed to compensate stock entry %s: %v", entryID, err)
-			// TODO: Enviar alerta a sistema de monitoreo (Prometheus/Grafana)
-		} else {
-			log.Printf("✅ Compensated stock entry: %s", entryID)
+// buildPosSaleItemsPayload arma las líneas de la venta con su desglose para el evento
+// HITO TAX-IVA - net / tax / gross ya incluyen el descuento prorrateado
+func buildPosSaleItemsPayload(posSale *entity.PosSale) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(posSale.Items))
+	for _, item := range posSale.Items {
+		items = append(items, map[string]interface{}{
+			"item_id":       item.ID.String(),
+			"sku":           item.SKU,
+			"quantity":      item.Quantity,
+			"unit_price":    item.UnitPrice.InexactFloat64(),
+			"subtotal":      item.Subtotal.InexactFloat64(),
+			"tax_rate_code": string(item.TaxRateCode),
+			"tax_rate":      item.TaxRate.InexactFloat64(),
+			"net_amount":    item.NetAmount.InexactFloat64(),
+			"tax_amount":    item.TaxAmount.InexactFloat64(),
+			"gross_amount":  item.GrossAmount.InexactFloat64(),
+			// HITO POS-PRICE
+			"list_price":          item.ListPrice.InexactFloat64(),
+			"price_overridden":    item.PriceOverridden,
+			"price_overridden_by": item.PriceOverriddenBy,
+		})
+	}
+	return items
+}
+
+// buildPaymentsPayload arma el desglose de pagos por método para el evento
+// HITO POS-SPLIT - Un registro por método (montos netos de vuelto)
+func (uc *POSSaleUseCase) buildPaymentsPayload(posSale *entity.PosSale) []map[string]interface{} {
+	type methodTotals struct {
+		amount decimal.Decimal
+		change decimal.Decimal
+		isCash bool
+	}
+
+	totals := make(map[uuid.UUID]*methodTotals)
+	var order []uuid.UUID
+	for _, payment := range posSale.Payments {
+		t, ok := totals[payment.PaymentMethodID]
+		if !ok {
+			t = &methodTotals{amount: decimal.Zero, change: decimal.Zero, isCash: payment.IsCash}
+			totals[payment.PaymentMethodID] = t
+			order = append(order, payment.PaymentMethodID)
 		}
+		t.amount = t.amount.Add(payment.Amount)
+		t.change = t.change.Add(payment.ChangeAmount)
 	}
+
+	payload := make([]map[string]interface{}, 0, len(order))
+	for _, methodID := range order {
+		t := totals[methodID]
+		payload = append(payload, map[string]interface{}{
+			"method":          methodID.String(),
+			"method_name":     uc.paymentMethodName(methodID),
+			"is_cash":         t.isCash,
+			"amount_received": t.amount.InexactFloat64(),
+			"change_given":    t.change.InexactFloat64(),
+			"net_amount":      t.amount.Sub(t.change).InexactFloat64(),
+		})
+	}
+	return payload
+}
+
+// isCash indica si el método de pago es efectivo
+// Un método que no se puede resolver (sin cache o desconocido) se trata como no-efectivo:
+// no genera vuelto y un pago de más se rechaza con ErrNonCashOverpayment
+func (uc *POSSaleUseCase) isCash(paymentMethodID uuid.UUID) bool {
+	if uc.paymentMethodCache == nil {
+		return false
+	}
+	return uc.paymentMethodCache.IsCash(paymentMethodID)
+}
+
+// paymentMethodName obtiene el nombre legible del método de pago desde cache
+func (uc *POSSaleUseCase) paymentMethodName(paymentMethodID uuid.UUID) string {
+	if uc.paymentMethodCache == nil {
+		return "Unknown"
+	}
+	return uc.paymentMethodCache.GetName(paymentMethodID)
+}
+
+// compensateProcessedStock revierte todas las ventas procesadas
+// HITO D: Función crítica para garantizar consistencia transaccional en POS
+// HITO STOCK-SAGA: lo que no se pueda revertir queda pendiente para el worker de reintentos
+func (uc *POSSaleUseCase) compensateProcessedStock(ctx context.Context, saga *entity.StockSaga, authToken, reason string) {
+	uc.sagaService.Compensate(ctx, saga, authToken, reason)
 }
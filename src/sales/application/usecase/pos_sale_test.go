@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/service"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/sales/infrastructure/cache"
+	"sales/src/sales/infrastructure/client"
+	"sales/src/sales/infrastructure/stock"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// HITO STOCK-GATEWAY - Caminos de compensación de POST /pos/sale contra FakeStockService
+
+var errPosSaleInsertFailed = errors.New("pos_sales insert failed")
+
+// cashPaymentMethodID método de pago en efectivo del cache de prueba
+var cashPaymentMethodID = uuid.New()
+
+// failingPosSaleRepo falla al persistir y guarda la venta que recibió
+type failingPosSaleRepo struct {
+	port.PosSaleRepository
+	sale *entity.PosSale
+}
+
+func (r *failingPosSaleRepo) Create(ctx context.Context, sale *entity.PosSale) error {
+	r.sale = sale
+	return errPosSaleInsertFailed
+}
+
+// openCashSessionRepo siempre tiene una sesión abierta en el punto de venta
+type openCashSessionRepo struct {
+	port.CashSessionRepository
+	session *entity.CashSession
+}
+
+func (r *openCashSessionRepo) FindOpenByPointOfSale(ctx context.Context, tenantID, pointOfSaleID uuid.UUID) (*entity.CashSession, error) {
+	return r.session, nil
+}
+
+// newPIMServer responde variante y producto de cualquier SKU con precio 100
+func newPIMServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/pim/api/v1/variants/by-sku/"):
+			sku := strings.TrimPrefix(r.URL.Path, "/pim/api/v1/variants/by-sku/")
+			_ = json.NewEncoder(w).Encode(client.PIMVariantResponse{
+				VariantID:  "variant-" + sku,
+				ProductID:  "product-" + sku,
+				VariantSKU: sku,
+				Name:       sku,
+				Price:      100,
+			})
+		case strings.HasPrefix(r.URL.Path, "/pim/api/v1/products/"):
+			_ = json.NewEncoder(w).Encode(client.PIMProductResponse{
+				ProductID: strings.TrimPrefix(r.URL.Path, "/pim/api/v1/products/"),
+				Name:      "Producto de prueba",
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("KONG_INTERNAL_URL", srv.URL)
+	t.Setenv("PIM_SERVICE_PATH", "/pim")
+	return srv
+}
+
+// newPOSSaleUseCaseWithFake arma el caso de uso sin DB: saga en memoria y stock en el fake
+func newPOSSaleUseCaseWithFake(t *testing.T, fake *stock.FakeStockService, posSaleRepo port.PosSaleRepository, tenantID, pointOfSaleID uuid.UUID) *POSSaleUseCase {
+	t.Helper()
+	newPIMServer(t)
+
+	session, err := entity.NewCashSession(tenantID, pointOfSaleID, decimal.Zero, "cashier")
+	if err != nil {
+		t.Fatalf("NewCashSession: %v", err)
+	}
+
+	pmCache := cache.NewPaymentMethodCache()
+	pmCache.Put(cache.PaymentMethod{ID: cashPaymentMethodID, Code: "CASH", Name: "Efectivo"})
+
+	sagaService := service.NewStockSagaService(nil, fake)
+	stockSale := service.NewStockSaleService(fake, sagaService, 1)
+	return NewPOSSaleUseCase(stockSale, posSaleRepo, &openCashSessionRepo{session: session},
+		pmCache, nil, nil, nil, nil, client.NewPIMClient(), sagaService, nil)
+}
+
+func posSaleRequest(pointOfSaleID uuid.UUID, skus ...string) *request.POSSaleRequest {
+	req := &request.POSSaleRequest{
+		PointOfSaleID: pointOfSaleID,
+		Payments: []request.POSSalePaymentRequest{
+			{PaymentMethodID: cashPaymentMethodID, Amount: decimal.NewFromInt(10000)},
+		},
+	}
+	for _, sku := range skus {
+		req.Items = append(req.Items, request.POSSaleItemRequest{SKU: sku, Quantity: 2})
+	}
+	return req
+}
+
+func assertAvailable(t *testing.T, fake *stock.FakeStockService, tenantID, sku string, want float64) {
+	t.Helper()
+	level, ok := fake.Stock(tenantID, sku)
+	if !ok {
+		t.Fatalf("SKU %s not initialized in fake", sku)
+	}
+	if level.Available != want || level.Total != want {
+		t.Errorf("SKU %s: available=%.0f total=%.0f, want %.0f", sku, level.Available, level.Total, want)
+	}
+}
+
+func TestPOSSaleCompensatesStockWhenPersistenceFails(t *testing.T) {
+	tenantID, pointOfSaleID := uuid.New(), uuid.New()
+	fake := stock.NewFakeStockService()
+	fake.SetStock(tenantID.String(), "SKU-A", 10)
+	fake.SetStock(tenantID.String(), "SKU-B", 10)
+
+	repo := &failingPosSaleRepo{}
+	uc := newPOSSaleUseCaseWithFake(t, fake, repo, tenantID, pointOfSaleID)
+
+	_, err := uc.Execute(context.Background(), tenantID.String(), "Bearer test", POSOperator{UserID: "cashier"},
+		posSaleRequest(pointOfSaleID, "SKU-A", "SKU-B"))
+	if !errors.Is(err, errPosSaleInsertFailed) {
+		t.Fatalf("Execute error = %v, want %v", err, errPosSaleInsertFailed)
+	}
+	if repo.sale == nil {
+		t.Fatal("sale never reached the repository: stock was not processed")
+	}
+
+	// Cada descuento se revirtió una sola vez por su stock_entry_id
+	for _, item := range repo.sale.Items {
+		entry, ok := fake.Entry(item.StockEntryID.String())
+		if !ok {
+			t.Fatalf("stock entry %s of %s not found in fake", item.StockEntryID, item.SKU)
+		}
+		if !entry.Compensated || entry.Compensations != 1 {
+			t.Errorf("stock entry of %s: compensated=%v compensations=%d, want true / 1", item.SKU, entry.Compensated, entry.Compensations)
+		}
+		if entry.Reason != "pos_sale_persistence_failed" {
+			t.Errorf("stock entry of %s: reason=%q, want pos_sale_persistence_failed", item.SKU, entry.Reason)
+		}
+	}
+	assertAvailable(t, fake, tenantID.String(), "SKU-A", 10)
+	assertAvailable(t, fake, tenantID.String(), "SKU-B", 10)
+}
+
+func TestPOSSaleCompensatesAppliedLinesWhenStockRejectsALine(t *testing.T) {
+	tenantID, pointOfSaleID := uuid.New(), uuid.New()
+	fake := stock.NewFakeStockService()
+	fake.SetBatchSupport(false) // por ítem: la primera línea se descuenta antes del rechazo
+	fake.SetStock(tenantID.String(), "SKU-A", 10)
+	fake.SetStock(tenantID.String(), "SKU-B", 10)
+	fake.RejectSKU("SKU-B", "insufficient stock")
+
+	repo := &failingPosSaleRepo{}
+	uc := newPOSSaleUseCaseWithFake(t, fake, repo, tenantID, pointOfSaleID)
+
+	_, err := uc.Execute(context.Background(), tenantID.String(), "Bearer test", POSOperator{UserID: "cashier"},
+		posSaleRequest(pointOfSaleID, "SKU-A", "SKU-B"))
+	var rejected *service.StockSaleRejectedError
+	if !errors.As(err, &rejected) || rejected.SKU != "SKU-B" {
+		t.Fatalf("Execute error = %v, want StockSaleRejectedError for SKU-B", err)
+	}
+	if repo.sale != nil {
+		t.Fatal("a rejected sale must not be persisted")
+	}
+
+	if got := fake.Calls(stock.FakeOpSale); got != 2 {
+		t.Errorf("sale calls = %d, want 2", got)
+	}
+	if got := fake.Calls(stock.FakeOpCompensate); got != 1 {
+		t.Errorf("compensate calls = %d, want 1 (only SKU-A was applied)", got)
+	}
+	assertAvailable(t, fake, tenantID.String(), "SKU-A", 10)
+	assertAvailable(t, fake, tenantID.String(), "SKU-B", 10)
+}
+
+func TestPOSSaleTreatsUnresolvedPaymentMethodAsNonCash(t *testing.T) {
+	tenantID, pointOfSaleID := uuid.New(), uuid.New()
+	fake := stock.NewFakeStockService()
+	fake.SetStock(tenantID.String(), "SKU-A", 10)
+
+	repo := &failingPosSaleRepo{}
+	uc := newPOSSaleUseCaseWithFake(t, fake, repo, tenantID, pointOfSaleID)
+
+	// Un método que no está en el cache no puede dar vuelto: el pago de más se rechaza
+	req := posSaleRequest(pointOfSaleID, "SKU-A")
+	req.Payments[0].PaymentMethodID = uuid.New()
+	_, err := uc.Execute(context.Background(), tenantID.String(), "Bearer test", POSOperator{UserID: "cashier"}, req)
+	if !errors.Is(err, entity.ErrNonCashOverpayment) {
+		t.Fatalf("Execute error = %v, want %v", err, entity.ErrNonCashOverpayment)
+	}
+	if repo.sale != nil {
+		t.Fatal("a rejected sale must not be persisted")
+	}
+	assertAvailable(t, fake, tenantID.String(), "SKU-A", 10)
+}
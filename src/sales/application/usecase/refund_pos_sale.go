@@ -0,0 +1,413 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/response"
+	"sales/src/sales/application/service"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/sales/infrastructure/cache"
+	"sales/src/shared/infrastructure/database"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// RefundPosSaleUseCase caso de uso para anular / devolver ventas POS
+// HITO POS-REFUND - Void total y devolución por línea con compensación de stock
+type RefundPosSaleUseCase struct {
+	sagaService        *service.StockSagaService // HITO STOCK-SAGA: reversión reintentable del stock devuelto
+	posSaleRepo        port.PosSaleRepository
+	cashSessionRepo    port.CashSessionRepository
+	paymentMethodCache *cache.PaymentMethodCache
+	outbox             *service.OutboxService     // HITO OUTBOX
+	creditNoteService  *service.CreditNoteService // HITO CREDIT-NOTE
+	txManager          *database.TxManager
+}
+
+// NewRefundPosSaleUseCase crea una nueva instancia del caso de uso
+// Registra en sagaService el handler que marca las líneas compensadas de las sagas POS_REFUND
+func NewRefundPosSaleUseCase(
+	sagaService *service.StockSagaService,
+	posSaleRepo port.PosSaleRepository,
+	cashSessionRepo port.CashSessionRepository,
+	paymentMethodCache *cache.PaymentMethodCache,
+	outbox *service.OutboxService,
+	creditNoteService *service.CreditNoteService,
+	txManager *database.TxManager,
+) *RefundPosSaleUseCase {
+	uc := &RefundPosSaleUseCase{
+		sagaService:        sagaService,
+		posSaleRepo:        posSaleRepo,
+		cashSessionRepo:    cashSessionRepo,
+		paymentMethodCache: paymentMethodCache,
+		outbox:             outbox,
+		creditNoteService:  creditNoteService,
+		txManager:          txManager,
+	}
+	sagaService.OnStepCompensated(entity.StockSagaTypePosRefund, uc.markRefundStockCompensated)
+	return uc
+}
+
+// ExecuteVoid anula la venta completa (todas las líneas pendientes)
+func (uc *RefundPosSaleUseCase) ExecuteVoid(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	authToken, userID string,
+	posSaleID uuid.UUID,
+	req *request.VoidPosSaleRequest,
+) (*response.PosSaleRefundResponse, error) {
+	return uc.execute(ctx, tenantID, authToken, posSaleID, func(sale *entity.PosSale) (*entity.PosSaleRefund, error) {
+		return sale.Void(req.Reason, userID, req.RefundPaymentMethodID)
+	})
+}
+
+// ExecuteRefund devuelve las líneas indicadas de la venta
+func (uc *RefundPosSaleUseCase) ExecuteRefund(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	authToken, userID string,
+	posSaleID uuid.UUID,
+	req *request.RefundPosSaleRequest,
+) (*response.PosSaleRefundResponse, error) {
+	// HITO POS-REFUND: quantity omitida = línea completa
+	var lines []entity.PosSaleRefundLine
+	for _, item := range req.NormalizedItems() {
+		lines = append(lines, entity.PosSaleRefundLine{ItemID: item.ItemID, Quantity: item.Quantity})
+	}
+
+	return uc.execute(ctx, tenantID, authToken, posSaleID, func(sale *entity.PosSale) (*entity.PosSaleRefund, error) {
+		return sale.Refund(lines, req.Reason, userID, req.RefundPaymentMethodID)
+	})
+}
+
+// execute flujo común de anulación / devolución:
+// 1. Cargar venta (con items ya devueltos marcados)
+// 2. Generar documento de devolución en el aggregate
+// 3. Vincular a la caja abierta del terminal (si hay)
+// 4. Persistir documento + estado de la venta + saga POS_REFUND + sales.pos.refunded
+// en una sola transacción (+ nota de crédito y sales.credit_note.issued)
+// 5. Compensar stock por línea usando el stock_entry_id guardado en la venta
+//
+// Se persiste ANTES de compensar: la restricción UNIQUE por línea impide compensar
+// dos veces el mismo stock_entry. La saga queda registrada con la devolución: lo que no
+// se pueda revertir lo reintenta el worker de compensaciones (backoff y ESCALATED).
+// Cada línea compensada emite sales.pos.refund_stock_compensated (HITO OUTBOX)
+func (uc *RefundPosSaleUseCase) execute(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	authToken string,
+	posSaleID uuid.UUID,
+	buildRefund func(sale *entity.PosSale) (*entity.PosSaleRefund, error),
+) (*response.PosSaleRefundResponse, error) {
+	// 1. Cargar venta
+	sale, err := uc.posSaleRepo.FindByID(ctx, tenantID, posSaleID)
+	if err != nil {
+		return nil, err
+	}
+	previousRefundedAmount := sale.RefundedAmount
+
+	// 2. Documento de devolución (reglas de negocio en el aggregate)
+	refund, err := buildRefund(sale)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Caja abierta del terminal (el dinero sale de ahí)
+	if sale.PointOfSaleID != nil && uc.cashSessionRepo != nil {
+		session, err := uc.cashSessionRepo.FindOpenByPointOfSale(ctx, tenantID, *sale.PointOfSaleID)
+		switch {
+		case err == nil:
+			refund.AssignCashSession(session)
+		case err == entity.ErrNoOpenCashSession:
+			log.Printf("⚠️ No open cash session for POS %s, refund %s not linked to a session", *sale.PointOfSaleID, refund.ID)
+		default:
+			return nil, fmt.Errorf("error checking cash session: %w", err)
+		}
+	}
+
+	// 4. Persistir con la saga de reversión y el evento (+ nota de crédito si la venta estaba facturada)
+	reason := "pos_refund"
+	if refund.Type == entity.PosSaleRefundTypeVoid {
+		reason = "pos_void"
+	}
+	saga := newRefundStockSaga(refund)
+	creditNote, err := uc.persistWithCreditNote(ctx, sale, refund, previousRefundedAmount, saga, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ PosSale %s: %s %s, Amount=%s, Items=%d, Status=%s",
+		sale.ID, refund.Type, refund.ID, refund.RefundAmount, len(refund.Items), sale.Status)
+
+	// 5. Compensar stock
+	uc.compensateRefundedStock(ctx, authToken, refund, saga)
+
+	resp := uc.buildResponse(sale, refund)
+	if creditNote != nil {
+		resp.CreditNote = toCreditNoteResponse(creditNote)
+	}
+
+	return resp, nil
+}
+
+// persistWithCreditNote persiste la devolución, la saga de reversión de stock, el evento
+// sales.pos.refunded y la nota de crédito en una sola transacción
+// Sin servicio de notas de crédito la tx no incluye la nota; sin TxManager (desarrollo
+// sin DB) las operaciones no son atómicas
+func (uc *RefundPosSaleUseCase) persistWithCreditNote(
+	ctx context.Context,
+	sale *entity.PosSale,
+	refund *entity.PosSaleRefund,
+	previousRefundedAmount decimal.Decimal,
+	saga *entity.StockSaga,
+	reason string,
+) (*entity.CreditNote, error) {
+	persistRefund := func(ctx context.Context) error {
+		if err := uc.posSaleRepo.CreateRefund(ctx, sale, refund, previousRefundedAmount); err != nil {
+			return err
+		}
+		// HITO STOCK-SAGA: sin la saga no hay devolución (el stock quedaría sin revertir)
+		if err := uc.sagaService.BeginReversal(ctx, saga, reason); err != nil {
+			return err
+		}
+		// HITO OUTBOX: el evento se confirma junto con la devolución (lo reenvía el relay)
+		return uc.enqueuePOSSaleRefundedEvent(ctx, sale, refund, saga)
+	}
+	if uc.txManager == nil {
+		return nil, persistRefund(ctx)
+	}
+
+	var creditNote *entity.CreditNote
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if err := persistRefund(ctx); err != nil {
+			return err
+		}
+		if uc.creditNoteService == nil {
+			return nil
+		}
+
+		refundID := refund.ID
+		var err error
+		creditNote, err = uc.creditNoteService.IssueTx(
+			ctx, tx,
+			sale.TenantID,
+			entity.InvoiceSourcePosSale,
+			sale.ID,
+			&refundID,
+			refund.Reason,
+			refund.RefundedBy,
+			func(invoice *entity.Invoice) []entity.CreditNoteItem {
+				return service.CreditNoteItemsFromPosRefund(refund)
+			},
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return creditNote, nil
+}
+
+// newRefundStockSaga arma la saga POS_REFUND de la devolución: un paso por stock_entry_id
+func newRefundStockSaga(refund *entity.PosSaleRefund) *entity.StockSaga {
+	saga := entity.NewStockSaga(refund.TenantID.String(), entity.StockSagaTypePosRefund, refund.ID.String())
+	for _, item := range refund.Items {
+		saga.AddStep(item.SKU, float64(item.Quantity), item.StockEntryID.String())
+	}
+	return saga
+}
+
+// compensateRefundedStock revierte el stock de cada línea devuelta a través de la saga
+// Lo que falle queda COMPENSATING para el worker de reintentos
+func (uc *RefundPosSaleUseCase) compensateRefundedStock(
+	ctx context.Context,
+	authToken string,
+	refund *entity.PosSaleRefund,
+	saga *entity.StockSaga,
+) {
+	log.Printf("🔄 Compensating %d stock entries for refund %s (saga %s). Reason: %s",
+		len(refund.Items), refund.ID, saga.ID, saga.CompensationReason)
+
+	// La devolución ya está persistida: un cliente que corta no debe dejar stock sin revertir
+	if err := uc.sagaService.CompensatePending(context.WithoutCancel(ctx), saga, authToken); err != nil {
+		log.Printf("❌ Stock saga %s of refund %s: %v", saga.ID, refund.ID, err)
+	}
+
+	compensated := make(map[string]bool, len(saga.Steps))
+	for _, step := range saga.Steps {
+		compensated[step.StockEntryID] = step.Status == entity.StockSagaStepCompensated
+	}
+	for i := range refund.Items {
+		refund.Items[i].StockCompensated = compensated[refund.Items[i].StockEntryID.String()]
+	}
+}
+
+// markRefundStockCompensated marca la línea devuelta de un paso compensado de la saga POS_REFUND
+// y registra sales.pos.refund_stock_compensated en la misma transacción
+// Corre tanto en el request como en el worker de reintentos
+func (uc *RefundPosSaleUseCase) markRefundStockCompensated(ctx context.Context, saga *entity.StockSaga, step *entity.StockSagaStep) error {
+	refundID, err := uuid.Parse(saga.Reference)
+	if err != nil {
+		return fmt.Errorf("invalid refund reference %q: %w", saga.Reference, err)
+	}
+
+	mark := func(ctx context.Context) error {
+		if err := uc.posSaleRepo.MarkRefundStockCompensated(ctx, refundID, step.StockEntryID); err != nil {
+			return err
+		}
+		return uc.enqueueRefundStockCompensatedEvent(ctx, saga, step)
+	}
+	if uc.txManager == nil {
+		return mark(ctx)
+	}
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context, _ *sql.Tx) error {
+		return mark(ctx)
+	})
+}
+
+// enqueuePOSSaleRefundedEvent registra el evento sales.pos.refunded en el outbox
+// La compensación de stock todavía no ocurrió: cada línea se informa por separado con
+// sales.pos.refund_stock_compensated (stock_saga_id permite seguirla en GET /admin/sagas)
+func (uc *RefundPosSaleUseCase) enqueuePOSSaleRefundedEvent(
+	ctx context.Context,
+	sale *entity.PosSale,
+	refund *entity.PosSaleRefund,
+	saga *entity.StockSaga,
+) error {
+	if uc.outbox == nil {
+		return nil
+	}
+
+	items := make([]map[string]interface{}, 0, len(refund.Items))
+	for _, item := range refund.Items {
+		items = append(items, map[string]interface{}{
+			"pos_sale_item_id": item.PosSaleItemID.String(),
+			"sku":              item.SKU,
+			"quantity":         item.Quantity,
+			"refund_amount":    item.RefundAmount.InexactFloat64(),
+			"stock_entry_id":   item.StockEntryID.String(),
+		})
+	}
+
+	payload := map[string]interface{}{
+		"refund_id":     refund.ID.String(),
+		"pos_sale_id":   sale.ID.String(),
+		"pos_number":    sale.TicketNumber(),
+		"refund_type":   string(refund.Type),
+		"reason":        refund.Reason,
+		"currency":      refund.Currency,
+		"refund_amount": refund.RefundAmount.InexactFloat64(),
+		"payment": map[string]interface{}{
+			"method":      refund.PaymentMethodID.String(),
+			"method_name": uc.paymentMethodName(refund.PaymentMethodID),
+		},
+		"items":                items,
+		"sale_status":          string(sale.Status),
+		"sale_refunded_amount": sale.RefundedAmount.InexactFloat64(),
+		"refunded_by":          refund.RefundedBy,
+		"stock_saga_id":        saga.ID.String(),
+	}
+	if refund.CashSessionID != nil {
+		payload["cash_session_id"] = refund.CashSessionID.String()
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return uc.outbox.Enqueue(
+		ctx,
+		sale.ID.String(),     // aggregateID
+		"pos_sale",           // aggregateType
+		"sales.pos.refunded", // eventType
+		payloadBytes,         // payload (solo datos de negocio)
+	)
+}
+
+// enqueueRefundStockCompensatedEvent registra sales.pos.refund_stock_compensated en el outbox
+// Una línea por evento: la compensación puede completarse en el request o en un reintento
+func (uc *RefundPosSaleUseCase) enqueueRefundStockCompensatedEvent(
+	ctx context.Context,
+	saga *entity.StockSaga,
+	step *entity.StockSagaStep,
+) error {
+	if uc.outbox == nil {
+		return nil
+	}
+
+	payload := map[string]interface{}{
+		"refund_id":           saga.Reference,
+		"stock_saga_id":       saga.ID.String(),
+		"sku":                 step.SKU,
+		"quantity":            step.Quantity,
+		"stock_entry_id":      step.StockEntryID,
+		"compensation_reason": saga.CompensationReason,
+	}
+	if step.CompensatedAt != nil {
+		payload["compensated_at"] = step.CompensatedAt.Format(time.RFC3339)
+	}
+
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return uc.outbox.Enqueue(
+		ctx,
+		saga.Reference,                       // aggregateID
+		"pos_sale_refund",                    // aggregateType
+		"sales.pos.refund_stock_compensated", // eventType
+		payloadBytes,                         // payload (solo datos de negocio)
+	)
+}
+
+// buildResponse arma el DTO de respuesta
+func (uc *RefundPosSaleUseCase) buildResponse(sale *entity.PosSale, refund *entity.PosSaleRefund) *response.PosSaleRefundResponse {
+	items := make([]response.PosSaleRefundItemResponse, 0, len(refund.Items))
+	for _, item := range refund.Items {
+		items = append(items, response.PosSaleRefundItemResponse{
+			PosSaleItemID:    item.PosSaleItemID,
+			SKU:              item.SKU,
+			Quantity:         item.Quantity,
+			Subtotal:         item.Subtotal,
+			RefundAmount:     item.RefundAmount,
+			StockEntryID:     item.StockEntryID,
+			StockCompensated: item.StockCompensated,
+		})
+	}
+
+	return &response.PosSaleRefundResponse{
+		RefundID:           refund.ID,
+		PosSaleID:          sale.ID,
+		RefundType:         string(refund.Type),
+		Reason:             refund.Reason,
+		RefundAmount:       refund.RefundAmount,
+		PaymentMethodID:    refund.PaymentMethodID,
+		PaymentMethodName:  uc.paymentMethodName(refund.PaymentMethodID),
+		Currency:           refund.Currency,
+		CashSessionID:      refund.CashSessionID,
+		RefundedBy:         refund.RefundedBy,
+		Items:              items,
+		SaleStatus:         string(sale.Status),
+		SaleRefundedAmount: sale.RefundedAmount,
+		CreatedAt:          refund.CreatedAt,
+	}
+}
+
+// paymentMethodName obtiene el nombre legible del método de pago desde cache
+func (uc *RefundPosSaleUseCase) paymentMethodName(paymentMethodID uuid.UUID) string {
+	if uc.paymentMethodCache == nil {
+		return "Unknown"
+	}
+	return uc.paymentMethodCache.GetName(paymentMethodID)
+}
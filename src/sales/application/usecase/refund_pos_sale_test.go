@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/service"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/sales/infrastructure/stock"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// HITO POS-REFUND / STOCK-SAGA - La anulación revierte stock a través de una saga reintentable
+
+// refundPosSaleRepo devuelve una venta fija y registra devolución y líneas compensadas
+type refundPosSaleRepo struct {
+	port.PosSaleRepository
+	sale        *entity.PosSale
+	refund      *entity.PosSaleRefund
+	compensated []string
+}
+
+func (r *refundPosSaleRepo) FindByID(ctx context.Context, tenantID, posSaleID uuid.UUID) (*entity.PosSale, error) {
+	return r.sale, nil
+}
+
+func (r *refundPosSaleRepo) CreateRefund(ctx context.Context, sale *entity.PosSale, refund *entity.PosSaleRefund, previousRefundedAmount decimal.Decimal) error {
+	r.refund = refund
+	return nil
+}
+
+func (r *refundPosSaleRepo) MarkRefundStockCompensated(ctx context.Context, refundID uuid.UUID, stockEntryID string) error {
+	if refundID != r.refund.ID {
+		return entity.ErrPosSaleItemNotFound
+	}
+	r.compensated = append(r.compensated, stockEntryID)
+	return nil
+}
+
+// memoryStockSagaRepo log de sagas en memoria; ClaimDue ignora el backoff (cada tick del
+// worker de prueba reintenta todas las sagas COMPENSATING)
+type memoryStockSagaRepo struct {
+	port.StockSagaRepository
+	sagas map[uuid.UUID]*entity.StockSaga
+}
+
+func newMemoryStockSagaRepo() *memoryStockSagaRepo {
+	return &memoryStockSagaRepo{sagas: make(map[uuid.UUID]*entity.StockSaga)}
+}
+
+func (r *memoryStockSagaRepo) Create(ctx context.Context, saga *entity.StockSaga) error {
+	r.sagas[saga.ID] = saga
+	return nil
+}
+
+func (r *memoryStockSagaRepo) Update(ctx context.Context, saga *entity.StockSaga) error {
+	r.sagas[saga.ID] = saga
+	return nil
+}
+
+func (r *memoryStockSagaRepo) AbandonStale(ctx context.Context, olderThan time.Duration, reason string) (int64, error) {
+	return 0, nil
+}
+
+func (r *memoryStockSagaRepo) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.StockSaga, error) {
+	var due []*entity.StockSaga
+	for _, saga := range r.sagas {
+		if saga.Status == entity.StockSagaStatusCompensating {
+			due = append(due, saga)
+		}
+	}
+	return due, nil
+}
+
+// memoryOutboxRepo guarda los eventos registrados; err simula una falla del INSERT
+type memoryOutboxRepo struct {
+	port.OutboxRepository
+	events []*entity.OutboxEvent
+	err    error
+}
+
+func (r *memoryOutboxRepo) Append(ctx context.Context, event *entity.OutboxEvent) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *memoryOutboxRepo) count(eventType string) int {
+	n := 0
+	for _, event := range r.events {
+		if event.EventType == eventType {
+			n++
+		}
+	}
+	return n
+}
+
+// soldPosSale descuenta stock en el fake y arma la venta con los stock_entry_id obtenidos
+func soldPosSale(t *testing.T, fake *stock.FakeStockService, tenantID uuid.UUID, skus ...string) *entity.PosSale {
+	t.Helper()
+	sale := &entity.PosSale{
+		ID:              uuid.New(),
+		TenantID:        tenantID,
+		PaymentMethodID: uuid.New(),
+		Currency:        "ARS",
+		Status:          entity.PosSaleStatusCompleted,
+	}
+	for _, sku := range skus {
+		fake.SetStock(tenantID.String(), sku, 10)
+		result, err := fake.ProcessSaleAtomic(context.Background(), tenantID.String(), "", sku, 2, "POS-1")
+		if err != nil || !result.Success {
+			t.Fatalf("ProcessSaleAtomic(%s) = %+v, %v", sku, result, err)
+		}
+		subtotal := decimal.NewFromInt(200)
+		sale.Items = append(sale.Items, entity.PosSaleItem{
+			ID:           uuid.New(),
+			PosSaleID:    sale.ID,
+			SKU:          sku,
+			Quantity:     2,
+			UnitPrice:    decimal.NewFromInt(100),
+			Subtotal:     subtotal,
+			StockEntryID: uuid.MustParse(result.StockEntryID),
+		})
+		sale.TotalAmount = sale.TotalAmount.Add(subtotal)
+	}
+	sale.FinalAmount = sale.TotalAmount
+	return sale
+}
+
+func TestVoidPosSaleRetriesFailedStockCompensationThroughSaga(t *testing.T) {
+	tenantID := uuid.New()
+	fake := stock.NewFakeStockService()
+	sale := soldPosSale(t, fake, tenantID, "SKU-A", "SKU-B")
+
+	sagaRepo := newMemoryStockSagaRepo()
+	sagaService := service.NewStockSagaService(sagaRepo, fake)
+	posSaleRepo := &refundPosSaleRepo{sale: sale}
+	outboxRepo := &memoryOutboxRepo{}
+	uc := NewRefundPosSaleUseCase(sagaService, posSaleRepo, nil, nil, service.NewOutboxService(outboxRepo), nil, nil)
+
+	// La primera compensación falla: queda pendiente en la saga, no en un log
+	fake.FailNext(stock.FakeOpCompensate, 1)
+	resp, err := uc.ExecuteVoid(context.Background(), tenantID, "", "cashier", sale.ID, &request.VoidPosSaleRequest{Reason: "cliente arrepentido"})
+	if err != nil {
+		t.Fatalf("ExecuteVoid: %v", err)
+	}
+
+	if len(sagaRepo.sagas) != 1 {
+		t.Fatalf("sagas recorded = %d, want 1", len(sagaRepo.sagas))
+	}
+	var saga *entity.StockSaga
+	for _, s := range sagaRepo.sagas {
+		saga = s
+	}
+	if saga.SagaType != entity.StockSagaTypePosRefund || saga.Reference != resp.RefundID.String() {
+		t.Errorf("saga = %s %s, want %s %s", saga.SagaType, saga.Reference, entity.StockSagaTypePosRefund, resp.RefundID)
+	}
+	if saga.Status != entity.StockSagaStatusCompensating || saga.PendingSteps() != 1 || saga.CompensationReason != "pos_void" {
+		t.Fatalf("saga status=%s pending=%d reason=%s, want COMPENSATING / 1 / pos_void", saga.Status, saga.PendingSteps(), saga.CompensationReason)
+	}
+	compensatedLines := 0
+	for _, item := range resp.Items {
+		if item.StockCompensated {
+			compensatedLines++
+		}
+	}
+	if compensatedLines != 1 || len(posSaleRepo.compensated) != 1 {
+		t.Errorf("compensated lines = %d (marked %d), want 1", compensatedLines, len(posSaleRepo.compensated))
+	}
+
+	// sales.pos.refunded se registró con la devolución e identifica la saga
+	if got := outboxRepo.count("sales.pos.refunded"); got != 1 {
+		t.Fatalf("sales.pos.refunded events = %d, want 1", got)
+	}
+	var refunded map[string]interface{}
+	if err := json.Unmarshal(outboxRepo.events[0].Payload, &refunded); err != nil {
+		t.Fatalf("sales.pos.refunded payload: %v", err)
+	}
+	if refunded["stock_saga_id"] != saga.ID.String() {
+		t.Errorf("stock_saga_id = %v, want %s", refunded["stock_saga_id"], saga.ID)
+	}
+	if got := outboxRepo.count("sales.pos.refund_stock_compensated"); got != 1 {
+		t.Errorf("sales.pos.refund_stock_compensated events = %d, want 1", got)
+	}
+
+	// El worker de reintentos completa la reversión y marca la línea pendiente
+	retry := NewRetryStockCompensationsUseCase(sagaRepo, sagaService, "")
+	if processed, err := retry.ProcessDue(context.Background(), 10); err != nil || processed != 1 {
+		t.Fatalf("ProcessDue = %d, %v; want 1 saga processed", processed, err)
+	}
+	if saga.Status != entity.StockSagaStatusCompensated {
+		t.Errorf("saga status = %s, want %s", saga.Status, entity.StockSagaStatusCompensated)
+	}
+	if len(posSaleRepo.compensated) != 2 {
+		t.Errorf("refund lines marked compensated = %d, want 2", len(posSaleRepo.compensated))
+	}
+	if got := outboxRepo.count("sales.pos.refund_stock_compensated"); got != 2 {
+		t.Errorf("sales.pos.refund_stock_compensated events = %d, want 2", got)
+	}
+	for _, item := range sale.Items {
+		if entry, _ := fake.Entry(item.StockEntryID.String()); entry.Compensations != 1 {
+			t.Errorf("stock entry of %s compensated %d times, want 1", item.SKU, entry.Compensations)
+		}
+		assertAvailable(t, fake, tenantID.String(), item.SKU, 10)
+	}
+}
+
+func TestRefundIsRejectedWhenRefundedEventCannotBeEnqueued(t *testing.T) {
+	tenantID := uuid.New()
+	fake := stock.NewFakeStockService()
+	sale := soldPosSale(t, fake, tenantID, "SKU-A")
+
+	errOutbox := errors.New("outbox insert failed")
+	sagaService := service.NewStockSagaService(newMemoryStockSagaRepo(), fake)
+	uc := NewRefundPosSaleUseCase(sagaService, &refundPosSaleRepo{sale: sale}, nil, nil,
+		service.NewOutboxService(&memoryOutboxRepo{err: errOutbox}), nil, nil)
+
+	// Sin evento no hay devolución: el error aborta antes de tocar stock
+	_, err := uc.ExecuteRefund(context.Background(), tenantID, "", "cashier", sale.ID, &request.RefundPosSaleRequest{
+		ItemIDs: []uuid.UUID{sale.Items[0].ID},
+		Reason:  "producto fallado",
+	})
+	if !errors.Is(err, errOutbox) {
+		t.Fatalf("ExecuteRefund error = %v, want %v", err, errOutbox)
+	}
+	if got := fake.Calls(stock.FakeOpCompensate); got != 0 {
+		t.Errorf("compensate calls = %d, want 0", got)
+	}
+}
+
+func TestRefundRejectsPartialLineQuantity(t *testing.T) {
+	tenantID := uuid.New()
+	fake := stock.NewFakeStockService()
+	sale := soldPosSale(t, fake, tenantID, "SKU-A", "SKU-B")
+
+	sagaService := service.NewStockSagaService(newMemoryStockSagaRepo(), fake)
+	posSaleRepo := &refundPosSaleRepo{sale: sale}
+	uc := NewRefundPosSaleUseCase(sagaService, posSaleRepo, nil, nil, service.NewOutboxService(&memoryOutboxRepo{}), nil, nil)
+
+	// La línea vendió 2 unidades: devolver 1 se rechaza antes de registrar la devolución
+	_, err := uc.ExecuteRefund(context.Background(), tenantID, "", "cashier", sale.ID, &request.RefundPosSaleRequest{
+		Items:  []request.RefundPosSaleItemRequest{{ItemID: sale.Items[0].ID, Quantity: 1}},
+		Reason: "devuelve una sola unidad",
+	})
+	if !errors.Is(err, entity.ErrRefundPartialQuantity) {
+		t.Fatalf("ExecuteRefund error = %v, want %v", err, entity.ErrRefundPartialQuantity)
+	}
+	if posSaleRepo.refund != nil || fake.Calls(stock.FakeOpCompensate) != 0 {
+		t.Fatal("a rejected partial refund must not be persisted nor compensate stock")
+	}
+
+	// Con la cantidad vendida (o sin cantidad) la línea se devuelve completa
+	resp, err := uc.ExecuteRefund(context.Background(), tenantID, "", "cashier", sale.ID, &request.RefundPosSaleRequest{
+		Items:  []request.RefundPosSaleItemRequest{{ItemID: sale.Items[0].ID, Quantity: 2}},
+		Reason: "producto fallado",
+	})
+	if err != nil {
+		t.Fatalf("ExecuteRefund with full quantity: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].Quantity != 2 {
+		t.Errorf("refund items = %+v, want one line with quantity 2", resp.Items)
+	}
+	if sale.Status != entity.PosSaleStatusPartiallyRefunded {
+		t.Errorf("sale status = %s, want %s", sale.Status, entity.PosSaleStatusPartiallyRefunded)
+	}
+}
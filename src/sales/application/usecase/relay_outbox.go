@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/mercadocercano/eventbus"
+)
+
+const (
+	// eventBusTimeout tiempo máximo por publicación en el eventbus
+	eventBusTimeout = 10 * time.Second
+	// staleOutboxAfter eventos PROCESSING sin cambios se consideran abandonados
+	staleOutboxAfter = 2 * time.Minute
+)
+
+// RelayOutboxUseCase reenvía los eventos del outbox al eventbus
+// HITO OUTBOX - Al-menos-una-vez: un relay caído entre publicar y marcar PUBLISHED
+// reenvía el evento (los consumidores deduplican por aggregate_id + event_type)
+type RelayOutboxUseCase struct {
+	outboxRepo     port.OutboxRepository
+	publishUseCase *eventbus.PublishEventUseCase
+}
+
+// NewRelayOutboxUseCase crea una nueva instancia del caso de uso
+func NewRelayOutboxUseCase(outboxRepo port.OutboxRepository, publishUseCase *eventbus.PublishEventUseCase) *RelayOutboxUseCase {
+	return &RelayOutboxUseCase{
+		outboxRepo:     outboxRepo,
+		publishUseCase: publishUseCase,
+	}
+}
+
+// ProcessDue reenvía hasta limit eventos con reintento vencido (lo invoca el worker)
+// Primero libera los PROCESSING abandonados por un relay caído
+func (uc *RelayOutboxUseCase) ProcessDue(ctx context.Context, limit int) (int, error) {
+	released, err := uc.outboxRepo.ReleaseStale(ctx, staleOutboxAfter)
+	if err != nil {
+		return 0, err
+	}
+	if released > 0 {
+		log.Printf("⚠️ Released %d stale PROCESSING outbox events", released)
+	}
+
+	events, err := uc.outboxRepo.ClaimDue(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, event := range events {
+		if err := uc.relay(ctx, event); err != nil {
+			// Queda PROCESSING: ReleaseStale lo devuelve a PENDING
+			log.Printf("❌ Error relaying outbox event %s: %v", event.ID, err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// relay publica un evento PROCESSING y persiste el resultado
+func (uc *RelayOutboxUseCase) relay(ctx context.Context, event *entity.OutboxEvent) error {
+	publishCtx, cancel := context.WithTimeout(ctx, eventBusTimeout)
+	err := uc.publishUseCase.Execute(
+		publishCtx,
+		event.AggregateID,
+		event.AggregateType,
+		event.EventType,
+		event.Payload,
+		event.PublishedBy,
+	)
+	cancel()
+
+	if err != nil {
+		log.Printf("⚠️ Outbox event %s (%s): publish failed (attempt %d/%d): %v",
+			event.ID, event.EventType, event.Attempts, entity.MaxOutboxAttempts, err)
+		err = event.ScheduleRetry(err)
+	} else {
+		err = event.MarkPublished()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := uc.outboxRepo.Update(ctx, event); err != nil {
+		return err
+	}
+
+	if event.Status == entity.OutboxStatusDead {
+		log.Printf("💀 Outbox event %s (%s) moved to DEAD: %s", event.ID, event.EventType, event.LastError)
+	}
+
+	return nil
+}
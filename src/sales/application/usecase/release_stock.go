@@ -1,28 +1,29 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"sales/src/sales/application/request"
 	"sales/src/sales/application/response"
-	"sales/src/sales/infrastructure/client"
+	"sales/src/sales/domain/port"
 )
 
 // ReleaseStockUseCase caso de uso para liberar stock reservado
 type ReleaseStockUseCase struct {
-	stockClient *client.StockClient
+	stockClient port.StockGateway
 }
 
 // NewReleaseStockUseCase crea una nueva instancia del caso de uso
-func NewReleaseStockUseCase(stockClient *client.StockClient) *ReleaseStockUseCase {
+func NewReleaseStockUseCase(stockClient port.StockGateway) *ReleaseStockUseCase {
 	return &ReleaseStockUseCase{
 		stockClient: stockClient,
 	}
 }
 
 // Execute ejecuta la liberación de stock
-func (uc *ReleaseStockUseCase) Execute(tenantID, authToken string, req *request.ReleaseStockRequest) (*response.ReleaseStockResponse, error) {
+func (uc *ReleaseStockUseCase) Execute(ctx context.Context, tenantID, authToken string, req *request.ReleaseStockRequest) (*response.ReleaseStockResponse, error) {
 	// Llamar a stock-service vía Kong
-	stockResp, err := uc.stockClient.ReleaseStock(tenantID, authToken, req.SKU, req.Quantity, req.Reference)
+	stockResp, err := uc.stockClient.ReleaseStock(ctx, tenantID, authToken, req.SKU, req.Quantity, req.Reference)
 	if err != nil {
 		// Si es error de stock reservado insuficiente, propagarlo como 409
 		if contains(err.Error(), "insufficient reserved stock") || contains(err.Error(), "409") {
@@ -1,28 +1,29 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"sales/src/sales/application/request"
 	"sales/src/sales/application/response"
-	"sales/src/sales/infrastructure/client"
+	"sales/src/sales/domain/port"
 
 	"github.com/google/uuid"
 )
 
 // ReserveStockUseCase caso de uso para reservar stock
 type ReserveStockUseCase struct {
-	stockClient *client.StockClient
+	stockClient port.StockGateway
 }
 
 // NewReserveStockUseCase crea una nueva instancia del caso de uso
-func NewReserveStockUseCase(stockClient *client.StockClient) *ReserveStockUseCase {
+func NewReserveStockUseCase(stockClient port.StockGateway) *ReserveStockUseCase {
 	return &ReserveStockUseCase{
 		stockClient: stockClient,
 	}
 }
 
 // Execute ejecuta la reserva de stock (multi-item, ALL OR NOTHING)
-func (uc *ReserveStockUseCase) Execute(tenantID, authToken string, req *request.ReserveStockRequest) (*response.ReserveStockResponse, error) {
+func (uc *ReserveStockUseCase) Execute(ctx context.Context, tenantID, authToken string, req *request.ReserveStockRequest) (*response.ReserveStockResponse, error) {
 	var itemsResponse []response.ReserveStockItemResponse
 	var reservedItems []response.ReserveStockItemResponse
 
@@ -31,11 +32,13 @@ func (uc *ReserveStockUseCase) Execute(tenantID, authToken string, req *request.
 		// Generar reference UUID por item
 		reference := uuid.New().String()
 
-		stockResp, err := uc.stockClient.ReserveStock(tenantID, authToken, item.SKU, item.Quantity, reference)
+		stockResp, err := uc.stockClient.ReserveStock(ctx, tenantID, authToken, item.SKU, item.Quantity, reference)
 		if err != nil {
 			// Si falla un item, liberar los ya reservados (rollback)
+			// El rollback no hereda la cancelación del request
+			rollbackCtx := context.WithoutCancel(ctx)
 			for _, reservedItem := range reservedItems {
-				_, _ = uc.stockClient.ReleaseStock(tenantID, authToken, reservedItem.SKU, reservedItem.Quantity, reservedItem.Reference)
+				_, _ = uc.stockClient.ReleaseStock(rollbackCtx, tenantID, authToken, reservedItem.SKU, reservedItem.Quantity, reservedItem.Reference)
 			}
 
 			// Propagarcomo 409
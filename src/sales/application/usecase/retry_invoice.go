@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// RetryInvoiceUseCase caso de uso para reintentar un comprobante rechazado
+// HITO FISCAL - Ej: tras corregir los datos fiscales del emisor o agotar reintentos
+type RetryInvoiceUseCase struct {
+	invoiceRepo port.InvoiceRepository
+	authorizeUC *AuthorizeInvoiceUseCase
+}
+
+// NewRetryInvoiceUseCase crea una nueva instancia del caso de uso
+func NewRetryInvoiceUseCase(invoiceRepo port.InvoiceRepository, authorizeUC *AuthorizeInvoiceUseCase) *RetryInvoiceUseCase {
+	return &RetryInvoiceUseCase{
+		invoiceRepo: invoiceRepo,
+		authorizeUC: authorizeUC,
+	}
+}
+
+// Execute vuelve el comprobante a PENDING (intentos en 0) e intenta autorizarlo en línea
+// Falla con entity.ErrInvoiceNotRetryable si no está REJECTED
+func (uc *RetryInvoiceUseCase) Execute(ctx context.Context, tenantID, invoiceID uuid.UUID) (*response.InvoiceResponse, error) {
+	invoice, err := uc.invoiceRepo.FindByID(ctx, tenantID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := invoice.Retry(); err != nil {
+		return nil, err
+	}
+	if err := uc.invoiceRepo.Update(ctx, invoice); err != nil {
+		return nil, err
+	}
+
+	if uc.authorizeUC != nil {
+		if err := uc.authorizeUC.AuthorizeNow(ctx, invoice.ID); err != nil {
+			log.Printf("⚠️ Invoice %s left for background authorization: %v", invoice.ID, err)
+		}
+	}
+
+	current, err := uc.invoiceRepo.FindByID(ctx, tenantID, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toInvoiceResponse(current), nil
+}
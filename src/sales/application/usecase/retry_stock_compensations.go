@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"sales/src/sales/application/service"
+	"sales/src/sales/domain/port"
+)
+
+const (
+	// abandonedSagaAfter sagas STARTED sin cambios se consideran de un proceso caído
+	abandonedSagaAfter = 10 * time.Minute
+	// sagaClaimLease tiempo que una saga tomada queda fuera de la cola mientras se compensa
+	sagaClaimLease = 2 * time.Minute
+	// abandonedSagaReason motivo de compensación de las sagas abandonadas
+	abandonedSagaReason = "saga_abandoned"
+)
+
+// RetryStockCompensationsUseCase reintenta las compensaciones de stock pendientes
+// HITO STOCK-SAGA - Backoff exponencial por saga; agotados los intentos queda ESCALATED
+type RetryStockCompensationsUseCase struct {
+	sagaRepo     port.StockSagaRepository
+	sagaService  *service.StockSagaService
+	serviceToken string // Authorization para stock-service fuera de un request (puede ser vacío)
+}
+
+// NewRetryStockCompensationsUseCase crea una nueva instancia del caso de uso
+func NewRetryStockCompensationsUseCase(
+	sagaRepo port.StockSagaRepository,
+	sagaService *service.StockSagaService,
+	serviceToken string,
+) *RetryStockCompensationsUseCase {
+	return &RetryStockCompensationsUseCase{
+		sagaRepo:     sagaRepo,
+		sagaService:  sagaService,
+		serviceToken: serviceToken,
+	}
+}
+
+// ProcessDue reintenta hasta limit sagas con reintento vencido (lo invoca el worker)
+// Primero pasa a compensación las sagas STARTED abandonadas por un proceso caído
+func (uc *RetryStockCompensationsUseCase) ProcessDue(ctx context.Context, limit int) (int, error) {
+	abandoned, err := uc.sagaRepo.AbandonStale(ctx, abandonedSagaAfter, abandonedSagaReason)
+	if err != nil {
+		return 0, err
+	}
+	if abandoned > 0 {
+		log.Printf("⚠️ %d abandoned stock sagas moved to COMPENSATING", abandoned)
+	}
+
+	sagas, err := uc.sagaRepo.ClaimDue(ctx, limit, sagaClaimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, saga := range sagas {
+		if err := uc.sagaService.CompensatePending(ctx, saga, uc.serviceToken); err != nil {
+			// Sigue COMPENSATING: se retoma al vencer el lease
+			log.Printf("❌ Error retrying stock saga %s: %v", saga.ID, err)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/infrastructure/cache"
+
+	"github.com/google/uuid"
+)
+
+// Agrupaciones de GET /reports/sales (HITO SALES-REPORT)
+const (
+	SalesReportGroupByDay           = "day"
+	SalesReportGroupByWeek          = "week"  // Semana que empieza el lunes
+	SalesReportGroupByMonth         = "month" // Mes calendario
+	SalesReportGroupByHour          = "hour"  // Hora del día (00-23) sumando todo el rango
+	SalesReportGroupByPaymentMethod = "payment_method"
+	SalesReportGroupByPointOfSale   = "point_of_sale"
+)
+
+// salesReportMaxDays rango máximo del reporte (from y to incluidos)
+const salesReportMaxDays = 366
+
+// salesReportUnassigned etiqueta del bucket sin método de pago / punto de venta
+const salesReportUnassigned = "Unassigned"
+
+// salesReportTimeKeys clave del bucket por fecha u hora (%s = columna created_at)
+var salesReportTimeKeys = map[string]string{
+	SalesReportGroupByDay:   "to_char(%s, 'YYYY-MM-DD')",
+	SalesReportGroupByWeek:  "to_char(date_trunc('week', %s), 'YYYY-MM-DD')",
+	SalesReportGroupByMonth: "to_char(%s, 'YYYY-MM')",
+	SalesReportGroupByHour:  "to_char(%s, 'HH24')",
+}
+
+// SalesReportUseCase caso de uso para el reporte de ventas por rango de fechas
+// HITO SALES-REPORT - Ventas POS + órdenes con importes por bucket
+//
+// Importes de cada venta POS: final_amount (bruto con IVA), discount_amount, net_amount, tax_amount
+// Importes de cada orden: total_amount (bruto con IVA), sin descuento, net_amount, tax_amount
+// Las ventas POS anuladas (VOIDED) y las órdenes canceladas o vencidas no son ventas.
+// Las devoluciones no se restan (se imputan al día en que se emiten: ver /reports/daily)
+type SalesReportUseCase struct {
+	db                 *sql.DB
+	paymentMethodCache *cache.PaymentMethodCache
+}
+
+// NewSalesReportUseCase crea una nueva instancia del caso de uso
+func NewSalesReportUseCase(db *sql.DB, paymentMethodCache *cache.PaymentMethodCache) *SalesReportUseCase {
+	return &SalesReportUseCase{
+		db:                 db,
+		paymentMethodCache: paymentMethodCache,
+	}
+}
+
+// Execute genera el reporte de ventas entre from y to (YYYY-MM-DD, ambos incluidos)
+// groupBy vacío agrupa por día. Una query por origen (POS y órdenes) trae los buckets
+// y el total del rango; se combinan en memoria
+func (uc *SalesReportUseCase) Execute(ctx context.Context, tenantID uuid.UUID, from, to, groupBy string) (*response.SalesReportResponse, error) {
+	// ========================================================================
+	// PASO 1: VALIDAR AGRUPACIÓN Y FECHAS
+	// ========================================================================
+	if groupBy == "" {
+		groupBy = SalesReportGroupByDay
+	}
+	_, isTimeGroup := salesReportTimeKeys[groupBy]
+	if !isTimeGroup && groupBy != SalesReportGroupByPaymentMethod && groupBy != SalesReportGroupByPointOfSale {
+		return nil, fmt.Errorf("%w: %q", entity.ErrInvalidReportGroupBy, groupBy)
+	}
+
+	fromDate, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from %q", entity.ErrInvalidReportDate, from)
+	}
+	toDate, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to %q", entity.ErrInvalidReportDate, to)
+	}
+	if toDate.Before(fromDate) || toDate.After(fromDate.AddDate(0, 0, salesReportMaxDays-1)) {
+		return nil, entity.ErrInvalidReportRange
+	}
+
+	// ========================================================================
+	// PASO 2: CALCULAR RANGO [from, to + 1 día) - NO usar DATE(created_at)
+	// ========================================================================
+	start := fromDate
+	end := toDate.AddDate(0, 0, 1)
+
+	// ========================================================================
+	// PASO 3: QUERY POS SALES Y ÓRDENES
+	// ========================================================================
+	posBuckets, posTotal, err := uc.queryBuckets(ctx, uc.posSalesQuery(groupBy), tenantID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pos_sales: %w", err)
+	}
+	orderBuckets, orderTotal, err := uc.queryBuckets(ctx, uc.ordersQuery(groupBy), tenantID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sales_orders: %w", err)
+	}
+
+	// ========================================================================
+	// PASO 4: COMBINAR BUCKETS (los de fecha / hora sin ventas van en cero)
+	// ========================================================================
+	buckets := make(map[string]*response.SalesReportBucket)
+	bucket := func(key string) *response.SalesReportBucket {
+		b, ok := buckets[key]
+		if !ok {
+			b = &response.SalesReportBucket{Key: key}
+			buckets[key] = b
+		}
+		return b
+	}
+	if isTimeGroup {
+		for _, key := range salesReportTimeSeries(groupBy, start, end) {
+			bucket(key)
+		}
+	}
+	for key, amounts := range posBuckets {
+		bucket(key).Pos = amounts
+	}
+	for key, amounts := range orderBuckets {
+		bucket(key).Orders = amounts
+	}
+
+	resp := &response.SalesReportResponse{
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+		Buckets: make([]response.SalesReportBucket, 0, len(buckets)),
+		Totals: response.SalesReportBucket{
+			Key:    "total",
+			Pos:    posTotal,
+			Orders: orderTotal,
+			Total:  posTotal.Add(orderTotal),
+		},
+	}
+	for _, b := range buckets {
+		b.Total = b.Pos.Add(b.Orders)
+		b.Label = uc.bucketLabel(groupBy, b.Key)
+		resp.Buckets = append(resp.Buckets, *b)
+	}
+
+	// ========================================================================
+	// PASO 5: ORDENAR (fecha / hora ascendente; el resto por bruto descendente)
+	// ========================================================================
+	sort.Slice(resp.Buckets, func(i, j int) bool {
+		a, b := resp.Buckets[i], resp.Buckets[j]
+		if isTimeGroup {
+			return a.Key < b.Key
+		}
+		if (a.Key == "") != (b.Key == "") {
+			return b.Key == ""
+		}
+		if !a.Total.GrossAmount.Equal(b.Total.GrossAmount) {
+			return a.Total.GrossAmount.GreaterThan(b.Total.GrossAmount)
+		}
+		return a.Key < b.Key
+	})
+
+	return resp, nil
+}
+
+// posSalesQuery una fila por venta POS (o por pago de la venta) con su bucket
+// HITO POS-SPLIT - Por método de pago, los importes de una venta con pago combinado
+// se reparten en proporción a lo cobrado con cada método (monto - vuelto); las ventas
+// sin pos_sale_payments usan pos_sales.payment_method_id
+func (uc *SalesReportUseCase) posSalesQuery(groupBy string) string {
+	if groupBy == SalesReportGroupByPaymentMethod {
+		return `
+			SELECT
+				bucket,
+				sale_id,
+				final_amount * share as gross,
+				discount_amount * share as discount,
+				net_amount * share as net,
+				tax_amount * share as tax
+			FROM (
+				SELECT
+					COALESCE(COALESCE(p.payment_method_id, s.payment_method_id)::text, '') as bucket,
+					s.id as sale_id,
+					s.final_amount,
+					s.discount_amount,
+					s.net_amount,
+					s.tax_amount,
+					COALESCE(
+						(p.amount - p.change_amount) / NULLIF(SUM(p.amount - p.change_amount) OVER (PARTITION BY s.id), 0),
+						1.0 / COUNT(*) OVER (PARTITION BY s.id)
+					) as share
+				FROM pos_sales s
+				LEFT JOIN pos_sale_payments p ON p.pos_sale_id = s.id
+				WHERE s.tenant_id = $1
+					AND s.created_at >= $2
+					AND s.created_at < $3
+					AND s.status <> 'VOIDED'
+			) allocated
+		`
+	}
+
+	key := "COALESCE(s.point_of_sale_id::text, '')"
+	if expr, ok := salesReportTimeKeys[groupBy]; ok {
+		key = fmt.Sprintf(expr, "s.created_at")
+	}
+	return fmt.Sprintf(`
+		SELECT
+			%s as bucket,
+			s.id as sale_id,
+			s.final_amount as gross,
+			s.discount_amount as discount,
+			s.net_amount as net,
+			s.tax_amount as tax
+		FROM pos_sales s
+		WHERE s.tenant_id = $1
+			AND s.created_at >= $2
+			AND s.created_at < $3
+			AND s.status <> 'VOIDED'
+	`, key)
+}
+
+// ordersQuery una fila por orden con su bucket
+// Las órdenes no tienen método de pago ni punto de venta: van al bucket sin asignar
+func (uc *SalesReportUseCase) ordersQuery(groupBy string) string {
+	key := "''"
+	if expr, ok := salesReportTimeKeys[groupBy]; ok {
+		key = fmt.Sprintf(expr, "o.created_at")
+	}
+	return fmt.Sprintf(`
+		SELECT
+			%s as bucket,
+			o.id as sale_id,
+			o.total_amount as gross,
+			0::numeric as discount,
+			o.net_amount as net,
+			o.tax_amount as tax
+		FROM sales_orders o
+		WHERE o.tenant_id = $1
+			AND o.created_at >= $2
+			AND o.created_at < $3
+			AND o.status NOT IN ('CANCELED', 'EXPIRED')
+	`, key)
+}
+
+// queryBuckets agrega las filas de rowsQuery por bucket y para todo el rango
+// El total sale de la misma query (GROUPING SETS): cuenta una vez las ventas repartidas
+// entre varios métodos de pago
+func (uc *SalesReportUseCase) queryBuckets(ctx context.Context, rowsQuery string, tenantID uuid.UUID, from, to time.Time) (map[string]response.SalesReportAmounts, response.SalesReportAmounts, error) {
+	query := `
+		SELECT
+			GROUPING(bucket) = 1 as is_total,
+			COALESCE(bucket, '') as bucket,
+			COUNT(DISTINCT sale_id) as transaction_count,
+			COALESCE(ROUND(SUM(gross), 2), 0) as gross_total,
+			COALESCE(ROUND(SUM(discount), 2), 0) as discount_total,
+			COALESCE(ROUND(SUM(net), 2), 0) as net_total,
+			COALESCE(ROUND(SUM(tax), 2), 0) as tax_total
+		FROM (` + rowsQuery + `) report_rows
+		GROUP BY GROUPING SETS ((bucket), ())
+	`
+
+	rows, err := uc.db.QueryContext(ctx, query, tenantID, from, to)
+	if err != nil {
+		return nil, response.SalesReportAmounts{}, err
+	}
+	defer rows.Close()
+
+	buckets := make(map[string]response.SalesReportAmounts)
+	var total response.SalesReportAmounts
+	for rows.Next() {
+		var isTotal bool
+		var key string
+		var amounts response.SalesReportAmounts
+		if err := rows.Scan(
+			&isTotal,
+			&key,
+			&amounts.TransactionCount,
+			&amounts.GrossAmount,
+			&amounts.DiscountAmount,
+			&amounts.NetAmount,
+			&amounts.TaxAmount,
+		); err != nil {
+			return nil, response.SalesReportAmounts{}, err
+		}
+		if isTotal {
+			total = amounts
+			continue
+		}
+		buckets[key] = amounts
+	}
+	if err := rows.Err(); err != nil {
+		return nil, response.SalesReportAmounts{}, err
+	}
+
+	return buckets, total, nil
+}
+
+// bucketLabel nombre del método de pago del bucket; los buckets sin asignar se marcan
+func (uc *SalesReportUseCase) bucketLabel(groupBy, key string) string {
+	if groupBy != SalesReportGroupByPaymentMethod && groupBy != SalesReportGroupByPointOfSale {
+		return ""
+	}
+	if key == "" {
+		return salesReportUnassigned
+	}
+	if groupBy == SalesReportGroupByPaymentMethod {
+		if id, err := uuid.Parse(key); err == nil {
+			return uc.paymentMethodCache.GetName(id)
+		}
+	}
+	return ""
+}
+
+// salesReportTimeSeries claves de todos los buckets de fecha / hora del rango [from, to)
+func salesReportTimeSeries(groupBy string, from, to time.Time) []string {
+	var keys []string
+	switch groupBy {
+	case SalesReportGroupByDay:
+		for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+			keys = append(keys, d.Format("2006-01-02"))
+		}
+	case SalesReportGroupByWeek:
+		monday := from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+		for d := monday; d.Before(to); d = d.AddDate(0, 0, 7) {
+			keys = append(keys, d.Format("2006-01-02"))
+		}
+	case SalesReportGroupByMonth:
+		first := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+		for d := first; d.Before(to); d = d.AddDate(0, 1, 0) {
+			keys = append(keys, d.Format("2006-01"))
+		}
+	case SalesReportGroupByHour:
+		for h := 0; h < 24; h++ {
+			keys = append(keys, fmt.Sprintf("%02d", h))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/response"
+	"sales/src/sales/application/service"
+
+	"github.com/google/uuid"
+)
+
+// SeedSequenceUseCase caso de uso para crear / ajustar una secuencia de un tenant
+// HITO SEQ-TX - Onboarding de tenants sin INSERT manual
+type SeedSequenceUseCase struct {
+	sequenceService *service.SequenceService
+}
+
+// NewSeedSequenceUseCase crea una nueva instancia del caso de uso
+func NewSeedSequenceUseCase(sequenceService *service.SequenceService) *SeedSequenceUseCase {
+	return &SeedSequenceUseCase{
+		sequenceService: sequenceService,
+	}
+}
+
+// Execute fija el último número emitido de la secuencia (crea la secuencia si no existe)
+// Falla con entity.ErrSequenceRewind si el número es menor al ya asignado
+func (uc *SeedSequenceUseCase) Execute(ctx context.Context, tenantID uuid.UUID, req *request.SeedSequenceRequest) (*response.SequenceResponse, error) {
+	seq, err := uc.sequenceService.Seed(ctx, tenantID, req.DocumentType, req.ScopeID, req.CurrentNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toSequenceResponse(*seq)
+	return &resp, nil
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+)
+
+// toTaxSummaryResponse mapea los totales impositivos al DTO de respuesta
+// HITO TAX-IVA - Compartido por ventas POS y órdenes
+func toTaxSummaryResponse(summary entity.TaxSummary) response.TaxSummaryResponse {
+	byRate := make([]response.TaxBreakdownResponse, 0, len(summary.ByRate))
+	for _, rate := range summary.ByRate {
+		byRate = append(byRate, response.TaxBreakdownResponse{
+			RateCode:    string(rate.RateCode),
+			Rate:        rate.Rate,
+			NetAmount:   rate.NetAmount,
+			TaxAmount:   rate.TaxAmount,
+			GrossAmount: rate.GrossAmount,
+		})
+	}
+
+	return response.TaxSummaryResponse{
+		PriceMode:   string(summary.PriceMode),
+		NetAmount:   summary.NetAmount,
+		TaxAmount:   summary.TaxAmount,
+		GrossAmount: summary.GrossAmount,
+		ByRate:      byRate,
+	}
+}
+
+// buildTaxesPayload arma el desglose por alícuota para los eventos (sales.pos.confirmed / sales.order.confirmed)
+func buildTaxesPayload(summary entity.TaxSummary) []map[string]interface{} {
+	taxes := make([]map[string]interface{}, 0, len(summary.ByRate))
+	for _, rate := range summary.ByRate {
+		taxes = append(taxes, map[string]interface{}{
+			"rate_code":    string(rate.RateCode),
+			"rate":         rate.Rate.InexactFloat64(),
+			"net_amount":   rate.NetAmount.InexactFloat64(),
+			"tax_amount":   rate.TaxAmount.InexactFloat64(),
+			"gross_amount": rate.GrossAmount.InexactFloat64(),
+		})
+	}
+	return taxes
+}
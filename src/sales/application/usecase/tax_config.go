@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// TaxConfigUseCase caso de uso para administrar la configuración de IVA del tenant
+// HITO TAX-IVA - Modo de precios, alícuota por defecto y reglas por SKU / categoría
+type TaxConfigUseCase struct {
+	taxRepo port.TaxRepository
+}
+
+// NewTaxConfigUseCase crea una nueva instancia del caso de uso
+func NewTaxConfigUseCase(taxRepo port.TaxRepository) *TaxConfigUseCase {
+	return &TaxConfigUseCase{
+		taxRepo: taxRepo,
+	}
+}
+
+// GetSettings retorna la configuración del tenant (o la configuración por defecto)
+func (uc *TaxConfigUseCase) GetSettings(ctx context.Context, tenantID uuid.UUID) (*response.TaxSettingsResponse, error) {
+	settings, err := uc.taxRepo.FindSettings(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTaxSettingsResponse(settings), nil
+}
+
+// SaveSettings crea o reemplaza la configuración del tenant
+// Aplica a las ventas siguientes: las registradas conservan su desglose
+func (uc *TaxConfigUseCase) SaveSettings(ctx context.Context, tenantID uuid.UUID, req *request.SaveTaxSettingsRequest) (*response.TaxSettingsResponse, error) {
+	settings, err := entity.NewTaxSettings(
+		tenantID,
+		entity.TaxPriceMode(req.PriceMode),
+		entity.TaxRateCode(req.DefaultRateCode),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.taxRepo.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return toTaxSettingsResponse(settings), nil
+}
+
+// ListRules retorna las reglas de alícuota del tenant
+func (uc *TaxConfigUseCase) ListRules(ctx context.Context, tenantID uuid.UUID) (*response.ListTaxRulesResponse, error) {
+	rules, err := uc.taxRepo.ListRules(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &response.ListTaxRulesResponse{
+		TenantID: tenantID,
+		Rules:    make([]response.TaxRuleResponse, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		resp.Rules = append(resp.Rules, toTaxRuleResponse(rule))
+	}
+
+	return resp, nil
+}
+
+// SaveRule asigna una alícuota a un SKU o categoría (reemplaza la regla existente)
+func (uc *TaxConfigUseCase) SaveRule(ctx context.Context, tenantID uuid.UUID, req *request.SaveTaxRuleRequest) (*response.TaxRuleResponse, error) {
+	rule, err := entity.NewTaxRule(
+		tenantID,
+		entity.TaxRuleScope(req.Scope),
+		req.ScopeValue,
+		entity.TaxRateCode(req.RateCode),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.taxRepo.SaveRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	resp := toTaxRuleResponse(rule)
+	return &resp, nil
+}
+
+// DeleteRule elimina una regla (el SKU / categoría vuelve a la alícuota por defecto)
+func (uc *TaxConfigUseCase) DeleteRule(ctx context.Context, tenantID, ruleID uuid.UUID) error {
+	return uc.taxRepo.DeleteRule(ctx, tenantID, ruleID)
+}
+
+// toTaxSettingsResponse mapea la configuración al DTO de respuesta
+func toTaxSettingsResponse(settings *entity.TaxSettings) *response.TaxSettingsResponse {
+	resp := &response.TaxSettingsResponse{
+		TenantID:        settings.TenantID,
+		PriceMode:       string(settings.PriceMode),
+		DefaultRateCode: string(settings.DefaultRateCode),
+	}
+	if !settings.UpdatedAt.IsZero() {
+		updatedAt := settings.UpdatedAt
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
+
+// toTaxRuleResponse mapea la regla al DTO de respuesta
+func toTaxRuleResponse(rule *entity.TaxRule) response.TaxRuleResponse {
+	return response.TaxRuleResponse{
+		ID:         rule.ID,
+		Scope:      string(rule.Scope),
+		ScopeValue: rule.ScopeValue,
+		RateCode:   string(rule.RateCode),
+		CreatedAt:  rule.CreatedAt,
+		UpdatedAt:  rule.UpdatedAt,
+	}
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/response"
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+// TransitionOrderStatusUseCase avanza la orden en el ciclo de preparación y envío
+// HITO ORDER-FULFILLMENT - PICKING, PACKED, DELIVERED y RETURNED; la máquina de estados
+// vive en la entidad y cada transición queda en order_status_history con su actor
+type TransitionOrderStatusUseCase struct {
+	orderRepo port.OrderRepository
+}
+
+// NewTransitionOrderStatusUseCase crea una nueva instancia del caso de uso
+func NewTransitionOrderStatusUseCase(orderRepo port.OrderRepository) *TransitionOrderStatusUseCase {
+	return &TransitionOrderStatusUseCase{
+		orderRepo: orderRepo,
+	}
+}
+
+// Execute aplica la transición pedida
+// CONFIRMED, CANCELED, EXPIRED y SHIPPED tienen su propio flujo: ErrOrderStatusNotSettable
+func (uc *TransitionOrderStatusUseCase) Execute(
+	ctx context.Context,
+	tenantID, userID, orderID string,
+	req *request.TransitionOrderStatusRequest,
+) (*response.OrderStatusChangeResponse, error) {
+	to, err := entity.ParseOrderStatus(req.Status)
+	if err != nil {
+		return nil, err
+	}
+	if !to.IsManual() {
+		return nil, entity.ErrOrderStatusNotSettable
+	}
+
+	order, err := uc.orderRepo.FindByID(ctx, orderID, tenantID)
+	if err != nil {
+		return nil, entity.ErrOrderNotFound
+	}
+
+	from := order.Status
+	if err := order.TransitionTo(to); err != nil {
+		return nil, err
+	}
+
+	change := entity.NewOrderStatusChange(order, from, userID, req.Note)
+	if err := uc.orderRepo.TransitionStatus(ctx, change); err != nil {
+		return nil, fmt.Errorf("error transitioning order: %w", err)
+	}
+
+	log.Printf("📦 Order %s: %s → %s by %q", order.OrderID, from, to, userID)
+
+	resp := toOrderStatusChangeResponse(change)
+	return &resp, nil
+}
+
+// toOrderStatusChangeResponse convierte una transición del historial
+func toOrderStatusChangeResponse(change *entity.OrderStatusChange) response.OrderStatusChangeResponse {
+	return response.OrderStatusChangeResponse{
+		ID:         change.ID.String(),
+		OrderID:    change.OrderID,
+		FromStatus: string(change.FromStatus),
+		ToStatus:   string(change.ToStatus),
+		Actor:      change.Actor,
+		Note:       change.Note,
+		CreatedAt:  change.CreatedAt,
+	}
+}
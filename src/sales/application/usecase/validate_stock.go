@@ -1,32 +1,33 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
 	"sales/src/sales/application/request"
 	"sales/src/sales/application/response"
-	"sales/src/sales/infrastructure/client"
+	"sales/src/sales/domain/port"
 )
 
 // ValidateStockUseCase caso de uso para validar stock
 type ValidateStockUseCase struct {
-	stockClient *client.StockClient
+	stockClient port.StockGateway
 }
 
 // NewValidateStockUseCase crea una nueva instancia del caso de uso
-func NewValidateStockUseCase(stockClient *client.StockClient) *ValidateStockUseCase {
+func NewValidateStockUseCase(stockClient port.StockGateway) *ValidateStockUseCase {
 	return &ValidateStockUseCase{
 		stockClient: stockClient,
 	}
 }
 
 // Execute ejecuta la validación de stock (multi-item)
-func (uc *ValidateStockUseCase) Execute(tenantID, authToken string, req *request.ValidateStockRequest) (*response.ValidateStockResponse, error) {
+func (uc *ValidateStockUseCase) Execute(ctx context.Context, tenantID, authToken string, req *request.ValidateStockRequest) (*response.ValidateStockResponse, error) {
 	var itemsResponse []response.ValidateStockItemResponse
 	allValid := true
 
 	// Validar cada item
 	for _, item := range req.Items {
-		stockResp, hasEnoughStock, err := uc.stockClient.ValidateStock(tenantID, authToken, item.SKU, item.Quantity)
+		stockResp, hasEnoughStock, err := uc.stockClient.ValidateStock(ctx, tenantID, authToken, item.SKU, item.Quantity)
 		if err != nil {
 			return nil, fmt.Errorf("error validating stock for SKU %s: %w", item.SKU, err)
 		}
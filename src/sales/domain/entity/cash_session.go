@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CashSessionStatus representa el estado de una sesión de caja
+type CashSessionStatus string
+
+const (
+	CashSessionStatusOpen   CashSessionStatus = "OPEN"
+	CashSessionStatusClosed CashSessionStatus = "CLOSED"
+)
+
+// CashSession representa un turno de caja en un punto de venta (Aggregate Root)
+// HITO POS-CASH - Apertura / cierre de caja con arqueo
+type CashSession struct {
+	ID            uuid.UUID         `json:"id"`
+	TenantID      uuid.UUID         `json:"tenant_id"`
+	PointOfSaleID uuid.UUID         `json:"point_of_sale_id"`
+	Status        CashSessionStatus `json:"status"`
+	OpeningFloat  decimal.Decimal   `json:"opening_float"` // Fondo de caja inicial
+	OpenedBy      string            `json:"opened_by,omitempty"`
+	OpenedAt      time.Time         `json:"opened_at"`
+	ClosedBy      string            `json:"closed_by,omitempty"`
+	ClosedAt      *time.Time        `json:"closed_at,omitempty"`
+	Notes         string            `json:"notes,omitempty"`
+	Counts        []CashCount       `json:"counts,omitempty"` // Arqueo (solo al cerrar)
+}
+
+// CashCount representa el arqueo de un método de pago al cerrar la sesión
+type CashCount struct {
+	PaymentMethodID uuid.UUID       `json:"payment_method_id"`
+	CountedAmount   decimal.Decimal `json:"counted_amount"`  // Contado por el cajero
+	ExpectedAmount  decimal.Decimal `json:"expected_amount"` // Según pos_sales de la sesión
+	Difference      decimal.Decimal `json:"difference"`      // counted - expected
+}
+
+// CashSessionMethodTotal representa los totales vendidos en una sesión para un método de pago
+type CashSessionMethodTotal struct {
+	PaymentMethodID uuid.UUID       `json:"payment_method_id"`
+	SalesCount      int             `json:"sales_count"`
+	FinalAmount     decimal.Decimal `json:"final_amount"` // Neto cobrado (amount - vuelto - devoluciones)
+	ChangeGiven     decimal.Decimal `json:"change_given"` // Vuelto entregado
+}
+
+// NewCashSession abre una nueva sesión de caja para un punto de venta
+func NewCashSession(tenantID, pointOfSaleID uuid.UUID, openingFloat decimal.Decimal, openedBy string) (*CashSession, error) {
+	if tenantID == uuid.Nil {
+		return nil, ErrTenantIDRequired
+	}
+	if pointOfSaleID == uuid.Nil {
+		return nil, ErrPointOfSaleIDRequired
+	}
+	if openingFloat.LessThan(decimal.Zero) {
+		return nil, ErrInvalidOpeningFloat
+	}
+
+	return &CashSession{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		PointOfSaleID: pointOfSaleID,
+		Status:        CashSessionStatusOpen,
+		OpeningFloat:  openingFloat,
+		OpenedBy:      openedBy,
+		OpenedAt:      time.Now(),
+	}, nil
+}
+
+// IsOpen indica si la sesión admite ventas
+func (s *CashSession) IsOpen() bool {
+	return s.Status == CashSessionStatusOpen
+}
+
+// Close cierra la sesión registrando el arqueo
+// Los montos esperados deben venir ya calculados (ver NewCashCount)
+func (s *CashSession) Close(closedBy, notes string, counts []CashCount) error {
+	if !s.IsOpen() {
+		return ErrCashSessionNotOpen
+	}
+
+	now := time.Now()
+	s.Status = CashSessionStatusClosed
+	s.ClosedBy = closedBy
+	s.ClosedAt = &now
+	s.Notes = notes
+	s.Counts = counts
+	return nil
+}
+
+// NewCashCount crea una línea de arqueo calculando la diferencia
+func NewCashCount(paymentMethodID uuid.UUID, counted, expected decimal.Decimal) (*CashCount, error) {
+	if paymentMethodID == uuid.Nil {
+		return nil, ErrPaymentMethodRequired
+	}
+	if counted.LessThan(decimal.Zero) {
+		return nil, ErrInvalidCountedAmount
+	}
+
+	return &CashCount{
+		PaymentMethodID: paymentMethodID,
+		CountedAmount:   counted,
+		ExpectedAmount:  expected,
+		Difference:      counted.Sub(expected),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"encoding/json"
+
+	"github.com/shopspring/decimal"
+)
+
+// PermissionPriceOverride permiso que habilita cobrar un precio distinto al del catálogo
+// HITO POS-PRICE - Lo informa el gateway en X-User-Permissions
+const PermissionPriceOverride = "sales.pos.price_override"
+
+// CatalogItem precio y nombre vigentes de un SKU según PIM
+// HITO POS-PRICE - El POS ya no confía en el precio que envía el terminal
+type CatalogItem struct {
+	SKU         string          `json:"sku"`
+	ProductName string          `json:"product_name"`
+	UnitPrice   decimal.Decimal `json:"unit_price"`
+	CategoryID  string          `json:"category_id"`
+}
+
+// CatalogItemFromSnapshots arma el precio de lista de un SKU a partir de los snapshots de PIM
+// Nombre: producto, o variante si el producto no lo trae, o el SKU como último recurso
+func CatalogItemFromSnapshots(sku string, productSnapshot, variantSnapshot json.RawMessage) (*CatalogItem, error) {
+	unitPrice, err := VariantPriceFromSnapshot(variantSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	var product struct {
+		Name string `json:"name"`
+	}
+	var variant struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(productSnapshot, &product)
+	_ = json.Unmarshal(variantSnapshot, &variant)
+
+	name := product.Name
+	if name == "" {
+		name = variant.Name
+	}
+	if name == "" {
+		name = sku
+	}
+
+	return &CatalogItem{
+		SKU:         sku,
+		ProductName: name,
+		UnitPrice:   unitPrice,
+		CategoryID:  CategoryIDFromSnapshot(productSnapshot),
+	}, nil
+}
+
+// ResolveUnitPrice decide el precio a cobrar por la línea
+// Sin precio del cliente (o con el mismo precio) se cobra el de catálogo. Un precio distinto
+// solo se acepta si el operador tiene PermissionPriceOverride; si no, ErrPriceMismatch
+func (c *CatalogItem) ResolveUnitPrice(requested *decimal.Decimal, canOverride bool) (decimal.Decimal, bool, error) {
+	if requested == nil || requested.Round(2).Equal(c.UnitPrice.Round(2)) {
+		return c.UnitPrice, false, nil
+	}
+	if requested.IsNegative() {
+		return decimal.Zero, false, ErrInvalidPrice
+	}
+	if !canOverride {
+		return decimal.Zero, false, ErrPriceMismatch
+	}
+	return *requested, true, nil
+}
@@ -0,0 +1,100 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CreditNote representa una nota de crédito contra una factura (Aggregate Root)
+// HITO CREDIT-NOTE - Documento de reversión al cancelar una orden o devolver una venta POS
+type CreditNote struct {
+	ID                uuid.UUID         `json:"id"`
+	TenantID          uuid.UUID         `json:"tenant_id"`
+	InvoiceID         uuid.UUID         `json:"invoice_id"`
+	SourceType        InvoiceSourceType `json:"source_type"`
+	SourceID          uuid.UUID         `json:"source_id"`
+	ReversalID        *uuid.UUID        `json:"reversal_id,omitempty"` // pos_sale_refunds.id (NULL = cancelación de orden)
+	CreditNoteType    InvoiceType       `json:"credit_note_type"`      // Misma letra que la factura
+	PointOfSaleNumber int               `json:"point_of_sale_number"`
+	CreditNoteNumber  int               `json:"credit_note_number"` // Secuencia CREDIT_NOTE del tenant
+	Reason            string            `json:"reason"`
+	Currency          string            `json:"currency"`
+	NetAmount         decimal.Decimal   `json:"net_amount"`
+	TaxAmount         decimal.Decimal   `json:"tax_amount"`
+	TotalAmount       decimal.Decimal   `json:"total_amount"`
+	IssuedBy          string            `json:"issued_by,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	Items             []CreditNoteItem  `json:"items"`
+}
+
+// CreditNoteItem representa una línea revertida (cantidad y monto devueltos)
+type CreditNoteItem struct {
+	ID           uuid.UUID       `json:"id"`
+	CreditNoteID uuid.UUID       `json:"credit_note_id"`
+	SourceItemID *uuid.UUID      `json:"source_item_id,omitempty"` // pos_sale_items.id / sales_order_items.id
+	SKU          string          `json:"sku"`
+	Quantity     int             `json:"quantity"`
+	Amount       decimal.Decimal `json:"amount"` // Monto revertido (IVA incluido)
+}
+
+// NewCreditNote crea una nota de crédito contra la factura con las líneas revertidas
+// El total es la suma de las líneas; el IVA se discrimina en la proporción de la factura
+// (HITO TAX-IVA: respeta la mezcla de alícuotas de la venta; en tipo C no hay IVA)
+func NewCreditNote(invoice *Invoice, reversalID *uuid.UUID, reason, issuedBy string, items []CreditNoteItem) (*CreditNote, error) {
+	if invoice == nil || !invoice.IsCreditable() {
+		return nil, ErrInvoiceNotCreditable
+	}
+	if len(items) == 0 {
+		return nil, ErrCreditNoteMustHaveItems
+	}
+
+	id := uuid.New()
+	total := decimal.Zero
+	for i := range items {
+		items[i].ID = uuid.New()
+		items[i].CreditNoteID = id
+		total = total.Add(items[i].Amount)
+	}
+	total = total.Round(2)
+	if !total.GreaterThan(decimal.Zero) || total.GreaterThan(invoice.TotalAmount) {
+		return nil, ErrInvalidCreditNoteAmount
+	}
+
+	tax := decimal.Zero
+	if invoice.TotalAmount.GreaterThan(decimal.Zero) {
+		tax = total.Mul(invoice.TaxAmount).Div(invoice.TotalAmount).Round(2)
+	}
+	net := total.Sub(tax)
+
+	return &CreditNote{
+		ID:                id,
+		TenantID:          invoice.TenantID,
+		InvoiceID:         invoice.ID,
+		SourceType:        invoice.SourceType,
+		SourceID:          invoice.SourceID,
+		ReversalID:        reversalID,
+		CreditNoteType:    invoice.InvoiceType,
+		PointOfSaleNumber: invoice.PointOfSaleNumber,
+		Reason:            reason,
+		Currency:          invoice.Currency,
+		NetAmount:         net,
+		TaxAmount:         tax,
+		TotalAmount:       total,
+		IssuedBy:          issuedBy,
+		CreatedAt:         time.Now(),
+		Items:             items,
+	}, nil
+}
+
+// AssignNumber asigna el número de la secuencia CREDIT_NOTE
+func (n *CreditNote) AssignNumber(number int) {
+	n.CreditNoteNumber = number
+}
+
+// FormattedNumber retorna el número impreso (ej: "NC B 0001-00000007")
+func (n *CreditNote) FormattedNumber() string {
+	return fmt.Sprintf("NC %s %04d-%08d", n.CreditNoteType, n.PointOfSaleNumber, n.CreditNoteNumber)
+}
@@ -0,0 +1,132 @@
+package entity
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// GenericCustomerID cliente con el que se publican las ventas a consumidor final
+// El ledger y los eventos históricos lo usan como comprador anónimo
+const GenericCustomerID = "00000000-0000-0000-0000-000000000001"
+
+// GenericCustomerName nombre del comprador anónimo en eventos y comprobantes
+const GenericCustomerName = "Consumidor Final"
+
+// Tipos de documento del comprador
+const (
+	CustomerDocumentCUIT = "CUIT"
+	CustomerDocumentCUIL = "CUIL"
+	CustomerDocumentDNI  = "DNI"
+)
+
+// CustomerAddress domicilio del comprador
+type CustomerAddress struct {
+	Street     string `json:"street,omitempty"`
+	City       string `json:"city,omitempty"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+}
+
+// CustomerSnapshot datos del comprador al momento de la venta
+// HITO CUSTOMER - Se resuelve en customer-service al crear la orden o la venta POS y
+// queda inmutable (igual que los snapshots de PIM): facturas, eventos y ledger usan
+// el comprador tal como era en la venta aunque después cambie en customer-service
+type CustomerSnapshot struct {
+	CustomerID     *uuid.UUID       `json:"customer_id,omitempty"` // nil = consumidor final
+	Name           string           `json:"name"`
+	DocumentType   string           `json:"document_type,omitempty"` // CUIT | CUIL | DNI | PASAPORTE
+	DocumentNumber string           `json:"document_number,omitempty"`
+	TaxCondition   TaxCondition     `json:"tax_condition"`
+	Email          string           `json:"email,omitempty"`
+	Address        *CustomerAddress `json:"address,omitempty"`
+}
+
+// NewCustomerSnapshot valida y normaliza el comprador informado por customer-service
+// Sin condición frente al IVA se asume consumidor final
+func NewCustomerSnapshot(customerID uuid.UUID, name, documentType, documentNumber string, taxCondition TaxCondition) (*CustomerSnapshot, error) {
+	if customerID == uuid.Nil {
+		return nil, ErrInvalidCustomerID
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrCustomerNameRequired
+	}
+	if taxCondition == "" {
+		taxCondition = TaxConditionConsumidorFinal
+	}
+	if !taxCondition.IsValid() {
+		return nil, ErrInvalidTaxCondition
+	}
+
+	return &CustomerSnapshot{
+		CustomerID:     &customerID,
+		Name:           name,
+		DocumentType:   strings.ToUpper(strings.TrimSpace(documentType)),
+		DocumentNumber: strings.TrimSpace(documentNumber),
+		TaxCondition:   taxCondition,
+	}, nil
+}
+
+// FinalConsumerSnapshot comprador anónimo (venta sin cliente)
+func FinalConsumerSnapshot() *CustomerSnapshot {
+	return &CustomerSnapshot{
+		Name:         GenericCustomerName,
+		TaxCondition: TaxConditionConsumidorFinal,
+	}
+}
+
+// IsFinalConsumer indica si la venta no tiene cliente identificado
+func (c *CustomerSnapshot) IsFinalConsumer() bool {
+	return c == nil || c.CustomerID == nil
+}
+
+// LedgerCustomerID ID del comprador en eventos: el genérico si es consumidor final
+func (c *CustomerSnapshot) LedgerCustomerID() string {
+	if c.IsFinalConsumer() {
+		return GenericCustomerID
+	}
+	return c.CustomerID.String()
+}
+
+// TaxID CUIT/CUIL del comprador para el comprobante ("" si se identificó con otro documento)
+func (c *CustomerSnapshot) TaxID() string {
+	if c == nil {
+		return ""
+	}
+	switch c.DocumentType {
+	case CustomerDocumentCUIT, CustomerDocumentCUIL:
+		return c.DocumentNumber
+	}
+	return ""
+}
+
+// OrFinalConsumer retorna el snapshot o el consumidor final (ventas previas sin snapshot)
+func (c *CustomerSnapshot) OrFinalConsumer() *CustomerSnapshot {
+	if c == nil {
+		return FinalConsumerSnapshot()
+	}
+	return c
+}
+
+// MarshalCustomerSnapshot serializa el snapshot para persistirlo (nil → NULL)
+func MarshalCustomerSnapshot(c *CustomerSnapshot) ([]byte, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+// UnmarshalCustomerSnapshot lee el snapshot persistido (vacío → nil, venta previa)
+func UnmarshalCustomerSnapshot(data []byte) (*CustomerSnapshot, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var snapshot CustomerSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
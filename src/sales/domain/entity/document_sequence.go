@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de documento numerados (document_sequences.document_type)
+const (
+	DocumentTypeSalesOrder = "SALES_ORDER"
+	DocumentTypePosSale    = "POS_SALE" // Con scope = point_of_sale_id
+	DocumentTypeInvoice    = "INVOICE"
+	DocumentTypeCreditNote = "CREDIT_NOTE"
+)
+
+// documentTypes tipos admitidos por la API de administración de secuencias
+var documentTypes = map[string]bool{
+	DocumentTypeSalesOrder: true,
+	DocumentTypePosSale:    true,
+	DocumentTypeInvoice:    true,
+	DocumentTypeCreditNote: true,
+}
+
+// IsValidDocumentType indica si el tipo de documento es conocido
+func IsValidDocumentType(documentType string) bool {
+	return documentTypes[documentType]
+}
+
+// DocumentSequence representa una secuencia de numeración por tenant y tipo de documento
+// HITO SEQ-TX - Numeración sin huecos dentro de la transacción del documento
+type DocumentSequence struct {
+	ID            uuid.UUID  `json:"id"`
+	TenantID      uuid.UUID  `json:"tenant_id"`
+	DocumentType  string     `json:"document_type"`
+	ScopeID       *uuid.UUID `json:"scope_id,omitempty"` // Ej: point_of_sale_id para POS_SALE
+	CurrentNumber int        `json:"current_number"`     // Último número asignado
+	Version       int        `json:"version"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
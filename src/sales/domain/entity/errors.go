@@ -20,4 +20,124 @@ var (
 	
 	// HITO: POST /pos/sale devuelve DTO listo para imprimir
 	ErrInsufficientPayment = errors.New("amount_paid must be greater than or equal to final_amount")
+
+	// HITO POS-CASH - Sesiones de caja
+	ErrPaymentMethodRequired  = errors.New("payment_method_id is required")
+	ErrPointOfSaleIDRequired  = errors.New("point_of_sale_id is required")
+	ErrInvalidOpeningFloat    = errors.New("opening_float must be greater than or equal to 0")
+	ErrInvalidCountedAmount   = errors.New("counted_amount must be greater than or equal to 0")
+	ErrCashSessionNotFound    = errors.New("cash session not found")
+	ErrCashSessionNotOpen     = errors.New("cash session is not OPEN")
+	ErrCashSessionAlreadyOpen = errors.New("a cash session is already open for this point of sale")
+	ErrNoOpenCashSession      = errors.New("no open cash session for this point of sale")
+
+	// HITO POS-SPLIT - Pagos combinados
+	ErrPosSaleMustHavePayments = errors.New("pos_sale must have at least one payment")
+	ErrInvalidPaymentAmount    = errors.New("payment amount must be greater than 0")
+	ErrNonCashOverpayment      = errors.New("non-cash payments cannot exceed final_amount (change is only given in cash)")
+
+	// HITO POS-REFUND - Anulación / devolución de ventas POS
+	ErrPosSaleNotFound            = errors.New("pos_sale not found")
+	ErrPosSaleAlreadyVoided       = errors.New("pos_sale is already voided or fully refunded")
+	ErrPosSaleItemNotFound        = errors.New("item does not belong to this pos_sale")
+	ErrPosSaleItemAlreadyRefunded = errors.New("item has already been refunded")
+	ErrRefundMustHaveItems        = errors.New("refund must have at least one item")
+	ErrRefundReasonRequired       = errors.New("refund reason is required")
+	ErrPosSaleRefundConflict      = errors.New("pos_sale was modified concurrently, retry the refund")
+	ErrRefundPartialQuantity      = errors.New("partial line refunds are not supported: each line is refunded with its full quantity")
+
+	// HITO SEQ-TX - Administración de secuencias
+	ErrInvalidDocumentType   = errors.New("invalid document_type")
+	ErrInvalidSequenceNumber = errors.New("current_number must be greater than or equal to 0")
+	ErrSequenceRewind        = errors.New("current_number cannot be lower than the last assigned number")
+
+	// HITO FISCAL - Facturación electrónica
+	ErrInvalidCUIT                 = errors.New("cuit must be 11 digits with a valid check digit")
+	ErrInvalidTaxCondition         = errors.New("invalid tax_condition")
+	ErrInvalidFiscalPointOfSale    = errors.New("point_of_sale_number must be between 1 and 99999")
+	ErrFiscalIssuerNotConfigured   = errors.New("fiscal issuer not configured for this tenant")
+	ErrInvalidInvoiceSourceType    = errors.New("source_type must be SALES_ORDER or POS_SALE")
+	ErrInvoiceSourceNotFound       = errors.New("sale to invoice not found")
+	ErrInvoiceSourceNotInvoiceable = errors.New("sale cannot be invoiced in its current state")
+	ErrInvoiceAmountRequired       = errors.New("sale has no amount to invoice")
+	ErrCustomerTaxIDRequired       = errors.New("a valid customer CUIT is required for invoice type A")
+	ErrInvoiceAlreadyExists        = errors.New("sale already has an invoice")
+	ErrInvoiceNotFound             = errors.New("invoice not found")
+	ErrInvoiceNotRetryable         = errors.New("only REJECTED invoices can be retried")
+	ErrInvalidFiscalTransition     = errors.New("invalid fiscal_status transition")
+	ErrFiscalAuthorityUnavailable  = errors.New("fiscal authority unavailable")
+
+	// HITO CREDIT-NOTE - Notas de crédito
+	ErrInvoiceNotCreditable    = errors.New("invoice was rejected and cannot be credited")
+	ErrCreditNoteMustHaveItems = errors.New("credit note must have at least one item")
+	ErrInvalidCreditNoteAmount = errors.New("credit note total must be greater than 0 and not exceed the invoice total")
+
+	// HITO TAX-IVA - Motor de IVA
+	ErrInvalidTaxRate       = errors.New("rate_code must be IVA_21, IVA_10_5, IVA_27 or EXENTO")
+	ErrInvalidTaxPriceMode  = errors.New("price_mode must be INCLUDED or EXCLUDED")
+	ErrInvalidTaxRuleScope  = errors.New("scope must be SKU or CATEGORY")
+	ErrTaxRuleValueRequired = errors.New("scope_value is required")
+	ErrTaxRuleNotFound      = errors.New("tax rule not found")
+
+	// HITO ORDER-PRICE - Precios de órdenes desde el snapshot de PIM
+	ErrItemPriceUnavailable = errors.New("variant price not available in PIM snapshot")
+
+	// HITO POS-PRICE - Precio de catálogo en ventas POS
+	ErrPriceMismatch = errors.New("unit_price does not match the catalog price and the operator cannot override prices")
+
+	// HITO OUTBOX - Outbox transaccional de eventos
+	ErrOutboxAggregateRequired = errors.New("outbox event aggregate_id and aggregate_type are required")
+	ErrOutboxEventTypeRequired = errors.New("outbox event_type is required")
+	ErrInvalidOutboxPayload    = errors.New("outbox payload must be valid JSON")
+	ErrInvalidOutboxTransition = errors.New("invalid outbox status transition")
+
+	// HITO STOCK-SAGA - Saga de descuentos de stock
+	ErrInvalidStockSagaTransition = errors.New("invalid stock saga status transition")
+	ErrInvalidStockSagaStatus     = errors.New("status must be STARTED, COMPENSATING or ESCALATED")
+
+	// HITO IDEMPOTENCY - Idempotency-Key en endpoints de venta
+	ErrInvalidIdempotencyKey    = errors.New("Idempotency-Key must be between 1 and 255 characters")
+	ErrIdempotencyKeyMismatch   = errors.New("Idempotency-Key was already used with a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this Idempotency-Key is still in progress")
+
+	// HITO RESILIENCE - Dependencias externas (stock-service, PIM)
+	ErrDependencyUnavailable = errors.New("dependency unavailable")
+
+	// HITO STOCK-BATCH - Venta en lote contra stock-service
+	ErrStockBatchNotSupported = errors.New("stock-service does not support batch sales")
+
+	// HITO ORDER-RESERVATION - Reserva de stock de órdenes con vencimiento
+	ErrOrderReservationExpired  = errors.New("order stock reservation expired")
+	ErrInsufficientStock        = errors.New("insufficient stock")
+	ErrOrderReservationReleased = errors.New("order stock reservation was released")
+	ErrOrderConfirmInProgress   = errors.New("order confirmation already in progress")
+
+	// HITO ORDER-CANCEL - Cancelación de órdenes y de líneas
+	ErrCancelReasonRequired      = errors.New("cancel reason is required")
+	ErrOrderNotCancelable        = errors.New("only CREATED or CONFIRMED orders can be canceled")
+	ErrOrderStockNotCompensable  = errors.New("order stock cannot be compensated automatically: no stock saga recorded")
+	ErrOrderItemNotFound         = errors.New("order item not found")
+	ErrLineCancelMustHaveItems   = errors.New("line cancellation must have at least one line")
+	ErrInvalidCancelQuantity     = errors.New("cancel quantity must be greater than 0 and not exceed the active quantity of the line")
+	ErrLineCancelWouldEmptyOrder = errors.New("canceling every remaining line cancels the order: use order cancellation")
+
+	// HITO ORDER-FULFILLMENT - Ciclo de vida de preparación y envío
+	ErrInvalidOrderStatus      = errors.New("status must be CREATED, CONFIRMED, PICKING, PACKED, SHIPPED, DELIVERED, RETURNED, CANCELED or EXPIRED")
+	ErrInvalidOrderTransition  = errors.New("invalid order status transition")
+	ErrOrderStatusNotSettable  = errors.New("status is set by its own flow: confirm, cancel, shipments or reservation expiry")
+	ErrOrderNotFullyShipped    = errors.New("order has units pending shipment")
+	ErrOrderNotShippable       = errors.New("only PACKED or SHIPPED orders accept shipments")
+	ErrShipmentCarrierRequired = errors.New("shipment carrier is required")
+	ErrShipmentMustHaveItems   = errors.New("shipment must have at least one line with units pending shipment")
+	ErrInvalidShipmentQuantity = errors.New("shipment quantity must be greater than 0 and not exceed the units pending shipment of the line")
+
+	// HITO CUSTOMER - Comprador de órdenes y ventas POS
+	ErrInvalidCustomerID    = errors.New("invalid customer_id")
+	ErrCustomerNotFound     = errors.New("customer not found")
+	ErrCustomerNameRequired = errors.New("customer name is required")
+
+	// HITO SALES-REPORT - Reporte de ventas por rango y agrupación
+	ErrInvalidReportDate    = errors.New("from and to are required (format: YYYY-MM-DD)")
+	ErrInvalidReportRange   = errors.New("to must not be before from and the range cannot exceed 366 days")
+	ErrInvalidReportGroupBy = errors.New("group_by must be day, week, month, hour, payment_method or point_of_sale")
 )
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaxCondition representa la condición frente al IVA de emisor o receptor
+type TaxCondition string
+
+const (
+	TaxConditionResponsableInscripto TaxCondition = "RESPONSABLE_INSCRIPTO"
+	TaxConditionMonotributo          TaxCondition = "MONOTRIBUTO"
+	TaxConditionExento               TaxCondition = "EXENTO"
+	TaxConditionConsumidorFinal      TaxCondition = "CONSUMIDOR_FINAL"
+)
+
+// IsValid indica si la condición es conocida
+func (c TaxCondition) IsValid() bool {
+	switch c {
+	case TaxConditionResponsableInscripto, TaxConditionMonotributo, TaxConditionExento, TaxConditionConsumidorFinal:
+		return true
+	}
+	return false
+}
+
+// FiscalIssuer representa los datos fiscales del tenant como emisor de comprobantes
+// HITO FISCAL - Un emisor por tenant (CUIT + condición + punto de venta fiscal)
+type FiscalIssuer struct {
+	TenantID          uuid.UUID    `json:"tenant_id"`
+	CUIT              string       `json:"cuit"`
+	LegalName         string       `json:"legal_name"`
+	TaxCondition      TaxCondition `json:"tax_condition"`
+	PointOfSaleNumber int          `json:"point_of_sale_number"` // Punto de venta habilitado ante el fisco
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
+
+// NewFiscalIssuer crea / actualiza los datos fiscales de un tenant
+// Un consumidor final no puede emitir comprobantes
+func NewFiscalIssuer(tenantID uuid.UUID, cuit, legalName string, taxCondition TaxCondition, pointOfSaleNumber int) (*FiscalIssuer, error) {
+	if tenantID == uuid.Nil {
+		return nil, ErrTenantIDRequired
+	}
+	if !IsValidCUIT(cuit) {
+		return nil, ErrInvalidCUIT
+	}
+	if !taxCondition.IsValid() || taxCondition == TaxConditionConsumidorFinal {
+		return nil, ErrInvalidTaxCondition
+	}
+	if pointOfSaleNumber <= 0 || pointOfSaleNumber > 99999 {
+		return nil, ErrInvalidFiscalPointOfSale
+	}
+
+	return &FiscalIssuer{
+		TenantID:          tenantID,
+		CUIT:              cuit,
+		LegalName:         legalName,
+		TaxCondition:      taxCondition,
+		PointOfSaleNumber: pointOfSaleNumber,
+		UpdatedAt:         time.Now(),
+	}, nil
+}
+
+// IsValidCUIT valida formato y dígito verificador de un CUIT/CUIL (11 dígitos, sin guiones)
+func IsValidCUIT(cuit string) bool {
+	if len(cuit) != 11 {
+		return false
+	}
+
+	weights := [10]int{5, 4, 3, 2, 7, 6, 5, 4, 3, 2}
+	sum := 0
+	for i := 0; i < 11; i++ {
+		if cuit[i] < '0' || cuit[i] > '9' {
+			return false
+		}
+		if i < 10 {
+			sum += int(cuit[i]-'0') * weights[i]
+		}
+	}
+
+	check := 11 - sum%11
+	switch check {
+	case 11:
+		check = 0
+	case 10:
+		check = 9
+	}
+	return int(cuit[10]-'0') == check
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyHeader header con el que el cliente identifica un intento de operación
+// HITO IDEMPOTENCY - Un reintento con la misma clave devuelve la respuesta original
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyStatus estado de una clave de idempotencia
+// IN_PROGRESS mientras el primer request se ejecuta; COMPLETED con la respuesta guardada
+type IdempotencyStatus string
+
+const (
+	IdempotencyStatusInProgress IdempotencyStatus = "IN_PROGRESS"
+	IdempotencyStatusCompleted  IdempotencyStatus = "COMPLETED"
+)
+
+const (
+	MaxIdempotencyKeyLength = 255
+	// IdempotencyKeyTTL tiempo durante el cual una clave completada se puede reproducir
+	IdempotencyKeyTTL = 24 * time.Hour
+	// IdempotencyLockTTL tiempo máximo de un IN_PROGRESS; vencido, otro request puede tomar la clave
+	IdempotencyLockTTL = 2 * time.Minute
+)
+
+// IdempotencyRecord clave de idempotencia de un tenant con el hash del request y la respuesta final
+type IdempotencyRecord struct {
+	TenantID       string            `json:"tenant_id"`
+	Key            string            `json:"key"`
+	RequestHash    string            `json:"request_hash"` // sha256(method + ruta + body)
+	Status         IdempotencyStatus `json:"status"`
+	ResponseStatus int               `json:"response_status,omitempty"`
+	ResponseBody   []byte            `json:"-"`
+	LockToken      uuid.UUID         `json:"-"` // Identifica al request dueño del IN_PROGRESS
+	LockedUntil    time.Time         `json:"locked_until"`
+	ExpiresAt      time.Time         `json:"expires_at"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// NewIdempotencyRecord crea una clave IN_PROGRESS para el request que la toma
+func NewIdempotencyRecord(tenantID, key, requestHash string) (*IdempotencyRecord, error) {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return nil, ErrInvalidIdempotencyKey
+	}
+
+	now := time.Now()
+	return &IdempotencyRecord{
+		TenantID:    tenantID,
+		Key:         key,
+		RequestHash: requestHash,
+		Status:      IdempotencyStatusInProgress,
+		LockToken:   uuid.New(),
+		LockedUntil: now.Add(IdempotencyLockTTL),
+		ExpiresAt:   now.Add(IdempotencyKeyTTL),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// Matches indica si el request reintentado es el mismo que originó la clave
+func (r *IdempotencyRecord) Matches(requestHash string) bool {
+	return r.RequestHash == requestHash
+}
+
+// Complete guarda la respuesta final para reproducirla en los reintentos
+func (r *IdempotencyRecord) Complete(status int, body []byte) {
+	r.Status = IdempotencyStatusCompleted
+	r.ResponseStatus = status
+	r.ResponseBody = body
+	r.UpdatedAt = time.Now()
+}
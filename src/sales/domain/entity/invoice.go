@@ -0,0 +1,269 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// InvoiceType letra del comprobante (A / B / C)
+type InvoiceType string
+
+const (
+	InvoiceTypeA InvoiceType = "A" // RI a RI / Monotributo (IVA discriminado)
+	InvoiceTypeB InvoiceType = "B" // RI a Consumidor Final / Exento
+	InvoiceTypeC InvoiceType = "C" // Emisor Monotributo / Exento
+)
+
+// FiscalStatus estado fiscal del comprobante (y de la venta que lo origina)
+// PENDING → PROCESSING (lo toma el repositorio, ver InvoiceRepository.Claim) → APPROVED / REJECTED
+// Un fallo transitorio vuelve a PENDING con next_attempt_at (reintento con backoff)
+type FiscalStatus string
+
+const (
+	FiscalStatusPending    FiscalStatus = "PENDING"
+	FiscalStatusProcessing FiscalStatus = "PROCESSING"
+	FiscalStatusApproved   FiscalStatus = "APPROVED"
+	FiscalStatusRejected   FiscalStatus = "REJECTED"
+)
+
+// InvoiceSourceType tipo de venta que origina el comprobante
+type InvoiceSourceType string
+
+const (
+	InvoiceSourceSalesOrder InvoiceSourceType = "SALES_ORDER"
+	InvoiceSourcePosSale    InvoiceSourceType = "POS_SALE"
+)
+
+// IsValid indica si el tipo de origen es conocido
+func (t InvoiceSourceType) IsValid() bool {
+	return t == InvoiceSourceSalesOrder || t == InvoiceSourcePosSale
+}
+
+// Política de reintentos ante fallos transitorios del fisco
+const (
+	MaxInvoiceAttempts      = 8
+	invoiceRetryBaseBackoff = 30 * time.Second
+	invoiceRetryMaxBackoff  = 30 * time.Minute
+)
+
+// InvoiceSource datos de la venta a facturar (orden confirmada o venta POS)
+type InvoiceSource struct {
+	Type        InvoiceSourceType
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	Status      string
+	TotalAmount decimal.Decimal // Precio final (IVA incluido)
+	Currency    string
+	InvoiceID   *uuid.UUID // Comprobante ya emitido para la venta
+
+	// HITO TAX-IVA - Desglose calculado al registrar la venta (neto + IVA = total)
+	NetAmount decimal.Decimal
+	TaxAmount decimal.Decimal
+
+	// HITO CUSTOMER - Comprador de la venta (nil = venta previa al snapshot)
+	Customer *CustomerSnapshot
+}
+
+// IsInvoiceable indica si la venta admite factura
+// Órdenes: confirmadas, también en preparación o envío (HITO ORDER-FULFILLMENT).
+// POS: cualquier venta no anulada
+func (s *InvoiceSource) IsInvoiceable() bool {
+	switch s.Type {
+	case InvoiceSourceSalesOrder:
+		return OrderStatus(s.Status).IsConfirmed()
+	case InvoiceSourcePosSale:
+		return s.Status != string(PosSaleStatusVoided)
+	}
+	return false
+}
+
+// Invoice representa un comprobante electrónico (Aggregate Root)
+// HITO FISCAL - Factura A/B/C autorizada por el fisco (CAE)
+type Invoice struct {
+	ID                   uuid.UUID         `json:"id"`
+	TenantID             uuid.UUID         `json:"tenant_id"`
+	SourceType           InvoiceSourceType `json:"source_type"`
+	SourceID             uuid.UUID         `json:"source_id"`
+	InvoiceType          InvoiceType       `json:"invoice_type"`
+	PointOfSaleNumber    int               `json:"point_of_sale_number"`
+	InvoiceNumber        int               `json:"invoice_number"` // 0 hasta la autorización (lo fija el fisco)
+	IssuerCUIT           string            `json:"issuer_cuit"`
+	IssuerTaxCondition   TaxCondition      `json:"issuer_tax_condition"`
+	CustomerTaxCondition TaxCondition      `json:"customer_tax_condition"`
+	CustomerTaxID        string            `json:"customer_tax_id,omitempty"` // CUIT (obligatorio en tipo A)
+	CustomerName         string            `json:"customer_name,omitempty"`
+	Currency             string            `json:"currency"`
+	NetAmount            decimal.Decimal   `json:"net_amount"` // Neto gravado
+	TaxAmount            decimal.Decimal   `json:"tax_amount"` // IVA (0 en tipo C)
+	TotalAmount          decimal.Decimal   `json:"total_amount"`
+	FiscalStatus         FiscalStatus      `json:"fiscal_status"`
+	CAE                  string            `json:"cae,omitempty"`
+	CAEExpiresAt         *time.Time        `json:"cae_expires_at,omitempty"`
+	RejectionReason      string            `json:"rejection_reason,omitempty"`
+	Attempts             int               `json:"attempts"`
+	NextAttemptAt        time.Time         `json:"next_attempt_at"`
+	LastError            string            `json:"last_error,omitempty"`
+	AuthorizedAt         *time.Time        `json:"authorized_at,omitempty"`
+	CreatedAt            time.Time         `json:"created_at"`
+	UpdatedAt            time.Time         `json:"updated_at"`
+}
+
+// DetermineInvoiceType determina la letra según condición de emisor y receptor
+func DetermineInvoiceType(issuer, customer TaxCondition) InvoiceType {
+	if issuer != TaxConditionResponsableInscripto {
+		return InvoiceTypeC
+	}
+	if customer == TaxConditionResponsableInscripto || customer == TaxConditionMonotributo {
+		return InvoiceTypeA
+	}
+	return InvoiceTypeB
+}
+
+// NewInvoice crea un comprobante PENDING para una venta
+// HITO TAX-IVA - Neto e IVA salen del desglose de la venta (alícuotas por línea)
+// En comprobantes C el IVA no se discrimina (neto = total)
+func NewInvoice(
+	issuer *FiscalIssuer,
+	source *InvoiceSource,
+	customerTaxCondition TaxCondition,
+	customerTaxID, customerName string,
+) (*Invoice, error) {
+	if issuer == nil {
+		return nil, ErrFiscalIssuerNotConfigured
+	}
+	if !source.Type.IsValid() {
+		return nil, ErrInvalidInvoiceSourceType
+	}
+	if source.InvoiceID != nil {
+		return nil, ErrInvoiceAlreadyExists
+	}
+	if !source.IsInvoiceable() {
+		return nil, ErrInvoiceSourceNotInvoiceable
+	}
+	if !source.TotalAmount.GreaterThan(decimal.Zero) {
+		return nil, ErrInvoiceAmountRequired
+	}
+	if customerTaxCondition == "" {
+		customerTaxCondition = TaxConditionConsumidorFinal
+	}
+	if !customerTaxCondition.IsValid() {
+		return nil, ErrInvalidTaxCondition
+	}
+
+	invoiceType := DetermineInvoiceType(issuer.TaxCondition, customerTaxCondition)
+	if invoiceType == InvoiceTypeA && !IsValidCUIT(customerTaxID) {
+		return nil, ErrCustomerTaxIDRequired
+	}
+
+	total := source.TotalAmount.Round(2)
+	net, tax := total, decimal.Zero
+	if invoiceType != InvoiceTypeC {
+		tax = source.TaxAmount.Round(2)
+		net = total.Sub(tax)
+	}
+
+	now := time.Now()
+	return &Invoice{
+		ID:                   uuid.New(),
+		TenantID:             source.TenantID,
+		SourceType:           source.Type,
+		SourceID:             source.ID,
+		InvoiceType:          invoiceType,
+		PointOfSaleNumber:    issuer.PointOfSaleNumber,
+		IssuerCUIT:           issuer.CUIT,
+		IssuerTaxCondition:   issuer.TaxCondition,
+		CustomerTaxCondition: customerTaxCondition,
+		CustomerTaxID:        customerTaxID,
+		CustomerName:         customerName,
+		Currency:             source.Currency,
+		NetAmount:            net,
+		TaxAmount:            tax,
+		TotalAmount:          total,
+		FiscalStatus:         FiscalStatusPending,
+		NextAttemptAt:        now,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}, nil
+}
+
+// Approve registra la autorización del fisco (número definitivo + CAE)
+func (i *Invoice) Approve(invoiceNumber int, cae string, caeExpiresAt time.Time) error {
+	if i.FiscalStatus != FiscalStatusProcessing {
+		return ErrInvalidFiscalTransition
+	}
+	now := time.Now()
+	i.FiscalStatus = FiscalStatusApproved
+	i.InvoiceNumber = invoiceNumber
+	i.CAE = cae
+	i.CAEExpiresAt = &caeExpiresAt
+	i.AuthorizedAt = &now
+	i.RejectionReason = ""
+	i.LastError = ""
+	i.UpdatedAt = now
+	return nil
+}
+
+// Reject registra el rechazo definitivo del fisco
+func (i *Invoice) Reject(reason string) error {
+	if i.FiscalStatus != FiscalStatusProcessing {
+		return ErrInvalidFiscalTransition
+	}
+	i.FiscalStatus = FiscalStatusRejected
+	i.RejectionReason = reason
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// ScheduleRetry registra un fallo transitorio
+// Vuelve a PENDING con backoff exponencial; agotados los intentos queda REJECTED
+func (i *Invoice) ScheduleRetry(cause error) error {
+	if i.FiscalStatus != FiscalStatusProcessing {
+		return ErrInvalidFiscalTransition
+	}
+	i.LastError = cause.Error()
+	if i.Attempts >= MaxInvoiceAttempts {
+		return i.Reject(fmt.Sprintf("authorization failed after %d attempts: %s", i.Attempts, i.LastError))
+	}
+
+	backoff := invoiceRetryBaseBackoff << (i.Attempts - 1)
+	if backoff > invoiceRetryMaxBackoff || backoff <= 0 {
+		backoff = invoiceRetryMaxBackoff
+	}
+	now := time.Now()
+	i.FiscalStatus = FiscalStatusPending
+	i.NextAttemptAt = now.Add(backoff)
+	i.UpdatedAt = now
+	return nil
+}
+
+// Retry reabre un comprobante rechazado (ej: tras corregir datos del emisor)
+func (i *Invoice) Retry() error {
+	if i.FiscalStatus != FiscalStatusRejected {
+		return ErrInvoiceNotRetryable
+	}
+	now := time.Now()
+	i.FiscalStatus = FiscalStatusPending
+	i.Attempts = 0
+	i.RejectionReason = ""
+	i.NextAttemptAt = now
+	i.UpdatedAt = now
+	return nil
+}
+
+// IsCreditable indica si la factura admite nota de crédito
+// Una factura rechazada por el fisco no tiene efecto fiscal: no se revierte
+func (i *Invoice) IsCreditable() bool {
+	return i.FiscalStatus != FiscalStatusRejected
+}
+
+// FormattedNumber retorna el número impreso (ej: "A 0001-00000042")
+// Vacío hasta que el fisco autoriza el comprobante
+func (i *Invoice) FormattedNumber() string {
+	if i.InvoiceNumber == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s %04d-%08d", i.InvoiceType, i.PointOfSaleNumber, i.InvoiceNumber)
+}
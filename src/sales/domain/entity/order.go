@@ -1,9 +1,12 @@
 package entity
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // OrderStatus representa el estado de una orden
@@ -13,6 +16,14 @@ const (
 	OrderStatusCreated   OrderStatus = "CREATED"
 	OrderStatusConfirmed OrderStatus = "CONFIRMED"
 	OrderStatusCanceled  OrderStatus = "CANCELED"
+	OrderStatusExpired   OrderStatus = "EXPIRED" // HITO ORDER-RESERVATION: venció la reserva sin confirmar
+
+	// HITO ORDER-FULFILLMENT - Preparación y envío (ver order_lifecycle.go)
+	OrderStatusPicking   OrderStatus = "PICKING"
+	OrderStatusPacked    OrderStatus = "PACKED"
+	OrderStatusShipped   OrderStatus = "SHIPPED"
+	OrderStatusDelivered OrderStatus = "DELIVERED"
+	OrderStatusReturned  OrderStatus = "RETURNED"
 )
 
 // Order representa una orden (Aggregate Root)
@@ -25,6 +36,23 @@ type Order struct {
 	CreatedAt   time.Time   `json:"created_at"`
 	Items       []OrderItem `json:"items"` // DDD: Collection of entities
 
+	// HITO TAX-IVA - Totales impositivos (total_amount = bruto)
+	PriceMode   TaxPriceMode    `json:"price_mode"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	TotalAmount decimal.Decimal `json:"total_amount"`
+
+	// HITO ORDER-RESERVATION - Vencimiento de la reserva de stock (nil = orden previa, sin reserva)
+	ReservationExpiresAt *time.Time `json:"reservation_expires_at,omitempty"`
+
+	// HITO ORDER-CANCEL - Motivo y autor de la cancelación
+	CancelReason string     `json:"cancel_reason,omitempty"`
+	CanceledBy   string     `json:"canceled_by,omitempty"`
+	CanceledAt   *time.Time `json:"canceled_at,omitempty"`
+
+	// HITO CUSTOMER - Comprador (nil = orden previa al snapshot, consumidor final)
+	Customer *CustomerSnapshot `json:"customer,omitempty"`
+
 	// Campos legacy (deprecated, usar Items)
 	SKU      string `json:"sku,omitempty"`
 	Quantity int    `json:"quantity,omitempty"`
@@ -100,12 +128,40 @@ func (o *Order) Confirm() error {
 	return nil
 }
 
+// ReserveItems asigna a cada línea su referencia de reserva y el vencimiento de la orden
+// HITO ORDER-RESERVATION - La referencia (<order_id>-ITEM<n>) es la que se usa en
+// stock-service para reservar, consumir al confirmar y liberar al vencer
+func (o *Order) ReserveItems(expiresAt time.Time) {
+	for i := range o.Items {
+		o.Items[i].ReservationReference = fmt.Sprintf("%s-ITEM%d", o.OrderID, i+1)
+		o.Items[i].ReservationStatus = ReservationStatusReserved
+	}
+	o.ReservationExpiresAt = &expiresAt
+}
+
+// ReservationExpired indica si la reserva de stock venció en now
+// Las órdenes previas a las reservas no vencen
+func (o *Order) ReservationExpired(now time.Time) bool {
+	return o.ReservationExpiresAt != nil && !now.Before(*o.ReservationExpiresAt)
+}
+
 // Cancel cancela una orden
-func (o *Order) Cancel() error {
-	if o.Status != OrderStatusConfirmed {
-		return ErrOrderNotInConfirmedState
+// HITO ORDER-CANCEL - Desde CREATED (se libera la reserva) o CONFIRMED (se revierte el
+// consumo), con motivo obligatorio y el usuario que cancela
+func (o *Order) Cancel(reason, canceledBy string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrCancelReasonRequired
 	}
+	if o.Status != OrderStatusCreated && o.Status != OrderStatusConfirmed {
+		return ErrOrderNotCancelable
+	}
+
+	now := time.Now()
 	o.Status = OrderStatusCanceled
+	o.CancelReason = reason
+	o.CanceledBy = canceledBy
+	o.CanceledAt = &now
 	return nil
 }
 
@@ -113,3 +169,52 @@ func (o *Order) Cancel() error {
 func (o *Order) AssignOrderNumber(number int) {
 	o.OrderNumber = &number
 }
+
+// ApplyTax calcula el desglose de cada línea y los totales de la orden (HITO TAX-IVA)
+// Cada item debe traer su alícuota resuelta; el importe gravado es su subtotal
+func (o *Order) ApplyTax(priceMode TaxPriceMode) error {
+	if !priceMode.IsValid() {
+		return ErrInvalidTaxPriceMode
+	}
+
+	lines := make([]TaxableLine, len(o.Items))
+	for i, item := range o.Items {
+		if !item.TaxRateCode.IsValid() {
+			return ErrInvalidTaxRate
+		}
+		lines[i] = TaxableLine{Amount: item.Subtotal, RateCode: item.TaxRateCode}
+	}
+
+	lineTaxes, summary := CalculateDocumentTax(lines, decimal.Zero, priceMode)
+	for i, breakdown := range lineTaxes {
+		o.Items[i].TaxRate = breakdown.Rate
+		o.Items[i].NetAmount = breakdown.NetAmount
+		o.Items[i].TaxAmount = breakdown.TaxAmount
+		o.Items[i].GrossAmount = breakdown.GrossAmount
+	}
+
+	o.PriceMode = priceMode
+	o.NetAmount = summary.NetAmount
+	o.TaxAmount = summary.TaxAmount
+	o.TotalAmount = summary.GrossAmount
+	return nil
+}
+
+// Subtotal suma los subtotales de las líneas (HITO ORDER-PRICE)
+// Con precios INCLUDED coincide con el total; con EXCLUDED, con el neto
+func (o *Order) Subtotal() decimal.Decimal {
+	subtotal := decimal.Zero
+	for _, item := range o.Items {
+		subtotal = subtotal.Add(item.Subtotal)
+	}
+	return subtotal
+}
+
+// TaxSummary retorna los totales impositivos agrupados por alícuota (HITO TAX-IVA)
+func (o *Order) TaxSummary() TaxSummary {
+	lines := make([]TaxBreakdown, 0, len(o.Items))
+	for _, item := range o.Items {
+		lines = append(lines, item.TaxBreakdown())
+	}
+	return SummarizeTax(lines, o.PriceMode)
+}
@@ -3,6 +3,21 @@ package entity
 import (
 	"encoding/json"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ReservationStatus estado de la reserva de stock de una línea (HITO ORDER-RESERVATION)
+// RESERVED → CONSUMING → CONSUMED al confirmar; RESERVED / CONSUMING / CONSUMED → RELEASED
+// al vencer la orden. CONSUMING marca la línea que una confirmación está consumiendo en
+// stock-service: una segunda confirmación concurrente no la vuelve a consumir.
+// Vacío en líneas previas a las reservas (su stock se descontó al crear la orden)
+type ReservationStatus string
+
+const (
+	ReservationStatusReserved  ReservationStatus = "RESERVED"
+	ReservationStatusConsuming ReservationStatus = "CONSUMING"
+	ReservationStatusConsumed  ReservationStatus = "CONSUMED"
+	ReservationStatusReleased  ReservationStatus = "RELEASED"
 )
 
 // OrderItem representa un item dentro de una orden (Entity dentro del Aggregate)
@@ -13,6 +28,28 @@ type OrderItem struct {
 	Quantity        int             `json:"quantity"`
 	ProductSnapshot json.RawMessage `json:"product_snapshot,omitempty"`
 	VariantSnapshot json.RawMessage `json:"variant_snapshot,omitempty"`
+
+	// HITO ORDER-PRICE - Precio unitario (snapshot de PIM u override de la línea)
+	UnitPrice       decimal.Decimal `json:"unit_price"`
+	PriceOverridden bool            `json:"price_overridden"`
+
+	// HITO TAX-IVA - Importe de la línea y su desglose impositivo
+	Subtotal    decimal.Decimal `json:"subtotal"`
+	TaxRateCode TaxRateCode     `json:"tax_rate_code"`
+	TaxRate     decimal.Decimal `json:"tax_rate"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	GrossAmount decimal.Decimal `json:"gross_amount"`
+
+	// HITO ORDER-RESERVATION - Reserva de stock de la línea en stock-service
+	ReservationReference string            `json:"reservation_reference,omitempty"`
+	ReservationStatus    ReservationStatus `json:"reservation_status,omitempty"`
+
+	// HITO ORDER-CANCEL - Unidades canceladas de la línea (Quantity queda como se pidió)
+	CanceledQuantity int `json:"canceled_quantity"`
+
+	// HITO ORDER-FULFILLMENT - Unidades despachadas en envíos
+	ShippedQuantity int `json:"shipped_quantity"`
 }
 
 // NewOrderItem crea un nuevo item de orden
@@ -50,3 +87,70 @@ func NewOrderItemWithSnapshots(orderID, sku string, quantity int, productSnapsho
 		VariantSnapshot: variantSnapshot,
 	}, nil
 }
+
+// ApplyPrice fija el precio unitario de la línea y calcula su subtotal (HITO ORDER-PRICE)
+// overridden indica que el precio no es el del snapshot de PIM
+func (i *OrderItem) ApplyPrice(unitPrice decimal.Decimal, overridden bool) error {
+	if unitPrice.IsNegative() {
+		return ErrInvalidPrice
+	}
+
+	i.UnitPrice = unitPrice.Round(2)
+	i.Subtotal = i.UnitPrice.Mul(decimal.NewFromInt(int64(i.ActiveQuantity()))).Round(2)
+	i.PriceOverridden = overridden
+	return nil
+}
+
+// SnapshotPrice retorna el precio de la variante según el snapshot de PIM (HITO ORDER-PRICE)
+func (i *OrderItem) SnapshotPrice() (decimal.Decimal, error) {
+	return VariantPriceFromSnapshot(i.VariantSnapshot)
+}
+
+// VariantPriceFromSnapshot extrae el precio del snapshot de variante de PIM
+// El precio se lee como decimal (sin pasar por float64)
+func VariantPriceFromSnapshot(variantSnapshot json.RawMessage) (decimal.Decimal, error) {
+	if len(variantSnapshot) == 0 {
+		return decimal.Zero, ErrItemPriceUnavailable
+	}
+	var variant struct {
+		Price *decimal.Decimal `json:"price"`
+	}
+	if err := json.Unmarshal(variantSnapshot, &variant); err != nil || variant.Price == nil {
+		return decimal.Zero, ErrItemPriceUnavailable
+	}
+	if variant.Price.IsNegative() {
+		return decimal.Zero, ErrInvalidPrice
+	}
+	return *variant.Price, nil
+}
+
+// CategoryID retorna la categoría PIM del producto según el snapshot (HITO TAX-IVA)
+func (i *OrderItem) CategoryID() string {
+	return CategoryIDFromSnapshot(i.ProductSnapshot)
+}
+
+// TaxBreakdown retorna el desglose impositivo de la línea (HITO TAX-IVA)
+func (i *OrderItem) TaxBreakdown() TaxBreakdown {
+	return TaxBreakdown{
+		RateCode:    i.TaxRateCode,
+		Rate:        i.TaxRate,
+		NetAmount:   i.NetAmount,
+		TaxAmount:   i.TaxAmount,
+		GrossAmount: i.GrossAmount,
+	}
+}
+
+// HasReservation indica si la línea tiene reserva propia en stock-service (HITO ORDER-RESERVATION)
+func (i *OrderItem) HasReservation() bool {
+	return i.ReservationReference != ""
+}
+
+// ActiveQuantity unidades vigentes de la línea: pedidas menos canceladas (HITO ORDER-CANCEL)
+func (i *OrderItem) ActiveQuantity() int {
+	return i.Quantity - i.CanceledQuantity
+}
+
+// PendingShipment unidades vigentes aún sin despachar (HITO ORDER-FULFILLMENT)
+func (i *OrderItem) PendingShipment() int {
+	return i.ActiveQuantity() - i.ShippedQuantity
+}
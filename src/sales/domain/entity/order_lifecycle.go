@@ -0,0 +1,149 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// orderTransitions máquina de estados de la orden (HITO ORDER-FULFILLMENT)
+//
+//	CREATED → CONFIRMED → PICKING → PACKED → SHIPPED → DELIVERED
+//	   │          │                            │          │
+//	   ├→ EXPIRED └→ CANCELED                  └→ RETURNED ←┘
+//	   └→ CANCELED
+//
+// CONFIRMED, CANCELED, EXPIRED y SHIPPED tienen su propio flujo (confirmación con
+// consumo de stock, cancelación, vencimiento de la reserva, registro de un envío)
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusCreated:   {OrderStatusConfirmed, OrderStatusCanceled, OrderStatusExpired},
+	OrderStatusConfirmed: {OrderStatusPicking, OrderStatusCanceled},
+	OrderStatusPicking:   {OrderStatusPacked},
+	OrderStatusPacked:    {OrderStatusShipped},
+	OrderStatusShipped:   {OrderStatusDelivered, OrderStatusReturned},
+	OrderStatusDelivered: {OrderStatusReturned},
+}
+
+// manualOrderStatuses estados a los que se llega con una transición explícita
+// (el resto los fija el flujo que les corresponde)
+var manualOrderStatuses = map[OrderStatus]bool{
+	OrderStatusPicking:   true,
+	OrderStatusPacked:    true,
+	OrderStatusDelivered: true,
+	OrderStatusReturned:  true,
+}
+
+// IsValid indica si el estado existe
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case OrderStatusCreated, OrderStatusConfirmed, OrderStatusCanceled, OrderStatusExpired,
+		OrderStatusPicking, OrderStatusPacked, OrderStatusShipped, OrderStatusDelivered, OrderStatusReturned:
+		return true
+	}
+	return false
+}
+
+// IsConfirmed indica si la orden está confirmada, esté o no en preparación o envío
+// (RETURNED no: la mercadería volvió)
+func (s OrderStatus) IsConfirmed() bool {
+	switch s {
+	case OrderStatusConfirmed, OrderStatusPicking, OrderStatusPacked, OrderStatusShipped, OrderStatusDelivered:
+		return true
+	}
+	return false
+}
+
+// IsManual indica si se llega al estado con una transición explícita (HITO ORDER-FULFILLMENT)
+func (s OrderStatus) IsManual() bool {
+	return manualOrderStatuses[s]
+}
+
+// ParseOrderStatus normaliza y valida un estado recibido por API
+func ParseOrderStatus(value string) (OrderStatus, error) {
+	status := OrderStatus(strings.ToUpper(strings.TrimSpace(value)))
+	if !status.IsValid() {
+		return "", ErrInvalidOrderStatus
+	}
+	return status, nil
+}
+
+// InvalidOrderTransitionError transición no permitida por la máquina de estados
+// errors.Is(err, ErrInvalidOrderTransition) es true
+type InvalidOrderTransitionError struct {
+	From OrderStatus
+	To   OrderStatus
+}
+
+func (e *InvalidOrderTransitionError) Error() string {
+	return fmt.Sprintf("invalid order status transition %s → %s", e.From, e.To)
+}
+
+func (e *InvalidOrderTransitionError) Is(target error) bool {
+	return target == ErrInvalidOrderTransition
+}
+
+// CanTransitionTo indica si la máquina de estados permite pasar a to
+func (o *Order) CanTransitionTo(to OrderStatus) bool {
+	for _, allowed := range orderTransitions[o.Status] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionTo cambia el estado de la orden según la máquina de estados
+// DELIVERED exige que no queden unidades sin despachar
+func (o *Order) TransitionTo(to OrderStatus) error {
+	if !to.IsValid() {
+		return ErrInvalidOrderStatus
+	}
+	if !o.CanTransitionTo(to) {
+		return &InvalidOrderTransitionError{From: o.Status, To: to}
+	}
+	if to == OrderStatusDelivered && !o.FullyShipped() {
+		return ErrOrderNotFullyShipped
+	}
+
+	o.Status = to
+	return nil
+}
+
+// FullyShipped indica si todas las unidades vigentes se despacharon
+func (o *Order) FullyShipped() bool {
+	for _, item := range o.Items {
+		if item.PendingShipment() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// OrderStatusChange transición registrada en order_status_history
+// HITO ORDER-FULFILLMENT - Quién cambió el estado y cuándo (Actor vacío = sistema)
+type OrderStatusChange struct {
+	ID         uuid.UUID   `json:"id"`
+	TenantID   string      `json:"tenant_id"`
+	OrderID    string      `json:"order_id"`
+	FromStatus OrderStatus `json:"from_status"`
+	ToStatus   OrderStatus `json:"to_status"`
+	Actor      string      `json:"actor,omitempty"`
+	Note       string      `json:"note,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// NewOrderStatusChange registra el paso de from al estado actual de la orden
+func NewOrderStatusChange(order *Order, from OrderStatus, actor, note string) *OrderStatusChange {
+	return &OrderStatusChange{
+		ID:         uuid.New(),
+		TenantID:   order.TenantID,
+		OrderID:    order.OrderID,
+		FromStatus: from,
+		ToStatus:   order.Status,
+		Actor:      actor,
+		Note:       strings.TrimSpace(note),
+		CreatedAt:  time.Now(),
+	}
+}
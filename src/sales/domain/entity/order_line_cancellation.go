@@ -0,0 +1,148 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// OrderLineCancel unidades a cancelar de una línea de la orden
+type OrderLineCancel struct {
+	ItemID   string
+	Quantity int
+}
+
+// OrderLineCancellation documento de cancelación parcial de una orden confirmada
+// HITO ORDER-CANCEL - Registra qué unidades de qué líneas se cancelaron y cuánto bajó
+// la orden; la orden conserva sus líneas con canceled_quantity y totales recalculados
+type OrderLineCancellation struct {
+	ID          uuid.UUID                   `json:"id"`
+	TenantID    string                      `json:"tenant_id"`
+	OrderID     string                      `json:"order_id"`
+	Reason      string                      `json:"reason"`
+	CanceledBy  string                      `json:"canceled_by,omitempty"`
+	NetAmount   decimal.Decimal             `json:"net_amount"`
+	TaxAmount   decimal.Decimal             `json:"tax_amount"`
+	TotalAmount decimal.Decimal             `json:"total_amount"` // Bruto cancelado
+	CreatedAt   time.Time                   `json:"created_at"`
+	Items       []OrderLineCancellationItem `json:"items"`
+}
+
+// OrderLineCancellationItem unidades canceladas de una línea y su importe
+type OrderLineCancellationItem struct {
+	ID          uuid.UUID       `json:"id"`
+	ItemID      string          `json:"item_id"`
+	SKU         string          `json:"sku"`
+	Quantity    int             `json:"quantity"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	GrossAmount decimal.Decimal `json:"gross_amount"`
+}
+
+// CancelLines cancela unidades de líneas de una orden confirmada (HITO ORDER-CANCEL)
+// Recalcula subtotal, IVA y totales de la orden; el importe cancelado por línea es la
+// diferencia de su bruto. Cancelar todo lo que queda es cancelar la orden
+func (o *Order) CancelLines(lines []OrderLineCancel, reason, canceledBy string) (*OrderLineCancellation, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrCancelReasonRequired
+	}
+	if o.Status != OrderStatusConfirmed {
+		return nil, ErrOrderNotInConfirmedState
+	}
+	if len(lines) == 0 {
+		return nil, ErrLineCancelMustHaveItems
+	}
+	if !o.PriceMode.IsValid() {
+		return nil, ErrInvalidTaxPriceMode
+	}
+
+	itemsByID := make(map[string]int, len(o.Items))
+	activeTotal := 0
+	for i, item := range o.Items {
+		itemsByID[item.ItemID] = i
+		activeTotal += item.ActiveQuantity()
+	}
+
+	// Cantidades por línea (una línea repetida en el request suma)
+	requested := make(map[int]int, len(lines))
+	var touched []int
+	canceledTotal := 0
+	for _, line := range lines {
+		idx, ok := itemsByID[line.ItemID]
+		if !ok {
+			return nil, ErrOrderItemNotFound
+		}
+		if line.Quantity <= 0 {
+			return nil, ErrInvalidCancelQuantity
+		}
+		if _, seen := requested[idx]; !seen {
+			touched = append(touched, idx)
+		}
+		requested[idx] += line.Quantity
+		if requested[idx] > o.Items[idx].ActiveQuantity() {
+			return nil, ErrInvalidCancelQuantity
+		}
+		canceledTotal += line.Quantity
+	}
+	if canceledTotal >= activeTotal {
+		return nil, ErrLineCancelWouldEmptyOrder
+	}
+
+	// Aplicar al aggregate y recalcular IVA con las cantidades vigentes
+	previous := make(map[int]TaxBreakdown, len(touched))
+	for _, idx := range touched {
+		item := &o.Items[idx]
+		previous[idx] = item.TaxBreakdown()
+		item.CanceledQuantity += requested[idx]
+		if err := item.ApplyPrice(item.UnitPrice, item.PriceOverridden); err != nil {
+			return nil, err
+		}
+	}
+	if err := o.ApplyTax(o.PriceMode); err != nil {
+		return nil, err
+	}
+
+	cancellation := &OrderLineCancellation{
+		ID:          uuid.New(),
+		TenantID:    o.TenantID,
+		OrderID:     o.OrderID,
+		Reason:      reason,
+		CanceledBy:  canceledBy,
+		NetAmount:   decimal.Zero,
+		TaxAmount:   decimal.Zero,
+		TotalAmount: decimal.Zero,
+		CreatedAt:   time.Now(),
+		Items:       make([]OrderLineCancellationItem, 0, len(touched)),
+	}
+	for _, idx := range touched {
+		item := o.Items[idx]
+		line := OrderLineCancellationItem{
+			ID:          uuid.New(),
+			ItemID:      item.ItemID,
+			SKU:         item.SKU,
+			Quantity:    requested[idx],
+			NetAmount:   previous[idx].NetAmount.Sub(item.NetAmount),
+			TaxAmount:   previous[idx].TaxAmount.Sub(item.TaxAmount),
+			GrossAmount: previous[idx].GrossAmount.Sub(item.GrossAmount),
+		}
+		cancellation.Items = append(cancellation.Items, line)
+		cancellation.NetAmount = cancellation.NetAmount.Add(line.NetAmount)
+		cancellation.TaxAmount = cancellation.TaxAmount.Add(line.TaxAmount)
+		cancellation.TotalAmount = cancellation.TotalAmount.Add(line.GrossAmount)
+	}
+
+	return cancellation, nil
+}
+
+// HasCanceledLines indica si la orden tuvo cancelaciones parciales (HITO ORDER-CANCEL)
+func (o *Order) HasCanceledLines() bool {
+	for _, item := range o.Items {
+		if item.CanceledQuantity > 0 {
+			return true
+		}
+	}
+	return false
+}
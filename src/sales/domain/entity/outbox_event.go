@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxStatus estado de un evento en el outbox
+// HITO OUTBOX - PENDING → PROCESSING → PUBLISHED. Un fallo del eventbus vuelve a PENDING
+// con backoff; agotados los intentos queda DEAD (dead-letter, requiere intervención manual)
+type OutboxStatus string
+
+const (
+	OutboxStatusPending    OutboxStatus = "PENDING"
+	OutboxStatusProcessing OutboxStatus = "PROCESSING"
+	OutboxStatusPublished  OutboxStatus = "PUBLISHED"
+	OutboxStatusDead       OutboxStatus = "DEAD"
+)
+
+const (
+	MaxOutboxAttempts      = 10
+	outboxRetryBaseBackoff = 5 * time.Second
+	outboxRetryMaxBackoff  = 10 * time.Minute
+)
+
+// OutboxEvent evento de negocio pendiente de reenviar al eventbus
+// Se escribe en la misma transacción que el cambio de la venta: o se confirman ambos o ninguno
+type OutboxEvent struct {
+	ID            uuid.UUID       `json:"id"`
+	AggregateID   string          `json:"aggregate_id"`
+	AggregateType string          `json:"aggregate_type"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	PublishedBy   string          `json:"published_by"`
+	Status        OutboxStatus    `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	PublishedAt   *time.Time      `json:"published_at,omitempty"`
+}
+
+// NewOutboxEvent crea un evento PENDING listo para el relay
+func NewOutboxEvent(aggregateID, aggregateType, eventType string, payload []byte, publishedBy string) (*OutboxEvent, error) {
+	if aggregateID == "" || aggregateType == "" {
+		return nil, ErrOutboxAggregateRequired
+	}
+	if eventType == "" {
+		return nil, ErrOutboxEventTypeRequired
+	}
+	if !json.Valid(payload) {
+		return nil, ErrInvalidOutboxPayload
+	}
+
+	now := time.Now()
+	return &OutboxEvent{
+		ID:            uuid.New(),
+		AggregateID:   aggregateID,
+		AggregateType: aggregateType,
+		EventType:     eventType,
+		Payload:       payload,
+		PublishedBy:   publishedBy,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// MarkPublished registra el reenvío exitoso al eventbus
+func (e *OutboxEvent) MarkPublished() error {
+	if e.Status != OutboxStatusProcessing {
+		return ErrInvalidOutboxTransition
+	}
+	now := time.Now()
+	e.Status = OutboxStatusPublished
+	e.PublishedAt = &now
+	e.LastError = ""
+	e.UpdatedAt = now
+	return nil
+}
+
+// ScheduleRetry registra un fallo al publicar
+// Vuelve a PENDING con backoff exponencial; agotados los intentos queda DEAD
+func (e *OutboxEvent) ScheduleRetry(cause error) error {
+	if e.Status != OutboxStatusProcessing {
+		return ErrInvalidOutboxTransition
+	}
+	now := time.Now()
+	e.LastError = cause.Error()
+	e.UpdatedAt = now
+	if e.Attempts >= MaxOutboxAttempts {
+		e.Status = OutboxStatusDead
+		e.LastError = fmt.Sprintf("publish failed after %d attempts: %s", e.Attempts, cause.Error())
+		return nil
+	}
+
+	backoff := outboxRetryBaseBackoff << (e.Attempts - 1)
+	if backoff > outboxRetryMaxBackoff || backoff <= 0 {
+		backoff = outboxRetryMaxBackoff
+	}
+	e.Status = OutboxStatusPending
+	e.NextAttemptAt = now.Add(backoff)
+	return nil
+}
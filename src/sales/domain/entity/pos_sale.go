@@ -1,52 +1,89 @@
 package entity
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
+// PosSaleStatus representa el estado de una venta POS
+// HITO POS-REFUND - La venta se registra COMPLETED y solo cambia por anulación / devolución
+type PosSaleStatus string
+
+const (
+	PosSaleStatusCompleted         PosSaleStatus = "COMPLETED"
+	PosSaleStatusPartiallyRefunded PosSaleStatus = "PARTIALLY_REFUNDED"
+	PosSaleStatusRefunded          PosSaleStatus = "REFUNDED"
+	PosSaleStatusVoided            PosSaleStatus = "VOIDED"
+)
+
 // PosSale representa una venta POS (Aggregate Root)
 // HITO B - Refactorizado para soportar multi-item + descuentos
 // HITO: POST /pos/sale devuelve DTO listo para imprimir
+// HITO POS-SPLIT - Soporta múltiples pagos por venta
 type PosSale struct {
-	ID              uuid.UUID       `json:"id"`
-	TenantID        uuid.UUID       `json:"tenant_id"`
-	CustomerID      *uuid.UUID      `json:"customer_id"`       // NULL = consumidor final
-	PaymentMethodID uuid.UUID       `json:"payment_method_id"` // Obligatorio
-	TotalAmount     decimal.Decimal `json:"total_amount"`      // Suma de subtotales
-	DiscountAmount  decimal.Decimal `json:"discount_amount"`   // Descuento fijo
-	FinalAmount     decimal.Decimal `json:"final_amount"`      // total - discount
-	AmountPaid      decimal.Decimal `json:"amount_paid"`       // Monto pagado por el cliente
-	Change          decimal.Decimal `json:"change"`            // Vuelto (amount_paid - final_amount)
-	Currency        string          `json:"currency"`
-	CreatedAt       time.Time       `json:"created_at"`
-	Items           []PosSaleItem   `json:"items"` // DDD: Collection of entities
+	ID              uuid.UUID        `json:"id"`
+	TenantID        uuid.UUID        `json:"tenant_id"`
+	CustomerID      *uuid.UUID       `json:"customer_id"`       // NULL = consumidor final
+	PaymentMethodID uuid.UUID        `json:"payment_method_id"` // Método principal (primer pago)
+	TotalAmount     decimal.Decimal  `json:"total_amount"`      // Suma de subtotales
+	DiscountAmount  decimal.Decimal  `json:"discount_amount"`   // Descuento fijo
+	FinalAmount     decimal.Decimal  `json:"final_amount"`      // total - discount (+ IVA si los precios no lo incluyen)
+	AmountPaid      decimal.Decimal  `json:"amount_paid"`       // Suma de todos los pagos
+	Change          decimal.Decimal  `json:"change"`            // Vuelto (amount_paid - final_amount, solo efectivo)
+	Currency        string           `json:"currency"`
+	CreatedAt       time.Time        `json:"created_at"`
+	Items           []PosSaleItem    `json:"items"`    // DDD: Collection of entities
+	Payments        []PosSalePayment `json:"payments"` // HITO POS-SPLIT
+
+	// HITO POS-CASH - Terminal y sesión de caja
+	PointOfSaleID *uuid.UUID `json:"point_of_sale_id,omitempty"`
+	CashSessionID *uuid.UUID `json:"cash_session_id,omitempty"`
+
+	// HITO POS-NUMBER - Ticket PPPP-NNNNNNNN (0 = venta previa a la numeración)
+	PointOfSaleNumber int `json:"point_of_sale_number"`
+	PosNumber         int `json:"pos_number"`
+
+	// HITO POS-REFUND - Estado y monto devuelto acumulado
+	Status         PosSaleStatus   `json:"status"`
+	RefundedAmount decimal.Decimal `json:"refunded_amount"`
+
+	// HITO TAX-IVA - Totales impositivos (bruto = final_amount)
+	PriceMode TaxPriceMode    `json:"price_mode"`
+	NetAmount decimal.Decimal `json:"net_amount"`
+	TaxAmount decimal.Decimal `json:"tax_amount"`
+
+	// HITO CUSTOMER - Comprador (nil = venta previa al snapshot, consumidor final)
+	Customer *CustomerSnapshot `json:"customer,omitempty"`
 }
 
 // NewPosSale crea una nueva venta POS con múltiples items (DDD Aggregate Root)
 // HITO B - Constructor multi-item
 // HITO: POST /pos/sale devuelve DTO listo para imprimir
+// HITO POS-SPLIT - Los pagos deben cubrir final_amount y el vuelto sale solo de efectivo
+// HITO TAX-IVA - Cada item trae su alícuota; el IVA se calcula sobre el importe descontado
 func NewPosSale(
 	tenantID uuid.UUID,
 	customerID *uuid.UUID,
-	paymentMethodID uuid.UUID,
 	items []PosSaleItem,
 	discountAmount decimal.Decimal,
-	amountPaid decimal.Decimal,
+	payments []PosSalePayment,
 	currency string,
+	priceMode TaxPriceMode,
 ) (*PosSale, error) {
 	// Validaciones básicas
 	if tenantID == uuid.Nil {
 		return nil, ErrTenantIDRequired
 	}
-	if paymentMethodID == uuid.Nil {
-		return nil, ErrTenantIDRequired // TODO: Crear ErrPaymentMethodRequired
-	}
 	if len(items) == 0 {
 		return nil, ErrPosSaleMustHaveItems
 	}
+	if len(payments) == 0 {
+		return nil, ErrPosSaleMustHavePayments
+	}
 	if discountAmount.LessThan(decimal.Zero) {
 		return nil, ErrInvalidDiscount
 	}
@@ -62,32 +99,78 @@ func NewPosSale(
 		totalAmount = totalAmount.Add(item.Subtotal)
 	}
 
-	// Calcular final_amount
-	finalAmount := totalAmount.Sub(discountAmount)
-	if finalAmount.LessThan(decimal.Zero) {
-		finalAmount = decimal.Zero // Descuento no puede generar monto negativo
+	// HITO TAX-IVA: Desglose por línea y total del documento
+	// final_amount = bruto (con IVA incluido: total - descuento; sin incluir: neto + IVA)
+	// El descuento no puede generar monto negativo (CalculateDocumentTax lo limita al total)
+	if !priceMode.IsValid() {
+		return nil, ErrInvalidTaxPriceMode
+	}
+	taxableLines := make([]TaxableLine, len(items))
+	for i, item := range items {
+		if !item.TaxRateCode.IsValid() {
+			return nil, ErrInvalidTaxRate
+		}
+		taxableLines[i] = TaxableLine{Amount: item.Subtotal, RateCode: item.TaxRateCode}
 	}
+	lineTaxes, taxSummary := CalculateDocumentTax(taxableLines, discountAmount, priceMode)
+	for i := range items {
+		items[i].applyTax(lineTaxes[i])
+	}
+	finalAmount := taxSummary.GrossAmount
 
-	// HITO: Validar amount_paid >= final_amount
+	// HITO POS-SPLIT: Sumar pagos (total y porción no-efectivo)
+	amountPaid := decimal.Zero
+	nonCashPaid := decimal.Zero
+	for _, payment := range payments {
+		if payment.PaymentMethodID == uuid.Nil {
+			return nil, ErrPaymentMethodRequired
+		}
+		if payment.Amount.LessThanOrEqual(decimal.Zero) {
+			return nil, ErrInvalidPaymentAmount
+		}
+		amountPaid = amountPaid.Add(payment.Amount)
+		if !payment.IsCash {
+			nonCashPaid = nonCashPaid.Add(payment.Amount)
+		}
+	}
+
+	// Validar que los pagos cubran final_amount
 	if amountPaid.LessThan(finalAmount) {
 		return nil, ErrInsufficientPayment
 	}
 
-	// HITO: Calcular change (vuelto)
+	// Tarjetas / transferencias no pueden generar vuelto
+	if nonCashPaid.GreaterThan(finalAmount) {
+		return nil, ErrNonCashOverpayment
+	}
+
+	// Calcular change (vuelto) y asignarlo a los pagos en efectivo (del último al primero)
 	change := amountPaid.Sub(finalAmount)
+	remaining := change
+	for i := len(payments) - 1; i >= 0 && remaining.GreaterThan(decimal.Zero); i-- {
+		if !payments[i].IsCash {
+			continue
+		}
+		assigned := decimal.Min(payments[i].Amount, remaining)
+		payments[i].ChangeAmount = assigned
+		remaining = remaining.Sub(assigned)
+	}
 
 	posSaleID := uuid.New()
 
-	// Asignar pos_sale_id a todos los items
+	// Asignar pos_sale_id a todos los items y pagos
 	for i := range items {
 		items[i].PosSaleID = posSaleID
 	}
+	for i := range payments {
+		payments[i].PosSaleID = posSaleID
+	}
 
 	return &PosSale{
 		ID:              posSaleID,
 		TenantID:        tenantID,
 		CustomerID:      customerID,
-		PaymentMethodID: paymentMethodID,
+		PaymentMethodID: payments[0].PaymentMethodID,
 		TotalAmount:     totalAmount,
 		DiscountAmount:  discountAmount,
 		FinalAmount:     finalAmount,
@@ -96,6 +179,12 @@ func NewPosSale(
 		Currency:        currency,
 		CreatedAt:       time.Now(),
 		Items:           items,
+		Payments:        payments,
+		Status:          PosSaleStatusCompleted,
+		RefundedAmount:  decimal.Zero,
+		PriceMode:       priceMode,
+		NetAmount:       taxSummary.NetAmount,
+		TaxAmount:       taxSummary.TaxAmount,
 	}, nil
 }
 
@@ -103,3 +192,197 @@ func NewPosSale(
 func (ps *PosSale) TotalItems() int {
 	return len(ps.Items)
 }
+
+// TaxSummary retorna los totales impositivos agrupados por alícuota (HITO TAX-IVA)
+func (ps *PosSale) TaxSummary() TaxSummary {
+	lines := make([]TaxBreakdown, 0, len(ps.Items))
+	for _, item := range ps.Items {
+		lines = append(lines, item.TaxBreakdown())
+	}
+	return SummarizeTax(lines, ps.PriceMode)
+}
+
+// AssignCashSession vincula la venta a la sesión de caja abierta (HITO POS-CASH)
+func (ps *PosSale) AssignCashSession(session *CashSession) error {
+	if session == nil || !session.IsOpen() {
+		return ErrNoOpenCashSession
+	}
+	pointOfSaleID := session.PointOfSaleID
+	cashSessionID := session.ID
+	ps.PointOfSaleID = &pointOfSaleID
+	ps.CashSessionID = &cashSessionID
+	return nil
+}
+
+// AssignPosNumber asigna el número de ticket (HITO POS-NUMBER)
+func (ps *PosSale) AssignPosNumber(pointOfSaleNumber, posNumber int) {
+	ps.PointOfSaleNumber = pointOfSaleNumber
+	ps.PosNumber = posNumber
+}
+
+// TicketNumber retorna el número de ticket formateado (ej: 0001-00001234)
+// Ventas previas a la numeración no tienen número: se usa el UUID
+func (ps *PosSale) TicketNumber() string {
+	if ps.PosNumber == 0 {
+		return ps.ID.String()
+	}
+	return fmt.Sprintf("%04d-%08d", ps.PointOfSaleNumber, ps.PosNumber)
+}
+
+// IsFullyRefunded indica si la venta ya fue anulada o devuelta por completo
+func (ps *PosSale) IsFullyRefunded() bool {
+	return ps.Status == PosSaleStatusVoided || ps.Status == PosSaleStatusRefunded
+}
+
+// Void anula la venta completa devolviendo todas las líneas pendientes (HITO POS-REFUND)
+// refundMethodID nil = se devuelve por el método principal de la venta
+func (ps *PosSale) Void(reason, refundedBy string, refundMethodID *uuid.UUID) (*PosSaleRefund, error) {
+	if ps.IsFullyRefunded() {
+		return nil, ErrPosSaleAlreadyVoided
+	}
+
+	var pending []uuid.UUID
+	for _, item := range ps.Items {
+		if !item.Refunded {
+			pending = append(pending, item.ID)
+		}
+	}
+
+	return ps.refund(PosSaleRefundTypeVoid, pending, reason, refundedBy, refundMethodID)
+}
+
+// Refund devuelve las líneas indicadas (HITO POS-REFUND)
+// Las líneas se devuelven completas: la compensación de stock revierte el stock_entry entero,
+// así que una cantidad menor a la vendida se rechaza con ErrRefundPartialQuantity
+func (ps *PosSale) Refund(lines []PosSaleRefundLine, reason, refundedBy string, refundMethodID *uuid.UUID) (*PosSaleRefund, error) {
+	if ps.IsFullyRefunded() {
+		return nil, ErrPosSaleAlreadyVoided
+	}
+
+	itemIDs := make([]uuid.UUID, 0, len(lines))
+	for _, line := range lines {
+		if line.Quantity != 0 {
+			for _, item := range ps.Items {
+				if item.ID == line.ItemID && item.Quantity != line.Quantity {
+					return nil, ErrRefundPartialQuantity
+				}
+			}
+		}
+		itemIDs = append(itemIDs, line.ItemID)
+	}
+	return ps.refund(PosSaleRefundTypeRefund, itemIDs, reason, refundedBy, refundMethodID)
+}
+
+// refund arma el documento de devolución y actualiza estado / monto devuelto de la venta
+// El descuento de la venta se prorratea por línea; si se devuelve todo lo pendiente,
+// el monto es exactamente lo que resta cobrar (sin diferencias de redondeo)
+func (ps *PosSale) refund(
+	refundType PosSaleRefundType,
+	itemIDs []uuid.UUID,
+	reason, refundedBy string,
+	refundMethodID *uuid.UUID,
+) (*PosSaleRefund, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrRefundReasonRequired
+	}
+	if len(itemIDs) == 0 {
+		return nil, ErrRefundMustHaveItems
+	}
+
+	itemsByID := make(map[uuid.UUID]int, len(ps.Items))
+	pendingCount := 0
+	for i, item := range ps.Items {
+		itemsByID[item.ID] = i
+		if !item.Refunded {
+			pendingCount++
+		}
+	}
+
+	refundID := uuid.New()
+	refundItems := make([]PosSaleRefundItem, 0, len(itemIDs))
+	seen := make(map[uuid.UUID]bool, len(itemIDs))
+	refundAmount := decimal.Zero
+
+	for _, itemID := range itemIDs {
+		idx, ok := itemsByID[itemID]
+		if !ok {
+			return nil, ErrPosSaleItemNotFound
+		}
+		item := ps.Items[idx]
+		if item.Refunded || seen[itemID] {
+			return nil, ErrPosSaleItemAlreadyRefunded
+		}
+		seen[itemID] = true
+
+		lineAmount := ps.refundableAmount(item)
+		refundAmount = refundAmount.Add(lineAmount)
+
+		refundItems = append(refundItems, PosSaleRefundItem{
+			ID:            uuid.New(),
+			RefundID:      refundID,
+			PosSaleItemID: item.ID,
+			SKU:           item.SKU,
+			Quantity:      item.Quantity,
+			Subtotal:      item.Subtotal,
+			RefundAmount:  lineAmount,
+			StockEntryID:  item.StockEntryID,
+		})
+	}
+
+	remaining := ps.FinalAmount.Sub(ps.RefundedAmount)
+	fullyRefunded := len(refundItems) == pendingCount
+	if fullyRefunded || refundAmount.GreaterThan(remaining) {
+		refundAmount = remaining
+	}
+
+	paymentMethodID := ps.PaymentMethodID
+	if refundMethodID != nil && *refundMethodID != uuid.Nil {
+		paymentMethodID = *refundMethodID
+	}
+
+	// Aplicar cambios al aggregate
+	for _, refundItem := range refundItems {
+		ps.Items[itemsByID[refundItem.PosSaleItemID]].Refunded = true
+	}
+	ps.RefundedAmount = ps.RefundedAmount.Add(refundAmount)
+	switch {
+	case refundType == PosSaleRefundTypeVoid:
+		ps.Status = PosSaleStatusVoided
+	case fullyRefunded:
+		ps.Status = PosSaleStatusRefunded
+	default:
+		ps.Status = PosSaleStatusPartiallyRefunded
+	}
+
+	return &PosSaleRefund{
+		ID:              refundID,
+		TenantID:        ps.TenantID,
+		PosSaleID:       ps.ID,
+		Type:            refundType,
+		Reason:          reason,
+		RefundAmount:    refundAmount,
+		PaymentMethodID: paymentMethodID,
+		Currency:        ps.Currency,
+		RefundedBy:      refundedBy,
+		CreatedAt:       time.Now(),
+		Items:           refundItems,
+	}, nil
+}
+
+// refundableAmount monto efectivamente cobrado por una línea
+// HITO TAX-IVA - Las líneas con desglose guardan su bruto (descuento prorrateado + IVA)
+func (ps *PosSale) refundableAmount(item PosSaleItem) decimal.Decimal {
+	if item.TaxRateCode != "" {
+		return item.GrossAmount
+	}
+	return ps.proratedAmount(item.Subtotal)
+}
+
+// proratedAmount aplica a un subtotal la proporción final_amount / total_amount
+func (ps *PosSale) proratedAmount(subtotal decimal.Decimal) decimal.Decimal {
+	if ps.TotalAmount.IsZero() {
+		return decimal.Zero
+	}
+	return subtotal.Mul(ps.FinalAmount).Div(ps.TotalAmount).Round(2)
+}
@@ -16,6 +16,19 @@ type PosSaleItem struct {
 	UnitPrice    decimal.Decimal `json:"unit_price"`
 	Subtotal     decimal.Decimal `json:"subtotal"`
 	StockEntryID uuid.UUID       `json:"stock_entry_id"`
+	Refunded     bool            `json:"refunded"` // HITO POS-REFUND - Línea ya devuelta
+
+	// HITO POS-PRICE - Precio de catálogo y override autorizado
+	ListPrice         decimal.Decimal `json:"list_price"`
+	PriceOverridden   bool            `json:"price_overridden"`
+	PriceOverriddenBy string          `json:"price_overridden_by,omitempty"`
+
+	// HITO TAX-IVA - Desglose de la línea (con el descuento de la venta ya prorrateado)
+	TaxRateCode TaxRateCode     `json:"tax_rate_code"`
+	TaxRate     decimal.Decimal `json:"tax_rate"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	GrossAmount decimal.Decimal `json:"gross_amount"`
 }
 
 // NewPosSaleItem crea un nuevo item de venta POS
@@ -59,3 +72,34 @@ func NewPosSaleItem(
 		StockEntryID: stockEntryID,
 	}, nil
 }
+
+// RecordPricing registra el precio de catálogo de la línea (HITO POS-PRICE)
+// Si el precio cobrado difiere, la línea queda marcada con el operador que lo autorizó
+func (i *PosSaleItem) RecordPricing(listPrice decimal.Decimal, overriddenBy string) {
+	i.ListPrice = listPrice
+	i.PriceOverridden = !i.UnitPrice.Equal(listPrice)
+	i.PriceOverriddenBy = ""
+	if i.PriceOverridden {
+		i.PriceOverriddenBy = overriddenBy
+	}
+}
+
+// TaxBreakdown retorna el desglose impositivo de la línea (HITO TAX-IVA)
+func (i *PosSaleItem) TaxBreakdown() TaxBreakdown {
+	return TaxBreakdown{
+		RateCode:    i.TaxRateCode,
+		Rate:        i.TaxRate,
+		NetAmount:   i.NetAmount,
+		TaxAmount:   i.TaxAmount,
+		GrossAmount: i.GrossAmount,
+	}
+}
+
+// applyTax registra el desglose calculado para la línea
+func (i *PosSaleItem) applyTax(breakdown TaxBreakdown) {
+	i.TaxRateCode = breakdown.RateCode
+	i.TaxRate = breakdown.Rate
+	i.NetAmount = breakdown.NetAmount
+	i.TaxAmount = breakdown.TaxAmount
+	i.GrossAmount = breakdown.GrossAmount
+}
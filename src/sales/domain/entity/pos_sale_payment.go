@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PosSalePayment representa un pago dentro de una venta POS (Entity dentro del Aggregate)
+// HITO POS-SPLIT - Pagos combinados (ej: parte efectivo + parte débito)
+type PosSalePayment struct {
+	ID              uuid.UUID       `json:"id"`
+	PosSaleID       uuid.UUID       `json:"pos_sale_id"`
+	PaymentMethodID uuid.UUID       `json:"payment_method_id"`
+	Amount          decimal.Decimal `json:"amount"`              // Monto entregado con este método
+	Reference       *string         `json:"reference,omitempty"` // Cupón, nro. de operación, etc.
+	IsCash          bool            `json:"is_cash"`             // Solo efectivo genera vuelto
+	ChangeAmount    decimal.Decimal `json:"change_amount"`       // Vuelto entregado sobre este pago
+}
+
+// NewPosSalePayment crea un nuevo pago de venta POS
+func NewPosSalePayment(
+	paymentMethodID uuid.UUID,
+	amount decimal.Decimal,
+	reference *string,
+	isCash bool,
+) (*PosSalePayment, error) {
+	if paymentMethodID == uuid.Nil {
+		return nil, ErrPaymentMethodRequired
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, ErrInvalidPaymentAmount
+	}
+
+	return &PosSalePayment{
+		ID:              uuid.New(),
+		PaymentMethodID: paymentMethodID,
+		Amount:          amount,
+		Reference:       reference,
+		IsCash:          isCash,
+		ChangeAmount:    decimal.Zero,
+	}, nil
+}
+
+// NetAmount retorna el monto efectivamente aplicado a la venta (amount - vuelto)
+func (p PosSalePayment) NetAmount() decimal.Decimal {
+	return p.Amount.Sub(p.ChangeAmount)
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PosSaleRefundType indica si el documento anula la venta o devuelve líneas
+type PosSaleRefundType string
+
+const (
+	PosSaleRefundTypeVoid   PosSaleRefundType = "VOID"   // Anulación total
+	PosSaleRefundTypeRefund PosSaleRefundType = "REFUND" // Devolución por línea
+)
+
+// PosSaleRefund representa el documento de anulación / devolución de una venta POS
+// HITO POS-REFUND - Vinculado a la venta original, que no se modifica salvo su estado
+type PosSaleRefund struct {
+	ID              uuid.UUID           `json:"id"`
+	TenantID        uuid.UUID           `json:"tenant_id"`
+	PosSaleID       uuid.UUID           `json:"pos_sale_id"`
+	Type            PosSaleRefundType   `json:"refund_type"`
+	Reason          string              `json:"reason"`
+	RefundAmount    decimal.Decimal     `json:"refund_amount"`     // Dinero devuelto al cliente
+	PaymentMethodID uuid.UUID           `json:"payment_method_id"` // Método por el que se devuelve
+	Currency        string              `json:"currency"`
+	CashSessionID   *uuid.UUID          `json:"cash_session_id,omitempty"` // Caja desde la que se devuelve
+	RefundedBy      string              `json:"refunded_by,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	Items           []PosSaleRefundItem `json:"items"`
+}
+
+// PosSaleRefundLine línea a devolver pedida por el cliente
+// Quantity 0 = línea completa; cualquier otra cantidad debe ser la de la línea vendida
+type PosSaleRefundLine struct {
+	ItemID   uuid.UUID
+	Quantity int
+}
+
+// PosSaleRefundItem representa una línea devuelta (Entity dentro del documento)
+type PosSaleRefundItem struct {
+	ID               uuid.UUID       `json:"id"`
+	RefundID         uuid.UUID       `json:"refund_id"`
+	PosSaleItemID    uuid.UUID       `json:"pos_sale_item_id"`
+	SKU              string          `json:"sku"`
+	Quantity         int             `json:"quantity"`
+	Subtotal         decimal.Decimal `json:"subtotal"`      // Subtotal original de la línea
+	RefundAmount     decimal.Decimal `json:"refund_amount"` // Subtotal con descuento prorrateado
+	StockEntryID     uuid.UUID       `json:"stock_entry_id"`
+	StockCompensated bool            `json:"stock_compensated"`
+}
+
+// AssignCashSession registra la caja abierta desde la que se devuelve el dinero
+func (r *PosSaleRefund) AssignCashSession(session *CashSession) {
+	if session == nil || !session.IsOpen() {
+		return
+	}
+	cashSessionID := session.ID
+	r.CashSessionID = &cashSessionID
+}
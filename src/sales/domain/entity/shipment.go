@@ -0,0 +1,116 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShipmentLine unidades a despachar de una línea de la orden
+type ShipmentLine struct {
+	ItemID   string
+	Quantity int
+}
+
+// Shipment envío de una orden (HITO ORDER-FULFILLMENT)
+// Una orden puede despacharse en varios envíos; cada uno registra transportista,
+// número de seguimiento y unidades despachadas por línea
+type Shipment struct {
+	ID             uuid.UUID      `json:"id"`
+	TenantID       string         `json:"tenant_id"`
+	OrderID        string         `json:"order_id"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"tracking_number,omitempty"`
+	ShippedBy      string         `json:"shipped_by,omitempty"`
+	ShippedAt      time.Time      `json:"shipped_at"`
+	Items          []ShipmentItem `json:"items"`
+}
+
+// ShipmentItem unidades de una línea despachadas en el envío
+type ShipmentItem struct {
+	ID          uuid.UUID `json:"id"`
+	ShipmentID  uuid.UUID `json:"shipment_id"`
+	OrderItemID string    `json:"order_item_id"`
+	SKU         string    `json:"sku"`
+	Quantity    int       `json:"quantity"`
+}
+
+// Ship registra un envío de la orden (HITO ORDER-FULFILLMENT)
+// Sin líneas despacha todo lo pendiente. El primer envío de una orden PACKED la
+// pasa a SHIPPED; los siguientes (envío parcial) la dejan en SHIPPED
+func (o *Order) Ship(lines []ShipmentLine, carrier, trackingNumber, shippedBy string) (*Shipment, error) {
+	if o.Status != OrderStatusPacked && o.Status != OrderStatusShipped {
+		return nil, ErrOrderNotShippable
+	}
+	carrier = strings.TrimSpace(carrier)
+	if carrier == "" {
+		return nil, ErrShipmentCarrierRequired
+	}
+
+	itemsByID := make(map[string]int, len(o.Items))
+	for i, item := range o.Items {
+		itemsByID[item.ItemID] = i
+	}
+
+	// Cantidades por línea (una línea repetida en el request suma)
+	requested := make(map[int]int, len(o.Items))
+	var touched []int
+	if len(lines) == 0 {
+		for i, item := range o.Items {
+			if pending := item.PendingShipment(); pending > 0 {
+				requested[i] = pending
+				touched = append(touched, i)
+			}
+		}
+	}
+	for _, line := range lines {
+		idx, ok := itemsByID[line.ItemID]
+		if !ok {
+			return nil, ErrOrderItemNotFound
+		}
+		if line.Quantity <= 0 {
+			return nil, ErrInvalidShipmentQuantity
+		}
+		if _, seen := requested[idx]; !seen {
+			touched = append(touched, idx)
+		}
+		requested[idx] += line.Quantity
+		if requested[idx] > o.Items[idx].PendingShipment() {
+			return nil, ErrInvalidShipmentQuantity
+		}
+	}
+	if len(touched) == 0 {
+		return nil, ErrShipmentMustHaveItems
+	}
+
+	if o.Status == OrderStatusPacked {
+		if err := o.TransitionTo(OrderStatusShipped); err != nil {
+			return nil, err
+		}
+	}
+
+	shipment := &Shipment{
+		ID:             uuid.New(),
+		TenantID:       o.TenantID,
+		OrderID:        o.OrderID,
+		Carrier:        carrier,
+		TrackingNumber: strings.TrimSpace(trackingNumber),
+		ShippedBy:      shippedBy,
+		ShippedAt:      time.Now(),
+		Items:          make([]ShipmentItem, 0, len(touched)),
+	}
+	for _, idx := range touched {
+		item := &o.Items[idx]
+		item.ShippedQuantity += requested[idx]
+		shipment.Items = append(shipment.Items, ShipmentItem{
+			ID:          uuid.New(),
+			ShipmentID:  shipment.ID,
+			OrderItemID: item.ItemID,
+			SKU:         item.SKU,
+			Quantity:    requested[idx],
+		})
+	}
+
+	return shipment, nil
+}
@@ -0,0 +1,218 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockSagaType operación de negocio que descuenta stock ítem por ítem
+// HITO POS-REFUND - POS_REFUND no descuenta: revierte los stock_entry_id de las
+// líneas anuladas / devueltas (nace COMPENSATING, ver StockSagaService.BeginReversal)
+type StockSagaType string
+
+const (
+	StockSagaTypePosSale    StockSagaType = "POS_SALE"
+	StockSagaTypeSalesOrder StockSagaType = "SALES_ORDER"
+	StockSagaTypePosRefund  StockSagaType = "POS_REFUND"
+)
+
+// StockSagaStatus estado de una saga de stock
+// HITO STOCK-SAGA - STARTED → COMPLETED si la venta se persiste.
+// Si falla: STARTED → COMPENSATING → COMPENSATED; agotados los reintentos de
+// compensación queda ESCALATED (requiere intervención manual)
+type StockSagaStatus string
+
+const (
+	StockSagaStatusStarted      StockSagaStatus = "STARTED"
+	StockSagaStatusCompleted    StockSagaStatus = "COMPLETED"
+	StockSagaStatusCompensating StockSagaStatus = "COMPENSATING"
+	StockSagaStatusCompensated  StockSagaStatus = "COMPENSATED"
+	StockSagaStatusEscalated    StockSagaStatus = "ESCALATED"
+)
+
+// StockSagaStepStatus estado de un paso (un descuento de stock)
+type StockSagaStepStatus string
+
+const (
+	StockSagaStepApplied     StockSagaStepStatus = "APPLIED"
+	StockSagaStepCompensated StockSagaStepStatus = "COMPENSATED"
+)
+
+// StockSagaStepType operación de stock-service que aplicó el paso
+// HITO ORDER-RESERVATION - Un paso SALE se revierte con CompensateSale (stock_entry_id);
+// un paso RESERVATION con ReleaseStock (stock_entry_id = referencia de la reserva)
+type StockSagaStepType string
+
+const (
+	StockSagaStepSale        StockSagaStepType = "SALE"
+	StockSagaStepReservation StockSagaStepType = "RESERVATION"
+)
+
+const (
+	MaxStockSagaAttempts      = 8
+	stockSagaRetryBaseBackoff = 10 * time.Second
+	stockSagaRetryMaxBackoff  = 30 * time.Minute
+)
+
+// StockSagaStep descuento de stock aplicado por stock-service
+type StockSagaStep struct {
+	ID            uuid.UUID           `json:"id"`
+	SagaID        uuid.UUID           `json:"saga_id"`
+	Sequence      int                 `json:"sequence"`
+	StepType      StockSagaStepType   `json:"step_type"`
+	SKU           string              `json:"sku"`
+	Quantity      float64             `json:"quantity"`
+	StockEntryID  string              `json:"stock_entry_id"`
+	Status        StockSagaStepStatus `json:"status"`
+	LastError     string              `json:"last_error,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	CompensatedAt *time.Time          `json:"compensated_at,omitempty"`
+}
+
+// MarkCompensated registra la reversión del descuento en stock-service
+func (s *StockSagaStep) MarkCompensated() {
+	now := time.Now()
+	s.Status = StockSagaStepCompensated
+	s.LastError = ""
+	s.CompensatedAt = &now
+}
+
+// StockSaga registro de una operación multi-ítem contra stock-service
+// Cada ProcessSaleAtomic exitoso es un paso; si la venta no se completa, todos los
+// pasos aplicados se compensan (CompensateSale) hasta lograrlo o escalar
+type StockSaga struct {
+	ID                 uuid.UUID       `json:"id"`
+	TenantID           string          `json:"tenant_id"`
+	SagaType           StockSagaType   `json:"saga_type"`
+	Reference          string          `json:"reference"`
+	Status             StockSagaStatus `json:"status"`
+	CompensationReason string          `json:"compensation_reason,omitempty"`
+	Attempts           int             `json:"attempts"`
+	NextAttemptAt      time.Time       `json:"next_attempt_at"`
+	LastError          string          `json:"last_error,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	Steps              []StockSagaStep `json:"steps"`
+}
+
+// NewStockSaga inicia una saga antes del primer descuento de stock
+func NewStockSaga(tenantID string, sagaType StockSagaType, reference string) *StockSaga {
+	now := time.Now()
+	return &StockSaga{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		SagaType:      sagaType,
+		Reference:     reference,
+		Status:        StockSagaStatusStarted,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Steps:         []StockSagaStep{},
+	}
+}
+
+// AddStep registra un descuento de stock aplicado y retorna el paso creado
+func (s *StockSaga) AddStep(sku string, quantity float64, stockEntryID string) *StockSagaStep {
+	return s.addStep(StockSagaStepSale, sku, quantity, stockEntryID)
+}
+
+// AddReservationStep registra una reserva de stock aplicada y retorna el paso creado
+func (s *StockSaga) AddReservationStep(sku string, quantity float64, reference string) *StockSagaStep {
+	return s.addStep(StockSagaStepReservation, sku, quantity, reference)
+}
+
+func (s *StockSaga) addStep(stepType StockSagaStepType, sku string, quantity float64, stockEntryID string) *StockSagaStep {
+	now := time.Now()
+	s.Steps = append(s.Steps, StockSagaStep{
+		ID:           uuid.New(),
+		SagaID:       s.ID,
+		Sequence:     len(s.Steps) + 1,
+		StepType:     stepType,
+		SKU:          sku,
+		Quantity:     quantity,
+		StockEntryID: stockEntryID,
+		Status:       StockSagaStepApplied,
+		CreatedAt:    now,
+	})
+	s.UpdatedAt = now
+	return &s.Steps[len(s.Steps)-1]
+}
+
+// Complete cierra la saga: la venta se persistió y el stock queda descontado
+func (s *StockSaga) Complete() error {
+	if s.Status != StockSagaStatusStarted {
+		return ErrInvalidStockSagaTransition
+	}
+	s.Status = StockSagaStatusCompleted
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// BeginCompensation marca la saga para revertir los pasos aplicados
+// Acepta COMPLETED: Complete corre dentro de la tx de la venta y, si esa tx no
+// confirma, la saga quedó COMPLETED solo en memoria (en la base sigue STARTED)
+func (s *StockSaga) BeginCompensation(reason string) error {
+	if s.Status != StockSagaStatusStarted && s.Status != StockSagaStatusCompleted {
+		return ErrInvalidStockSagaTransition
+	}
+	now := time.Now()
+	s.Status = StockSagaStatusCompensating
+	s.CompensationReason = reason
+	s.NextAttemptAt = now
+	s.UpdatedAt = now
+	return nil
+}
+
+// DeferNextAttempt corre el próximo intento de compensación (el worker no toma la saga antes)
+func (s *StockSaga) DeferNextAttempt(delay time.Duration) {
+	s.NextAttemptAt = time.Now().Add(delay)
+}
+
+// PendingSteps cantidad de pasos aplicados aún sin compensar
+func (s *StockSaga) PendingSteps() int {
+	pending := 0
+	for _, step := range s.Steps {
+		if step.Status == StockSagaStepApplied {
+			pending++
+		}
+	}
+	return pending
+}
+
+// FinishCompensationAttempt registra el resultado de un intento de compensación
+// Sin pasos pendientes queda COMPENSATED. Si no, reintento con backoff exponencial;
+// agotados los intentos queda ESCALATED
+func (s *StockSaga) FinishCompensationAttempt(cause error) error {
+	if s.Status != StockSagaStatusCompensating {
+		return ErrInvalidStockSagaTransition
+	}
+	now := time.Now()
+	s.Attempts++
+	s.UpdatedAt = now
+
+	pending := s.PendingSteps()
+	if pending == 0 {
+		s.Status = StockSagaStatusCompensated
+		s.LastError = ""
+		return nil
+	}
+
+	if cause == nil {
+		cause = fmt.Errorf("%d steps pending compensation", pending)
+	}
+	s.LastError = cause.Error()
+	if s.Attempts >= MaxStockSagaAttempts {
+		s.Status = StockSagaStatusEscalated
+		s.LastError = fmt.Sprintf("compensation failed after %d attempts (%d steps pending): %s", s.Attempts, pending, cause.Error())
+		return nil
+	}
+
+	backoff := stockSagaRetryBaseBackoff << (s.Attempts - 1)
+	if backoff > stockSagaRetryMaxBackoff || backoff <= 0 {
+		backoff = stockSagaRetryMaxBackoff
+	}
+	s.NextAttemptAt = now.Add(backoff)
+	return nil
+}
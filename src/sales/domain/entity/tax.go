@@ -0,0 +1,317 @@
+package entity
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TaxRateCode alícuota de IVA aplicable a una línea
+// HITO TAX-IVA - Alícuotas vigentes: 21% (general), 10,5% (reducida), 27% (servicios) y exento
+type TaxRateCode string
+
+const (
+	TaxRateIVA21  TaxRateCode = "IVA_21"
+	TaxRateIVA105 TaxRateCode = "IVA_10_5"
+	TaxRateIVA27  TaxRateCode = "IVA_27"
+	TaxRateExempt TaxRateCode = "EXENTO"
+)
+
+// IsValid indica si la alícuota es conocida
+func (c TaxRateCode) IsValid() bool {
+	switch c {
+	case TaxRateIVA21, TaxRateIVA105, TaxRateIVA27, TaxRateExempt:
+		return true
+	}
+	return false
+}
+
+// Rate retorna el porcentaje de la alícuota (ej: 21, 10.5, 0)
+func (c TaxRateCode) Rate() decimal.Decimal {
+	switch c {
+	case TaxRateIVA21:
+		return decimal.NewFromInt(21)
+	case TaxRateIVA105:
+		return decimal.NewFromFloat(10.5)
+	case TaxRateIVA27:
+		return decimal.NewFromInt(27)
+	}
+	return decimal.Zero
+}
+
+// TaxPriceMode indica si los precios de venta del tenant incluyen IVA
+type TaxPriceMode string
+
+const (
+	TaxPriceIncluded TaxPriceMode = "INCLUDED" // Precio final: el IVA se discrimina desde el precio
+	TaxPriceExcluded TaxPriceMode = "EXCLUDED" // Precio neto: el IVA se suma al precio
+)
+
+// IsValid indica si el modo es conocido
+func (m TaxPriceMode) IsValid() bool {
+	return m == TaxPriceIncluded || m == TaxPriceExcluded
+}
+
+// TaxRuleScope a qué se asigna una alícuota (SKU puntual o categoría PIM)
+type TaxRuleScope string
+
+const (
+	TaxRuleScopeSKU      TaxRuleScope = "SKU"
+	TaxRuleScopeCategory TaxRuleScope = "CATEGORY"
+)
+
+// IsValid indica si el alcance es conocido
+func (s TaxRuleScope) IsValid() bool {
+	return s == TaxRuleScopeSKU || s == TaxRuleScopeCategory
+}
+
+// TaxSettings configuración impositiva del tenant
+// HITO TAX-IVA - Sin configuración: precios con IVA incluido al 21% (comportamiento previo)
+type TaxSettings struct {
+	TenantID        uuid.UUID    `json:"tenant_id"`
+	PriceMode       TaxPriceMode `json:"price_mode"`
+	DefaultRateCode TaxRateCode  `json:"default_rate_code"` // Alícuota de SKUs sin regla
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// DefaultTaxSettings configuración de un tenant que nunca cargó la suya
+func DefaultTaxSettings(tenantID uuid.UUID) *TaxSettings {
+	return &TaxSettings{
+		TenantID:        tenantID,
+		PriceMode:       TaxPriceIncluded,
+		DefaultRateCode: TaxRateIVA21,
+	}
+}
+
+// NewTaxSettings crea la configuración impositiva validando modo y alícuota
+func NewTaxSettings(tenantID uuid.UUID, priceMode TaxPriceMode, defaultRateCode TaxRateCode) (*TaxSettings, error) {
+	if tenantID == uuid.Nil {
+		return nil, ErrTenantIDRequired
+	}
+	if !priceMode.IsValid() {
+		return nil, ErrInvalidTaxPriceMode
+	}
+	if !defaultRateCode.IsValid() {
+		return nil, ErrInvalidTaxRate
+	}
+
+	return &TaxSettings{
+		TenantID:        tenantID,
+		PriceMode:       priceMode,
+		DefaultRateCode: defaultRateCode,
+		UpdatedAt:       time.Now(),
+	}, nil
+}
+
+// TaxRule asigna una alícuota a un SKU o a una categoría de producto
+type TaxRule struct {
+	ID         uuid.UUID    `json:"id"`
+	TenantID   uuid.UUID    `json:"tenant_id"`
+	Scope      TaxRuleScope `json:"scope"`
+	ScopeValue string       `json:"scope_value"` // SKU o category_id del snapshot PIM
+	RateCode   TaxRateCode  `json:"rate_code"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// NewTaxRule crea una regla de alícuota validando alcance y código
+func NewTaxRule(tenantID uuid.UUID, scope TaxRuleScope, scopeValue string, rateCode TaxRateCode) (*TaxRule, error) {
+	if tenantID == uuid.Nil {
+		return nil, ErrTenantIDRequired
+	}
+	if !scope.IsValid() {
+		return nil, ErrInvalidTaxRuleScope
+	}
+	scopeValue = strings.TrimSpace(scopeValue)
+	if scopeValue == "" {
+		return nil, ErrTaxRuleValueRequired
+	}
+	if !rateCode.IsValid() {
+		return nil, ErrInvalidTaxRate
+	}
+
+	now := time.Now()
+	return &TaxRule{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		Scope:      scope,
+		ScopeValue: scopeValue,
+		RateCode:   rateCode,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// TaxProfile configuración + reglas del tenant listas para resolver alícuotas
+// Prioridad: regla por SKU > regla por categoría > alícuota por defecto
+type TaxProfile struct {
+	Settings   TaxSettings
+	bySKU      map[string]TaxRateCode
+	byCategory map[string]TaxRateCode
+}
+
+// NewTaxProfile arma el perfil impositivo a partir de la configuración y las reglas
+func NewTaxProfile(settings *TaxSettings, rules []*TaxRule) *TaxProfile {
+	profile := &TaxProfile{
+		Settings:   *settings,
+		bySKU:      make(map[string]TaxRateCode),
+		byCategory: make(map[string]TaxRateCode),
+	}
+	for _, rule := range rules {
+		switch rule.Scope {
+		case TaxRuleScopeSKU:
+			profile.bySKU[rule.ScopeValue] = rule.RateCode
+		case TaxRuleScopeCategory:
+			profile.byCategory[rule.ScopeValue] = rule.RateCode
+		}
+	}
+	return profile
+}
+
+// NeedsCategory indica si la alícuota del SKU depende de su categoría
+// (no tiene regla propia y el tenant definió reglas por categoría)
+func (p *TaxProfile) NeedsCategory(sku string) bool {
+	_, ok := p.bySKU[sku]
+	return !ok && len(p.byCategory) > 0
+}
+
+// ResolveRate retorna la alícuota de un SKU (categoryID vacío = sin categoría conocida)
+func (p *TaxProfile) ResolveRate(sku, categoryID string) TaxRateCode {
+	if code, ok := p.bySKU[sku]; ok {
+		return code
+	}
+	if categoryID != "" {
+		if code, ok := p.byCategory[categoryID]; ok {
+			return code
+		}
+	}
+	return p.Settings.DefaultRateCode
+}
+
+// TaxBreakdown desglose neto / IVA / bruto de una línea (o de una alícuota del documento)
+type TaxBreakdown struct {
+	RateCode    TaxRateCode     `json:"rate_code"`
+	Rate        decimal.Decimal `json:"rate"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	GrossAmount decimal.Decimal `json:"gross_amount"`
+}
+
+// CalculateTax calcula el desglose de un importe según la alícuota y el modo de precios
+// INCLUDED: el importe es bruto (neto = bruto / (1 + alícuota)). EXCLUDED: el importe es neto
+func CalculateTax(amount decimal.Decimal, rateCode TaxRateCode, priceMode TaxPriceMode) TaxBreakdown {
+	rate := rateCode.Rate()
+	factor := rate.Div(decimal.NewFromInt(100))
+	amount = amount.Round(2)
+
+	breakdown := TaxBreakdown{RateCode: rateCode, Rate: rate}
+	if priceMode == TaxPriceExcluded {
+		breakdown.NetAmount = amount
+		breakdown.TaxAmount = amount.Mul(factor).Round(2)
+		breakdown.GrossAmount = amount.Add(breakdown.TaxAmount)
+		return breakdown
+	}
+
+	breakdown.GrossAmount = amount
+	breakdown.NetAmount = amount.Div(decimal.NewFromInt(1).Add(factor)).Round(2)
+	breakdown.TaxAmount = amount.Sub(breakdown.NetAmount)
+	return breakdown
+}
+
+// TaxSummary totales impositivos de un documento (suma de líneas, agrupado por alícuota)
+type TaxSummary struct {
+	PriceMode   TaxPriceMode    `json:"price_mode"`
+	NetAmount   decimal.Decimal `json:"net_amount"`
+	TaxAmount   decimal.Decimal `json:"tax_amount"`
+	GrossAmount decimal.Decimal `json:"gross_amount"`
+	ByRate      []TaxBreakdown  `json:"by_rate"`
+}
+
+// TaxableLine importe gravado de una línea antes del cálculo
+type TaxableLine struct {
+	Amount   decimal.Decimal
+	RateCode TaxRateCode
+}
+
+// CalculateDocumentTax calcula el desglose de cada línea y el total del documento
+// El descuento del documento se prorratea entre las líneas (la última absorbe el redondeo)
+// y el impuesto se calcula sobre el importe ya descontado
+func CalculateDocumentTax(lines []TaxableLine, discount decimal.Decimal, priceMode TaxPriceMode) ([]TaxBreakdown, TaxSummary) {
+	total := decimal.Zero
+	for _, line := range lines {
+		total = total.Add(line.Amount)
+	}
+	if discount.GreaterThan(total) {
+		discount = total
+	}
+
+	breakdowns := make([]TaxBreakdown, len(lines))
+	allocated := decimal.Zero
+	for i, line := range lines {
+		share := decimal.Zero
+		if discount.GreaterThan(decimal.Zero) {
+			share = discount.Mul(line.Amount).Div(total).Round(2)
+			if i == len(lines)-1 {
+				share = discount.Sub(allocated)
+			}
+			allocated = allocated.Add(share)
+		}
+		breakdowns[i] = CalculateTax(line.Amount.Sub(share), line.RateCode, priceMode)
+	}
+
+	return breakdowns, SummarizeTax(breakdowns, priceMode)
+}
+
+// SummarizeTax suma desgloses de línea por alícuota (orden de aparición)
+func SummarizeTax(lines []TaxBreakdown, priceMode TaxPriceMode) TaxSummary {
+	summary := TaxSummary{
+		PriceMode:   priceMode,
+		NetAmount:   decimal.Zero,
+		TaxAmount:   decimal.Zero,
+		GrossAmount: decimal.Zero,
+		ByRate:      make([]TaxBreakdown, 0),
+	}
+
+	index := make(map[TaxRateCode]int)
+	for _, line := range lines {
+		summary.NetAmount = summary.NetAmount.Add(line.NetAmount)
+		summary.TaxAmount = summary.TaxAmount.Add(line.TaxAmount)
+		summary.GrossAmount = summary.GrossAmount.Add(line.GrossAmount)
+
+		i, ok := index[line.RateCode]
+		if !ok {
+			i = len(summary.ByRate)
+			index[line.RateCode] = i
+			summary.ByRate = append(summary.ByRate, TaxBreakdown{
+				RateCode:    line.RateCode,
+				Rate:        line.Rate,
+				NetAmount:   decimal.Zero,
+				TaxAmount:   decimal.Zero,
+				GrossAmount: decimal.Zero,
+			})
+		}
+		summary.ByRate[i].NetAmount = summary.ByRate[i].NetAmount.Add(line.NetAmount)
+		summary.ByRate[i].TaxAmount = summary.ByRate[i].TaxAmount.Add(line.TaxAmount)
+		summary.ByRate[i].GrossAmount = summary.ByRate[i].GrossAmount.Add(line.GrossAmount)
+	}
+
+	return summary
+}
+
+// CategoryIDFromSnapshot extrae category_id del snapshot de producto de PIM
+// Snapshot vacío o ilegible = sin categoría (aplica la regla por SKU o la alícuota por defecto)
+func CategoryIDFromSnapshot(productSnapshot json.RawMessage) string {
+	if len(productSnapshot) == 0 {
+		return ""
+	}
+	var product struct {
+		CategoryID string `json:"category_id"`
+	}
+	if err := json.Unmarshal(productSnapshot, &product); err != nil {
+		return ""
+	}
+	return product.CategoryID
+}
@@ -0,0 +1,33 @@
+package port
+
+import (
+	"context"
+	"sales/src/sales/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// CashSessionRepository define el contrato para persistir sesiones de caja
+// HITO POS-CASH - Apertura / cierre de caja con arqueo
+type CashSessionRepository interface {
+	// Create persiste una nueva sesión abierta
+	// Retorna entity.ErrCashSessionAlreadyOpen si ya hay una abierta para el punto de venta
+	Create(ctx context.Context, session *entity.CashSession) error
+
+	// FindByID retorna una sesión (con su arqueo si está cerrada)
+	FindByID(ctx context.Context, tenantID, sessionID uuid.UUID) (*entity.CashSession, error)
+
+	// FindOpenByPointOfSale retorna la sesión abierta de un punto de venta
+	// Retorna entity.ErrNoOpenCashSession si no hay ninguna
+	FindOpenByPointOfSale(ctx context.Context, tenantID, pointOfSaleID uuid.UUID) (*entity.CashSession, error)
+
+	// FindByIDForUpdate retorna la sesión bloqueando su fila hasta el fin de la transacción
+	// del contexto (cierre de caja: nadie más vende ni devuelve en ella mientras se arquea)
+	FindByIDForUpdate(ctx context.Context, tenantID, sessionID uuid.UUID) (*entity.CashSession, error)
+
+	// Close persiste el cierre de la sesión y sus líneas de arqueo
+	Close(ctx context.Context, session *entity.CashSession) error
+
+	// SalesTotalsByPaymentMethod agrega las pos_sales de la sesión por método de pago
+	SalesTotalsByPaymentMethod(ctx context.Context, sessionID uuid.UUID) ([]entity.CashSessionMethodTotal, error)
+}
@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+
+	"sales/src/sales/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// CreditNoteRepository define el contrato para persistir notas de crédito
+// HITO CREDIT-NOTE - Se suma a la transacción del contexto (cancelación / devolución + nota)
+type CreditNoteRepository interface {
+	// Create persiste la nota de crédito con sus líneas
+	Create(ctx context.Context, note *entity.CreditNote) error
+
+	// ListByInvoice retorna las notas de crédito emitidas contra una factura
+	ListByInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) ([]*entity.CreditNote, error)
+}
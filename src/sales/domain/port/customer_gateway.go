@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+
+	"sales/src/sales/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// CustomerGateway acceso a customer-service (HITO CUSTOMER)
+// Resuelve el comprador de una orden o venta POS para guardarlo como snapshot
+//
+// Retorna entity.ErrCustomerNotFound si el cliente no existe en el tenant; si
+// customer-service no responde el error envuelve entity.ErrDependencyUnavailable
+type CustomerGateway interface {
+	// GetCustomerSnapshot obtiene el comprador tal como está hoy en customer-service
+	GetCustomerSnapshot(ctx context.Context, tenantID, authToken string, customerID uuid.UUID) (*entity.CustomerSnapshot, error)
+}
@@ -0,0 +1,52 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"sales/src/sales/domain/entity"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FiscalAuthorizationRequest datos del comprobante enviados al fisco
+type FiscalAuthorizationRequest struct {
+	Reference            uuid.UUID // ID del comprobante (clave de idempotencia)
+	IssuerCUIT           string
+	PointOfSaleNumber    int
+	InvoiceType          entity.InvoiceType
+	InvoiceNumber        int // Último autorizado + 1
+	IssueDate            time.Time
+	CustomerTaxCondition entity.TaxCondition
+	CustomerTaxID        string
+	Currency             string
+	NetAmount            decimal.Decimal
+	TaxAmount            decimal.Decimal
+	TotalAmount          decimal.Decimal
+}
+
+// FiscalAuthorizationResult respuesta del fisco
+// Approved=false es un rechazo definitivo (datos inválidos), no se reintenta
+type FiscalAuthorizationResult struct {
+	Approved        bool
+	InvoiceNumber   int
+	CAE             string
+	CAEExpiresAt    time.Time
+	RejectionReason string
+}
+
+// FiscalAuthority define el contrato con la autoridad fiscal (ej: AFIP WSFE)
+// HITO FISCAL - Puerto enchufable; infrastructure/fiscal provee un fake local
+//
+// Cualquier error retornado se considera transitorio (timeout, servicio caído,
+// número fuera de secuencia por concurrencia) y el comprobante se reintenta.
+type FiscalAuthority interface {
+	// LastAuthorizedNumber retorna el último número autorizado para emisor + punto de venta + letra
+	LastAuthorizedNumber(ctx context.Context, issuerCUIT string, pointOfSaleNumber int, invoiceType entity.InvoiceType) (int, error)
+
+	// Authorize solicita el CAE de un comprobante
+	// Debe ser idempotente por Reference: reenviar un comprobante ya autorizado
+	// retorna la autorización original (reintentos tras un corte a mitad de la llamada)
+	Authorize(ctx context.Context, req FiscalAuthorizationRequest) (*FiscalAuthorizationResult, error)
+}
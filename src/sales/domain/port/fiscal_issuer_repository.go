@@ -0,0 +1,20 @@
+package port
+
+import (
+	"context"
+
+	"sales/src/sales/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// FiscalIssuerRepository define el contrato para persistir los datos fiscales del tenant
+// HITO FISCAL - Emisor de comprobantes
+type FiscalIssuerRepository interface {
+	// FindByTenant retorna el emisor del tenant
+	// Retorna entity.ErrFiscalIssuerNotConfigured si no fue cargado
+	FindByTenant(ctx context.Context, tenantID uuid.UUID) (*entity.FiscalIssuer, error)
+
+	// Save crea o reemplaza los datos fiscales del tenant
+	Save(ctx context.Context, issuer *entity.FiscalIssuer) error
+}
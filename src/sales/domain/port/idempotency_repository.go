@@ -0,0 +1,25 @@
+package port
+
+import (
+	"context"
+
+	"sales/src/sales/domain/entity"
+)
+
+// IdempotencyRepository define el contrato para persistir claves de idempotencia
+// HITO IDEMPOTENCY - Clave única por tenant (idempotency_keys)
+type IdempotencyRepository interface {
+	// Acquire inserta la clave IN_PROGRESS. También la toma si la existente venció
+	// (expires_at) o quedó IN_PROGRESS con el lock vencido (proceso caído)
+	// Retorna false sin error si otra clave vigente la tiene
+	Acquire(ctx context.Context, record *entity.IdempotencyRecord) (bool, error)
+
+	// Find retorna la clave vigente del tenant (nil si no existe)
+	Find(ctx context.Context, tenantID, key string) (*entity.IdempotencyRecord, error)
+
+	// Complete guarda la respuesta final de la clave (solo si record sigue siendo el dueño)
+	Complete(ctx context.Context, record *entity.IdempotencyRecord) error
+
+	// Release borra una clave IN_PROGRESS (el request falló y se puede reintentar)
+	Release(ctx context.Context, record *entity.IdempotencyRecord) error
+}
@@ -0,0 +1,44 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"sales/src/sales/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// InvoiceRepository define el contrato para persistir comprobantes electrónicos
+// HITO FISCAL - Cada cambio de estado se replica en fiscal_status / invoice_id de la venta
+type InvoiceRepository interface {
+	// FindSource carga la venta a facturar (sales_orders o pos_sales)
+	// Retorna entity.ErrInvoiceSourceNotFound si no existe para el tenant
+	FindSource(ctx context.Context, tenantID uuid.UUID, sourceType entity.InvoiceSourceType, sourceID uuid.UUID) (*entity.InvoiceSource, error)
+
+	// Create persiste un comprobante PENDING y lo vincula a la venta
+	// Retorna entity.ErrInvoiceAlreadyExists si la venta ya tiene comprobante
+	Create(ctx context.Context, invoice *entity.Invoice) error
+
+	// FindByID retorna un comprobante del tenant
+	FindByID(ctx context.Context, tenantID, invoiceID uuid.UUID) (*entity.Invoice, error)
+
+	// FindBySource retorna el comprobante de una venta (se suma a la tx del contexto)
+	// Retorna entity.ErrInvoiceNotFound si la venta no fue facturada
+	FindBySource(ctx context.Context, tenantID uuid.UUID, sourceType entity.InvoiceSourceType, sourceID uuid.UUID) (*entity.Invoice, error)
+
+	// Claim pasa a PROCESSING un comprobante PENDING puntual
+	// Retorna nil sin error si ya no está PENDING (lo tomó otro proceso)
+	Claim(ctx context.Context, invoiceID uuid.UUID) (*entity.Invoice, error)
+
+	// ClaimDue pasa a PROCESSING hasta limit comprobantes PENDING cuyo reintento venció
+	// Usa SKIP LOCKED: varias réplicas del worker no toman el mismo comprobante
+	ClaimDue(ctx context.Context, limit int) ([]*entity.Invoice, error)
+
+	// Update persiste el estado del comprobante y lo replica en la venta
+	Update(ctx context.Context, invoice *entity.Invoice) error
+
+	// ReleaseStale devuelve a PENDING los comprobantes PROCESSING sin cambios hace más de olderThan
+	// (proceso caído a mitad de la autorización)
+	ReleaseStale(ctx context.Context, olderThan time.Duration) (int64, error)
+}
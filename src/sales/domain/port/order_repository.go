@@ -3,14 +3,58 @@ package port
 import (
 	"context"
 	"sales/src/sales/domain/entity"
+	"sales/src/shared/domain/criteria"
+	"time"
 )
 
 // OrderRepository define los métodos para persistir Orders
 type OrderRepository interface {
 	Save(ctx context.Context, order *entity.Order) error
 	FindByID(ctx context.Context, orderID, tenantID string) (*entity.Order, error)
-	List(ctx context.Context, tenantID string, page, pageSize int) ([]*entity.Order, int, error)
+	// SearchByCriteria / CountByCriteria listan órdenes con filtros, orden y paginación
+	// HITO ORDER-SEARCH - Campos del criteria: tenant_id, status, order_number, created_at,
+	// total_amount, sku, customer_id, customer_document (otro campo es un error)
+	criteria.CriteriaRepository[entity.Order]
 	Confirm(ctx context.Context, orderID, tenantID string) error
-	Cancel(ctx context.Context, orderID, tenantID string) error
+	Cancel(ctx context.Context, order *entity.Order, from entity.OrderStatus) error
 	UpdateOrderNumber(ctx context.Context, orderID, tenantID string, orderNumber int) error
+
+	// CancelLines persiste una cancelación parcial de líneas (HITO ORDER-CANCEL)
+	CancelLines(ctx context.Context, order *entity.Order, cancellation *entity.OrderLineCancellation) error
+
+	// HITO ORDER-RESERVATION - Reservas de stock con vencimiento
+
+	// UpdateItemReservation persiste el estado de la reserva de una línea (se suma a la tx del contexto)
+	UpdateItemReservation(ctx context.Context, itemID string, status entity.ReservationStatus) error
+
+	// TransitionItemReservation pasa la reserva de una línea de from a to solo si sigue en from.
+	// Retorna false si otro proceso ya la movió (se suma a la tx del contexto)
+	TransitionItemReservation(ctx context.Context, itemID string, from, to entity.ReservationStatus) (bool, error)
+
+	// ClaimExpiredReservations pasa a EXPIRED hasta limit órdenes CREATED cuya reserva venció
+	// antes de expiredBefore, y retoma las EXPIRED con reservas sin liberar cuyo lease venció.
+	// Las órdenes tomadas quedan fuera de la cola durante lease. Usa SKIP LOCKED
+	ClaimExpiredReservations(ctx context.Context, expiredBefore time.Time, limit int, lease time.Duration) ([]*entity.Order, error)
+
+	// MarkReservationsReleased registra que todas las reservas de la orden vencida se liberaron
+	MarkReservationsReleased(ctx context.Context, orderID string) error
+
+	// HITO ORDER-FULFILLMENT - Ciclo de vida, historial de estados y envíos
+
+	// RecordStatusChange registra una transición en order_status_history (se suma a la tx del contexto)
+	RecordStatusChange(ctx context.Context, change *entity.OrderStatusChange) error
+
+	// TransitionStatus pasa la orden de change.FromStatus a change.ToStatus y registra la transición
+	// Falla si la orden ya no está en change.FromStatus
+	TransitionStatus(ctx context.Context, change *entity.OrderStatusChange) error
+
+	// SaveShipment persiste un envío y las unidades despachadas de cada línea
+	// change no nil = el envío cambió el estado de la orden (PACKED → SHIPPED)
+	SaveShipment(ctx context.Context, shipment *entity.Shipment, change *entity.OrderStatusChange) error
+
+	// ListStatusHistory retorna las transiciones de la orden en orden cronológico
+	ListStatusHistory(ctx context.Context, orderID, tenantID string) ([]*entity.OrderStatusChange, error)
+
+	// ListShipments retorna los envíos de la orden con sus líneas
+	ListShipments(ctx context.Context, orderID, tenantID string) ([]*entity.Shipment, error)
 }
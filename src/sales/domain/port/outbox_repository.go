@@ -0,0 +1,26 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"sales/src/sales/domain/entity"
+)
+
+// OutboxRepository define el contrato para persistir eventos del outbox
+// HITO OUTBOX - Tabla en la base de órdenes, reenviada al eventbus por el relay
+type OutboxRepository interface {
+	// Append persiste un evento PENDING (se suma a la tx del contexto)
+	Append(ctx context.Context, event *entity.OutboxEvent) error
+
+	// ClaimDue pasa a PROCESSING hasta limit eventos PENDING cuyo reintento venció
+	// (orden de creación). Usa SKIP LOCKED: varias réplicas del relay no toman el mismo evento
+	ClaimDue(ctx context.Context, limit int) ([]*entity.OutboxEvent, error)
+
+	// Update persiste el resultado del reenvío
+	Update(ctx context.Context, event *entity.OutboxEvent) error
+
+	// ReleaseStale devuelve a PENDING los eventos PROCESSING sin cambios hace más de olderThan
+	// (relay caído a mitad del reenvío)
+	ReleaseStale(ctx context.Context, olderThan time.Duration) (int64, error)
+}
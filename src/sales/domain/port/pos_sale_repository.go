@@ -3,20 +3,52 @@ package port
 import (
 	"context"
 	"sales/src/sales/domain/entity"
+	"sales/src/shared/domain/criteria"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
+// PosSaleSummary totales del conjunto filtrado de ventas (HITO POS-SEARCH)
+// Gross = final_amount; Refunded = devuelto acumulado de esas ventas
+type PosSaleSummary struct {
+	SalesCount int
+	Subtotal   decimal.Decimal
+	Discount   decimal.Decimal
+	Net        decimal.Decimal
+	Tax        decimal.Decimal
+	Gross      decimal.Decimal
+	Refunded   decimal.Decimal
+}
+
 // PosSaleRepository define el contrato para persistir ventas POS
-// Solo operaciones mínimas: Create y ListByTenant
-// Sin GetByID, sin Updates, sin Deletes
+// La venta es inmutable: solo se insertan ventas y documentos de devolución
 // Hito: POS-SALE-02.BE - Paso 2
+// HITO POS-REFUND - FindByID + CreateRefund (único cambio permitido: status / refunded_amount)
 type PosSaleRepository interface {
 	// Create persiste una nueva venta POS
 	// No valida, solo inserta
 	Create(ctx context.Context, sale *entity.PosSale) error
 
-	// ListByTenant retorna todas las ventas POS de un tenant
-	// Sin paginación, sin filtros, sin ordenamiento
-	ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*entity.PosSale, error)
+	// SearchPage retorna una página de ventas con sus items paginada por cursor
+	// HITO POS-SEARCH - Keyset sobre (created_at, id) con c.Cursor; devuelve hasta
+	// c.Limit+1 ventas en el orden de la consulta (criteria.NewCursorListResponse arma
+	// la página). Campos del criteria: tenant_id, created_at, status, point_of_sale_id,
+	// payment_method_id, customer_id, customer_document, customer_tax_condition,
+	// final_amount, sku (otro campo es un error); admite grupos AND / OR / NOT
+	SearchPage(ctx context.Context, c criteria.Criteria) ([]*entity.PosSale, error)
+
+	// Summarize agrega el conjunto filtrado completo (sin paginar)
+	Summarize(ctx context.Context, c criteria.Criteria) (*PosSaleSummary, error)
+
+	// FindByID retorna una venta con sus items (marcando los ya devueltos) y pagos
+	FindByID(ctx context.Context, tenantID, posSaleID uuid.UUID) (*entity.PosSale, error)
+
+	// CreateRefund persiste el documento de devolución y el nuevo estado de la venta (atomically)
+	// previousRefundedAmount protege contra devoluciones concurrentes sobre la misma venta
+	CreateRefund(ctx context.Context, sale *entity.PosSale, refund *entity.PosSaleRefund, previousRefundedAmount decimal.Decimal) error
+
+	// MarkRefundStockCompensated registra que stock-service revirtió el stock_entry_id de una
+	// línea de la devolución (lo invoca la saga POS_REFUND al compensar cada paso)
+	MarkRefundStockCompensated(ctx context.Context, refundID uuid.UUID, stockEntryID string) error
 }
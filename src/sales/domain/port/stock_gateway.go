@@ -0,0 +1,121 @@
+package port
+
+import (
+	"context"
+	"time"
+)
+
+// StockAvailability disponibilidad de un SKU en stock-service
+type StockAvailability struct {
+	VariantSKU        string  `json:"variant_sku"`
+	ProductSKU        string  `json:"product_sku"`
+	AvailableQuantity float64 `json:"available_quantity"`
+	ReservedQuantity  float64 `json:"reserved_quantity"`
+	TotalQuantity     float64 `json:"total_quantity"`
+	IsOutOfStock      bool    `json:"is_out_of_stock"`
+	IsLowStock        bool    `json:"is_low_stock"`
+}
+
+// StockReservation resultado de reservar stock
+type StockReservation struct {
+	SKU          string `json:"sku"`
+	ReservedQty  int    `json:"reserved_qty"`
+	RemainingQty int    `json:"remaining_qty"`
+	Reference    string `json:"reference"`
+}
+
+// StockRelease resultado de liberar stock reservado
+type StockRelease struct {
+	SKU          string `json:"sku"`
+	ReleasedQty  int    `json:"released_qty"`
+	AvailableQty int    `json:"available_qty"`
+	ReservedQty  int    `json:"reserved_qty"`
+	Reference    string `json:"reference"`
+}
+
+// StockConsumption resultado de consumir stock reservado
+type StockConsumption struct {
+	SKU         string `json:"sku"`
+	ConsumedQty int    `json:"consumed_qty"`
+	ReservedQty int    `json:"reserved_qty"`
+	Reference   string `json:"reference"`
+}
+
+// StockConsumeReversal resultado de revertir un consumo
+type StockConsumeReversal struct {
+	SKU          string `json:"sku"`
+	RevertedQty  int    `json:"reverted_qty"`
+	AvailableQty int    `json:"available_qty"`
+	Reference    string `json:"reference"`
+}
+
+// StockSaleResult resultado de una venta atómica
+// Success=false es un rechazo de negocio (stock insuficiente, SKU sin inicializar), no un error
+type StockSaleResult struct {
+	Success        bool      `json:"success"`
+	Message        string    `json:"message"`
+	VariantSKU     string    `json:"variant_sku"`
+	QuantitySold   float64   `json:"quantity_sold"`
+	RemainingStock float64   `json:"remaining_stock"`
+	TotalQuantity  float64   `json:"total_quantity"`
+	StockEntryID   string    `json:"stock_entry_id"` // Movimiento a revertir con CompensateSale
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// StockSaleLine línea de una venta en lote
+type StockSaleLine struct {
+	VariantSKU string  `json:"variant_sku"`
+	Quantity   float64 `json:"quantity"`
+	Reference  string  `json:"reference,omitempty"`
+}
+
+// StockSaleBatchResult resultado de una venta en lote
+// HITO STOCK-BATCH - Atómica: con Success=true todas las líneas se descontaron (cada una
+// con su stock_entry_id); con Success=false ninguna, y las líneas rechazadas traen Success=false
+type StockSaleBatchResult struct {
+	Success   bool              `json:"success"`
+	Message   string            `json:"message"`
+	Lines     []StockSaleResult `json:"lines"` // Mismo orden que el request
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// StockGateway define el contrato con stock-service
+// HITO STOCK-GATEWAY - Los casos de uso dependen del puerto, no del cliente HTTP:
+// infrastructure/client provee el cliente HTTP (vía Kong) e infrastructure/stock
+// un fake en memoria que también se puede servir detrás de httptest
+//
+// Un error retornado es técnico (red, timeout, status inesperado); los rechazos de
+// negocio de ProcessSaleAtomic llegan como StockSaleResult.Success=false
+//
+// HITO RESILIENCE - ctx trae el deadline del request HTTP. Si stock-service no responde
+// (breaker abierto, timeout, red) el error envuelve entity.ErrDependencyUnavailable
+type StockGateway interface {
+	// ValidateStock retorna la disponibilidad del SKU y si alcanza para quantity
+	ValidateStock(ctx context.Context, tenantID, authToken, sku string, quantity int) (*StockAvailability, bool, error)
+
+	// ReserveStock reserva quantity unidades (available↓, reserved↑)
+	// Stock insuficiente → error que envuelve entity.ErrInsufficientStock
+	ReserveStock(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*StockReservation, error)
+
+	// ReleaseStock libera una reserva (reserved↓, available↑)
+	ReleaseStock(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*StockRelease, error)
+
+	// ConsumeStock consume stock reservado (reserved↓, total↓)
+	ConsumeStock(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*StockConsumption, error)
+
+	// RevertConsume revierte un consumo (total↑, available↑)
+	RevertConsume(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*StockConsumeReversal, error)
+
+	// ProcessSaleAtomic valida y descuenta en una sola operación (available↓, total↓)
+	ProcessSaleAtomic(ctx context.Context, tenantID, authToken, sku string, quantity float64, reference string) (*StockSaleResult, error)
+
+	// CompensateSale revierte una venta atómica por su stock_entry_id
+	CompensateSale(ctx context.Context, tenantID, authToken, stockEntryID, reason string) error
+
+	// SupportsBatchSale indica si stock-service acepta ProcessSaleBatch (probe con cache)
+	SupportsBatchSale(ctx context.Context, tenantID, authToken string) bool
+
+	// ProcessSaleBatch valida y descuenta todas las líneas en una sola operación atómica
+	// Retorna entity.ErrStockBatchNotSupported si stock-service no tiene el endpoint
+	ProcessSaleBatch(ctx context.Context, tenantID, authToken string, lines []StockSaleLine, reference string) (*StockSaleBatchResult, error)
+}
@@ -0,0 +1,39 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"sales/src/sales/domain/entity"
+)
+
+// StockSagaRepository define el contrato para persistir el log de sagas de stock
+// HITO STOCK-SAGA - stock_sagas + stock_saga_steps en la base de órdenes
+type StockSagaRepository interface {
+	// Create persiste una saga STARTED (sin pasos)
+	Create(ctx context.Context, saga *entity.StockSaga) error
+
+	// AppendStep persiste un descuento de stock aplicado
+	AppendStep(ctx context.Context, step *entity.StockSagaStep) error
+
+	// Update persiste el estado de la saga y de todos sus pasos (se suma a la tx del contexto)
+	// Los pasos que no llegaron a persistirse con AppendStep se insertan
+	Update(ctx context.Context, saga *entity.StockSaga) error
+
+	// ClaimDue toma hasta limit sagas COMPENSATING cuyo reintento venció y corre su
+	// next_attempt_at en lease (otra réplica no las toma mientras se compensan)
+	// Usa SKIP LOCKED: varias réplicas del worker no toman la misma saga
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.StockSaga, error)
+
+	// AbandonStale pasa a COMPENSATING las sagas STARTED sin cambios hace más de olderThan
+	// (proceso caído entre el descuento de stock y la persistencia de la venta)
+	AbandonStale(ctx context.Context, olderThan time.Duration, reason string) (int64, error)
+
+	// FindCompletedByReference retorna la saga COMPLETED de una operación (con sus pasos)
+	// o nil si no hay (HITO ORDER-CANCEL - cancelar una orden revierte su saga)
+	FindCompletedByReference(ctx context.Context, tenantID string, sagaType entity.StockSagaType, reference string) (*entity.StockSaga, error)
+
+	// ListStuck retorna las sagas que no terminaron: ESCALATED, COMPENSATING y STARTED
+	// sin cambios hace más de startedBefore. status vacío = todos; tenantID vacío = todos
+	ListStuck(ctx context.Context, tenantID string, status entity.StockSagaStatus, startedBefore time.Duration, limit int) ([]*entity.StockSaga, error)
+}
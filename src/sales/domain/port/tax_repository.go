@@ -0,0 +1,30 @@
+package port
+
+import (
+	"context"
+
+	"sales/src/sales/domain/entity"
+
+	"github.com/google/uuid"
+)
+
+// TaxRepository define el contrato para persistir la configuración de IVA del tenant
+// HITO TAX-IVA - Modo de precios, alícuota por defecto y reglas por SKU / categoría
+type TaxRepository interface {
+	// FindSettings retorna la configuración del tenant
+	// Sin configuración cargada retorna entity.DefaultTaxSettings (IVA incluido, 21%)
+	FindSettings(ctx context.Context, tenantID uuid.UUID) (*entity.TaxSettings, error)
+
+	// SaveSettings crea o reemplaza la configuración del tenant
+	SaveSettings(ctx context.Context, settings *entity.TaxSettings) error
+
+	// ListRules retorna las reglas del tenant ordenadas por alcance y valor
+	ListRules(ctx context.Context, tenantID uuid.UUID) ([]*entity.TaxRule, error)
+
+	// SaveRule crea la regla o reemplaza la alícuota de la existente (tenant + scope + scope_value)
+	// Actualiza rule.ID / rule.CreatedAt con los de la regla persistida
+	SaveRule(ctx context.Context, rule *entity.TaxRule) error
+
+	// DeleteRule elimina una regla. Retorna entity.ErrTaxRuleNotFound si no existe
+	DeleteRule(ctx context.Context, tenantID, ruleID uuid.UUID) error
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"database/sql"
 	"log"
+	"strings"
 	"sync"
 
 	"github.com/google/uuid"
@@ -71,6 +72,13 @@ func (c *PaymentMethodCache) LoadFromDB(db *sql.DB) error {
 	return nil
 }
 
+// Put agrega o reemplaza un método de pago (desarrollo / tests sin payment_method_db)
+func (c *PaymentMethodCache) Put(pm PaymentMethod) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.methods[pm.ID] = pm
+}
+
 // Get obtiene el nombre de un método de pago por ID
 func (c *PaymentMethodCache) Get(id uuid.UUID) (PaymentMethod, bool) {
 	c.mu.RLock()
@@ -88,3 +96,18 @@ func (c *PaymentMethodCache) GetName(id uuid.UUID) string {
 	}
 	return pm.Name
 }
+
+// cashCodes códigos de métodos de pago que representan efectivo
+var cashCodes = map[string]bool{
+	"CASH":     true,
+	"EFECTIVO": true,
+}
+
+// IsCash indica si un método de pago es efectivo (afecta fondo de caja y vuelto)
+func (c *PaymentMethodCache) IsCash(id uuid.UUID) bool {
+	pm, ok := c.Get(id)
+	if !ok {
+		return false
+	}
+	return cashCodes[strings.ToUpper(pm.Code)]
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/shared/infrastructure/resilience"
+
+	"github.com/google/uuid"
+)
+
+// CustomerServiceAddress domicilio en la respuesta de customer-service
+type CustomerServiceAddress struct {
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+// CustomerServiceResponse representa la respuesta de customer-service para un cliente
+type CustomerServiceResponse struct {
+	CustomerID     string                  `json:"customer_id"`
+	Name           string                  `json:"name"`
+	DocumentType   string                  `json:"document_type"`
+	DocumentNumber string                  `json:"document_number"`
+	TaxCondition   string                  `json:"tax_condition"`
+	Email          string                  `json:"email"`
+	Address        *CustomerServiceAddress `json:"address,omitempty"`
+}
+
+// CustomerClient cliente HTTP para comunicarse con customer-service vía Kong
+// Implementa port.CustomerGateway
+// HITO CUSTOMER - Lecturas idempotentes con la resiliencia propia de customer-service
+// (CUSTOMER_SERVICE_TIMEOUT, CUSTOMER_SERVICE_MAX_ATTEMPTS, CUSTOMER_SERVICE_BREAKER_THRESHOLD,
+// CUSTOMER_SERVICE_BREAKER_OPEN_TIMEOUT)
+type CustomerClient struct {
+	httpClient   *resilience.Client
+	kongURL      string
+	customerPath string
+}
+
+// customerServiceName nombre de la dependencia en métricas y errores
+const customerServiceName = "customer-service"
+
+// NewCustomerClient crea una nueva instancia del cliente de customer-service
+func NewCustomerClient() *CustomerClient {
+	kongURL := os.Getenv("KONG_INTERNAL_URL")
+	if kongURL == "" {
+		kongURL = "http://kong:8000" // Default para entorno Docker
+	}
+
+	customerPath := os.Getenv("CUSTOMER_SERVICE_PATH")
+	if customerPath == "" {
+		customerPath = "/customers" // Default
+	}
+
+	return &CustomerClient{
+		httpClient:   resilience.NewClient(resilience.ConfigFromEnv(customerServiceName, "CUSTOMER_SERVICE")),
+		kongURL:      kongURL,
+		customerPath: customerPath,
+	}
+}
+
+// GetCustomerSnapshot obtiene el cliente y lo convierte en snapshot del comprador
+func (c *CustomerClient) GetCustomerSnapshot(ctx context.Context, tenantID, authToken string, customerID uuid.UUID) (*entity.CustomerSnapshot, error) {
+	resp, err := doKongCall(ctx, c.httpClient, customerServiceName, kongCall{
+		operation:  "customer_by_id",
+		method:     http.MethodGet,
+		url:        fmt.Sprintf("%s%s/api/v1/customers/%s", c.kongURL, c.customerPath, customerID),
+		tenantID:   tenantID,
+		authToken:  authToken,
+		idempotent: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Verificar status code
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", entity.ErrCustomerNotFound, customerID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("customer-service returned status %d: %s", resp.StatusCode, string(resp.Body))
+	}
+
+	// Parse response
+	var customer CustomerServiceResponse
+	if err := json.Unmarshal(resp.Body, &customer); err != nil {
+		return nil, fmt.Errorf("error unmarshalling customer response: %w", err)
+	}
+
+	snapshot, err := entity.NewCustomerSnapshot(
+		customerID,
+		customer.Name,
+		customer.DocumentType,
+		customer.DocumentNumber,
+		entity.TaxCondition(customer.TaxCondition),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid customer %s from customer-service: %w", customerID, err)
+	}
+	snapshot.Email = customer.Email
+	if customer.Address != nil {
+		snapshot.Address = &entity.CustomerAddress{
+			Street:     customer.Address.Street,
+			City:       customer.Address.City,
+			State:      customer.Address.State,
+			PostalCode: customer.Address.PostalCode,
+			Country:    customer.Address.Country,
+		}
+	}
+
+	return snapshot, nil
+}
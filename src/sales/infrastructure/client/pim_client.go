@@ -1,12 +1,13 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
-	"time"
+
+	"sales/src/shared/infrastructure/resilience"
 )
 
 // PIMProductResponse representa la respuesta de PIM para un producto
@@ -42,12 +43,18 @@ type PIMVariantResponse struct {
 }
 
 // PIMClient cliente HTTP para comunicarse con PIM service vía Kong
+// HITO RESILIENCE - Lecturas idempotentes: timeout por intento, reintentos con jitter y
+// circuit breaker propio de PIM (PIM_SERVICE_TIMEOUT, PIM_SERVICE_MAX_ATTEMPTS,
+// PIM_SERVICE_BREAKER_THRESHOLD, PIM_SERVICE_BREAKER_OPEN_TIMEOUT)
 type PIMClient struct {
-	httpClient *http.Client
+	httpClient *resilience.Client
 	kongURL    string
 	pimPath    string
 }
 
+// pimServiceName nombre de la dependencia en métricas y errores
+const pimServiceName = "pim-service"
+
 // NewPIMClient crea una nueva instancia del cliente PIM
 func NewPIMClient() *PIMClient {
 	kongURL := os.Getenv("KONG_INTERNAL_URL")
@@ -61,44 +68,24 @@ func NewPIMClient() *PIMClient {
 	}
 
 	return &PIMClient{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		kongURL: kongURL,
-		pimPath: pimPath,
+		httpClient: resilience.NewClient(resilience.ConfigFromEnv(pimServiceName, "PIM_SERVICE")),
+		kongURL:    kongURL,
+		pimPath:    pimPath,
 	}
 }
 
 // GetVariantBySKU obtiene una variante por su SKU
-func (c *PIMClient) GetVariantBySKU(tenantID, authToken, sku string) (*PIMVariantResponse, error) {
-	// Construir URL completa vía Kong
-	url := fmt.Sprintf("%s%s/api/v1/variants/by-sku/%s", c.kongURL, c.pimPath, sku)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	// Pasar Authorization si existe
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
-	}
-
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error calling pim-service: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
+func (c *PIMClient) GetVariantBySKU(ctx context.Context, tenantID, authToken, sku string) (*PIMVariantResponse, error) {
+	resp, err := doKongCall(ctx, c.httpClient, pimServiceName, kongCall{
+		operation:  "variant_by_sku",
+		method:     http.MethodGet,
+		url:        fmt.Sprintf("%s%s/api/v1/variants/by-sku/%s", c.kongURL, c.pimPath, sku),
+		tenantID:   tenantID,
+		authToken:  authToken,
+		idempotent: true,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return nil, err
 	}
 
 	// Verificar status code
@@ -106,12 +93,12 @@ func (c *PIMClient) GetVariantBySKU(tenantID, authToken, sku string) (*PIMVarian
 		return nil, fmt.Errorf("variant not found: %s", sku)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("pim-service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("pim-service returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response
 	var variant PIMVariantResponse
-	if err := json.Unmarshal(body, &variant); err != nil {
+	if err := json.Unmarshal(resp.Body, &variant); err != nil {
 		return nil, fmt.Errorf("error unmarshalling variant response: %w", err)
 	}
 
@@ -119,35 +106,17 @@ func (c *PIMClient) GetVariantBySKU(tenantID, authToken, sku string) (*PIMVarian
 }
 
 // GetProductByID obtiene un producto por su ID
-func (c *PIMClient) GetProductByID(tenantID, authToken, productID string) (*PIMProductResponse, error) {
-	// Construir URL completa vía Kong
-	url := fmt.Sprintf("%s%s/api/v1/products/%s", c.kongURL, c.pimPath, productID)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	// Pasar Authorization si existe
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
-	}
-
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error calling pim-service: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
+func (c *PIMClient) GetProductByID(ctx context.Context, tenantID, authToken, productID string) (*PIMProductResponse, error) {
+	resp, err := doKongCall(ctx, c.httpClient, pimServiceName, kongCall{
+		operation:  "product_by_id",
+		method:     http.MethodGet,
+		url:        fmt.Sprintf("%s%s/api/v1/products/%s", c.kongURL, c.pimPath, productID),
+		tenantID:   tenantID,
+		authToken:  authToken,
+		idempotent: true,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return nil, err
 	}
 
 	// Verificar status code
@@ -155,12 +124,12 @@ func (c *PIMClient) GetProductByID(tenantID, authToken, productID string) (*PIMP
 		return nil, fmt.Errorf("product not found: %s", productID)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("pim-service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("pim-service returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response
 	var product PIMProductResponse
-	if err := json.Unmarshal(body, &product); err != nil {
+	if err := json.Unmarshal(resp.Body, &product); err != nil {
 		return nil, fmt.Errorf("error unmarshalling product response: %w", err)
 	}
 
@@ -168,15 +137,15 @@ func (c *PIMClient) GetProductByID(tenantID, authToken, productID string) (*PIMP
 }
 
 // GetSnapshotForSKU obtiene tanto el producto como la variante y retorna ambos como JSON
-func (c *PIMClient) GetSnapshotForSKU(tenantID, authToken, sku string) (productSnapshot, variantSnapshot json.RawMessage, err error) {
+func (c *PIMClient) GetSnapshotForSKU(ctx context.Context, tenantID, authToken, sku string) (productSnapshot, variantSnapshot json.RawMessage, err error) {
 	// 1. Obtener variante por SKU
-	variant, err := c.GetVariantBySKU(tenantID, authToken, sku)
+	variant, err := c.GetVariantBySKU(ctx, tenantID, authToken, sku)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error fetching variant: %w", err)
 	}
 
 	// 2. Obtener producto asociado
-	product, err := c.GetProductByID(tenantID, authToken, variant.ProductID)
+	product, err := c.GetProductByID(ctx, tenantID, authToken, variant.ProductID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error fetching product: %w", err)
 	}
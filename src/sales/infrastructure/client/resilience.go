@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/shared/infrastructure/resilience"
+)
+
+// kongCall llamada a un servicio interno vía Kong
+// HITO RESILIENCE - Headers comunes (tenant, Authorization) y body JSON
+type kongCall struct {
+	operation  string
+	method     string
+	url        string
+	tenantID   string
+	authToken  string
+	payload    interface{}
+	idempotent bool
+}
+
+// doKongCall ejecuta la llamada con el cliente resiliente de la dependencia
+// Los errores sin respuesta (breaker abierto, red, timeout) se envuelven en
+// entity.ErrDependencyUnavailable para que los controllers respondan 503
+func doKongCall(ctx context.Context, httpClient *resilience.Client, service string, call kongCall) (*resilience.Response, error) {
+	header := http.Header{}
+	header.Set("X-Tenant-ID", call.tenantID)
+	if call.authToken != "" {
+		header.Set("Authorization", call.authToken)
+	}
+
+	var body []byte
+	if call.payload != nil {
+		jsonData, err := json.Marshal(call.payload)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling request: %w", err)
+		}
+		body = jsonData
+		header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := httpClient.Do(ctx, resilience.Request{
+		Operation:  call.operation,
+		Method:     call.method,
+		URL:        call.url,
+		Header:     header,
+		Body:       body,
+		Idempotent: call.idempotent,
+	})
+	if err != nil {
+		return nil, dependencyError(service, err)
+	}
+	return resp, nil
+}
+
+// dependencyError clasifica un error sin respuesta de la dependencia
+// La cancelación del request original no es indisponibilidad de la dependencia
+func dependencyError(service string, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("call to %s canceled: %w", service, err)
+	}
+	return fmt.Errorf("%w: %s: %w", entity.ErrDependencyUnavailable, service, err)
+}
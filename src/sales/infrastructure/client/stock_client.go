@@ -1,25 +1,18 @@
 package client
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
+	"sync"
 	"time"
-)
 
-// StockAvailabilityResponse representa la respuesta de stock-service
-type StockAvailabilityResponse struct {
-	VariantSKU        string  `json:"variant_sku"`
-	ProductSKU        string  `json:"product_sku"`
-	AvailableQuantity float64 `json:"available_quantity"`
-	ReservedQuantity  float64 `json:"reserved_quantity"`
-	TotalQuantity     float64 `json:"total_quantity"`
-	IsOutOfStock      bool    `json:"is_out_of_stock"`
-	IsLowStock        bool    `json:"is_low_stock"`
-}
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/shared/infrastructure/resilience"
+)
 
 // StockReserveRequest representa el request para reservar stock
 type StockReserveRequest struct {
@@ -28,14 +21,6 @@ type StockReserveRequest struct {
 	Reference string `json:"reference"`
 }
 
-// StockReserveResponse representa la respuesta de reserva de stock
-type StockReserveResponse struct {
-	SKU          string `json:"sku"`
-	ReservedQty  int    `json:"reserved_qty"`
-	RemainingQty int    `json:"remaining_qty"`
-	Reference    string `json:"reference"`
-}
-
 // StockReleaseRequest representa el request para liberar stock
 type StockReleaseRequest struct {
 	SKU       string `json:"sku"`
@@ -43,15 +28,6 @@ type StockReleaseRequest struct {
 	Reference string `json:"reference"`
 }
 
-// StockReleaseResponse representa la respuesta de liberación de stock
-type StockReleaseResponse struct {
-	SKU          string `json:"sku"`
-	ReleasedQty  int    `json:"released_qty"`
-	AvailableQty int    `json:"available_qty"`
-	ReservedQty  int    `json:"reserved_qty"`
-	Reference    string `json:"reference"`
-}
-
 // StockConsumeRequest representa el request para consumir stock
 type StockConsumeRequest struct {
 	SKU       string `json:"sku"`
@@ -59,14 +35,6 @@ type StockConsumeRequest struct {
 	Reference string `json:"reference"`
 }
 
-// StockConsumeResponse representa la respuesta de consumo de stock
-type StockConsumeResponse struct {
-	SKU         string `json:"sku"`
-	ConsumedQty int    `json:"consumed_qty"`
-	ReservedQty int    `json:"reserved_qty"`
-	Reference   string `json:"reference"`
-}
-
 // StockRevertConsumeRequest representa el request para revertir consumo
 type StockRevertConsumeRequest struct {
 	SKU       string `json:"sku"`
@@ -74,21 +42,35 @@ type StockRevertConsumeRequest struct {
 	Reference string `json:"reference"`
 }
 
-// StockRevertConsumeResponse representa la respuesta de reversión
-type StockRevertConsumeResponse struct {
-	SKU          string `json:"sku"`
-	RevertedQty  int    `json:"reverted_qty"`
-	AvailableQty int    `json:"available_qty"`
-	Reference    string `json:"reference"`
-}
-
 // StockClient cliente HTTP para comunicarse con stock-service vía Kong
+// Implementa port.StockGateway
+// HITO RESILIENCE - Timeout por intento, reintentos solo en llamadas idempotentes y
+// circuit breaker propio de stock-service (STOCK_SERVICE_TIMEOUT, STOCK_SERVICE_MAX_ATTEMPTS,
+// STOCK_SERVICE_BREAKER_THRESHOLD, STOCK_SERVICE_BREAKER_OPEN_TIMEOUT)
 type StockClient struct {
-	httpClient *http.Client
+	httpClient *resilience.Client
 	kongURL    string
 	stockPath  string
+
+	// HITO STOCK-BATCH: resultado del probe de capacidades
+	// capMu solo protege el cache: el probe corre fuera del lock (capProbing)
+	capMu          sync.Mutex
+	batchSupported bool
+	capExpiresAt   time.Time
+	capProbing     bool
 }
 
+// stockServiceName nombre de la dependencia en métricas y errores
+const stockServiceName = "stock-service"
+
+const (
+	// stockFeatureSaleBatch feature informada por GET /capabilities para POST /sale/batch
+	stockFeatureSaleBatch = "sale_batch"
+	// capabilityTTL vigencia del probe; capabilityErrorTTL si el probe no obtuvo respuesta
+	capabilityTTL      = 5 * time.Minute
+	capabilityErrorTTL = 30 * time.Second
+)
+
 // NewStockClient crea una nueva instancia del cliente
 func NewStockClient() *StockClient {
 	kongURL := os.Getenv("KONG_INTERNAL_URL")
@@ -101,55 +83,47 @@ func NewStockClient() *StockClient {
 		stockPath = "/stock" // Default
 	}
 
-	return &StockClient{
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		kongURL:   kongURL,
-		stockPath: stockPath,
-	}
+	return NewStockClientWithURL(kongURL, stockPath)
 }
 
-// ValidateStock valida disponibilidad de stock vía Kong usando GET /availability
-func (c *StockClient) ValidateStock(tenantID, authToken, sku string, quantity int) (*StockAvailabilityResponse, bool, error) {
-	// Construir URL completa vía Kong con query parameter
-	url := fmt.Sprintf("%s%s/api/v1/availability?sku=%s", c.kongURL, c.stockPath, sku)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, false, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	// Pasar Authorization si existe
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
+// NewStockClientWithURL crea un cliente contra una URL base explícita
+// Permite apuntar al fake de stock-service servido con httptest (stockPath vacío)
+func NewStockClientWithURL(baseURL, stockPath string) *StockClient {
+	return &StockClient{
+		httpClient: resilience.NewClient(resilience.ConfigFromEnv(stockServiceName, "STOCK_SERVICE")),
+		kongURL:    baseURL,
+		stockPath:  stockPath,
 	}
+}
 
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, false, fmt.Errorf("error calling stock-service: %w", err)
-	}
-	defer resp.Body.Close()
+// call ejecuta una llamada a stock-service
+func (c *StockClient) call(ctx context.Context, call kongCall) (*resilience.Response, error) {
+	return doKongCall(ctx, c.httpClient, stockServiceName, call)
+}
 
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
+// ValidateStock valida disponibilidad de stock vía Kong usando GET /availability
+// Idempotente: se reintenta ante fallas transitorias
+func (c *StockClient) ValidateStock(ctx context.Context, tenantID, authToken, sku string, quantity int) (*port.StockAvailability, bool, error) {
+	resp, err := c.call(ctx, kongCall{
+		operation:  "availability",
+		method:     http.MethodGet,
+		url:        fmt.Sprintf("%s%s/api/v1/availability?sku=%s", c.kongURL, c.stockPath, sku),
+		tenantID:   tenantID,
+		authToken:  authToken,
+		idempotent: true,
+	})
 	if err != nil {
-		return nil, false, fmt.Errorf("error reading response: %w", err)
+		return nil, false, err
 	}
 
 	// Verificar status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, false, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, false, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response
-	var stockResp StockAvailabilityResponse
-	if err := json.Unmarshal(body, &stockResp); err != nil {
+	var stockResp port.StockAvailability
+	if err := json.Unmarshal(resp.Body, &stockResp); err != nil {
 		return nil, false, fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
@@ -160,61 +134,35 @@ func (c *StockClient) ValidateStock(tenantID, authToken, sku string, quantity in
 }
 
 // ReserveStock reserva stock vía Kong usando POST /reserve
-func (c *StockClient) ReserveStock(tenantID, authToken, sku string, quantity int, reference string) (*StockReserveResponse, error) {
-	// Preparar request body
-	reqBody := StockReserveRequest{
-		SKU:       sku,
-		Quantity:  quantity,
-		Reference: reference,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling request: %w", err)
-	}
-
-	// Construir URL completa vía Kong
-	url := fmt.Sprintf("%s%s/api/v1/reserve", c.kongURL, c.stockPath)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	// Pasar Authorization si existe
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
-	}
-
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error calling stock-service: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
+func (c *StockClient) ReserveStock(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*port.StockReservation, error) {
+	resp, err := c.call(ctx, kongCall{
+		operation: "reserve",
+		method:    http.MethodPost,
+		url:       fmt.Sprintf("%s%s/api/v1/reserve", c.kongURL, c.stockPath),
+		tenantID:  tenantID,
+		authToken: authToken,
+		payload: StockReserveRequest{
+			SKU:       sku,
+			Quantity:  quantity,
+			Reference: reference,
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return nil, err
 	}
 
 	// Verificar status code
+	// HITO ORDER-RESERVATION: rechazo de negocio tipado (el caller no compara textos)
 	if resp.StatusCode == http.StatusConflict {
-		return nil, fmt.Errorf("insufficient stock: %s", string(body))
+		return nil, fmt.Errorf("%w: %s", entity.ErrInsufficientStock, string(resp.Body))
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response
-	var stockResp StockReserveResponse
-	if err := json.Unmarshal(body, &stockResp); err != nil {
+	var stockResp port.StockReservation
+	if err := json.Unmarshal(resp.Body, &stockResp); err != nil {
 		return nil, fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
@@ -222,61 +170,34 @@ func (c *StockClient) ReserveStock(tenantID, authToken, sku string, quantity int
 }
 
 // ReleaseStock libera stock reservado vía Kong usando POST /release
-func (c *StockClient) ReleaseStock(tenantID, authToken, sku string, quantity int, reference string) (*StockReleaseResponse, error) {
-	// Preparar request body
-	reqBody := StockReleaseRequest{
-		SKU:       sku,
-		Quantity:  quantity,
-		Reference: reference,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling request: %w", err)
-	}
-
-	// Construir URL completa vía Kong
-	url := fmt.Sprintf("%s%s/api/v1/release", c.kongURL, c.stockPath)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	// Pasar Authorization si existe
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
-	}
-
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error calling stock-service: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
+func (c *StockClient) ReleaseStock(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*port.StockRelease, error) {
+	resp, err := c.call(ctx, kongCall{
+		operation: "release",
+		method:    http.MethodPost,
+		url:       fmt.Sprintf("%s%s/api/v1/release", c.kongURL, c.stockPath),
+		tenantID:  tenantID,
+		authToken: authToken,
+		payload: StockReleaseRequest{
+			SKU:       sku,
+			Quantity:  quantity,
+			Reference: reference,
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return nil, err
 	}
 
 	// Verificar status code
 	if resp.StatusCode == http.StatusConflict {
-		return nil, fmt.Errorf("insufficient reserved stock: %s", string(body))
+		return nil, fmt.Errorf("insufficient reserved stock: %s", string(resp.Body))
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response
-	var stockResp StockReleaseResponse
-	if err := json.Unmarshal(body, &stockResp); err != nil {
+	var stockResp port.StockRelease
+	if err := json.Unmarshal(resp.Body, &stockResp); err != nil {
 		return nil, fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
@@ -284,61 +205,34 @@ func (c *StockClient) ReleaseStock(tenantID, authToken, sku string, quantity int
 }
 
 // ConsumeStock consume stock reservado vía Kong usando POST /consume
-func (c *StockClient) ConsumeStock(tenantID, authToken, sku string, quantity int, reference string) (*StockConsumeResponse, error) {
-	// Preparar request body
-	reqBody := StockConsumeRequest{
-		SKU:       sku,
-		Quantity:  quantity,
-		Reference: reference,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling request: %w", err)
-	}
-
-	// Construir URL completa vía Kong
-	url := fmt.Sprintf("%s%s/api/v1/consume", c.kongURL, c.stockPath)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	// Pasar Authorization si existe
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
-	}
-
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error calling stock-service: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
+func (c *StockClient) ConsumeStock(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*port.StockConsumption, error) {
+	resp, err := c.call(ctx, kongCall{
+		operation: "consume",
+		method:    http.MethodPost,
+		url:       fmt.Sprintf("%s%s/api/v1/consume", c.kongURL, c.stockPath),
+		tenantID:  tenantID,
+		authToken: authToken,
+		payload: StockConsumeRequest{
+			SKU:       sku,
+			Quantity:  quantity,
+			Reference: reference,
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return nil, err
 	}
 
 	// Verificar status code
 	if resp.StatusCode == http.StatusConflict {
-		return nil, fmt.Errorf("insufficient reserved stock: %s", string(body))
+		return nil, fmt.Errorf("insufficient reserved stock: %s", string(resp.Body))
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response
-	var stockResp StockConsumeResponse
-	if err := json.Unmarshal(body, &stockResp); err != nil {
+	var stockResp port.StockConsumption
+	if err := json.Unmarshal(resp.Body, &stockResp); err != nil {
 		return nil, fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
@@ -346,58 +240,31 @@ func (c *StockClient) ConsumeStock(tenantID, authToken, sku string, quantity int
 }
 
 // RevertConsume revierte un consumo de stock vía Kong usando POST /revert-consume
-func (c *StockClient) RevertConsume(tenantID, authToken, sku string, quantity int, reference string) (*StockRevertConsumeResponse, error) {
-	// Preparar request body
-	reqBody := StockRevertConsumeRequest{
-		SKU:       sku,
-		Quantity:  quantity,
-		Reference: reference,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling request: %w", err)
-	}
-
-	// Construir URL completa vía Kong
-	url := fmt.Sprintf("%s%s/api/v1/revert-consume", c.kongURL, c.stockPath)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	// Pasar Authorization si existe
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
-	}
-
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error calling stock-service: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
+func (c *StockClient) RevertConsume(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*port.StockConsumeReversal, error) {
+	resp, err := c.call(ctx, kongCall{
+		operation: "revert_consume",
+		method:    http.MethodPost,
+		url:       fmt.Sprintf("%s%s/api/v1/revert-consume", c.kongURL, c.stockPath),
+		tenantID:  tenantID,
+		authToken: authToken,
+		payload: StockRevertConsumeRequest{
+			SKU:       sku,
+			Quantity:  quantity,
+			Reference: reference,
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return nil, err
 	}
 
 	// Verificar status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response
-	var stockResp StockRevertConsumeResponse
-	if err := json.Unmarshal(body, &stockResp); err != nil {
+	var stockResp port.StockConsumeReversal
+	if err := json.Unmarshal(resp.Body, &stockResp); err != nil {
 		return nil, fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
@@ -427,62 +294,35 @@ type DirectSaleResponse struct {
 
 // DirectSale realiza una venta directa POS vía Kong usando POST /sale
 // No crea orden, no reserva, venta inmediata (available↓, total↓)
-func (c *StockClient) DirectSale(tenantID, authToken, sku string, quantity int, reference, notes string) (*DirectSaleResponse, error) {
-	// Preparar request body
-	reqBody := DirectSaleRequest{
-		VariantSKU: sku,
-		Quantity:   quantity,
-		Reference:  reference,
-		Notes:      notes,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling request: %w", err)
-	}
-
-	// Construir URL completa vía Kong
-	url := fmt.Sprintf("%s%s/api/v1/sale", c.kongURL, c.stockPath)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	// Pasar Authorization si existe
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
-	}
-
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error calling stock-service /sale: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
+func (c *StockClient) DirectSale(ctx context.Context, tenantID, authToken, sku string, quantity int, reference, notes string) (*DirectSaleResponse, error) {
+	resp, err := c.call(ctx, kongCall{
+		operation: "sale",
+		method:    http.MethodPost,
+		url:       fmt.Sprintf("%s%s/api/v1/sale", c.kongURL, c.stockPath),
+		tenantID:  tenantID,
+		authToken: authToken,
+		payload: DirectSaleRequest{
+			VariantSKU: sku,
+			Quantity:   quantity,
+			Reference:  reference,
+			Notes:      notes,
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return nil, err
 	}
 
 	// Verificar status code
 	if resp.StatusCode == http.StatusConflict {
-		return nil, fmt.Errorf("insufficient_stock: %s", string(body))
+		return nil, fmt.Errorf("insufficient_stock: %s", string(resp.Body))
 	}
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("stock-service /sale returned status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("stock-service /sale returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response
 	var saleResp DirectSaleResponse
-	if err := json.Unmarshal(body, &saleResp); err != nil {
+	if err := json.Unmarshal(resp.Body, &saleResp); err != nil {
 		return nil, fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
@@ -499,35 +339,17 @@ func (c *StockClient) DirectSale(tenantID, authToken, sku string, quantity int,
 //
 // DEPRECATED: Este método tiene race condition entre check y sale.
 // Usar ProcessSaleAtomic() en su lugar.
-func (c *StockClient) CheckAvailability(tenantID, authToken, sku string, quantity int) (bool, error) {
-	// Construir URL completa vía Kong con query parameter
-	url := fmt.Sprintf("%s%s/api/v1/availability?sku=%s", c.kongURL, c.stockPath, sku)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return false, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	// Pasar Authorization si existe
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
-	}
-
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return false, fmt.Errorf("error calling stock-service: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
+func (c *StockClient) CheckAvailability(ctx context.Context, tenantID, authToken, sku string, quantity int) (bool, error) {
+	resp, err := c.call(ctx, kongCall{
+		operation:  "availability",
+		method:     http.MethodGet,
+		url:        fmt.Sprintf("%s%s/api/v1/availability?sku=%s", c.kongURL, c.stockPath, sku),
+		tenantID:   tenantID,
+		authToken:  authToken,
+		idempotent: true,
+	})
 	if err != nil {
-		return false, fmt.Errorf("error reading response: %w", err)
+		return false, err
 	}
 
 	// Si el SKU no existe (404) → no hay stock disponible
@@ -537,12 +359,12 @@ func (c *StockClient) CheckAvailability(tenantID, authToken, sku string, quantit
 
 	// Verificar status code
 	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(body))
+		return false, fmt.Errorf("stock-service returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response
-	var stockResp StockAvailabilityResponse
-	if err := json.Unmarshal(body, &stockResp); err != nil {
+	var stockResp port.StockAvailability
+	if err := json.Unmarshal(resp.Body, &stockResp); err != nil {
 		return false, fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
@@ -558,59 +380,32 @@ func (c *StockClient) CheckAvailability(tenantID, authToken, sku string, quantit
 //
 // DEPRECATED: Usar ProcessSaleAtomic() que retorna stock_entry_id para compensación.
 // Este método no retorna el ID necesario para CompensateSale().
-func (c *StockClient) ProcessSale(tenantID, authToken, sku string, quantity int, orderID string) error {
-	// Preparar request body
-	reqBody := DirectSaleRequest{
-		VariantSKU: sku,
-		Quantity:   quantity,
-		Reference:  orderID, // Usar order_id como reference para trazabilidad
-		Notes:      "Order stock exit",
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return fmt.Errorf("error marshalling request: %w", err)
-	}
-
-	// Construir URL completa vía Kong
-	url := fmt.Sprintf("%s%s/api/v1/sale", c.kongURL, c.stockPath)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	// Pasar Authorization si existe
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
-	}
-
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("error calling stock-service /sale: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
+func (c *StockClient) ProcessSale(ctx context.Context, tenantID, authToken, sku string, quantity int, orderID string) error {
+	resp, err := c.call(ctx, kongCall{
+		operation: "sale",
+		method:    http.MethodPost,
+		url:       fmt.Sprintf("%s%s/api/v1/sale", c.kongURL, c.stockPath),
+		tenantID:  tenantID,
+		authToken: authToken,
+		payload: DirectSaleRequest{
+			VariantSKU: sku,
+			Quantity:   quantity,
+			Reference:  orderID, // Usar order_id como reference para trazabilidad
+			Notes:      "Order stock exit",
+		},
+	})
 	if err != nil {
-		return fmt.Errorf("error reading response: %w", err)
+		return err
 	}
 
 	// Verificar status code (puede ser 200 o 400 según stock-service)
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
-		return fmt.Errorf("stock-service /sale returned status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("stock-service /sale returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response
 	var saleResp DirectSaleResponse
-	if err := json.Unmarshal(body, &saleResp); err != nil {
+	if err := json.Unmarshal(resp.Body, &saleResp); err != nil {
 		return fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
@@ -633,71 +428,40 @@ type ProcessSaleAtomicRequest struct {
 	Reference  string  `json:"reference,omitempty"`
 }
 
-// ProcessSaleAtomicResponse representa la respuesta de venta atómica
-type ProcessSaleAtomicResponse struct {
-	Success        bool      `json:"success"`
-	Message        string    `json:"message"`
-	VariantSKU     string    `json:"variant_sku"`
-	QuantitySold   float64   `json:"quantity_sold"`
-	RemainingStock float64   `json:"remaining_stock"`
-	TotalQuantity  float64   `json:"total_quantity"`
-	StockEntryID   string    `json:"stock_entry_id"`
-	Timestamp      time.Time `json:"timestamp"`
-}
-
 // ProcessSaleAtomic ejecuta venta atómica con SELECT FOR UPDATE
 // HITO D: Elimina race condition, valida y descuenta en una sola transacción
 // Retorna stock_entry_id para posterior compensación si es necesario
+// HITO RESILIENCE: no idempotente (descuenta stock) → nunca se reintenta
 func (c *StockClient) ProcessSaleAtomic(
+	ctx context.Context,
 	tenantID, authToken, sku string,
 	quantity float64,
 	reference string,
-) (*ProcessSaleAtomicResponse, error) {
-	// Preparar request body
-	reqBody := ProcessSaleAtomicRequest{
-		VariantSKU: sku,
-		Quantity:   quantity,
-		Reference:  reference,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("error marshalling request: %w", err)
-	}
-
-	// Construir URL completa vía Kong
-	url := fmt.Sprintf("%s%s/api/v1/sale", c.kongURL, c.stockPath)
-
-	// Crear request HTTP
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
-
-	// Headers obligatorios
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
-	}
-
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
+) (*port.StockSaleResult, error) {
+	resp, err := c.call(ctx, kongCall{
+		operation: "process_sale",
+		method:    http.MethodPost,
+		url:       fmt.Sprintf("%s%s/api/v1/sale", c.kongURL, c.stockPath),
+		tenantID:  tenantID,
+		authToken: authToken,
+		payload: ProcessSaleAtomicRequest{
+			VariantSKU: sku,
+			Quantity:   quantity,
+			Reference:  reference,
+		},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("error calling stock-service /sale: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+	// 5xx / gateway: no es un rechazo de negocio
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("stock-service /sale returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
 	// Parse response (puede ser success=false con 200 o 400)
-	var saleResp ProcessSaleAtomicResponse
-	if err := json.Unmarshal(body, &saleResp); err != nil {
+	var saleResp port.StockSaleResult
+	if err := json.Unmarshal(resp.Body, &saleResp); err != nil {
 		return nil, fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
@@ -713,56 +477,155 @@ type CompensateSaleRequest struct {
 
 // CompensateSale revierte una venta creando movimiento inverso
 // HITO D: Usado para rollback cuando falla creación de orden
+// HITO RESILIENCE: no idempotente → nunca se reintenta. Cada llamada crea un movimiento
+// inverso y stock-service no garantiza deduplicar por stock_entry_id: reintentar un
+// timeout o un 5xx podría devolver el stock dos veces. Los reintentos quedan a cargo de
+// la saga (StockCompensationWorker), con backoff y escalamiento
 func (c *StockClient) CompensateSale(
+	ctx context.Context,
 	tenantID, authToken string,
 	stockEntryID string,
 	reason string,
 ) error {
-	// Preparar request body
-	reqBody := CompensateSaleRequest{
-		StockEntryID: stockEntryID,
-		Reason:       reason,
+	resp, err := c.call(ctx, kongCall{
+		operation: "compensate_sale",
+		method:    http.MethodPost,
+		url:       fmt.Sprintf("%s%s/api/v1/compensate-sale", c.kongURL, c.stockPath),
+		tenantID:  tenantID,
+		authToken: authToken,
+		payload: CompensateSaleRequest{
+			StockEntryID: stockEntryID,
+			Reason:       reason,
+		},
+	})
+	if err != nil {
+		return err
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return fmt.Errorf("error marshalling request: %w", err)
+	// Verificar status code
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("compensation failed (status %d): %s", resp.StatusCode, string(resp.Body))
 	}
 
-	// Construir URL completa vía Kong
-	url := fmt.Sprintf("%s%s/api/v1/compensate-sale", c.kongURL, c.stockPath)
+	return nil
+}
+
+// ============================================================================
+// HITO STOCK-BATCH - Venta en lote (una sola llamada por venta)
+// ============================================================================
+
+// StockCapabilitiesResponse respuesta de GET /api/v1/capabilities
+type StockCapabilitiesResponse struct {
+	Features []string `json:"features"`
+}
+
+// ProcessSaleBatchRequest representa el request para venta en lote
+type ProcessSaleBatchRequest struct {
+	Reference string               `json:"reference,omitempty"`
+	Lines     []port.StockSaleLine `json:"lines"`
+}
 
-	// Crear request HTTP
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+// SupportsBatchSale consulta GET /capabilities y cachea el resultado
+// Un stock-service sin el endpoint (404) no soporta lote; sin respuesta se asume que no
+// y se vuelve a probar en capabilityErrorTTL
+// Vencido el cache, una sola llamada ejecuta el probe; mientras tanto las demás usan el
+// valor anterior (false antes del primer probe) en lugar de esperar el timeout de stock-service
+func (c *StockClient) SupportsBatchSale(ctx context.Context, tenantID, authToken string) bool {
+	c.capMu.Lock()
+	if c.capProbing || time.Now().Before(c.capExpiresAt) {
+		supported := c.batchSupported
+		c.capMu.Unlock()
+		return supported
+	}
+	c.capProbing = true
+	c.capMu.Unlock()
+
+	supported, err := c.probeBatchSale(ctx, tenantID, authToken)
+	ttl := capabilityTTL
+	if err != nil {
+		ttl = capabilityErrorTTL
+	}
+
+	c.capMu.Lock()
+	defer c.capMu.Unlock()
+	c.batchSupported = supported
+	c.capExpiresAt = time.Now().Add(ttl)
+	c.capProbing = false
+	return supported
+}
+
+// probeBatchSale ejecuta el probe de capacidades
+func (c *StockClient) probeBatchSale(ctx context.Context, tenantID, authToken string) (bool, error) {
+	resp, err := c.call(ctx, kongCall{
+		operation:  "capabilities",
+		method:     http.MethodGet,
+		url:        fmt.Sprintf("%s%s/api/v1/capabilities", c.kongURL, c.stockPath),
+		tenantID:   tenantID,
+		authToken:  authToken,
+		idempotent: true,
+	})
 	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+		return false, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("stock-service /capabilities returned status %d", resp.StatusCode)
 	}
 
-	// Headers obligatorios
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Tenant-ID", tenantID)
-
-	if authToken != "" {
-		req.Header.Set("Authorization", authToken)
+	var capabilities StockCapabilitiesResponse
+	if err := json.Unmarshal(resp.Body, &capabilities); err != nil {
+		return false, fmt.Errorf("error unmarshalling capabilities: %w", err)
+	}
+	for _, feature := range capabilities.Features {
+		if feature == stockFeatureSaleBatch {
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	// Ejecutar request
-	resp, err := c.httpClient.Do(req)
+// ProcessSaleBatch ejecuta la venta de todas las líneas en POST /sale/batch
+// Atómica en stock-service; como ProcessSaleAtomic, no idempotente → nunca se reintenta
+func (c *StockClient) ProcessSaleBatch(
+	ctx context.Context,
+	tenantID, authToken string,
+	lines []port.StockSaleLine,
+	reference string,
+) (*port.StockSaleBatchResult, error) {
+	resp, err := c.call(ctx, kongCall{
+		operation: "process_sale_batch",
+		method:    http.MethodPost,
+		url:       fmt.Sprintf("%s%s/api/v1/sale/batch", c.kongURL, c.stockPath),
+		tenantID:  tenantID,
+		authToken: authToken,
+		payload: ProcessSaleBatchRequest{
+			Reference: reference,
+			Lines:     lines,
+		},
+	})
 	if err != nil {
-		return fmt.Errorf("error calling stock-service /compensate-sale: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Leer response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("error reading response: %w", err)
+	// Endpoint inexistente: el probe quedó desactualizado (ej: rollback de stock-service)
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		c.capMu.Lock()
+		c.batchSupported = false
+		c.capExpiresAt = time.Now().Add(capabilityTTL)
+		c.capMu.Unlock()
+		return nil, entity.ErrStockBatchNotSupported
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("stock-service /sale/batch returned status %d: %s", resp.StatusCode, string(resp.Body))
 	}
 
-	// Verificar status code
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("compensation failed (status %d): %s", resp.StatusCode, string(body))
+	// Parse response (success=false con 400 / 409 y el detalle por línea)
+	var batchResp port.StockSaleBatchResult
+	if err := json.Unmarshal(resp.Body, &batchResp); err != nil {
+		return nil, fmt.Errorf("error unmarshalling response: %w", err)
 	}
 
-	return nil
+	return &batchResp, nil
 }
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// HITO STOCK-BATCH - El probe de capacidades no bloquea a las demás ventas
+
+func TestSupportsBatchSaleDoesNotWaitForInFlightProbe(t *testing.T) {
+	var probes atomic.Int32
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stock/api/v1/capabilities" {
+			http.NotFound(w, r)
+			return
+		}
+		if probes.Add(1) == 1 {
+			close(probing)
+		}
+		<-release
+		_ = json.NewEncoder(w).Encode(StockCapabilitiesResponse{Features: []string{stockFeatureSaleBatch}})
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	})
+	stockClient := NewStockClientWithURL(srv.URL, "/stock")
+
+	leader := make(chan bool)
+	go func() {
+		leader <- stockClient.SupportsBatchSale(context.Background(), "tenant-1", "")
+	}()
+	<-probing
+
+	// Con el probe en curso se responde el valor cacheado (ninguno todavía: por ítem)
+	follower := make(chan bool)
+	go func() {
+		follower <- stockClient.SupportsBatchSale(context.Background(), "tenant-1", "")
+	}()
+	select {
+	case supported := <-follower:
+		if supported {
+			t.Error("SupportsBatchSale during the first probe = true, want false")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SupportsBatchSale blocked behind the in-flight probe")
+	}
+
+	close(release)
+	if !<-leader {
+		t.Error("probe result = false, want true")
+	}
+	if !stockClient.SupportsBatchSale(context.Background(), "tenant-1", "") {
+		t.Error("cached result = false, want true")
+	}
+	if got := probes.Load(); got != 1 {
+		t.Errorf("capabilities probes = %d, want 1", got)
+	}
+}
@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/usecase"
+	"sales/src/sales/domain/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CashSessionController maneja las peticiones HTTP para sesiones de caja
+// HITO POS-CASH - Apertura / cierre de caja con arqueo
+type CashSessionController struct {
+	openCashSessionUC  *usecase.OpenCashSessionUseCase
+	closeCashSessionUC *usecase.CloseCashSessionUseCase
+	getCashSessionUC   *usecase.GetCashSessionUseCase
+}
+
+// NewCashSessionController crea una nueva instancia del controlador
+func NewCashSessionController(
+	openCashSessionUC *usecase.OpenCashSessionUseCase,
+	closeCashSessionUC *usecase.CloseCashSessionUseCase,
+	getCashSessionUC *usecase.GetCashSessionUseCase,
+) *CashSessionController {
+	return &CashSessionController{
+		openCashSessionUC:  openCashSessionUC,
+		closeCashSessionUC: closeCashSessionUC,
+		getCashSessionUC:   getCashSessionUC,
+	}
+}
+
+// RegisterRoutes registra las rutas del controlador
+func (c *CashSessionController) RegisterRoutes(router *gin.RouterGroup) {
+	sessions := router.Group("/pos/cash-sessions")
+	{
+		sessions.POST("", c.OpenCashSession)
+		sessions.GET("/current", c.GetCurrentCashSession)
+		sessions.GET("/:session_id", c.GetCashSession)
+		sessions.POST("/:session_id/close", c.CloseCashSession)
+	}
+
+	log.Println("Rutas Cash Session disponibles:")
+	log.Println("  POST   /api/v1/pos/cash-sessions")
+	log.Println("  GET    /api/v1/pos/cash-sessions/current?point_of_sale_id=...")
+	log.Println("  GET    /api/v1/pos/cash-sessions/:session_id")
+	log.Println("  POST   /api/v1/pos/cash-sessions/:session_id/close  (arqueo)")
+}
+
+// OpenCashSession abre una sesión de caja para un punto de venta
+func (c *CashSessionController) OpenCashSession(ctx *gin.Context) {
+	if c.openCashSessionUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Cash sessions not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.OpenCashSessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.openCashSessionUC.Execute(ctx.Request.Context(), tenantUUID, ctx.GetHeader("X-User-ID"), &req)
+	if err != nil {
+		log.Printf("Error opening cash session: %v", err)
+		c.handleError(ctx, err, "Error opening cash session")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// GetCurrentCashSession obtiene la sesión abierta de un punto de venta (arqueo parcial)
+func (c *CashSessionController) GetCurrentCashSession(ctx *gin.Context) {
+	if c.getCashSessionUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Cash sessions not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	pointOfSaleID, err := uuid.Parse(ctx.Query("point_of_sale_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "point_of_sale_id query parameter is required (UUID)",
+		})
+		return
+	}
+
+	resp, err := c.getCashSessionUC.ExecuteCurrent(ctx.Request.Context(), tenantUUID, pointOfSaleID)
+	if err != nil {
+		log.Printf("Error getting current cash session: %v", err)
+		c.handleError(ctx, err, "Error getting current cash session")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetCashSession obtiene una sesión de caja con su arqueo
+func (c *CashSessionController) GetCashSession(ctx *gin.Context) {
+	if c.getCashSessionUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Cash sessions not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(ctx.Param("session_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session_id format"})
+		return
+	}
+
+	resp, err := c.getCashSessionUC.Execute(ctx.Request.Context(), tenantUUID, sessionID)
+	if err != nil {
+		log.Printf("Error getting cash session: %v", err)
+		c.handleError(ctx, err, "Error getting cash session")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// CloseCashSession cierra una sesión de caja registrando el arqueo
+func (c *CashSessionController) CloseCashSession(ctx *gin.Context) {
+	if c.closeCashSessionUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Cash sessions not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(ctx.Param("session_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session_id format"})
+		return
+	}
+
+	var req request.CloseCashSessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.closeCashSessionUC.Execute(ctx.Request.Context(), tenantUUID, sessionID, ctx.GetHeader("X-User-ID"), &req)
+	if err != nil {
+		log.Printf("Error closing cash session: %v", err)
+		c.handleError(ctx, err, "Error closing cash session")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// handleError mapea errores de dominio a códigos HTTP
+func (c *CashSessionController) handleError(ctx *gin.Context, err error, message string) {
+	switch err {
+	case entity.ErrCashSessionNotFound, entity.ErrNoOpenCashSession:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case entity.ErrCashSessionAlreadyOpen, entity.ErrCashSessionNotOpen:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case entity.ErrTenantIDRequired, entity.ErrPointOfSaleIDRequired, entity.ErrPaymentMethodRequired,
+		entity.ErrInvalidOpeningFloat, entity.ErrInvalidCountedAmount:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// tenantUUIDFromHeader valida y parsea el header X-Tenant-ID
+// Responde 400 y retorna false si falta o es inválido
+func tenantUUIDFromHeader(ctx *gin.Context) (uuid.UUID, bool) {
+	tenantID := ctx.GetHeader("X-Tenant-ID")
+	if tenantID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "X-Tenant-ID header is required"})
+		return uuid.Nil, false
+	}
+
+	tenantUUID, err := uuid.Parse(tenantID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid X-Tenant-ID format"})
+		return uuid.Nil, false
+	}
+
+	return tenantUUID, true
+}
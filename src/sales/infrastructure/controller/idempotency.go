@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+
+	"sales/src/sales/application/service"
+	"sales/src/sales/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotentReplayHeader marca las respuestas reproducidas desde una clave ya completada
+const idempotentReplayHeader = "Idempotent-Replayed"
+
+// responseRecorder copia el body escrito por el handler para guardarlo con la clave
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMiddleware aplica Idempotency-Key a un endpoint mutante
+// HITO IDEMPOTENCY - Clave por tenant + hash del request (método, ruta y body):
+// - replay de una clave completada → respuesta original (Idempotent-Replayed: true)
+// - misma clave con otro body → 422
+// - duplicado concurrente → espera al primero; si no termina a tiempo → 409
+// Las respuestas 5xx no se guardan: la clave se libera para que el cliente reintente
+// Sin header (o sin DB) el request pasa sin cambios
+func idempotencyMiddleware(idempotencyService *service.IdempotencyService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(entity.IdempotencyKeyHeader)
+		tenantID := ctx.GetHeader("X-Tenant-ID")
+		if idempotencyService == nil || key == "" || tenantID == "" {
+			ctx.Next()
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, acquired, err := idempotencyService.Begin(ctx.Request.Context(), tenantID, key, requestHash(ctx, body))
+		if err != nil {
+			abortIdempotencyError(ctx, err)
+			return
+		}
+		if !acquired {
+			log.Printf("🔁 Idempotency-Key %s replayed (tenant %s)", key, tenantID)
+			ctx.Header(idempotentReplayHeader, "true")
+			ctx.Data(record.ResponseStatus, "application/json; charset=utf-8", record.ResponseBody)
+			ctx.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		// El resultado se registra aunque el cliente haya cortado la conexión
+		storeCtx := context.WithoutCancel(ctx.Request.Context())
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			if err := idempotencyService.Release(storeCtx, record); err != nil {
+				log.Printf("⚠️ Error releasing Idempotency-Key %s: %v", key, err)
+			}
+			return
+		}
+		if err := idempotencyService.Complete(storeCtx, record, status, recorder.body.Bytes()); err != nil {
+			log.Printf("⚠️ Error storing Idempotency-Key %s response: %v", key, err)
+		}
+	}
+}
+
+// requestHash identifica el request original: método, ruta y body
+func requestHash(ctx *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Request.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.Request.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// abortIdempotencyError mapea los errores de idempotencia a códigos HTTP
+func abortIdempotencyError(ctx *gin.Context, err error) {
+	switch err {
+	case entity.ErrInvalidIdempotencyKey:
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case entity.ErrIdempotencyKeyMismatch:
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case entity.ErrIdempotencyKeyInProgress:
+		ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error checking Idempotency-Key: %v", err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error checking Idempotency-Key",
+			"details": err.Error(),
+		})
+	}
+}
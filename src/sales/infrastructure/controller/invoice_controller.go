@@ -0,0 +1,275 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/usecase"
+	"sales/src/sales/domain/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// InvoiceController maneja la facturación electrónica
+// HITO FISCAL - Emisión de comprobantes A/B/C y datos fiscales del tenant
+type InvoiceController struct {
+	issueInvoiceUC *usecase.IssueInvoiceUseCase
+	getInvoiceUC   *usecase.GetInvoiceUseCase
+	retryInvoiceUC *usecase.RetryInvoiceUseCase
+	fiscalIssuerUC *usecase.FiscalIssuerUseCase
+
+	// HITO CREDIT-NOTE
+	listCreditNotesUC *usecase.ListCreditNotesUseCase
+}
+
+// NewInvoiceController crea una nueva instancia del controlador
+func NewInvoiceController(
+	issueInvoiceUC *usecase.IssueInvoiceUseCase,
+	getInvoiceUC *usecase.GetInvoiceUseCase,
+	retryInvoiceUC *usecase.RetryInvoiceUseCase,
+	fiscalIssuerUC *usecase.FiscalIssuerUseCase,
+	listCreditNotesUC *usecase.ListCreditNotesUseCase,
+) *InvoiceController {
+	return &InvoiceController{
+		issueInvoiceUC:    issueInvoiceUC,
+		getInvoiceUC:      getInvoiceUC,
+		retryInvoiceUC:    retryInvoiceUC,
+		fiscalIssuerUC:    fiscalIssuerUC,
+		listCreditNotesUC: listCreditNotesUC,
+	}
+}
+
+// RegisterRoutes registra las rutas del controlador
+func (c *InvoiceController) RegisterRoutes(router *gin.RouterGroup) {
+	invoices := router.Group("/invoices")
+	{
+		invoices.POST("", c.IssueInvoice)
+		invoices.GET("/:invoice_id", c.GetInvoice)
+		invoices.POST("/:invoice_id/retry", c.RetryInvoice)
+		invoices.GET("/:invoice_id/credit-notes", c.ListCreditNotes)
+	}
+
+	issuer := router.Group("/admin/fiscal-issuer")
+	{
+		issuer.GET("", c.GetFiscalIssuer)
+		issuer.PUT("", c.SaveFiscalIssuer)
+	}
+
+	log.Println("Rutas Invoice disponibles:")
+	log.Println("  POST   /api/v1/invoices                     (facturar orden / venta POS)")
+	log.Println("  GET    /api/v1/invoices/:invoice_id")
+	log.Println("  POST   /api/v1/invoices/:invoice_id/retry   (reintentar rechazada)")
+	log.Println("  GET    /api/v1/invoices/:invoice_id/credit-notes")
+	log.Println("  GET    /api/v1/admin/fiscal-issuer")
+	log.Println("  PUT    /api/v1/admin/fiscal-issuer")
+}
+
+// IssueInvoice emite el comprobante de una orden confirmada o venta POS
+// 201 si el fisco lo autorizó en línea, 202 si quedó pendiente de autorización
+func (c *InvoiceController) IssueInvoice(ctx *gin.Context) {
+	if c.issueInvoiceUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Invoicing not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.IssueInvoiceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.issueInvoiceUC.Execute(ctx.Request.Context(), tenantUUID, &req)
+	if err != nil {
+		log.Printf("Error issuing invoice: %v", err)
+		c.handleError(ctx, err, "Error issuing invoice")
+		return
+	}
+
+	ctx.JSON(invoiceStatusCode(resp.FiscalStatus), resp)
+}
+
+// GetInvoice retorna un comprobante con su estado fiscal
+func (c *InvoiceController) GetInvoice(ctx *gin.Context) {
+	if c.getInvoiceUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Invoicing not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	invoiceID, err := uuid.Parse(ctx.Param("invoice_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice_id format"})
+		return
+	}
+
+	resp, err := c.getInvoiceUC.Execute(ctx.Request.Context(), tenantUUID, invoiceID)
+	if err != nil {
+		log.Printf("Error getting invoice: %v", err)
+		c.handleError(ctx, err, "Error getting invoice")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// RetryInvoice reintenta la autorización de un comprobante rechazado
+func (c *InvoiceController) RetryInvoice(ctx *gin.Context) {
+	if c.retryInvoiceUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Invoicing not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	invoiceID, err := uuid.Parse(ctx.Param("invoice_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice_id format"})
+		return
+	}
+
+	resp, err := c.retryInvoiceUC.Execute(ctx.Request.Context(), tenantUUID, invoiceID)
+	if err != nil {
+		log.Printf("Error retrying invoice: %v", err)
+		c.handleError(ctx, err, "Error retrying invoice")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// ListCreditNotes lista las notas de crédito emitidas contra una factura
+func (c *InvoiceController) ListCreditNotes(ctx *gin.Context) {
+	if c.listCreditNotesUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Invoicing not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	invoiceID, err := uuid.Parse(ctx.Param("invoice_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invoice_id format"})
+		return
+	}
+
+	resp, err := c.listCreditNotesUC.Execute(ctx.Request.Context(), tenantUUID, invoiceID)
+	if err != nil {
+		log.Printf("Error listing credit notes: %v", err)
+		c.handleError(ctx, err, "Error listing credit notes")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetFiscalIssuer retorna los datos fiscales del tenant
+func (c *InvoiceController) GetFiscalIssuer(ctx *gin.Context) {
+	if c.fiscalIssuerUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Invoicing not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := c.fiscalIssuerUC.Get(ctx.Request.Context(), tenantUUID)
+	if err != nil {
+		log.Printf("Error getting fiscal issuer: %v", err)
+		c.handleError(ctx, err, "Error getting fiscal issuer")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// SaveFiscalIssuer crea o reemplaza los datos fiscales del tenant
+func (c *InvoiceController) SaveFiscalIssuer(ctx *gin.Context) {
+	if c.fiscalIssuerUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Invoicing not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.SaveFiscalIssuerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.fiscalIssuerUC.Save(ctx.Request.Context(), tenantUUID, &req)
+	if err != nil {
+		log.Printf("Error saving fiscal issuer: %v", err)
+		c.handleError(ctx, err, "Error saving fiscal issuer")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// invoiceStatusCode 201 si quedó autorizado o rechazado, 202 si sigue pendiente de CAE
+func invoiceStatusCode(fiscalStatus string) int {
+	switch entity.FiscalStatus(fiscalStatus) {
+	case entity.FiscalStatusPending, entity.FiscalStatusProcessing:
+		return http.StatusAccepted
+	}
+	return http.StatusCreated
+}
+
+// handleError mapea errores de dominio a códigos HTTP
+func (c *InvoiceController) handleError(ctx *gin.Context, err error, message string) {
+	switch err {
+	case entity.ErrInvoiceNotFound, entity.ErrInvoiceSourceNotFound, entity.ErrFiscalIssuerNotConfigured:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case entity.ErrInvoiceAlreadyExists, entity.ErrInvoiceNotRetryable, entity.ErrInvoiceSourceNotInvoiceable:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case entity.ErrInvalidInvoiceSourceType, entity.ErrInvalidTaxCondition, entity.ErrCustomerTaxIDRequired,
+		entity.ErrInvoiceAmountRequired, entity.ErrInvalidCUIT, entity.ErrInvalidFiscalPointOfSale:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
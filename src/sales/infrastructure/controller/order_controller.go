@@ -1,12 +1,19 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"net/http"
 	"sales/src/sales/application/request"
+	"sales/src/sales/application/service"
 	"sales/src/sales/application/usecase"
 	"sales/src/sales/domain/entity"
+	"sales/src/shared/infrastructure/resilience"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -24,6 +31,7 @@ type OrderController struct {
 	getOrderUC      *usecase.GetOrderUseCase
 	posSaleUC       *usecase.POSSaleUseCase
 	listPosSalesUC  *usecase.ListPosSalesUseCase
+	idempotency     *service.IdempotencyService // HITO IDEMPOTENCY
 }
 
 // NewOrderController crea una nueva instancia del controlador
@@ -38,6 +46,7 @@ func NewOrderController(
 	getOrderUC *usecase.GetOrderUseCase,
 	posSaleUC *usecase.POSSaleUseCase,
 	listPosSalesUC *usecase.ListPosSalesUseCase,
+	idempotency *service.IdempotencyService,
 ) *OrderController {
 	return &OrderController{
 		validateStockUC: validateStockUC,
@@ -50,18 +59,23 @@ func NewOrderController(
 		getOrderUC:      getOrderUC,
 		posSaleUC:       posSaleUC,
 		listPosSalesUC:  listPosSalesUC,
+		idempotency:     idempotency,
 	}
 }
 
 // RegisterRoutes registra las rutas del controlador
+// HITO IDEMPOTENCY: Idempotency-Key en los endpoints que descuentan stock o cambian la orden
 func (c *OrderController) RegisterRoutes(router *gin.RouterGroup) {
+	idempotent := idempotencyMiddleware(c.idempotency)
+
 	orders := router.Group("/orders")
 	{
 		orders.GET("", c.ListOrders)
 		orders.GET("/:order_id", c.GetOrder)
-		orders.POST("", c.CreateOrder)
-		orders.POST("/:order_id/confirm", c.ConfirmOrder)
-		orders.POST("/:order_id/cancel", c.CancelOrder)
+		orders.POST("", idempotent, c.CreateOrder)
+		orders.POST("/:order_id/confirm", idempotent, c.ConfirmOrder)
+		orders.POST("/:order_id/cancel", idempotent, c.CancelOrder)
+		orders.POST("/:order_id/lines/cancel", idempotent, c.CancelOrderLines)
 		orders.POST("/validate-stock", c.ValidateStock)
 		orders.POST("/reserve-stock", c.ReserveStock)
 		orders.POST("/release-stock", c.ReleaseStock)
@@ -70,24 +84,27 @@ func (c *OrderController) RegisterRoutes(router *gin.RouterGroup) {
 	// Grupo POS para ventas directas
 	pos := router.Group("/pos")
 	{
-		pos.POST("/sale", c.POSSale)
+		pos.POST("/sale", idempotent, c.POSSale)
 		pos.GET("/sales", c.ListPosSales)
 	}
 
 	log.Println("Rutas Order disponibles:")
-	log.Println("  GET    /api/v1/orders")
+	log.Println("  GET    /api/v1/orders  (status, order_number_from/to, created_from/to, sku, customer_id, customer_document, filter, sort_by)")
 	log.Println("  GET    /api/v1/orders/:order_id")
 	log.Println("  POST   /api/v1/orders")
 	log.Println("  POST   /api/v1/orders/:order_id/confirm")
 	log.Println("  POST   /api/v1/orders/:order_id/cancel")
+	log.Println("  POST   /api/v1/orders/:order_id/lines/cancel")
 	log.Println("  POST   /api/v1/orders/validate-stock")
 	log.Println("  POST   /api/v1/orders/reserve-stock")
 	log.Println("  POST   /api/v1/orders/release-stock")
 	log.Println("  POST   /api/v1/pos/sale  ⭐ (POS Direct Sale)")
-	log.Println("  GET    /api/v1/pos/sales  (POS Sales Report)")
+	log.Println("  (POST orders / confirm / cancel / lines/cancel / pos/sale aceptan Idempotency-Key)")
+	log.Println("  GET    /api/v1/pos/sales  (created_from/to, payment_method_id, customer_id, customer_document, amount_min/max, sku, filter, cursor, summary)")
 }
 
 // ListPosSales lista las ventas POS del tenant (para reporte)
+// HITO POS-SEARCH - Filtros, cursor (next_cursor) y summary=true con los totales del filtro
 func (c *OrderController) ListPosSales(ctx *gin.Context) {
 	if c.listPosSalesUC == nil {
 		ctx.JSON(http.StatusServiceUnavailable, gin.H{
@@ -108,20 +125,39 @@ func (c *OrderController) ListPosSales(ctx *gin.Context) {
 		return
 	}
 
-	items, err := c.listPosSalesUC.Execute(ctx.Request.Context(), tenantUUID)
+	builder, err := NewPosSaleCriteriaBuilder(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid filter",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	withSummary := false
+	if value := ctx.Query("summary"); value != "" {
+		withSummary, err = strconv.ParseBool(value)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid filter",
+				"details": fmt.Sprintf("invalid summary: %q", value),
+			})
+			return
+		}
+	}
+
+	resp, err := c.listPosSalesUC.Execute(ctx.Request.Context(), tenantUUID, builder.Build(), withSummary)
 	if err != nil {
 		log.Printf("Error listing POS sales: %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"items":       items,
-		"total_count": len(items),
-	})
+	ctx.JSON(http.StatusOK, resp)
 }
 
-// CancelOrder maneja la cancelación de una orden confirmada
+// CancelOrder maneja la cancelación de una orden
+// HITO ORDER-CANCEL - Desde CREATED o CONFIRMED; motivo obligatorio, usuario en X-User-ID
 func (c *OrderController) CancelOrder(ctx *gin.Context) {
 	// Verificar que el use case esté disponible
 	if c.cancelOrderUC == nil {
@@ -152,22 +188,21 @@ func (c *OrderController) CancelOrder(ctx *gin.Context) {
 		return
 	}
 
-	// 4. Ejecutar use case
-	order, err := c.cancelOrderUC.Execute(ctx.Request.Context(), tenantID, authToken, orderID)
+	// 4. Validar body (motivo obligatorio)
+	var req request.CancelOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// 5. Ejecutar use case
+	resp, err := c.cancelOrderUC.Execute(ctx.Request.Context(), tenantID, authToken, ctx.GetHeader("X-User-ID"), orderID, req.Reason)
 	if err != nil {
 		log.Printf("Error canceling order: %v", err)
-
-		// Manejar errores específicos
-		if err == entity.ErrOrderNotFound {
-			ctx.JSON(http.StatusNotFound, gin.H{
-				"error": "Order not found",
-			})
-			return
-		}
-		if err == entity.ErrOrderNotInConfirmedState {
-			ctx.JSON(http.StatusConflict, gin.H{
-				"error": "Order is not in CONFIRMED state",
-			})
+		if respondDependencyUnavailable(ctx, err) || respondOrderCancelError(ctx, err) {
 			return
 		}
 
@@ -179,11 +214,97 @@ func (c *OrderController) CancelOrder(ctx *gin.Context) {
 		return
 	}
 
-	// 5. Responder exitosamente
-	ctx.JSON(http.StatusOK, gin.H{
-		"order_id": order.OrderID,
-		"status":   string(order.Status),
-	})
+	// 6. Responder exitosamente (incluye nota de crédito si la orden estaba facturada)
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// CancelOrderLines maneja la cancelación parcial de líneas de una orden confirmada
+// HITO ORDER-CANCEL - Recalcula totales y emite sales.order.line_canceled
+func (c *OrderController) CancelOrderLines(ctx *gin.Context) {
+	if c.cancelOrderUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Order cancellation not available (database not configured)",
+		})
+		return
+	}
+
+	tenantID := ctx.GetHeader("X-Tenant-ID")
+	if tenantID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "X-Tenant-ID header is required",
+		})
+		return
+	}
+
+	orderID := ctx.Param("order_id")
+	if orderID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "order_id is required",
+		})
+		return
+	}
+
+	var req request.CancelOrderLinesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	lines := make([]entity.OrderLineCancel, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, entity.OrderLineCancel{ItemID: line.ItemID, Quantity: line.Quantity})
+	}
+
+	resp, err := c.cancelOrderUC.ExecuteLines(
+		ctx.Request.Context(),
+		tenantID,
+		ctx.GetHeader("Authorization"),
+		ctx.GetHeader("X-User-ID"),
+		orderID,
+		lines,
+		req.Reason,
+	)
+	if err != nil {
+		log.Printf("Error canceling order lines: %v", err)
+		if respondDependencyUnavailable(ctx, err) || respondOrderCancelError(ctx, err) {
+			return
+		}
+
+		ctx.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Error canceling order lines",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// respondOrderCancelError mapea los errores de dominio de la cancelación (HITO ORDER-CANCEL)
+// Retorna false si el error no es de dominio
+func respondOrderCancelError(ctx *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, entity.ErrOrderNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	case errors.Is(err, entity.ErrOrderItemNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrCancelReasonRequired),
+		errors.Is(err, entity.ErrLineCancelMustHaveItems),
+		errors.Is(err, entity.ErrInvalidCancelQuantity):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrOrderNotInConfirmedState):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Order is not in CONFIRMED state"})
+	case errors.Is(err, entity.ErrOrderNotCancelable),
+		errors.Is(err, entity.ErrLineCancelWouldEmptyOrder),
+		errors.Is(err, entity.ErrOrderStockNotCompensable):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
 }
 
 // ConfirmOrder maneja la confirmación de una orden
@@ -217,9 +338,9 @@ func (c *OrderController) ConfirmOrder(ctx *gin.Context) {
 		return
 	}
 
-	// 4. Validar body
+	// 4. Validar body (opcional desde HITO ORDER-RESERVATION)
 	var req request.ConfirmOrderRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
 			"details": err.Error(),
@@ -228,9 +349,12 @@ func (c *OrderController) ConfirmOrder(ctx *gin.Context) {
 	}
 
 	// 5. Ejecutar use case
-	order, err := c.confirmOrderUC.Execute(ctx.Request.Context(), tenantID, authToken, orderID, req.Reference)
+	order, err := c.confirmOrderUC.Execute(ctx.Request.Context(), tenantID, authToken, ctx.GetHeader("X-User-ID"), orderID)
 	if err != nil {
 		log.Printf("Error confirming order: %v", err)
+		if respondDependencyUnavailable(ctx, err) {
+			return
+		}
 
 		// Manejar errores específicos
 		if err == entity.ErrOrderNotFound {
@@ -245,6 +369,27 @@ func (c *OrderController) ConfirmOrder(ctx *gin.Context) {
 			})
 			return
 		}
+		// HITO ORDER-RESERVATION: la reserva venció (el worker libera el stock)
+		if errors.Is(err, entity.ErrOrderReservationExpired) {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": "Order stock reservation expired",
+			})
+			return
+		}
+		// HITO ORDER-CANCEL: la orden se está cancelando (reservas liberadas)
+		if errors.Is(err, entity.ErrOrderReservationReleased) {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": "Order stock reservation was released",
+			})
+			return
+		}
+		// HITO ORDER-RESERVATION: otra confirmación está consumiendo las reservas
+		if errors.Is(err, entity.ErrOrderConfirmInProgress) {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": "Order confirmation already in progress",
+			})
+			return
+		}
 		if contains(err.Error(), "insufficient_reserved_stock") {
 			ctx.JSON(http.StatusConflict, gin.H{
 				"error": "Insufficient reserved stock",
@@ -302,7 +447,38 @@ func (c *OrderController) CreateOrder(ctx *gin.Context) {
 	// 4. Ejecutar use case con snapshots de PIM
 	resp, err := c.createOrderUC.Execute(ctx.Request.Context(), tenantID, authToken, &req)
 	if err != nil {
+		// HITO ORDER-PRICE: Override negativo o variante sin precio en PIM
+		if errors.Is(err, entity.ErrInvalidPrice) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid unit_price",
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, entity.ErrItemPriceUnavailable) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   "Variant price not available",
+				"details": err.Error(),
+			})
+			return
+		}
+		// HITO CUSTOMER: comprador inválido o inexistente en customer-service
+		if respondCustomerError(ctx, err) {
+			return
+		}
+		// HITO ORDER-RESERVATION: no alcanza el stock para reservar
+		if errors.Is(err, entity.ErrInsufficientStock) {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error":   "Insufficient stock",
+				"details": err.Error(),
+			})
+			return
+		}
+
 		log.Printf("Error creating order: %v", err)
+		if respondDependencyUnavailable(ctx, err) {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Error creating order",
 			"details": err.Error(),
@@ -339,9 +515,12 @@ func (c *OrderController) ReleaseStock(ctx *gin.Context) {
 	}
 
 	// 4. Ejecutar use case
-	resp, err := c.releaseStockUC.Execute(tenantID, authToken, &req)
+	resp, err := c.releaseStockUC.Execute(ctx.Request.Context(), tenantID, authToken, &req)
 	if err != nil {
 		log.Printf("Error releasing stock: %v", err)
+		if respondDependencyUnavailable(ctx, err) {
+			return
+		}
 
 		// Si es error de stock reservado insuficiente → 409
 		if contains(err.Error(), "insufficient_reserved_stock") {
@@ -388,9 +567,12 @@ func (c *OrderController) ReserveStock(ctx *gin.Context) {
 	}
 
 	// 4. Ejecutar use case
-	resp, err := c.reserveStockUC.Execute(tenantID, authToken, &req)
+	resp, err := c.reserveStockUC.Execute(ctx.Request.Context(), tenantID, authToken, &req)
 	if err != nil {
 		log.Printf("Error reserving stock: %v", err)
+		if respondDependencyUnavailable(ctx, err) {
+			return
+		}
 
 		// Si es error de stock insuficiente → 409
 		if contains(err.Error(), "insufficient_stock") {
@@ -412,6 +594,56 @@ func (c *OrderController) ReserveStock(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, resp)
 }
 
+// hasPermission indica si X-User-Permissions (lista separada por comas) incluye el permiso
+// HITO POS-PRICE
+func hasPermission(ctx *gin.Context, permission string) bool {
+	for _, granted := range strings.Split(ctx.GetHeader("X-User-Permissions"), ",") {
+		if strings.TrimSpace(granted) == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// respondCustomerError responde los errores del comprador de la venta (HITO CUSTOMER)
+// customer_id inválido → 400; cliente inexistente en el tenant → 422
+func respondCustomerError(ctx *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, entity.ErrInvalidCustomerID):
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid customer_id",
+			"details": err.Error(),
+		})
+	case errors.Is(err, entity.ErrCustomerNotFound):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Customer not found",
+			"details": err.Error(),
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// respondDependencyUnavailable responde 503 si stock-service, PIM o customer-service no están disponibles
+// HITO RESILIENCE - Breaker abierto, timeout o error de red: el terminal puede reintentar.
+// Con el breaker abierto, Retry-After indica cuándo se vuelve a probar la dependencia
+func respondDependencyUnavailable(ctx *gin.Context, err error) bool {
+	if !errors.Is(err, entity.ErrDependencyUnavailable) {
+		return false
+	}
+
+	var circuitOpen *resilience.CircuitOpenError
+	if errors.As(err, &circuitOpen) {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(circuitOpen.RetryAfter.Seconds()))))
+	}
+	ctx.JSON(http.StatusServiceUnavailable, gin.H{
+		"error":   "Dependency unavailable, retry later",
+		"details": err.Error(),
+	})
+	return true
+}
+
 // contains helper para verificar substring
 func contains(s, substr string) bool {
 	for i := 0; i <= len(s)-len(substr); i++ {
@@ -422,7 +654,8 @@ func contains(s, substr string) bool {
 	return false
 }
 
-// ListOrders maneja el listado de órdenes con paginación
+// ListOrders maneja el listado de órdenes con filtros, orden y paginación
+// HITO ORDER-SEARCH - Ver OrderCriteriaBuilder para los query params
 func (c *OrderController) ListOrders(ctx *gin.Context) {
 	// Verificar que el use case esté disponible
 	if c.listOrdersUC == nil {
@@ -441,28 +674,18 @@ func (c *OrderController) ListOrders(ctx *gin.Context) {
 		return
 	}
 
-	// 2. Obtener parámetros de paginación
-	page := 1
-	pageSize := 10
-
-	if pageStr := ctx.Query("page"); pageStr != "" {
-		if p, err := ctx.GetQuery("page"); err {
-			if n, parseErr := parsePageParam(p); parseErr == nil {
-				page = n
-			}
-		}
-	}
-
-	if pageSizeStr := ctx.Query("page_size"); pageSizeStr != "" {
-		if ps, err := ctx.GetQuery("page_size"); err {
-			if n, parseErr := parsePageParam(ps); parseErr == nil {
-				pageSize = n
-			}
-		}
+	// 2. Filtros, orden y paginación (HITO ORDER-SEARCH)
+	builder, err := NewOrderCriteriaBuilder(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid filter",
+			"details": err.Error(),
+		})
+		return
 	}
 
 	// 3. Ejecutar use case
-	resp, err := c.listOrdersUC.Execute(ctx.Request.Context(), tenantID, page, pageSize)
+	resp, err := c.listOrdersUC.Execute(ctx.Request.Context(), tenantID, builder.Build())
 	if err != nil {
 		log.Printf("Error listing orders: %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -529,13 +752,22 @@ func (c *OrderController) GetOrder(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, resp)
 }
 
-// parsePageParam parsea parámetros numéricos
-func parsePageParam(s string) (int, error) {
-	var n int
-	if _, err := fmt.Sscanf(s, "%d", &n); err != nil {
-		return 0, err
+// parseOrderStatuses valida los estados del filtro (valores separados por coma o repetidos)
+func parseOrderStatuses(values []string) ([]entity.OrderStatus, error) {
+	var statuses []entity.OrderStatus
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			status, err := entity.ParseOrderStatus(part)
+			if err != nil {
+				return nil, err
+			}
+			statuses = append(statuses, status)
+		}
 	}
-	return n, nil
+	return statuses, nil
 }
 
 // POSSale maneja venta directa POS sin crear orden
@@ -562,10 +794,62 @@ func (c *OrderController) POSSale(ctx *gin.Context) {
 		return
 	}
 
+	// HITO POS-PRICE: Operador y permiso de override (informados por el gateway)
+	operator := usecase.POSOperator{
+		UserID:           ctx.GetHeader("X-User-ID"),
+		CanOverridePrice: hasPermission(ctx, entity.PermissionPriceOverride),
+	}
+
 	// 4. Ejecutar use case
-	resp, err := c.posSaleUC.Execute(tenantID, authToken, &req)
+	resp, err := c.posSaleUC.Execute(ctx.Request.Context(), tenantID, authToken, operator, &req)
 	if err != nil {
 		log.Printf("Error processing POS sale: %v", err)
+		if respondDependencyUnavailable(ctx, err) {
+			return
+		}
+
+		// HITO CUSTOMER: comprador inválido o inexistente en customer-service
+		if respondCustomerError(ctx, err) {
+			return
+		}
+
+		// HITO POS-PRICE: Precio del terminal distinto al de catálogo sin permiso → 403
+		if errors.Is(err, entity.ErrPriceMismatch) {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":   "Price override not allowed",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		// HITO POS-PRICE: Precio negativo o SKU sin precio en catálogo → 422
+		if errors.Is(err, entity.ErrInvalidPrice) || errors.Is(err, entity.ErrItemPriceUnavailable) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   "Invalid item price",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		// HITO POS-CASH: Sin sesión de caja abierta (o cerrada mientras se vendía) → 409
+		if errors.Is(err, entity.ErrNoOpenCashSession) || errors.Is(err, entity.ErrCashSessionNotOpen) {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": "No open cash session for this point of sale",
+			})
+			return
+		}
+
+		// HITO POS-SPLIT: Pagos inválidos → 400
+		if errors.Is(err, entity.ErrInsufficientPayment) ||
+			errors.Is(err, entity.ErrNonCashOverpayment) ||
+			errors.Is(err, entity.ErrInvalidPaymentAmount) ||
+			errors.Is(err, entity.ErrPaymentMethodRequired) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid payments",
+				"details": err.Error(),
+			})
+			return
+		}
 
 		// Si es error de stock insuficiente → 409
 		if contains(err.Error(), "insufficient_stock") {
@@ -620,9 +904,12 @@ func (c *OrderController) ValidateStock(ctx *gin.Context) {
 	}
 
 	// 5. Ejecutar use case
-	resp, err := c.validateStockUC.Execute(tenantID, authToken, &req)
+	resp, err := c.validateStockUC.Execute(ctx.Request.Context(), tenantID, authToken, &req)
 	if err != nil {
 		log.Printf("Error validating stock: %v", err)
+		if respondDependencyUnavailable(ctx, err) {
+			return
+		}
 		ctx.JSON(http.StatusBadGateway, gin.H{
 			"error":   "Error communicating with stock service",
 			"details": err.Error(),
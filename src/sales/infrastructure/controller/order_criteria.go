@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sales/src/shared/domain/criteria"
+	sharedCriteria "sales/src/shared/infrastructure/criteria"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// orderFilterFields campos filtrables de GET /orders (HITO ORDER-SEARCH)
+// Los últimos solo se filtran con el parámetro filter (product_* = snapshot PIM de alguna línea)
+var orderFilterFields = []string{
+	"status", "order_number", "created_at", "sku", "customer_id", "customer_document",
+	"total_amount", "customer_tax_condition", "product_name", "product_category_id", "product_brand_id",
+}
+
+// orderSortFields campos por los que se puede ordenar GET /orders
+var orderSortFields = []string{"created_at", "order_number", "total_amount", "status"}
+
+// OrderCriteriaBuilder arma el criteria de GET /orders desde el query string
+// Implementa sharedCriteria.BaseCriteriaBuilder
+// HITO ORDER-SEARCH - Query params:
+//
+//	status=CONFIRMED,SHIPPED          (o status repetido)
+//	order_number_from / order_number_to
+//	created_from / created_to         (RFC3339 o YYYY-MM-DD; created_to por fecha incluye el día)
+//	sku                               (alguna línea de la orden)
+//	customer_id / customer_document
+//	filter=or(status:eq:SHIPPED,and(total_amount:gte:1000,product_brand_id:contains:<uuid>))
+//	                                  (grupos and / or / not, ver criteria.ParseFilterExpression)
+//	sort_by=created_at|order_number|total_amount|status, sort_dir=asc|desc, page, page_size
+type OrderCriteriaBuilder struct {
+	helper  *sharedCriteria.EntityCriteriaHelper
+	builder *criteria.CriteriaBuilder
+}
+
+// NewOrderCriteriaBuilder valida los query params; un valor inválido es un error (400)
+func NewOrderCriteriaBuilder(ctx *gin.Context) (*OrderCriteriaBuilder, error) {
+	helper := sharedCriteria.NewEntityCriteriaHelper()
+	b := &OrderCriteriaBuilder{
+		helper:  helper,
+		builder: helper.BuildBaseFromContext(ctx),
+	}
+	if err := b.parse(ctx); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Build construye el criteria con solo los campos permitidos
+func (b *OrderCriteriaBuilder) Build() criteria.Criteria {
+	return b.helper.ValidateAndSanitizeCriteria(b.builder.Build(), b.GetAllowedFields())
+}
+
+// GetAllowedFields retorna los campos permitidos para filtrar y ordenar
+func (b *OrderCriteriaBuilder) GetAllowedFields() []string {
+	return append(append([]string{}, orderFilterFields...), orderSortFields...)
+}
+
+// parse agrega los filtros del query string al builder
+func (b *OrderCriteriaBuilder) parse(ctx *gin.Context) error {
+	if err := validateSort(ctx, orderSortFields); err != nil {
+		return err
+	}
+
+	statuses, err := parseOrderStatuses(ctx.QueryArray("status"))
+	if err != nil {
+		return err
+	}
+	if len(statuses) > 0 {
+		values := make([]string, 0, len(statuses))
+		for _, status := range statuses {
+			values = append(values, string(status))
+		}
+		b.builder.AddFilter("status", criteria.OpIn, values)
+	}
+
+	if value := ctx.Query("order_number_from"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid order_number_from: %q", value)
+		}
+		b.builder.AddGreaterThanOrEqualFilter("order_number", n)
+	}
+	if value := ctx.Query("order_number_to"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid order_number_to: %q", value)
+		}
+		b.builder.AddLessThanOrEqualFilter("order_number", n)
+	}
+
+	if err := addTimeRangeFilters(ctx, b.builder, "created_at", "created_from", "created_to"); err != nil {
+		return err
+	}
+
+	if sku := strings.TrimSpace(ctx.Query("sku")); sku != "" {
+		b.builder.AddArrayContainsFilter("sku", sku)
+	}
+
+	if value := ctx.Query("customer_id"); value != "" {
+		customerID, err := uuid.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid customer_id: %q", value)
+		}
+		b.builder.AddEqualFilter("customer_id", customerID.String())
+	}
+	if document := strings.TrimSpace(ctx.Query("customer_document")); document != "" {
+		b.builder.AddEqualFilter("customer_document", document)
+	}
+
+	return b.helper.ApplyFilterExpressionsFromQuery(ctx, b.builder, orderFilterFields)
+}
+
+// validateSort rechaza sort_by fuera de la lista y sort_dir distinto de asc/desc
+func validateSort(ctx *gin.Context, sortFields []string) error {
+	if sortBy := ctx.Query("sort_by"); sortBy != "" {
+		allowed := false
+		for _, field := range sortFields {
+			if field == sortBy {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("invalid sort_by %q: must be one of %s", sortBy, strings.Join(sortFields, ", "))
+		}
+	}
+	if sortDir := ctx.Query("sort_dir"); sortDir != "" {
+		switch strings.ToLower(sortDir) {
+		case string(criteria.ASC), string(criteria.DESC):
+		default:
+			return fmt.Errorf("invalid sort_dir %q: must be asc or desc", sortDir)
+		}
+	}
+	return nil
+}
+
+// addTimeRangeFilters agrega field >= from y field <= to (o < día siguiente si to es una fecha)
+func addTimeRangeFilters(ctx *gin.Context, builder *criteria.CriteriaBuilder, field, fromParam, toParam string) error {
+	if value := ctx.Query(fromParam); value != "" {
+		from, _, err := parseTimeParam(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %q (use RFC3339 or YYYY-MM-DD)", fromParam, value)
+		}
+		builder.AddGreaterThanOrEqualFilter(field, from)
+	}
+	if value := ctx.Query(toParam); value != "" {
+		to, dateOnly, err := parseTimeParam(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %q (use RFC3339 or YYYY-MM-DD)", toParam, value)
+		}
+		if dateOnly {
+			builder.AddLessThanFilter(field, to.AddDate(0, 0, 1))
+		} else {
+			builder.AddLessThanOrEqualFilter(field, to)
+		}
+	}
+	return nil
+}
+
+// parseTimeParam acepta RFC3339 o YYYY-MM-DD (UTC); dateOnly indica el segundo formato
+func parseTimeParam(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
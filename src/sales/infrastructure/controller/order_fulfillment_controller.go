@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/service"
+	"sales/src/sales/application/usecase"
+	"sales/src/sales/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderFulfillmentController maneja la preparación y el envío de órdenes
+// HITO ORDER-FULFILLMENT - Transiciones de estado, historial y envíos
+type OrderFulfillmentController struct {
+	transitionUC     *usecase.TransitionOrderStatusUseCase
+	historyUC        *usecase.GetOrderStatusHistoryUseCase
+	createShipmentUC *usecase.CreateShipmentUseCase
+	listShipmentsUC  *usecase.ListOrderShipmentsUseCase
+	idempotency      *service.IdempotencyService // HITO IDEMPOTENCY
+}
+
+// NewOrderFulfillmentController crea una nueva instancia del controlador
+func NewOrderFulfillmentController(
+	transitionUC *usecase.TransitionOrderStatusUseCase,
+	historyUC *usecase.GetOrderStatusHistoryUseCase,
+	createShipmentUC *usecase.CreateShipmentUseCase,
+	listShipmentsUC *usecase.ListOrderShipmentsUseCase,
+	idempotency *service.IdempotencyService,
+) *OrderFulfillmentController {
+	return &OrderFulfillmentController{
+		transitionUC:     transitionUC,
+		historyUC:        historyUC,
+		createShipmentUC: createShipmentUC,
+		listShipmentsUC:  listShipmentsUC,
+		idempotency:      idempotency,
+	}
+}
+
+// RegisterRoutes registra las rutas del controlador
+func (c *OrderFulfillmentController) RegisterRoutes(router *gin.RouterGroup) {
+	idempotent := idempotencyMiddleware(c.idempotency)
+
+	orders := router.Group("/orders")
+	{
+		orders.POST("/:order_id/status", idempotent, c.TransitionStatus)
+		orders.GET("/:order_id/history", c.GetStatusHistory)
+		orders.POST("/:order_id/shipments", idempotent, c.CreateShipment)
+		orders.GET("/:order_id/shipments", c.ListShipments)
+	}
+
+	log.Println("Rutas Order Fulfillment disponibles:")
+	log.Println("  POST   /api/v1/orders/:order_id/status     (PICKING / PACKED / DELIVERED / RETURNED)")
+	log.Println("  GET    /api/v1/orders/:order_id/history")
+	log.Println("  POST   /api/v1/orders/:order_id/shipments  (PACKED → SHIPPED)")
+	log.Println("  GET    /api/v1/orders/:order_id/shipments")
+}
+
+// TransitionStatus avanza la orden en el ciclo de preparación
+func (c *OrderFulfillmentController) TransitionStatus(ctx *gin.Context) {
+	if c.transitionUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Order fulfillment not available (database not configured)",
+		})
+		return
+	}
+
+	tenantID, ok := tenantIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.TransitionOrderStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.transitionUC.Execute(ctx.Request.Context(), tenantID, ctx.GetHeader("X-User-ID"), ctx.Param("order_id"), &req)
+	if err != nil {
+		log.Printf("Error transitioning order: %v", err)
+		c.handleError(ctx, err, "Error transitioning order")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GetStatusHistory retorna el historial de estados de la orden
+func (c *OrderFulfillmentController) GetStatusHistory(ctx *gin.Context) {
+	if c.historyUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Order fulfillment not available (database not configured)",
+		})
+		return
+	}
+
+	tenantID, ok := tenantIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := c.historyUC.Execute(ctx.Request.Context(), tenantID, ctx.Param("order_id"))
+	if err != nil {
+		log.Printf("Error getting order history: %v", err)
+		c.handleError(ctx, err, "Error getting order history")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// CreateShipment registra un envío de la orden
+func (c *OrderFulfillmentController) CreateShipment(ctx *gin.Context) {
+	if c.createShipmentUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Order fulfillment not available (database not configured)",
+		})
+		return
+	}
+
+	tenantID, ok := tenantIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.CreateShipmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.createShipmentUC.Execute(ctx.Request.Context(), tenantID, ctx.GetHeader("X-User-ID"), ctx.Param("order_id"), &req)
+	if err != nil {
+		log.Printf("Error creating shipment: %v", err)
+		c.handleError(ctx, err, "Error creating shipment")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// ListShipments lista los envíos de la orden
+func (c *OrderFulfillmentController) ListShipments(ctx *gin.Context) {
+	if c.listShipmentsUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Order fulfillment not available (database not configured)",
+		})
+		return
+	}
+
+	tenantID, ok := tenantIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := c.listShipmentsUC.Execute(ctx.Request.Context(), tenantID, ctx.Param("order_id"))
+	if err != nil {
+		log.Printf("Error listing shipments: %v", err)
+		c.handleError(ctx, err, "Error listing shipments")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// handleError mapea errores de dominio a códigos HTTP
+// Una transición inválida informa el estado actual y el pedido
+func (c *OrderFulfillmentController) handleError(ctx *gin.Context, err error, message string) {
+	var transition *entity.InvalidOrderTransitionError
+	switch {
+	case errors.As(err, &transition):
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"from":  transition.From,
+			"to":    transition.To,
+		})
+	case errors.Is(err, entity.ErrOrderNotFound), errors.Is(err, entity.ErrOrderItemNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrInvalidOrderStatus),
+		errors.Is(err, entity.ErrOrderStatusNotSettable),
+		errors.Is(err, entity.ErrShipmentCarrierRequired),
+		errors.Is(err, entity.ErrShipmentMustHaveItems),
+		errors.Is(err, entity.ErrInvalidShipmentQuantity):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, entity.ErrOrderNotShippable), errors.Is(err, entity.ErrOrderNotFullyShipped):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
+
+// tenantIDFromHeader lee X-Tenant-ID (obligatorio); responde 400 si falta
+func tenantIDFromHeader(ctx *gin.Context) (string, bool) {
+	tenantID := ctx.GetHeader("X-Tenant-ID")
+	if tenantID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "X-Tenant-ID header is required"})
+		return "", false
+	}
+	return tenantID, true
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/service"
+	"sales/src/sales/application/usecase"
+	"sales/src/sales/domain/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PosRefundController maneja anulaciones y devoluciones de ventas POS
+// HITO POS-REFUND - Void total / devolución por línea
+type PosRefundController struct {
+	refundPosSaleUC *usecase.RefundPosSaleUseCase
+	idempotency     *service.IdempotencyService // HITO IDEMPOTENCY
+}
+
+// NewPosRefundController crea una nueva instancia del controlador
+func NewPosRefundController(refundPosSaleUC *usecase.RefundPosSaleUseCase, idempotency *service.IdempotencyService) *PosRefundController {
+	return &PosRefundController{
+		refundPosSaleUC: refundPosSaleUC,
+		idempotency:     idempotency,
+	}
+}
+
+// RegisterRoutes registra las rutas del controlador
+// HITO IDEMPOTENCY: void / refund aceptan Idempotency-Key (compensan stock)
+func (c *PosRefundController) RegisterRoutes(router *gin.RouterGroup) {
+	idempotent := idempotencyMiddleware(c.idempotency)
+
+	sales := router.Group("/pos/sales")
+	{
+		sales.POST("/:pos_sale_id/void", idempotent, c.VoidPosSale)
+		sales.POST("/:pos_sale_id/refund", idempotent, c.RefundPosSale)
+	}
+
+	log.Println("Rutas POS Refund disponibles:")
+	log.Println("  POST   /api/v1/pos/sales/:pos_sale_id/void    (anulación total)")
+	log.Println("  POST   /api/v1/pos/sales/:pos_sale_id/refund  (devolución por línea)")
+}
+
+// VoidPosSale anula una venta POS completa y compensa el stock de todas sus líneas
+func (c *PosRefundController) VoidPosSale(ctx *gin.Context) {
+	if c.refundPosSaleUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "POS refunds not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	posSaleID, err := uuid.Parse(ctx.Param("pos_sale_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pos_sale_id format"})
+		return
+	}
+
+	var req request.VoidPosSaleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.refundPosSaleUC.ExecuteVoid(
+		ctx.Request.Context(),
+		tenantUUID,
+		ctx.GetHeader("Authorization"),
+		ctx.GetHeader("X-User-ID"),
+		posSaleID,
+		&req,
+	)
+	if err != nil {
+		log.Printf("Error voiding POS sale: %v", err)
+		c.handleError(ctx, err, "Error voiding POS sale")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// RefundPosSale devuelve líneas de una venta POS y compensa su stock
+func (c *PosRefundController) RefundPosSale(ctx *gin.Context) {
+	if c.refundPosSaleUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "POS refunds not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	posSaleID, err := uuid.Parse(ctx.Param("pos_sale_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pos_sale_id format"})
+		return
+	}
+
+	var req request.RefundPosSaleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.refundPosSaleUC.ExecuteRefund(
+		ctx.Request.Context(),
+		tenantUUID,
+		ctx.GetHeader("Authorization"),
+		ctx.GetHeader("X-User-ID"),
+		posSaleID,
+		&req,
+	)
+	if err != nil {
+		log.Printf("Error refunding POS sale: %v", err)
+		c.handleError(ctx, err, "Error refunding POS sale")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// handleError mapea errores de dominio a códigos HTTP
+func (c *PosRefundController) handleError(ctx *gin.Context, err error, message string) {
+	switch err {
+	case entity.ErrPosSaleNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case entity.ErrPosSaleAlreadyVoided, entity.ErrPosSaleItemAlreadyRefunded, entity.ErrPosSaleRefundConflict,
+		entity.ErrCashSessionNotOpen: // HITO POS-CASH: la caja se cerró mientras se devolvía
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case entity.ErrPosSaleItemNotFound, entity.ErrRefundMustHaveItems, entity.ErrRefundReasonRequired,
+		entity.ErrRefundPartialQuantity:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"sales/src/shared/domain/criteria"
+	sharedCriteria "sales/src/shared/infrastructure/criteria"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// posSaleFilterFields campos filtrables de GET /pos/sales (HITO POS-SEARCH)
+// Los últimos solo se filtran con el parámetro filter
+var posSaleFilterFields = []string{
+	"created_at", "payment_method_id", "customer_id", "customer_document", "final_amount", "sku",
+	"status", "point_of_sale_id", "customer_tax_condition",
+}
+
+// PosSaleCriteriaBuilder arma el criteria de GET /pos/sales desde el query string
+// Implementa sharedCriteria.BaseCriteriaBuilder
+// HITO POS-SEARCH - Query params:
+//
+//	created_from / created_to         (RFC3339 o YYYY-MM-DD; created_to por fecha incluye el día)
+//	payment_method_id                 (algún pago de la venta)
+//	customer_id / customer_document
+//	amount_min / amount_max           (sobre final_amount)
+//	sku                               (alguna línea de la venta)
+//	filter=or(status:eq:REFUNDED,final_amount:gte:50000)
+//	                                  (grupos and / or / not, ver criteria.ParseFilterExpression)
+//	page_size, cursor=<next_cursor o prev_cursor>, summary=true
+//
+// El orden es fijo (created_at DESC, id DESC): sort_by y page no aplican
+type PosSaleCriteriaBuilder struct {
+	helper  *sharedCriteria.EntityCriteriaHelper
+	builder *criteria.CriteriaBuilder
+}
+
+// NewPosSaleCriteriaBuilder valida los query params; un valor inválido es un error (400)
+func NewPosSaleCriteriaBuilder(ctx *gin.Context) (*PosSaleCriteriaBuilder, error) {
+	helper := sharedCriteria.NewEntityCriteriaHelper()
+	b := &PosSaleCriteriaBuilder{
+		helper:  helper,
+		builder: helper.BuildBaseFromContext(ctx),
+	}
+	if err := b.parse(ctx); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Build construye el criteria con solo los campos permitidos
+func (b *PosSaleCriteriaBuilder) Build() criteria.Criteria {
+	return b.helper.ValidateAndSanitizeCriteria(b.builder.Build(), b.GetAllowedFields())
+}
+
+// GetAllowedFields retorna los campos permitidos para filtrar
+func (b *PosSaleCriteriaBuilder) GetAllowedFields() []string {
+	return append([]string{}, posSaleFilterFields...)
+}
+
+// parse agrega los filtros del query string al builder
+func (b *PosSaleCriteriaBuilder) parse(ctx *gin.Context) error {
+	// Orden fijo y paginación por cursor (criteria.Cursor)
+	b.builder.SetOrder("created_at", criteria.DESC)
+	if err := b.helper.ApplyCursorFromQuery(ctx, b.builder); err != nil {
+		return err
+	}
+
+	if err := addTimeRangeFilters(ctx, b.builder, "created_at", "created_from", "created_to"); err != nil {
+		return err
+	}
+
+	if value := ctx.Query("payment_method_id"); value != "" {
+		paymentMethodID, err := uuid.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid payment_method_id: %q", value)
+		}
+		b.builder.AddArrayContainsFilter("payment_method_id", paymentMethodID.String())
+	}
+
+	if value := ctx.Query("customer_id"); value != "" {
+		customerID, err := uuid.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid customer_id: %q", value)
+		}
+		b.builder.AddEqualFilter("customer_id", customerID.String())
+	}
+	if document := strings.TrimSpace(ctx.Query("customer_document")); document != "" {
+		b.builder.AddEqualFilter("customer_document", document)
+	}
+
+	if value := ctx.Query("amount_min"); value != "" {
+		amount, err := decimal.NewFromString(value)
+		if err != nil || amount.IsNegative() {
+			return fmt.Errorf("invalid amount_min: %q", value)
+		}
+		b.builder.AddGreaterThanOrEqualFilter("final_amount", amount)
+	}
+	if value := ctx.Query("amount_max"); value != "" {
+		amount, err := decimal.NewFromString(value)
+		if err != nil || amount.IsNegative() {
+			return fmt.Errorf("invalid amount_max: %q", value)
+		}
+		b.builder.AddLessThanOrEqualFilter("final_amount", amount)
+	}
+
+	if sku := strings.TrimSpace(ctx.Query("sku")); sku != "" {
+		b.builder.AddArrayContainsFilter("sku", sku)
+	}
+
+	return b.helper.ApplyFilterExpressionsFromQuery(ctx, b.builder, posSaleFilterFields)
+}
@@ -1,10 +1,12 @@
 package controller
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
 	"sales/src/sales/application/usecase"
+	"sales/src/sales/domain/entity"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -12,14 +14,17 @@ import (
 
 // ReportController maneja las peticiones HTTP para reportes
 // HITO C - Reportes Diarios
+// HITO SALES-REPORT - Reporte de ventas por rango de fechas
 type ReportController struct {
 	dailyReportUC *usecase.DailyReportUseCase
+	salesReportUC *usecase.SalesReportUseCase
 }
 
 // NewReportController crea una nueva instancia del controlador
-func NewReportController(dailyReportUC *usecase.DailyReportUseCase) *ReportController {
+func NewReportController(dailyReportUC *usecase.DailyReportUseCase, salesReportUC *usecase.SalesReportUseCase) *ReportController {
 	return &ReportController{
 		dailyReportUC: dailyReportUC,
+		salesReportUC: salesReportUC,
 	}
 }
 
@@ -28,10 +33,12 @@ func (c *ReportController) RegisterRoutes(router *gin.RouterGroup) {
 	reports := router.Group("/reports")
 	{
 		reports.GET("/daily", c.DailyReport)
+		reports.GET("/sales", c.SalesReport)
 	}
 
 	log.Println("Rutas Report disponibles:")
 	log.Println("  GET    /api/v1/reports/daily?date=YYYY-MM-DD")
+	log.Println("  GET    /api/v1/reports/sales?from=YYYY-MM-DD&to=YYYY-MM-DD&group_by=day|week|month|hour|payment_method|point_of_sale")
 }
 
 // DailyReport maneja el reporte diario de ventas
@@ -98,3 +105,46 @@ func (c *ReportController) DailyReport(ctx *gin.Context) {
 	// ========================================================================
 	ctx.JSON(http.StatusOK, resp)
 }
+
+// SalesReport maneja el reporte de ventas (POS + órdenes) de un rango de fechas
+// HITO SALES-REPORT - from / to obligatorios (YYYY-MM-DD, ambos incluidos), group_by opcional (day)
+func (c *ReportController) SalesReport(ctx *gin.Context) {
+	// ========================================================================
+	// PASO 1: Validar header X-Tenant-ID (OBLIGATORIO)
+	// ========================================================================
+	tenantUUID, err := uuid.Parse(ctx.GetHeader("X-Tenant-ID"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "X-Tenant-ID header is required (UUID)",
+		})
+		return
+	}
+
+	// ========================================================================
+	// PASO 2: Ejecutar use case (valida from, to y group_by)
+	// ========================================================================
+	resp, err := c.salesReportUC.Execute(ctx.Request.Context(), tenantUUID, ctx.Query("from"), ctx.Query("to"), ctx.Query("group_by"))
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidReportDate) ||
+			errors.Is(err, entity.ErrInvalidReportRange) ||
+			errors.Is(err, entity.ErrInvalidReportGroupBy) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid report parameters",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		log.Printf("Error generating sales report: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Error generating sales report",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// ========================================================================
+	// PASO 3: Responder exitosamente
+	// ========================================================================
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/usecase"
+	"sales/src/sales/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SequenceController maneja la administración de secuencias de numeración
+// HITO SEQ-TX - Inspección y seed de document_sequences por tenant
+type SequenceController struct {
+	listSequencesUC *usecase.ListSequencesUseCase
+	seedSequenceUC  *usecase.SeedSequenceUseCase
+}
+
+// NewSequenceController crea una nueva instancia del controlador
+func NewSequenceController(
+	listSequencesUC *usecase.ListSequencesUseCase,
+	seedSequenceUC *usecase.SeedSequenceUseCase,
+) *SequenceController {
+	return &SequenceController{
+		listSequencesUC: listSequencesUC,
+		seedSequenceUC:  seedSequenceUC,
+	}
+}
+
+// RegisterRoutes registra las rutas del controlador
+func (c *SequenceController) RegisterRoutes(router *gin.RouterGroup) {
+	sequences := router.Group("/admin/sequences")
+	{
+		sequences.GET("", c.ListSequences)
+		sequences.PUT("", c.SeedSequence)
+	}
+
+	log.Println("Rutas Sequence Admin disponibles:")
+	log.Println("  GET    /api/v1/admin/sequences?document_type=...")
+	log.Println("  PUT    /api/v1/admin/sequences  (seed)")
+}
+
+// ListSequences lista las secuencias del tenant
+func (c *SequenceController) ListSequences(ctx *gin.Context) {
+	if c.listSequencesUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Sequences not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := c.listSequencesUC.Execute(ctx.Request.Context(), tenantUUID, ctx.Query("document_type"))
+	if err != nil {
+		log.Printf("Error listing sequences: %v", err)
+		c.handleError(ctx, err, "Error listing sequences")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// SeedSequence crea o ajusta una secuencia del tenant
+func (c *SequenceController) SeedSequence(ctx *gin.Context) {
+	if c.seedSequenceUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Sequences not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.SeedSequenceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.seedSequenceUC.Execute(ctx.Request.Context(), tenantUUID, &req)
+	if err != nil {
+		log.Printf("Error seeding sequence: %v", err)
+		c.handleError(ctx, err, "Error seeding sequence")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// handleError mapea errores de dominio a códigos HTTP
+func (c *SequenceController) handleError(ctx *gin.Context, err error, message string) {
+	switch err {
+	case entity.ErrInvalidDocumentType, entity.ErrInvalidSequenceNumber:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case entity.ErrSequenceRewind:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"sales/src/sales/application/usecase"
+	"sales/src/sales/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StockSagaController expone el log de sagas de stock para operación
+// HITO STOCK-SAGA - Sagas trabadas (compensación pendiente o escalada)
+type StockSagaController struct {
+	listStuckSagasUC *usecase.ListStuckSagasUseCase
+}
+
+// NewStockSagaController crea una nueva instancia del controlador
+func NewStockSagaController(listStuckSagasUC *usecase.ListStuckSagasUseCase) *StockSagaController {
+	return &StockSagaController{
+		listStuckSagasUC: listStuckSagasUC,
+	}
+}
+
+// RegisterRoutes registra las rutas del controlador
+func (c *StockSagaController) RegisterRoutes(router *gin.RouterGroup) {
+	sagas := router.Group("/admin/sagas")
+	{
+		sagas.GET("", c.ListStuckSagas)
+	}
+
+	log.Println("Rutas Stock Saga Admin disponibles:")
+	log.Println("  GET    /api/v1/admin/sagas?status=STARTED|COMPENSATING|ESCALATED&tenant_id=...")
+}
+
+// ListStuckSagas lista las sagas de stock que no terminaron
+// Sin tenant_id lista las de todos los tenants (vista de operación)
+func (c *StockSagaController) ListStuckSagas(ctx *gin.Context) {
+	if c.listStuckSagasUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Stock sagas not available (database not configured)",
+		})
+		return
+	}
+
+	resp, err := c.listStuckSagasUC.Execute(ctx.Request.Context(), ctx.Query("tenant_id"), ctx.Query("status"))
+	if err != nil {
+		log.Printf("Error listing stuck sagas: %v", err)
+		c.handleError(ctx, err, "Error listing stuck sagas")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// handleError mapea errores de dominio a códigos HTTP
+func (c *StockSagaController) handleError(ctx *gin.Context, err error, message string) {
+	switch err {
+	case entity.ErrInvalidStockSagaStatus:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"log"
+	"net/http"
+
+	"sales/src/sales/application/request"
+	"sales/src/sales/application/usecase"
+	"sales/src/sales/domain/entity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TaxController maneja la configuración de IVA del tenant
+// HITO TAX-IVA - Modo de precios, alícuota por defecto y reglas por SKU / categoría
+type TaxController struct {
+	taxConfigUC *usecase.TaxConfigUseCase
+}
+
+// NewTaxController crea una nueva instancia del controlador
+func NewTaxController(taxConfigUC *usecase.TaxConfigUseCase) *TaxController {
+	return &TaxController{
+		taxConfigUC: taxConfigUC,
+	}
+}
+
+// RegisterRoutes registra las rutas del controlador
+func (c *TaxController) RegisterRoutes(router *gin.RouterGroup) {
+	tax := router.Group("/admin/tax")
+	{
+		tax.GET("/settings", c.GetSettings)
+		tax.PUT("/settings", c.SaveSettings)
+		tax.GET("/rules", c.ListRules)
+		tax.PUT("/rules", c.SaveRule)
+		tax.DELETE("/rules/:rule_id", c.DeleteRule)
+	}
+
+	log.Println("Rutas Tax Admin disponibles:")
+	log.Println("  GET    /api/v1/admin/tax/settings")
+	log.Println("  PUT    /api/v1/admin/tax/settings")
+	log.Println("  GET    /api/v1/admin/tax/rules")
+	log.Println("  PUT    /api/v1/admin/tax/rules           (alícuota por SKU / categoría)")
+	log.Println("  DELETE /api/v1/admin/tax/rules/:rule_id")
+}
+
+// GetSettings retorna la configuración de IVA del tenant
+func (c *TaxController) GetSettings(ctx *gin.Context) {
+	if c.taxConfigUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Tax configuration not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := c.taxConfigUC.GetSettings(ctx.Request.Context(), tenantUUID)
+	if err != nil {
+		log.Printf("Error getting tax settings: %v", err)
+		c.handleError(ctx, err, "Error getting tax settings")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// SaveSettings crea o reemplaza la configuración de IVA del tenant
+func (c *TaxController) SaveSettings(ctx *gin.Context) {
+	if c.taxConfigUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Tax configuration not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.SaveTaxSettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.taxConfigUC.SaveSettings(ctx.Request.Context(), tenantUUID, &req)
+	if err != nil {
+		log.Printf("Error saving tax settings: %v", err)
+		c.handleError(ctx, err, "Error saving tax settings")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// ListRules lista las reglas de alícuota del tenant
+func (c *TaxController) ListRules(ctx *gin.Context) {
+	if c.taxConfigUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Tax configuration not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := c.taxConfigUC.ListRules(ctx.Request.Context(), tenantUUID)
+	if err != nil {
+		log.Printf("Error listing tax rules: %v", err)
+		c.handleError(ctx, err, "Error listing tax rules")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// SaveRule asigna una alícuota a un SKU o categoría
+func (c *TaxController) SaveRule(ctx *gin.Context) {
+	if c.taxConfigUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Tax configuration not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	var req request.SaveTaxRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	resp, err := c.taxConfigUC.SaveRule(ctx.Request.Context(), tenantUUID, &req)
+	if err != nil {
+		log.Printf("Error saving tax rule: %v", err)
+		c.handleError(ctx, err, "Error saving tax rule")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// DeleteRule elimina una regla de alícuota
+func (c *TaxController) DeleteRule(ctx *gin.Context) {
+	if c.taxConfigUC == nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Tax configuration not available (database not configured)",
+		})
+		return
+	}
+
+	tenantUUID, ok := tenantUUIDFromHeader(ctx)
+	if !ok {
+		return
+	}
+
+	ruleID, err := uuid.Parse(ctx.Param("rule_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule_id format"})
+		return
+	}
+
+	if err := c.taxConfigUC.DeleteRule(ctx.Request.Context(), tenantUUID, ruleID); err != nil {
+		log.Printf("Error deleting tax rule: %v", err)
+		c.handleError(ctx, err, "Error deleting tax rule")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// handleError mapea errores de dominio a códigos HTTP
+func (c *TaxController) handleError(ctx *gin.Context, err error, message string) {
+	switch err {
+	case entity.ErrTaxRuleNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case entity.ErrInvalidTaxRate, entity.ErrInvalidTaxPriceMode, entity.ErrInvalidTaxRuleScope,
+		entity.ErrTaxRuleValueRequired:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+	}
+}
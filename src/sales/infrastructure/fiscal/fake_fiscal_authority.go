@@ -0,0 +1,130 @@
+package fiscal
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// FakeFiscalAuthority implementación local de FiscalAuthority (desarrollo / tests)
+// HITO FISCAL - Simula WSFE: numeración correlativa por emisor + punto de venta + letra,
+// CAE de 14 dígitos con vencimiento a 10 días e idempotencia por Reference
+type FakeFiscalAuthority struct {
+	mu           sync.Mutex
+	lastNumbers  map[string]int
+	authorized   map[uuid.UUID]*port.FiscalAuthorizationResult
+	failuresLeft int
+	rejectFn     func(req port.FiscalAuthorizationRequest) string
+}
+
+// NewFakeFiscalAuthority crea una nueva instancia
+func NewFakeFiscalAuthority() *FakeFiscalAuthority {
+	return &FakeFiscalAuthority{
+		lastNumbers: make(map[string]int),
+		authorized:  make(map[uuid.UUID]*port.FiscalAuthorizationResult),
+	}
+}
+
+// FailNext hace que las próximas n llamadas fallen con un error transitorio
+func (f *FakeFiscalAuthority) FailNext(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failuresLeft = n
+}
+
+// RejectWhen registra una regla de rechazo adicional (motivo vacío = no rechaza)
+func (f *FakeFiscalAuthority) RejectWhen(fn func(req port.FiscalAuthorizationRequest) string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rejectFn = fn
+}
+
+// LastAuthorizedNumber retorna el último número autorizado
+func (f *FakeFiscalAuthority) LastAuthorizedNumber(ctx context.Context, issuerCUIT string, pointOfSaleNumber int, invoiceType entity.InvoiceType) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.transientFailure(ctx); err != nil {
+		return 0, err
+	}
+	return f.lastNumbers[numberKey(issuerCUIT, pointOfSaleNumber, invoiceType)], nil
+}
+
+// Authorize valida el comprobante y otorga CAE
+func (f *FakeFiscalAuthority) Authorize(ctx context.Context, req port.FiscalAuthorizationRequest) (*port.FiscalAuthorizationResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.transientFailure(ctx); err != nil {
+		return nil, err
+	}
+
+	// Idempotencia: el comprobante ya fue autorizado
+	if result, ok := f.authorized[req.Reference]; ok {
+		copied := *result
+		return &copied, nil
+	}
+
+	if reason := f.validate(req); reason != "" {
+		return &port.FiscalAuthorizationResult{Approved: false, RejectionReason: reason}, nil
+	}
+
+	key := numberKey(req.IssuerCUIT, req.PointOfSaleNumber, req.InvoiceType)
+	if expected := f.lastNumbers[key] + 1; req.InvoiceNumber != expected {
+		// Otro proceso autorizó en el medio: transitorio, se reintenta con el número nuevo
+		return nil, fmt.Errorf("%w: invoice number %d out of sequence (expected %d)",
+			entity.ErrFiscalAuthorityUnavailable, req.InvoiceNumber, expected)
+	}
+
+	f.lastNumbers[key] = req.InvoiceNumber
+	result := &port.FiscalAuthorizationResult{
+		Approved:      true,
+		InvoiceNumber: req.InvoiceNumber,
+		CAE:           fmt.Sprintf("%014d", rand.Int63n(1e14)),
+		CAEExpiresAt:  req.IssueDate.AddDate(0, 0, 10),
+	}
+	f.authorized[req.Reference] = result
+
+	copied := *result
+	return &copied, nil
+}
+
+// validate reglas mínimas del fisco (retorna el motivo de rechazo)
+func (f *FakeFiscalAuthority) validate(req port.FiscalAuthorizationRequest) string {
+	if !entity.IsValidCUIT(req.IssuerCUIT) {
+		return "issuer CUIT is invalid"
+	}
+	if req.InvoiceType == entity.InvoiceTypeA && !entity.IsValidCUIT(req.CustomerTaxID) {
+		return "invoice type A requires customer CUIT"
+	}
+	if !req.NetAmount.Add(req.TaxAmount).Equal(req.TotalAmount) {
+		return "net_amount + tax_amount must equal total_amount"
+	}
+	if f.rejectFn != nil {
+		return f.rejectFn(req)
+	}
+	return ""
+}
+
+// transientFailure consume una falla inyectada o respeta la cancelación del contexto
+func (f *FakeFiscalAuthority) transientFailure(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %v", entity.ErrFiscalAuthorityUnavailable, err)
+	}
+	if f.failuresLeft > 0 {
+		f.failuresLeft--
+		return fmt.Errorf("%w: simulated outage", entity.ErrFiscalAuthorityUnavailable)
+	}
+	return nil
+}
+
+// numberKey clave de la numeración (emisor + punto de venta + letra)
+func numberKey(issuerCUIT string, pointOfSaleNumber int, invoiceType entity.InvoiceType) string {
+	return fmt.Sprintf("%s|%d|%s", issuerCUIT, pointOfSaleNumber, invoiceType)
+}
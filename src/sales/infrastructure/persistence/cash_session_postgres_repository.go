@@ -0,0 +1,314 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/shared/infrastructure/database"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// CashSessionPostgresRepository implementa CashSessionRepository usando PostgreSQL
+// HITO POS-CASH - Apertura / cierre de caja con arqueo
+type CashSessionPostgresRepository struct {
+	db *sql.DB
+}
+
+// NewCashSessionPostgresRepository crea una nueva instancia del repositorio
+func NewCashSessionPostgresRepository(db *sql.DB) port.CashSessionRepository {
+	return &CashSessionPostgresRepository{
+		db: db,
+	}
+}
+
+// Create persiste una nueva sesión abierta
+// El índice parcial uq_cash_sessions_open_pos garantiza una sola sesión OPEN por punto de venta
+func (r *CashSessionPostgresRepository) Create(ctx context.Context, session *entity.CashSession) error {
+	query := `
+		INSERT INTO cash_sessions (
+			id, tenant_id, point_of_sale_id, status,
+			opening_float, opened_by, opened_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		session.ID,
+		session.TenantID,
+		session.PointOfSaleID,
+		session.Status,
+		session.OpeningFloat,
+		session.OpenedBy,
+		session.OpenedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return entity.ErrCashSessionAlreadyOpen
+		}
+		return fmt.Errorf("error creating cash_session: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID retorna una sesión con sus líneas de arqueo
+func (r *CashSessionPostgresRepository) FindByID(ctx context.Context, tenantID, sessionID uuid.UUID) (*entity.CashSession, error) {
+	query := `
+		SELECT
+			id, tenant_id, point_of_sale_id, status,
+			opening_float, opened_by, opened_at,
+			closed_by, closed_at, notes
+		FROM cash_sessions
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	session, err := r.scanSession(r.db.QueryRowContext(ctx, query, sessionID, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrCashSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding cash_session: %w", err)
+	}
+
+	// Cargar arqueo (solo existe si la sesión está cerrada)
+	queryCounts := `
+		SELECT payment_method_id, counted_amount, expected_amount, difference
+		FROM cash_session_counts
+		WHERE cash_session_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, queryCounts, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying cash_session_counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var count entity.CashCount
+		if err := rows.Scan(
+			&count.PaymentMethodID,
+			&count.CountedAmount,
+			&count.ExpectedAmount,
+			&count.Difference,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning cash_session_count: %w", err)
+		}
+		session.Counts = append(session.Counts, count)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cash_session_counts: %w", err)
+	}
+
+	return session, nil
+}
+
+// FindOpenByPointOfSale retorna la sesión abierta de un punto de venta
+func (r *CashSessionPostgresRepository) FindOpenByPointOfSale(ctx context.Context, tenantID, pointOfSaleID uuid.UUID) (*entity.CashSession, error) {
+	query := `
+		SELECT
+			id, tenant_id, point_of_sale_id, status,
+			opening_float, opened_by, opened_at,
+			closed_by, closed_at, notes
+		FROM cash_sessions
+		WHERE tenant_id = $1 AND point_of_sale_id = $2 AND status = 'OPEN'
+	`
+
+	session, err := r.scanSession(r.db.QueryRowContext(ctx, query, tenantID, pointOfSaleID))
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrNoOpenCashSession
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding open cash_session: %w", err)
+	}
+
+	return session, nil
+}
+
+// FindByIDForUpdate bloquea la fila de la sesión (FOR UPDATE) en la transacción del contexto
+// Espera a las ventas y devoluciones en curso de la sesión (la toman FOR SHARE al persistir)
+// y hace esperar a las que lleguen hasta el commit del cierre
+func (r *CashSessionPostgresRepository) FindByIDForUpdate(ctx context.Context, tenantID, sessionID uuid.UUID) (*entity.CashSession, error) {
+	query := `
+		SELECT
+			id, tenant_id, point_of_sale_id, status,
+			opening_float, opened_by, opened_at,
+			closed_by, closed_at, notes
+		FROM cash_sessions
+		WHERE id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`
+
+	session, err := r.scanSession(database.Executor(ctx, r.db).QueryRowContext(ctx, query, sessionID, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrCashSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error locking cash_session: %w", err)
+	}
+
+	return session, nil
+}
+
+// Close persiste el cierre de la sesión y sus líneas de arqueo (atomically)
+// Se suma a la transacción del contexto (lock + totales + cierre)
+func (r *CashSessionPostgresRepository) Close(ctx context.Context, session *entity.CashSession) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		return r.close(ctx, tx, session)
+	})
+}
+
+// close actualiza la sesión e inserta el arqueo dentro de tx
+func (r *CashSessionPostgresRepository) close(ctx context.Context, tx *sql.Tx, session *entity.CashSession) error {
+	// 1. Cerrar sesión (solo si sigue abierta)
+	querySession := `
+		UPDATE cash_sessions
+		SET status = $1, closed_by = $2, closed_at = $3, notes = $4
+		WHERE id = $5 AND tenant_id = $6 AND status = 'OPEN'
+	`
+
+	result, err := tx.ExecContext(ctx, querySession,
+		session.Status,
+		session.ClosedBy,
+		session.ClosedAt,
+		session.Notes,
+		session.ID,
+		session.TenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("error closing cash_session: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return entity.ErrCashSessionNotOpen
+	}
+
+	// 2. Insertar líneas de arqueo
+	queryCount := `
+		INSERT INTO cash_session_counts (
+			cash_session_id, payment_method_id,
+			counted_amount, expected_amount, difference
+		) VALUES (
+			$1, $2, $3, $4, $5
+		)
+	`
+
+	for _, count := range session.Counts {
+		_, err = tx.ExecContext(ctx, queryCount,
+			session.ID,
+			count.PaymentMethodID,
+			count.CountedAmount,
+			count.ExpectedAmount,
+			count.Difference,
+		)
+		if err != nil {
+			return fmt.Errorf("error creating cash_session_count for %s: %w", count.PaymentMethodID, err)
+		}
+	}
+
+	return nil
+}
+
+// SalesTotalsByPaymentMethod agrega las pos_sales de la sesión por método de pago
+// HITO POS-SPLIT - Agrega sobre pos_sale_payments (una venta puede aportar a varios métodos)
+// HITO POS-REFUND - Las devoluciones pagadas desde la sesión se restan del método devuelto
+// final_amount = monto neto cobrado (amount - vuelto - devoluciones)
+// Se suma a la transacción del contexto: en el cierre se lee con la sesión bloqueada
+func (r *CashSessionPostgresRepository) SalesTotalsByPaymentMethod(ctx context.Context, sessionID uuid.UUID) ([]entity.CashSessionMethodTotal, error) {
+	query := `
+		SELECT
+			movements.payment_method_id,
+			COUNT(DISTINCT movements.pos_sale_id) as sales_count,
+			COALESCE(SUM(movements.net_amount), 0) as final_amount,
+			COALESCE(SUM(movements.change_amount), 0) as change_given
+		FROM (
+			SELECT
+				p.payment_method_id,
+				p.pos_sale_id,
+				p.amount - p.change_amount as net_amount,
+				p.change_amount
+			FROM pos_sale_payments p
+			JOIN pos_sales ps ON ps.id = p.pos_sale_id
+			WHERE ps.cash_session_id = $1
+
+			UNION ALL
+
+			SELECT
+				r.payment_method_id,
+				NULL as pos_sale_id,
+				-r.refund_amount as net_amount,
+				0 as change_amount
+			FROM pos_sale_refunds r
+			WHERE r.cash_session_id = $1
+		) movements
+		GROUP BY movements.payment_method_id
+	`
+
+	rows, err := database.Executor(ctx, r.db).QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying session totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []entity.CashSessionMethodTotal
+	for rows.Next() {
+		var total entity.CashSessionMethodTotal
+		if err := rows.Scan(
+			&total.PaymentMethodID,
+			&total.SalesCount,
+			&total.FinalAmount,
+			&total.ChangeGiven,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning session totals: %w", err)
+		}
+		totals = append(totals, total)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating session totals: %w", err)
+	}
+
+	return totals, nil
+}
+
+// scanSession escanea una fila de cash_sessions
+func (r *CashSessionPostgresRepository) scanSession(row *sql.Row) (*entity.CashSession, error) {
+	session := &entity.CashSession{}
+	var openedBy, closedBy, notes sql.NullString
+	var closedAt sql.NullTime
+
+	err := row.Scan(
+		&session.ID,
+		&session.TenantID,
+		&session.PointOfSaleID,
+		&session.Status,
+		&session.OpeningFloat,
+		&openedBy,
+		&session.OpenedAt,
+		&closedBy,
+		&closedAt,
+		&notes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	session.OpenedBy = openedBy.String
+	session.ClosedBy = closedBy.String
+	session.Notes = notes.String
+	if closedAt.Valid {
+		session.ClosedAt = &closedAt.Time
+	}
+
+	return session, nil
+}
@@ -0,0 +1,195 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/shared/infrastructure/database"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// CreditNotePostgresRepository implementa CreditNoteRepository usando PostgreSQL
+// HITO CREDIT-NOTE - Notas de crédito contra facturas
+type CreditNotePostgresRepository struct {
+	db *sql.DB
+}
+
+// NewCreditNotePostgresRepository crea una nueva instancia del repositorio
+func NewCreditNotePostgresRepository(db *sql.DB) port.CreditNoteRepository {
+	return &CreditNotePostgresRepository{
+		db: db,
+	}
+}
+
+// Create persiste la nota de crédito con sus líneas
+// Se suma a la transacción del contexto (la nota y la reversión se confirman juntas)
+func (r *CreditNotePostgresRepository) Create(ctx context.Context, note *entity.CreditNote) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			INSERT INTO credit_notes (
+				id, tenant_id, invoice_id, source_type, source_id, reversal_id,
+				credit_note_type, point_of_sale_number, credit_note_number, reason,
+				currency, net_amount, tax_amount, total_amount, issued_by, created_at
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+			)
+		`
+
+		_, err := tx.ExecContext(ctx, query,
+			note.ID,
+			note.TenantID,
+			note.InvoiceID,
+			note.SourceType,
+			note.SourceID,
+			note.ReversalID,
+			note.CreditNoteType,
+			note.PointOfSaleNumber,
+			note.CreditNoteNumber,
+			note.Reason,
+			note.Currency,
+			note.NetAmount,
+			note.TaxAmount,
+			note.TotalAmount,
+			nullableText(note.IssuedBy),
+			note.CreatedAt,
+		)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return fmt.Errorf("credit note already issued for this reversal: %w", err)
+			}
+			return fmt.Errorf("error creating credit_note: %w", err)
+		}
+
+		queryItem := `
+			INSERT INTO credit_note_items (
+				id, credit_note_id, source_item_id, sku, quantity, amount, created_at
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, NOW()
+			)
+		`
+
+		for _, item := range note.Items {
+			_, err := tx.ExecContext(ctx, queryItem,
+				item.ID,
+				item.CreditNoteID,
+				item.SourceItemID,
+				item.SKU,
+				item.Quantity,
+				item.Amount,
+			)
+			if err != nil {
+				return fmt.Errorf("error creating credit_note_item for SKU %s: %w", item.SKU, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListByInvoice retorna las notas de crédito de una factura con sus líneas
+func (r *CreditNotePostgresRepository) ListByInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) ([]*entity.CreditNote, error) {
+	query := `
+		SELECT
+			id, tenant_id, invoice_id, source_type, source_id, reversal_id,
+			credit_note_type, point_of_sale_number, credit_note_number, reason,
+			currency, net_amount, tax_amount, total_amount, COALESCE(issued_by, ''), created_at
+		FROM credit_notes
+		WHERE tenant_id = $1 AND invoice_id = $2
+		ORDER BY credit_note_number
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing credit_notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []*entity.CreditNote
+	for rows.Next() {
+		note := &entity.CreditNote{}
+		var reversalID uuid.NullUUID
+		err := rows.Scan(
+			&note.ID,
+			&note.TenantID,
+			&note.InvoiceID,
+			&note.SourceType,
+			&note.SourceID,
+			&reversalID,
+			&note.CreditNoteType,
+			&note.PointOfSaleNumber,
+			&note.CreditNoteNumber,
+			&note.Reason,
+			&note.Currency,
+			&note.NetAmount,
+			&note.TaxAmount,
+			&note.TotalAmount,
+			&note.IssuedBy,
+			&note.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning credit_note: %w", err)
+		}
+		if reversalID.Valid {
+			note.ReversalID = &reversalID.UUID
+		}
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating credit_notes: %w", err)
+	}
+
+	for _, note := range notes {
+		items, err := r.loadItems(ctx, note.ID)
+		if err != nil {
+			return nil, err
+		}
+		note.Items = items
+	}
+
+	return notes, nil
+}
+
+// loadItems carga las líneas de una nota de crédito
+func (r *CreditNotePostgresRepository) loadItems(ctx context.Context, creditNoteID uuid.UUID) ([]entity.CreditNoteItem, error) {
+	query := `
+		SELECT id, credit_note_id, source_item_id, sku, quantity, amount
+		FROM credit_note_items
+		WHERE credit_note_id = $1
+		ORDER BY created_at, sku
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, creditNoteID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading credit_note_items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []entity.CreditNoteItem
+	for rows.Next() {
+		var item entity.CreditNoteItem
+		var sourceItemID uuid.NullUUID
+		if err := rows.Scan(
+			&item.ID,
+			&item.CreditNoteID,
+			&sourceItemID,
+			&item.SKU,
+			&item.Quantity,
+			&item.Amount,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning credit_note_item: %w", err)
+		}
+		if sourceItemID.Valid {
+			item.SourceItemID = &sourceItemID.UUID
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// FiscalIssuerPostgresRepository implementa FiscalIssuerRepository usando PostgreSQL
+// HITO FISCAL - Datos fiscales del tenant (emisor)
+type FiscalIssuerPostgresRepository struct {
+	db *sql.DB
+}
+
+// NewFiscalIssuerPostgresRepository crea una nueva instancia del repositorio
+func NewFiscalIssuerPostgresRepository(db *sql.DB) port.FiscalIssuerRepository {
+	return &FiscalIssuerPostgresRepository{
+		db: db,
+	}
+}
+
+// FindByTenant retorna el emisor del tenant
+func (r *FiscalIssuerPostgresRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) (*entity.FiscalIssuer, error) {
+	query := `
+		SELECT tenant_id, cuit, legal_name, tax_condition, point_of_sale_number, updated_at
+		FROM fiscal_issuers
+		WHERE tenant_id = $1
+	`
+
+	issuer := &entity.FiscalIssuer{}
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&issuer.TenantID,
+		&issuer.CUIT,
+		&issuer.LegalName,
+		&issuer.TaxCondition,
+		&issuer.PointOfSaleNumber,
+		&issuer.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrFiscalIssuerNotConfigured
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding fiscal issuer: %w", err)
+	}
+
+	return issuer, nil
+}
+
+// Save crea o reemplaza los datos fiscales del tenant
+// Los comprobantes ya emitidos conservan su propia copia de los datos del emisor
+func (r *FiscalIssuerPostgresRepository) Save(ctx context.Context, issuer *entity.FiscalIssuer) error {
+	query := `
+		INSERT INTO fiscal_issuers (
+			tenant_id, cuit, legal_name, tax_condition, point_of_sale_number, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			cuit = EXCLUDED.cuit,
+			legal_name = EXCLUDED.legal_name,
+			tax_condition = EXCLUDED.tax_condition,
+			point_of_sale_number = EXCLUDED.point_of_sale_number,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		issuer.TenantID,
+		issuer.CUIT,
+		issuer.LegalName,
+		issuer.TaxCondition,
+		issuer.PointOfSaleNumber,
+		issuer.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving fiscal issuer: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+)
+
+// IdempotencyPostgresRepository implementa IdempotencyRepository usando PostgreSQL
+// HITO IDEMPOTENCY - La PK (tenant_id, idempotency_key) serializa los requests duplicados
+type IdempotencyPostgresRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyPostgresRepository crea una nueva instancia del repositorio
+func NewIdempotencyPostgresRepository(db *sql.DB) port.IdempotencyRepository {
+	return &IdempotencyPostgresRepository{
+		db: db,
+	}
+}
+
+// Acquire inserta la clave o toma una vencida en una sola sentencia
+// Dos requests concurrentes con la misma clave: solo uno obtiene la fila
+func (r *IdempotencyPostgresRepository) Acquire(ctx context.Context, record *entity.IdempotencyRecord) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (
+			tenant_id, idempotency_key, request_hash, status, lock_token,
+			locked_until, expires_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+		ON CONFLICT (tenant_id, idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			status = EXCLUDED.status,
+			lock_token = EXCLUDED.lock_token,
+			response_status = NULL,
+			response_body = NULL,
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at,
+			updated_at = EXCLUDED.updated_at
+		WHERE idempotency_keys.expires_at < NOW()
+			OR (idempotency_keys.status = 'IN_PROGRESS' AND idempotency_keys.locked_until < NOW())
+		RETURNING tenant_id
+	`
+
+	var tenantID string
+	err := r.db.QueryRowContext(ctx, query,
+		record.TenantID,
+		record.Key,
+		record.RequestHash,
+		record.Status,
+		record.LockToken,
+		record.LockedUntil,
+		record.ExpiresAt,
+		record.CreatedAt,
+		record.UpdatedAt,
+	).Scan(&tenantID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error acquiring idempotency key: %w", err)
+	}
+
+	return true, nil
+}
+
+// Find retorna la clave vigente del tenant
+func (r *IdempotencyPostgresRepository) Find(ctx context.Context, tenantID, key string) (*entity.IdempotencyRecord, error) {
+	query := `
+		SELECT tenant_id, idempotency_key, request_hash, status,
+			COALESCE(response_status, 0), response_body, lock_token,
+			locked_until, expires_at, created_at, updated_at
+		FROM idempotency_keys
+		WHERE tenant_id = $1 AND idempotency_key = $2 AND expires_at >= NOW()
+	`
+
+	record := &entity.IdempotencyRecord{}
+	err := r.db.QueryRowContext(ctx, query, tenantID, key).Scan(
+		&record.TenantID,
+		&record.Key,
+		&record.RequestHash,
+		&record.Status,
+		&record.ResponseStatus,
+		&record.ResponseBody,
+		&record.LockToken,
+		&record.LockedUntil,
+		&record.ExpiresAt,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding idempotency key: %w", err)
+	}
+
+	return record, nil
+}
+
+// Complete guarda la respuesta final
+// Solo si el request sigue siendo dueño de la clave (lock_token): si su lock venció
+// y otro request la tomó, la respuesta de este se descarta
+func (r *IdempotencyPostgresRepository) Complete(ctx context.Context, record *entity.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status = $3,
+			response_status = $4,
+			response_body = $5,
+			updated_at = $6
+		WHERE tenant_id = $1 AND idempotency_key = $2 AND lock_token = $7
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		record.TenantID,
+		record.Key,
+		record.Status,
+		record.ResponseStatus,
+		record.ResponseBody,
+		record.UpdatedAt,
+		record.LockToken,
+	)
+	if err != nil {
+		return fmt.Errorf("error completing idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Release borra una clave IN_PROGRESS del request dueño (lock_token)
+func (r *IdempotencyPostgresRepository) Release(ctx context.Context, record *entity.IdempotencyRecord) error {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE tenant_id = $1 AND idempotency_key = $2 AND status = 'IN_PROGRESS' AND lock_token = $3
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, record.TenantID, record.Key, record.LockToken); err != nil {
+		return fmt.Errorf("error releasing idempotency key: %w", err)
+	}
+
+	return nil
+}
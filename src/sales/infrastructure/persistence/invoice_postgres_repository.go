@@ -0,0 +1,440 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/shared/infrastructure/database"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// invoiceColumns columnas leídas en todas las consultas de invoices
+const invoiceColumns = `
+	id, tenant_id, source_type, source_id, invoice_type,
+	point_of_sale_number, COALESCE(invoice_number, 0),
+	issuer_cuit, issuer_tax_condition,
+	customer_tax_condition, COALESCE(customer_tax_id, ''), COALESCE(customer_name, ''),
+	currency, net_amount, tax_amount, total_amount,
+	fiscal_status, COALESCE(cae, ''), cae_expires_at, COALESCE(rejection_reason, ''),
+	attempts, next_attempt_at, COALESCE(last_error, ''), authorized_at,
+	created_at, updated_at
+`
+
+// InvoicePostgresRepository implementa InvoiceRepository usando PostgreSQL
+// HITO FISCAL - Comprobantes electrónicos + fiscal_status de la venta
+type InvoicePostgresRepository struct {
+	db *sql.DB
+}
+
+// NewInvoicePostgresRepository crea una nueva instancia del repositorio
+func NewInvoicePostgresRepository(db *sql.DB) port.InvoiceRepository {
+	return &InvoicePostgresRepository{
+		db: db,
+	}
+}
+
+// FindSource carga la venta a facturar
+// sales_orders no tiene moneda: las órdenes se facturan en ARS
+func (r *InvoicePostgresRepository) FindSource(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	sourceType entity.InvoiceSourceType,
+	sourceID uuid.UUID,
+) (*entity.InvoiceSource, error) {
+	var query string
+	switch sourceType {
+	case entity.InvoiceSourceSalesOrder:
+		query = `
+			SELECT id, tenant_id, status, total_amount, 'ARS', invoice_id, net_amount, tax_amount, customer_snapshot
+			FROM sales_orders
+			WHERE id = $1 AND tenant_id = $2
+		`
+	case entity.InvoiceSourcePosSale:
+		query = `
+			SELECT id, tenant_id, COALESCE(status, 'COMPLETED'), final_amount, currency, invoice_id, net_amount, tax_amount, customer_snapshot
+			FROM pos_sales
+			WHERE id = $1 AND tenant_id = $2
+		`
+	default:
+		return nil, entity.ErrInvalidInvoiceSourceType
+	}
+
+	source := &entity.InvoiceSource{Type: sourceType}
+	var invoiceID uuid.NullUUID
+	var customerSnapshot []byte
+	err := r.db.QueryRowContext(ctx, query, sourceID, tenantID).Scan(
+		&source.ID,
+		&source.TenantID,
+		&source.Status,
+		&source.TotalAmount,
+		&source.Currency,
+		&invoiceID,
+		&source.NetAmount, // HITO TAX-IVA
+		&source.TaxAmount,
+		&customerSnapshot, // HITO CUSTOMER
+	)
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrInvoiceSourceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding invoice source: %w", err)
+	}
+	if source.Customer, err = entity.UnmarshalCustomerSnapshot(customerSnapshot); err != nil {
+		return nil, fmt.Errorf("error unmarshalling customer snapshot: %w", err)
+	}
+	if invoiceID.Valid {
+		source.InvoiceID = &invoiceID.UUID
+	}
+
+	return source, nil
+}
+
+// Create persiste el comprobante y lo vincula a la venta en la misma transacción
+// La venta solo se vincula si no tenía comprobante (invoice_id IS NULL)
+func (r *InvoicePostgresRepository) Create(ctx context.Context, invoice *entity.Invoice) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			INSERT INTO invoices (
+				id, tenant_id, source_type, source_id, invoice_type,
+				point_of_sale_number, issuer_cuit, issuer_tax_condition,
+				customer_tax_condition, customer_tax_id, customer_name,
+				currency, net_amount, tax_amount, total_amount,
+				fiscal_status, attempts, next_attempt_at, created_at, updated_at
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+				$11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+			)
+		`
+
+		_, err := tx.ExecContext(ctx, query,
+			invoice.ID,
+			invoice.TenantID,
+			invoice.SourceType,
+			invoice.SourceID,
+			invoice.InvoiceType,
+			invoice.PointOfSaleNumber,
+			invoice.IssuerCUIT,
+			invoice.IssuerTaxCondition,
+			invoice.CustomerTaxCondition,
+			nullableText(invoice.CustomerTaxID),
+			nullableText(invoice.CustomerName),
+			invoice.Currency,
+			invoice.NetAmount,
+			invoice.TaxAmount,
+			invoice.TotalAmount,
+			invoice.FiscalStatus,
+			invoice.Attempts,
+			invoice.NextAttemptAt,
+			invoice.CreatedAt,
+			invoice.UpdatedAt,
+		)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return entity.ErrInvoiceAlreadyExists
+			}
+			return fmt.Errorf("error creating invoice: %w", err)
+		}
+
+		linkQuery := fmt.Sprintf(`
+			UPDATE %s
+			SET invoice_id = $1, fiscal_status = $2, updated_at = NOW()
+			WHERE id = $3 AND tenant_id = $4 AND invoice_id IS NULL
+		`, sourceTable(invoice.SourceType))
+
+		result, err := tx.ExecContext(ctx, linkQuery, invoice.ID, invoice.FiscalStatus, invoice.SourceID, invoice.TenantID)
+		if err != nil {
+			return fmt.Errorf("error linking invoice to %s: %w", invoice.SourceType, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("error checking linked rows: %w", err)
+		}
+		if rows == 0 {
+			return entity.ErrInvoiceAlreadyExists
+		}
+
+		return nil
+	})
+}
+
+// FindByID retorna un comprobante del tenant
+func (r *InvoicePostgresRepository) FindByID(ctx context.Context, tenantID, invoiceID uuid.UUID) (*entity.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE id = $1 AND tenant_id = $2`
+
+	invoice, err := scanInvoice(r.db.QueryRowContext(ctx, query, invoiceID, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding invoice: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// FindBySource retorna el comprobante de una venta
+func (r *InvoicePostgresRepository) FindBySource(
+	ctx context.Context,
+	tenantID uuid.UUID,
+	sourceType entity.InvoiceSourceType,
+	sourceID uuid.UUID,
+) (*entity.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE tenant_id = $1 AND source_type = $2 AND source_id = $3`
+
+	invoice, err := scanInvoice(database.Executor(ctx, r.db).QueryRowContext(ctx, query, tenantID, sourceType, sourceID))
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding invoice by source: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// Claim pasa a PROCESSING un comprobante PENDING (cuenta un intento)
+func (r *InvoicePostgresRepository) Claim(ctx context.Context, invoiceID uuid.UUID) (*entity.Invoice, error) {
+	var claimed *entity.Invoice
+	err := database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			UPDATE invoices
+			SET fiscal_status = 'PROCESSING', attempts = attempts + 1, updated_at = NOW()
+			WHERE id = $1 AND fiscal_status = 'PENDING'
+			RETURNING ` + invoiceColumns
+
+		invoice, err := scanInvoice(tx.QueryRowContext(ctx, query, invoiceID))
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error claiming invoice: %w", err)
+		}
+
+		if err := syncSourceStatus(ctx, tx, invoice); err != nil {
+			return err
+		}
+		claimed = invoice
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// ClaimDue pasa a PROCESSING los comprobantes PENDING con reintento vencido
+func (r *InvoicePostgresRepository) ClaimDue(ctx context.Context, limit int) ([]*entity.Invoice, error) {
+	var claimed []*entity.Invoice
+	err := database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			UPDATE invoices
+			SET fiscal_status = 'PROCESSING', attempts = attempts + 1, updated_at = NOW()
+			WHERE id IN (
+				SELECT id FROM invoices
+				WHERE fiscal_status = 'PENDING' AND next_attempt_at <= NOW()
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + invoiceColumns
+
+		rows, err := tx.QueryContext(ctx, query, limit)
+		if err != nil {
+			return fmt.Errorf("error claiming due invoices: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			invoice, err := scanInvoice(rows)
+			if err != nil {
+				return fmt.Errorf("error scanning invoice: %w", err)
+			}
+			claimed = append(claimed, invoice)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating invoices: %w", err)
+		}
+		rows.Close()
+
+		for _, invoice := range claimed {
+			if err := syncSourceStatus(ctx, tx, invoice); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// Update persiste el estado del comprobante y lo replica en la venta
+func (r *InvoicePostgresRepository) Update(ctx context.Context, invoice *entity.Invoice) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			UPDATE invoices
+			SET fiscal_status = $2,
+				invoice_number = $3,
+				cae = $4,
+				cae_expires_at = $5,
+				rejection_reason = $6,
+				attempts = $7,
+				next_attempt_at = $8,
+				last_error = $9,
+				authorized_at = $10,
+				updated_at = $11
+			WHERE id = $1
+		`
+
+		_, err := tx.ExecContext(ctx, query,
+			invoice.ID,
+			invoice.FiscalStatus,
+			nullableNumber(invoice.InvoiceNumber),
+			nullableText(invoice.CAE),
+			invoice.CAEExpiresAt,
+			nullableText(invoice.RejectionReason),
+			invoice.Attempts,
+			invoice.NextAttemptAt,
+			nullableText(invoice.LastError),
+			invoice.AuthorizedAt,
+			invoice.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("error updating invoice: %w", err)
+		}
+
+		return syncSourceStatus(ctx, tx, invoice)
+	})
+}
+
+// ReleaseStale devuelve a PENDING los comprobantes PROCESSING abandonados
+// El reintento es seguro porque Authorize es idempotente por ID de comprobante
+func (r *InvoicePostgresRepository) ReleaseStale(ctx context.Context, olderThan time.Duration) (int64, error) {
+	var released int64
+	err := database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			UPDATE invoices
+			SET fiscal_status = 'PENDING', next_attempt_at = NOW(), updated_at = NOW()
+			WHERE fiscal_status = 'PROCESSING' AND updated_at < $1
+			RETURNING ` + invoiceColumns
+
+		rows, err := tx.QueryContext(ctx, query, time.Now().Add(-olderThan))
+		if err != nil {
+			return fmt.Errorf("error releasing stale invoices: %w", err)
+		}
+		defer rows.Close()
+
+		var invoices []*entity.Invoice
+		for rows.Next() {
+			invoice, err := scanInvoice(rows)
+			if err != nil {
+				return fmt.Errorf("error scanning invoice: %w", err)
+			}
+			invoices = append(invoices, invoice)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating invoices: %w", err)
+		}
+		rows.Close()
+
+		for _, invoice := range invoices {
+			if err := syncSourceStatus(ctx, tx, invoice); err != nil {
+				return err
+			}
+		}
+		released = int64(len(invoices))
+		return nil
+	})
+
+	return released, err
+}
+
+// syncSourceStatus replica fiscal_status en la venta vinculada al comprobante
+func syncSourceStatus(ctx context.Context, exec database.DBTX, invoice *entity.Invoice) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET fiscal_status = $1, updated_at = NOW()
+		WHERE id = $2 AND invoice_id = $3
+	`, sourceTable(invoice.SourceType))
+
+	if _, err := exec.ExecContext(ctx, query, invoice.FiscalStatus, invoice.SourceID, invoice.ID); err != nil {
+		return fmt.Errorf("error updating fiscal_status of %s: %w", invoice.SourceType, err)
+	}
+	return nil
+}
+
+// sourceTable tabla de la venta según el tipo de origen (valores fijos, no input del usuario)
+func sourceTable(sourceType entity.InvoiceSourceType) string {
+	if sourceType == entity.InvoiceSourceSalesOrder {
+		return "sales_orders"
+	}
+	return "pos_sales"
+}
+
+// rowScanner abstrae *sql.Row y *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanInvoice mapea una fila de invoiceColumns
+func scanInvoice(row rowScanner) (*entity.Invoice, error) {
+	invoice := &entity.Invoice{}
+	var caeExpiresAt, authorizedAt sql.NullTime
+
+	err := row.Scan(
+		&invoice.ID,
+		&invoice.TenantID,
+		&invoice.SourceType,
+		&invoice.SourceID,
+		&invoice.InvoiceType,
+		&invoice.PointOfSaleNumber,
+		&invoice.InvoiceNumber,
+		&invoice.IssuerCUIT,
+		&invoice.IssuerTaxCondition,
+		&invoice.CustomerTaxCondition,
+		&invoice.CustomerTaxID,
+		&invoice.CustomerName,
+		&invoice.Currency,
+		&invoice.NetAmount,
+		&invoice.TaxAmount,
+		&invoice.TotalAmount,
+		&invoice.FiscalStatus,
+		&invoice.CAE,
+		&caeExpiresAt,
+		&invoice.RejectionReason,
+		&invoice.Attempts,
+		&invoice.NextAttemptAt,
+		&invoice.LastError,
+		&authorizedAt,
+		&invoice.CreatedAt,
+		&invoice.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if caeExpiresAt.Valid {
+		invoice.CAEExpiresAt = &caeExpiresAt.Time
+	}
+	if authorizedAt.Valid {
+		invoice.AuthorizedAt = &authorizedAt.Time
+	}
+
+	return invoice, nil
+}
+
+// nullableText convierte "" en NULL
+func nullableText(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
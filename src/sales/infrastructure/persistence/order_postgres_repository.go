@@ -4,49 +4,74 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"sales/src/sales/domain/entity"
+	"sales/src/shared/domain/criteria"
+	sqlCriteria "sales/src/shared/infrastructure/criteria"
+	"sales/src/shared/infrastructure/database"
 )
 
 // OrderPostgresRepository implementa OrderRepository usando PostgreSQL
 type OrderPostgresRepository struct {
-	db *sql.DB
+	db        *sql.DB
+	converter *sqlCriteria.SQLCriteriaConverter // HITO ORDER-SEARCH
 }
 
 // NewOrderPostgresRepository crea una nueva instancia del repositorio
 func NewOrderPostgresRepository(db *sql.DB) *OrderPostgresRepository {
 	return &OrderPostgresRepository{
-		db: db,
+		db:        db,
+		converter: sqlCriteria.NewMappedSQLCriteriaConverter(orderCriteriaFields),
 	}
 }
 
 // Save persiste una orden con sus items en la base de datos (DDD Aggregate)
+// HITO TAX-IVA - Persiste el desglose impositivo de la orden y de cada línea
+// HITO STOCK-SAGA - Se suma a la transacción del contexto (orden + cierre de la saga)
 func (r *OrderPostgresRepository) Save(ctx context.Context, order *entity.Order) error {
-	// Iniciar transacción para garantizar atomicidad del aggregate
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("error starting transaction: %w", err)
-	}
-	defer tx.Rollback()
+	// Transacción propia o la del contexto para garantizar atomicidad del aggregate
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		return r.save(ctx, tx, order)
+	})
+}
 
+// save inserta la orden y sus items en la transacción
+func (r *OrderPostgresRepository) save(ctx context.Context, tx *sql.Tx, order *entity.Order) error {
 	// 1. Insertar orden (aggregate root)
 	queryOrder := `
 		INSERT INTO sales_orders (
-			id, tenant_id, customer_id, status, total_amount, created_at, updated_at, version
+			id, tenant_id, customer_id, status, total_amount, created_at, updated_at, version,
+			price_mode, net_amount, tax_amount, reservation_expires_at, customer_snapshot
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 		)
 	`
 
+	// HITO CUSTOMER: comprador como snapshot inmutable (customer_id NULL = consumidor final)
+	customerSnapshot, err := entity.MarshalCustomerSnapshot(order.Customer)
+	if err != nil {
+		return fmt.Errorf("error marshalling customer snapshot: %w", err)
+	}
+	var customerID interface{}
+	if !order.Customer.IsFinalConsumer() {
+		customerID = *order.Customer.CustomerID
+	}
+
 	_, err = tx.ExecContext(ctx, queryOrder,
 		order.OrderID,
 		order.TenantID,
-		"00000000-0000-0000-0000-000000000001", // customer_id temporal
+		customerID,
 		order.Status,
-		0.00, // total_amount (calculado después)
+		order.TotalAmount, // HITO TAX-IVA: bruto (neto + IVA)
 		order.CreatedAt,
 		order.CreatedAt, // updated_at
 		1, // version
+		order.PriceMode,
+		order.NetAmount,
+		order.TaxAmount,
+		order.ReservationExpiresAt, // HITO ORDER-RESERVATION
+		nullableJSON(customerSnapshot),
 	)
 
 	if err != nil {
@@ -56,9 +81,12 @@ func (r *OrderPostgresRepository) Save(ctx context.Context, order *entity.Order)
 	// 2. Insertar items (entities dentro del aggregate) con snapshots
 	queryItem := `
 		INSERT INTO sales_order_items (
-			id, sales_order_id, sku, quantity, product_snapshot, variant_snapshot, created_at
+			id, sales_order_id, sku, quantity, product_snapshot, variant_snapshot, created_at,
+			unit_price, price_overridden,
+			subtotal, tax_rate_code, tax_rate, net_amount, tax_amount, gross_amount,
+			reservation_reference, reservation_status
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
 		)
 	`
 
@@ -71,6 +99,16 @@ func (r *OrderPostgresRepository) Save(ctx context.Context, order *entity.Order)
 			item.ProductSnapshot,
 			item.VariantSnapshot,
 			order.CreatedAt,
+			item.UnitPrice,
+			item.PriceOverridden,
+			item.Subtotal,
+			item.TaxRateCode,
+			item.TaxRate,
+			item.NetAmount,
+			item.TaxAmount,
+			item.GrossAmount,
+			nullableText(item.ReservationReference),
+			nullableText(string(item.ReservationStatus)),
 		)
 
 		if err != nil {
@@ -78,31 +116,36 @@ func (r *OrderPostgresRepository) Save(ctx context.Context, order *entity.Order)
 		}
 	}
 
-	// Commit transacción
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("error committing transaction: %w", err)
-	}
-
 	return nil
 }
 
+// orderColumns columnas de sales_orders leídas al cargar el aggregate
+const orderColumns = `
+	id, tenant_id, status, created_at,
+	price_mode, net_amount, tax_amount, total_amount, reservation_expires_at,
+	COALESCE(cancel_reason, ''), COALESCE(canceled_by, ''), canceled_at,
+	customer_snapshot, order_number
+`
+
+// orderItemColumns columnas de sales_order_items leídas al cargar el aggregate
+const orderItemColumns = `
+	id, sales_order_id, sku, quantity::int, product_snapshot, variant_snapshot,
+	unit_price, price_overridden,
+	subtotal, tax_rate_code, tax_rate, net_amount, tax_amount, gross_amount,
+	COALESCE(reservation_reference, ''), COALESCE(reservation_status, ''), canceled_quantity,
+	shipped_quantity
+`
+
 // FindByID busca una orden con sus items por su ID (DDD: load aggregate)
 func (r *OrderPostgresRepository) FindByID(ctx context.Context, orderID, tenantID string) (*entity.Order, error) {
 	// 1. Buscar orden (aggregate root)
 	queryOrder := `
-		SELECT id, tenant_id, status, created_at
+		SELECT ` + orderColumns + `
 		FROM sales_orders
 		WHERE id = $1 AND tenant_id = $2
 	`
 
-	order := &entity.Order{}
-	err := r.db.QueryRowContext(ctx, queryOrder, orderID, tenantID).Scan(
-		&order.OrderID,
-		&order.TenantID,
-		&order.Status,
-		&order.CreatedAt,
-	)
-
+	order, err := scanOrder(r.db.QueryRowContext(ctx, queryOrder, orderID, tenantID))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("order not found")
 	}
@@ -111,16 +154,25 @@ func (r *OrderPostgresRepository) FindByID(ctx context.Context, orderID, tenantI
 	}
 
 	// 2. Cargar items (entities dentro del aggregate) con snapshots
+	if err := r.loadItems(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// loadItems carga los items de la orden con sus snapshots
+func (r *OrderPostgresRepository) loadItems(ctx context.Context, order *entity.Order) error {
 	queryItems := `
-		SELECT id, id, sku, quantity, product_snapshot, variant_snapshot
+		SELECT ` + orderItemColumns + `
 		FROM sales_order_items
-		WHERE id = $1
+		WHERE sales_order_id = $1
 		ORDER BY created_at
 	`
 
-	rows, err := r.db.QueryContext(ctx, queryItems, orderID)
+	rows, err := r.db.QueryContext(ctx, queryItems, order.OrderID)
 	if err != nil {
-		return nil, fmt.Errorf("error finding order items: %w", err)
+		return fmt.Errorf("error loading items for order %s: %w", order.OrderID, err)
 	}
 	defer rows.Close()
 
@@ -134,19 +186,76 @@ func (r *OrderPostgresRepository) FindByID(ctx context.Context, orderID, tenantI
 			&item.Quantity,
 			&item.ProductSnapshot,
 			&item.VariantSnapshot,
+			&item.UnitPrice,
+			&item.PriceOverridden,
+			&item.Subtotal,
+			&item.TaxRateCode,
+			&item.TaxRate,
+			&item.NetAmount,
+			&item.TaxAmount,
+			&item.GrossAmount,
+			&item.ReservationReference,
+			&item.ReservationStatus,
+			&item.CanceledQuantity,
+			&item.ShippedQuantity,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning order item: %w", err)
+			return fmt.Errorf("error scanning order item: %w", err)
 		}
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating order items: %w", err)
+	}
 
 	order.Items = items
+	return nil
+}
 
+// scanOrder mapea una fila de orderColumns (sin items)
+func scanOrder(row rowScanner) (*entity.Order, error) {
+	order := &entity.Order{}
+	var reservationExpiresAt, canceledAt sql.NullTime
+	var customerSnapshot []byte
+	var orderNumber sql.NullInt64
+	err := row.Scan(
+		&order.OrderID,
+		&order.TenantID,
+		&order.Status,
+		&order.CreatedAt,
+		&order.PriceMode,
+		&order.NetAmount,
+		&order.TaxAmount,
+		&order.TotalAmount,
+		&reservationExpiresAt,
+		&order.CancelReason,
+		&order.CanceledBy,
+		&canceledAt,
+		&customerSnapshot,
+		&orderNumber,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if orderNumber.Valid {
+		order.AssignOrderNumber(int(orderNumber.Int64))
+	}
+	customer, err := entity.UnmarshalCustomerSnapshot(customerSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling customer snapshot: %w", err)
+	}
+	order.Customer = customer
+	if reservationExpiresAt.Valid {
+		order.ReservationExpiresAt = &reservationExpiresAt.Time
+	}
+	if canceledAt.Valid {
+		order.CanceledAt = &canceledAt.Time
+	}
 	return order, nil
 }
 
 // Confirm actualiza el estado de una orden a CONFIRMED y asigna order_number
+// HITO SEQ-TX - Se suma a la transacción del contexto (numeración + confirmación atómicas)
 func (r *OrderPostgresRepository) Confirm(ctx context.Context, orderID, tenantID string) error {
 	query := `
 		UPDATE sales_orders
@@ -154,7 +263,7 @@ func (r *OrderPostgresRepository) Confirm(ctx context.Context, orderID, tenantID
 		WHERE id = $1 AND tenant_id = $2 AND status = 'CREATED'
 	`
 
-	result, err := r.db.ExecContext(ctx, query, orderID, tenantID)
+	result, err := database.Executor(ctx, r.db).ExecContext(ctx, query, orderID, tenantID)
 	if err != nil {
 		return fmt.Errorf("error confirming order: %w", err)
 	}
@@ -168,6 +277,7 @@ func (r *OrderPostgresRepository) Confirm(ctx context.Context, orderID, tenantID
 }
 
 // UpdateOrderNumber actualiza el número de orden (HITO v0.4)
+// HITO SEQ-TX - Se suma a la transacción del contexto
 func (r *OrderPostgresRepository) UpdateOrderNumber(ctx context.Context, orderID, tenantID string, orderNumber int) error {
 	query := `
 		UPDATE sales_orders
@@ -175,7 +285,7 @@ func (r *OrderPostgresRepository) UpdateOrderNumber(ctx context.Context, orderID
 		WHERE id = $2 AND tenant_id = $3
 	`
 
-	_, err := r.db.ExecContext(ctx, query, orderNumber, orderID, tenantID)
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, query, orderNumber, orderID, tenantID)
 	if err != nil {
 		return fmt.Errorf("error updating order number: %w", err)
 	}
@@ -184,110 +294,539 @@ func (r *OrderPostgresRepository) UpdateOrderNumber(ctx context.Context, orderID
 }
 
 // Cancel actualiza el estado de una orden a CANCELED
-func (r *OrderPostgresRepository) Cancel(ctx context.Context, orderID, tenantID string) error {
+// HITO CREDIT-NOTE - Se suma a la transacción del contexto (cancelación + nota de crédito)
+// HITO ORDER-CANCEL - Solo si la orden sigue en el estado desde el que se canceló
+func (r *OrderPostgresRepository) Cancel(ctx context.Context, order *entity.Order, from entity.OrderStatus) error {
 	query := `
 		UPDATE sales_orders
-		SET status = 'CANCELED'
-		WHERE id = $1 AND tenant_id = $2 AND status = 'CONFIRMED'
+		SET status = 'CANCELED', cancel_reason = $4, canceled_by = $5, canceled_at = $6, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = $3
 	`
 
-	result, err := r.db.ExecContext(ctx, query, orderID, tenantID)
+	result, err := database.Executor(ctx, r.db).ExecContext(ctx, query,
+		order.OrderID,
+		order.TenantID,
+		from,
+		order.CancelReason,
+		nullableText(order.CanceledBy),
+		order.CanceledAt,
+	)
 	if err != nil {
 		return fmt.Errorf("error canceling order: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("order not found or not in CONFIRMED state")
+		return fmt.Errorf("order not found or not in %s state", from)
 	}
 
 	return nil
 }
 
-// List retorna todas las órdenes de un tenant con paginación
-func (r *OrderPostgresRepository) List(ctx context.Context, tenantID string, page, pageSize int) ([]*entity.Order, int, error) {
-	// 1. Contar total de órdenes
-	var totalCount int
-	queryCount := `
-		SELECT COUNT(*)
-		FROM sales_orders
-		WHERE tenant_id = $1
+// CancelLines persiste una cancelación parcial: cantidades y totales recalculados de las
+// líneas, totales de la orden y el documento de cancelación (HITO ORDER-CANCEL)
+// Se suma a la transacción del contexto (cancelación + nota de crédito + evento)
+func (r *OrderPostgresRepository) CancelLines(ctx context.Context, order *entity.Order, cancellation *entity.OrderLineCancellation) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		queryOrder := `
+			UPDATE sales_orders
+			SET net_amount = $3, tax_amount = $4, total_amount = $5, updated_at = NOW()
+			WHERE id = $1 AND tenant_id = $2 AND status = 'CONFIRMED'
+		`
+
+		result, err := tx.ExecContext(ctx, queryOrder,
+			order.OrderID,
+			order.TenantID,
+			order.NetAmount,
+			order.TaxAmount,
+			order.TotalAmount,
+		)
+		if err != nil {
+			return fmt.Errorf("error updating order totals: %w", err)
+		}
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			return fmt.Errorf("order not found or not in CONFIRMED state")
+		}
+
+		// Todas las líneas: el recálculo de IVA puede tocar más que las canceladas
+		queryItem := `
+			UPDATE sales_order_items
+			SET canceled_quantity = $2, subtotal = $3, tax_rate = $4,
+				net_amount = $5, tax_amount = $6, gross_amount = $7
+			WHERE id = $1
+		`
+		for _, item := range order.Items {
+			_, err := tx.ExecContext(ctx, queryItem,
+				item.ItemID,
+				item.CanceledQuantity,
+				item.Subtotal,
+				item.TaxRate,
+				item.NetAmount,
+				item.TaxAmount,
+				item.GrossAmount,
+			)
+			if err != nil {
+				return fmt.Errorf("error updating order item %s: %w", item.ItemID, err)
+			}
+		}
+
+		queryCancellation := `
+			INSERT INTO sales_order_line_cancellations (
+				id, cancellation_id, tenant_id, sales_order_id, sales_order_item_id, sku, quantity,
+				net_amount, tax_amount, gross_amount, reason, canceled_by, created_at
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+			)
+		`
+		for _, line := range cancellation.Items {
+			_, err := tx.ExecContext(ctx, queryCancellation,
+				line.ID,
+				cancellation.ID,
+				cancellation.TenantID,
+				cancellation.OrderID,
+				line.ItemID,
+				line.SKU,
+				line.Quantity,
+				line.NetAmount,
+				line.TaxAmount,
+				line.GrossAmount,
+				cancellation.Reason,
+				nullableText(cancellation.CanceledBy),
+				cancellation.CreatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("error saving line cancellation: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// orderCriteriaFields expresión SQL de cada campo filtrable/ordenable
+// HITO ORDER-SEARCH - Solo estos campos llegan al SQL; el resto se rechaza
+// Los campos product_* filtran por el snapshot PIM de alguna línea (operador contains)
+var orderCriteriaFields = sqlCriteria.FieldMap{
+	"tenant_id":              "o.tenant_id",
+	"status":                 "o.status",
+	"order_number":           "o.order_number",
+	"created_at":             "o.created_at",
+	"total_amount":           "o.total_amount",
+	"customer_id":            "o.customer_id",
+	"customer_document":      sqlCriteria.JSONBText("o.customer_snapshot", "document_number"),
+	"customer_tax_condition": sqlCriteria.JSONBText("o.customer_snapshot", "tax_condition"),
+	"sku":                    orderItemsArray("i.sku::text"),
+	"product_name":           orderItemsArray(sqlCriteria.JSONBText("i.product_snapshot", "name")),
+	"product_category_id":    orderItemsArray(sqlCriteria.JSONBText("i.product_snapshot", "category_id")),
+	"product_brand_id":       orderItemsArray(sqlCriteria.JSONBText("i.product_snapshot", "brand_id")),
+}
+
+// orderItemsArray arma el array de una expresión sobre las líneas de la orden
+func orderItemsArray(expression string) string {
+	return "ARRAY(SELECT " + expression + " FROM sales_order_items i WHERE i.sales_order_id = o.id)"
+}
+
+// SearchByCriteria retorna las órdenes que cumplen el criteria con sus items
+// HITO ORDER-SEARCH - Filtros, orden y paginación del paquete criteria
+func (r *OrderPostgresRepository) SearchByCriteria(ctx context.Context, c criteria.Criteria) ([]*entity.Order, error) {
+	query, args, err := r.converter.ToSelectSQL(`SELECT `+orderColumns+` FROM sales_orders o`, c)
+	if err != nil {
+		return nil, fmt.Errorf("order criteria: %w", err)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []*entity.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating orders: %w", err)
+	}
+
+	// Cargar items de cada orden con snapshots
+	for _, order := range orders {
+		if err := r.loadItems(ctx, order); err != nil {
+			return nil, err
+		}
+	}
+
+	return orders, nil
+}
+
+// CountByCriteria cuenta las órdenes que cumplen los filtros del criteria
+func (r *OrderPostgresRepository) CountByCriteria(ctx context.Context, c criteria.Criteria) (int, error) {
+	query, args, err := r.converter.ToCountSQL(`SELECT COUNT(*) FROM sales_orders o`, c)
+	if err != nil {
+		return 0, fmt.Errorf("order criteria: %w", err)
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error counting orders: %w", err)
+	}
+	return total, nil
+}
+
+// UpdateItemReservation persiste el estado de la reserva de una línea (HITO ORDER-RESERVATION)
+// Se suma a la transacción del contexto
+func (r *OrderPostgresRepository) UpdateItemReservation(ctx context.Context, itemID string, status entity.ReservationStatus) error {
+	query := `
+		UPDATE sales_order_items
+		SET reservation_status = $2
+		WHERE id = $1 AND reservation_reference IS NOT NULL
 	`
-	err := r.db.QueryRowContext(ctx, queryCount, tenantID).Scan(&totalCount)
+
+	result, err := database.Executor(ctx, r.db).ExecContext(ctx, query, itemID, status)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error counting orders: %w", err)
+		return fmt.Errorf("error updating reservation of order item %s: %w", itemID, err)
 	}
 
-	// 2. Calcular offset
-	offset := (page - 1) * pageSize
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("order item %s not found or without reservation", itemID)
+	}
 
-	// 3. Obtener órdenes paginadas
-	queryOrders := `
-		SELECT id, tenant_id, status, created_at
-		FROM sales_orders
-		WHERE tenant_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+	return nil
+}
+
+// TransitionItemReservation cambia el estado de la reserva de una línea con un UPDATE
+// condicional: solo un proceso gana la transición (HITO ORDER-RESERVATION)
+// Se suma a la transacción del contexto
+func (r *OrderPostgresRepository) TransitionItemReservation(ctx context.Context, itemID string, from, to entity.ReservationStatus) (bool, error) {
+	query := `
+		UPDATE sales_order_items
+		SET reservation_status = $3
+		WHERE id = $1 AND reservation_status = $2
 	`
 
-	rows, err := r.db.QueryContext(ctx, queryOrders, tenantID, pageSize, offset)
+	result, err := database.Executor(ctx, r.db).ExecContext(ctx, query, itemID, from, to)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error listing orders: %w", err)
+		return false, fmt.Errorf("error updating reservation of order item %s: %w", itemID, err)
 	}
-	defer rows.Close()
 
-	var orders []*entity.Order
-	for rows.Next() {
-		order := &entity.Order{}
-		err := rows.Scan(
-			&order.OrderID,
-			&order.TenantID,
-			&order.Status,
-			&order.CreatedAt,
-		)
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error updating reservation of order item %s: %w", itemID, err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ClaimExpiredReservations vence las órdenes CREATED con la reserva vencida y retoma las
+// EXPIRED con reservas pendientes de liberar (HITO ORDER-RESERVATION)
+// El cambio de estado y el lease se confirman antes de cargar las órdenes
+func (r *OrderPostgresRepository) ClaimExpiredReservations(ctx context.Context, expiredBefore time.Time, limit int, lease time.Duration) ([]*entity.Order, error) {
+	type claimedOrder struct{ orderID, tenantID string }
+	var claimed []claimedOrder
+
+	err := database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		// HITO ORDER-FULFILLMENT: due.status distingue las recién vencidas (CREATED → EXPIRED)
+		query := `
+			WITH due AS (
+				SELECT id, status FROM sales_orders
+				WHERE reservations_released_at IS NULL
+				AND reservation_expires_at <= $1
+				AND (
+					status = 'CREATED'
+					OR (status = 'EXPIRED' AND (reservation_lease_until IS NULL OR reservation_lease_until <= NOW()))
+				)
+				ORDER BY reservation_expires_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			UPDATE sales_orders o
+			SET status = 'EXPIRED', reservation_lease_until = $3, updated_at = NOW()
+			FROM due
+			WHERE o.id = due.id
+			RETURNING o.id, o.tenant_id, due.status
+		`
+
+		rows, err := tx.QueryContext(ctx, query, expiredBefore, limit, time.Now().Add(lease))
 		if err != nil {
-			return nil, 0, fmt.Errorf("error scanning order: %w", err)
+			return fmt.Errorf("error claiming expired order reservations: %w", err)
+		}
+		defer rows.Close()
+
+		var expired []*entity.OrderStatusChange
+		for rows.Next() {
+			var c claimedOrder
+			var previous entity.OrderStatus
+			if err := rows.Scan(&c.orderID, &c.tenantID, &previous); err != nil {
+				return fmt.Errorf("error scanning expired order: %w", err)
+			}
+			claimed = append(claimed, c)
+			if previous == entity.OrderStatusCreated {
+				order := &entity.Order{OrderID: c.orderID, TenantID: c.tenantID, Status: entity.OrderStatusExpired}
+				expired = append(expired, entity.NewOrderStatusChange(order, previous, "", "stock reservation expired"))
+			}
 		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
 
-		// 4. Cargar items de cada orden con snapshots
-		queryItems := `
-			SELECT id, sales_order_id, sku, quantity, product_snapshot, variant_snapshot
-			FROM sales_order_items
-			WHERE id = $1
-			ORDER BY created_at
-		`
+		for _, change := range expired {
+			if err := r.RecordStatusChange(ctx, change); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*entity.Order, 0, len(claimed))
+	for _, c := range claimed {
+		order, err := r.FindByID(ctx, c.orderID, c.tenantID)
+		if err != nil {
+			// Queda EXPIRED con lease: se retoma al vencer
+			return orders, fmt.Errorf("error loading expired order %s: %w", c.orderID, err)
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+// MarkReservationsReleased cierra la liberación de reservas de una orden vencida
+func (r *OrderPostgresRepository) MarkReservationsReleased(ctx context.Context, orderID string) error {
+	query := `
+		UPDATE sales_orders
+		SET reservations_released_at = NOW(), reservation_lease_until = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'EXPIRED'
+	`
+
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, query, orderID)
+	if err != nil {
+		return fmt.Errorf("error marking reservations released for order %s: %w", orderID, err)
+	}
+
+	return nil
+}
+
+// RecordStatusChange registra una transición en order_status_history (HITO ORDER-FULFILLMENT)
+// Se suma a la transacción del contexto
+func (r *OrderPostgresRepository) RecordStatusChange(ctx context.Context, change *entity.OrderStatusChange) error {
+	query := `
+		INSERT INTO order_status_history (
+			id, tenant_id, sales_order_id, from_status, to_status, actor, note, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8
+		)
+	`
+
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, query,
+		change.ID,
+		change.TenantID,
+		change.OrderID,
+		nullableText(string(change.FromStatus)),
+		change.ToStatus,
+		nullableText(change.Actor),
+		nullableText(change.Note),
+		change.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error recording status change of order %s: %w", change.OrderID, err)
+	}
+
+	return nil
+}
+
+// TransitionStatus cambia el estado de la orden y registra la transición en una sola tx
+// Solo si la orden sigue en el estado de origen (otra transición concurrente gana)
+func (r *OrderPostgresRepository) TransitionStatus(ctx context.Context, change *entity.OrderStatusChange) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		if err := r.updateStatus(ctx, tx, change); err != nil {
+			return err
+		}
+		return r.RecordStatusChange(ctx, change)
+	})
+}
+
+// updateStatus aplica change sobre sales_orders si la orden sigue en change.FromStatus
+func (r *OrderPostgresRepository) updateStatus(ctx context.Context, tx *sql.Tx, change *entity.OrderStatusChange) error {
+	query := `
+		UPDATE sales_orders
+		SET status = $4, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = $3
+	`
+
+	result, err := tx.ExecContext(ctx, query, change.OrderID, change.TenantID, change.FromStatus, change.ToStatus)
+	if err != nil {
+		return fmt.Errorf("error updating order status: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("order not found or not in %s state", change.FromStatus)
+	}
+
+	return nil
+}
 
-		itemRows, err := r.db.QueryContext(ctx, queryItems, order.OrderID)
+// SaveShipment persiste el envío, sus líneas y las unidades despachadas (HITO ORDER-FULFILLMENT)
+// El guard de shipped_quantity evita despachar de más con envíos concurrentes
+func (r *OrderPostgresRepository) SaveShipment(ctx context.Context, shipment *entity.Shipment, change *entity.OrderStatusChange) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		if change != nil {
+			if err := r.updateStatus(ctx, tx, change); err != nil {
+				return err
+			}
+			if err := r.RecordStatusChange(ctx, change); err != nil {
+				return err
+			}
+		}
+
+		queryShipment := `
+			INSERT INTO sales_order_shipments (
+				id, tenant_id, sales_order_id, carrier, tracking_number, shipped_by, shipped_at
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7
+			)
+		`
+		_, err := tx.ExecContext(ctx, queryShipment,
+			shipment.ID,
+			shipment.TenantID,
+			shipment.OrderID,
+			shipment.Carrier,
+			nullableText(shipment.TrackingNumber),
+			nullableText(shipment.ShippedBy),
+			shipment.ShippedAt,
+		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("error loading items for order %s: %w", order.OrderID, err)
+			return fmt.Errorf("error saving shipment: %w", err)
 		}
 
-		var items []entity.OrderItem
-		for itemRows.Next() {
-			var item entity.OrderItem
-			err := itemRows.Scan(
-				&item.ItemID,
-				&item.OrderID,
-				&item.SKU,
-				&item.Quantity,
-				&item.ProductSnapshot,
-				&item.VariantSnapshot,
+		queryItem := `
+			INSERT INTO sales_order_shipment_items (
+				id, shipment_id, sales_order_item_id, sku, quantity
+			) VALUES (
+				$1, $2, $3, $4, $5
+			)
+		`
+		queryShipped := `
+			UPDATE sales_order_items
+			SET shipped_quantity = shipped_quantity + $2
+			WHERE id = $1 AND shipped_quantity + $2 <= quantity - canceled_quantity
+		`
+		for _, item := range shipment.Items {
+			_, err := tx.ExecContext(ctx, queryItem,
+				item.ID,
+				item.ShipmentID,
+				item.OrderItemID,
+				item.SKU,
+				item.Quantity,
 			)
 			if err != nil {
-				itemRows.Close()
-				return nil, 0, fmt.Errorf("error scanning order item: %w", err)
+				return fmt.Errorf("error saving shipment item: %w", err)
+			}
+
+			result, err := tx.ExecContext(ctx, queryShipped, item.OrderItemID, item.Quantity)
+			if err != nil {
+				return fmt.Errorf("error updating shipped quantity of order item %s: %w", item.OrderItemID, err)
+			}
+			rowsAffected, _ := result.RowsAffected()
+			if rowsAffected == 0 {
+				return entity.ErrInvalidShipmentQuantity
 			}
-			items = append(items, item)
 		}
-		itemRows.Close()
 
-		order.Items = items
-		orders = append(orders, order)
+		return nil
+	})
+}
+
+// ListStatusHistory retorna las transiciones de la orden en orden cronológico
+func (r *OrderPostgresRepository) ListStatusHistory(ctx context.Context, orderID, tenantID string) ([]*entity.OrderStatusChange, error) {
+	query := `
+		SELECT id, tenant_id, sales_order_id, COALESCE(from_status, ''), to_status,
+			COALESCE(actor, ''), COALESCE(note, ''), created_at
+		FROM order_status_history
+		WHERE sales_order_id = $1 AND tenant_id = $2
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing status history of order %s: %w", orderID, err)
 	}
+	defer rows.Close()
 
-	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating orders: %w", err)
+	history := []*entity.OrderStatusChange{}
+	for rows.Next() {
+		change := &entity.OrderStatusChange{}
+		err := rows.Scan(
+			&change.ID,
+			&change.TenantID,
+			&change.OrderID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.Actor,
+			&change.Note,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning status change: %w", err)
+		}
+		history = append(history, change)
+	}
+
+	return history, rows.Err()
+}
+
+// ListShipments retorna los envíos de la orden con sus líneas
+func (r *OrderPostgresRepository) ListShipments(ctx context.Context, orderID, tenantID string) ([]*entity.Shipment, error) {
+	query := `
+		SELECT s.id, s.tenant_id, s.sales_order_id, s.carrier, COALESCE(s.tracking_number, ''),
+			COALESCE(s.shipped_by, ''), s.shipped_at,
+			i.id, i.sales_order_item_id, i.sku, i.quantity
+		FROM sales_order_shipments s
+		JOIN sales_order_shipment_items i ON i.shipment_id = s.id
+		WHERE s.sales_order_id = $1 AND s.tenant_id = $2
+		ORDER BY s.shipped_at, s.id, i.sku
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing shipments of order %s: %w", orderID, err)
+	}
+	defer rows.Close()
+
+	shipments := []*entity.Shipment{}
+	var current *entity.Shipment
+	for rows.Next() {
+		var shipment entity.Shipment
+		var item entity.ShipmentItem
+		err := rows.Scan(
+			&shipment.ID,
+			&shipment.TenantID,
+			&shipment.OrderID,
+			&shipment.Carrier,
+			&shipment.TrackingNumber,
+			&shipment.ShippedBy,
+			&shipment.ShippedAt,
+			&item.ID,
+			&item.OrderItemID,
+			&item.SKU,
+			&item.Quantity,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning shipment: %w", err)
+		}
+		if current == nil || current.ID != shipment.ID {
+			current = &shipment
+			shipments = append(shipments, current)
+		}
+		item.ShipmentID = current.ID
+		current.Items = append(current.Items, item)
 	}
 
-	return orders, totalCount, nil
+	return shipments, rows.Err()
 }
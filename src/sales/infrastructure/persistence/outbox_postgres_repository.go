@@ -0,0 +1,181 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/shared/infrastructure/database"
+)
+
+// outboxColumns columnas leídas en todas las consultas de outbox_events
+const outboxColumns = `
+	id, aggregate_id, aggregate_type, event_type, payload, published_by,
+	status, attempts, next_attempt_at, COALESCE(last_error, ''),
+	created_at, updated_at, published_at
+`
+
+// OutboxPostgresRepository implementa OutboxRepository usando PostgreSQL
+// HITO OUTBOX - outbox_events vive en la base de órdenes (misma tx que la venta)
+type OutboxPostgresRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxPostgresRepository crea una nueva instancia del repositorio
+func NewOutboxPostgresRepository(db *sql.DB) port.OutboxRepository {
+	return &OutboxPostgresRepository{
+		db: db,
+	}
+}
+
+// Append persiste un evento PENDING
+// Se suma a la transacción del contexto (el evento y la venta se confirman juntos)
+func (r *OutboxPostgresRepository) Append(ctx context.Context, event *entity.OutboxEvent) error {
+	query := `
+		INSERT INTO outbox_events (
+			id, aggregate_id, aggregate_type, event_type, payload, published_by,
+			status, attempts, next_attempt_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+	`
+
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, query,
+		event.ID,
+		event.AggregateID,
+		event.AggregateType,
+		event.EventType,
+		[]byte(event.Payload),
+		event.PublishedBy,
+		event.Status,
+		event.Attempts,
+		event.NextAttemptAt,
+		event.CreatedAt,
+		event.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error appending outbox event %s: %w", event.EventType, err)
+	}
+
+	return nil
+}
+
+// ClaimDue pasa a PROCESSING los eventos PENDING con reintento vencido (cuenta un intento)
+func (r *OutboxPostgresRepository) ClaimDue(ctx context.Context, limit int) ([]*entity.OutboxEvent, error) {
+	var claimed []*entity.OutboxEvent
+	err := database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			UPDATE outbox_events
+			SET status = 'PROCESSING', attempts = attempts + 1, updated_at = NOW()
+			WHERE id IN (
+				SELECT id FROM outbox_events
+				WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+				ORDER BY created_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + outboxColumns
+
+		rows, err := tx.QueryContext(ctx, query, limit)
+		if err != nil {
+			return fmt.Errorf("error claiming due outbox events: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			event, err := scanOutboxEvent(rows)
+			if err != nil {
+				return fmt.Errorf("error scanning outbox event: %w", err)
+			}
+			claimed = append(claimed, event)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// Update persiste el resultado del reenvío
+func (r *OutboxPostgresRepository) Update(ctx context.Context, event *entity.OutboxEvent) error {
+	query := `
+		UPDATE outbox_events
+		SET status = $2,
+			attempts = $3,
+			next_attempt_at = $4,
+			last_error = $5,
+			published_at = $6,
+			updated_at = $7
+		WHERE id = $1
+	`
+
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, query,
+		event.ID,
+		event.Status,
+		event.Attempts,
+		event.NextAttemptAt,
+		nullableText(event.LastError),
+		event.PublishedAt,
+		event.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating outbox event %s: %w", event.ID, err)
+	}
+
+	return nil
+}
+
+// ReleaseStale devuelve a PENDING los eventos PROCESSING abandonados
+// El reenvío puede duplicar un evento ya publicado: el relay garantiza al-menos-una-vez
+func (r *OutboxPostgresRepository) ReleaseStale(ctx context.Context, olderThan time.Duration) (int64, error) {
+	query := `
+		UPDATE outbox_events
+		SET status = 'PENDING', next_attempt_at = NOW(), updated_at = NOW()
+		WHERE status = 'PROCESSING' AND updated_at < $1
+	`
+
+	result, err := database.Executor(ctx, r.db).ExecContext(ctx, query, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("error releasing stale outbox events: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// scanOutboxEvent mapea una fila de outboxColumns
+func scanOutboxEvent(row rowScanner) (*entity.OutboxEvent, error) {
+	event := &entity.OutboxEvent{}
+	var payload []byte
+	var publishedAt sql.NullTime
+
+	err := row.Scan(
+		&event.ID,
+		&event.AggregateID,
+		&event.AggregateType,
+		&event.EventType,
+		&payload,
+		&event.PublishedBy,
+		&event.Status,
+		&event.Attempts,
+		&event.NextAttemptAt,
+		&event.LastError,
+		&event.CreatedAt,
+		&event.UpdatedAt,
+		&publishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Payload = payload
+	if publishedAt.Valid {
+		event.PublishedAt = &publishedAt.Time
+	}
+
+	return event, nil
+}
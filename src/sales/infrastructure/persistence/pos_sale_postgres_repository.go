@@ -3,37 +3,53 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"sales/src/sales/domain/entity"
 	"sales/src/sales/domain/port"
+	"sales/src/shared/domain/criteria"
+	sqlCriteria "sales/src/shared/infrastructure/criteria"
+	"sales/src/shared/infrastructure/database"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 )
 
 // PosSalePostgresRepository implementa PosSaleRepository usando PostgreSQL
-// Sin transacciones, sin lógica, solo insert y select
+// Sin lógica: insert, select y documentos de devolución (HITO POS-REFUND)
 // Hito: POS-SALE-02.BE - Paso 2
 type PosSalePostgresRepository struct {
-	db *sql.DB
+	db        *sql.DB
+	converter *sqlCriteria.SQLCriteriaConverter // HITO POS-SEARCH
 }
 
 // NewPosSalePostgresRepository crea una nueva instancia del repositorio
 func NewPosSalePostgresRepository(db *sql.DB) port.PosSaleRepository {
 	return &PosSalePostgresRepository{
-		db: db,
+		db:        db,
+		converter: sqlCriteria.NewMappedSQLCriteriaConverter(posSaleCriteriaFields),
 	}
 }
 
-// Create persiste una nueva venta POS con sus items (atomically)
+// Create persiste una nueva venta POS con sus items y pagos (atomically)
 // HITO B - Refactorizado para multi-item
+// HITO POS-SPLIT - Persiste pos_sale_payments
+// HITO POS-NUMBER - Se suma a la transacción del contexto (numeración + insert atómicos)
+// HITO TAX-IVA - Persiste el desglose impositivo de la venta y de cada línea
 func (r *PosSalePostgresRepository) Create(ctx context.Context, sale *entity.PosSale) error {
-	// Iniciar transacción para garantizar atomicidad
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("error starting transaction: %w", err)
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		return r.create(ctx, tx, sale)
+	})
+}
+
+// create inserta la venta, items y pagos dentro de la transacción recibida
+func (r *PosSalePostgresRepository) create(ctx context.Context, tx *sql.Tx, sale *entity.PosSale) error {
+	// 0. HITO POS-CASH: la sesión de caja tiene que seguir abierta al persistir
+	if err := lockOpenCashSession(ctx, tx, sale.TenantID, sale.CashSessionID); err != nil {
+		return err
 	}
-	defer tx.Rollback()
 
 	// 1. Insertar pos_sale (aggregate root)
 	// HITO: POST /pos/sale devuelve DTO listo para imprimir
@@ -41,12 +57,21 @@ func (r *PosSalePostgresRepository) Create(ctx context.Context, sale *entity.Pos
 		INSERT INTO pos_sales (
 			id, tenant_id, customer_id, payment_method_id,
 			total_amount, discount_amount, final_amount,
-			amount_paid, change, currency, created_at
+			amount_paid, change, currency, created_at,
+			point_of_sale_id, cash_session_id,
+			point_of_sale_number, pos_number,
+			price_mode, net_amount, tax_amount, customer_snapshot
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
 		)
 	`
 
+	// HITO CUSTOMER: comprador como snapshot inmutable
+	customerSnapshot, err := entity.MarshalCustomerSnapshot(sale.Customer)
+	if err != nil {
+		return fmt.Errorf("error marshalling customer snapshot: %w", err)
+	}
+
 	_, err = tx.ExecContext(ctx, querySale,
 		sale.ID,
 		sale.TenantID,
@@ -59,6 +84,14 @@ func (r *PosSalePostgresRepository) Create(ctx context.Context, sale *entity.Pos
 		sale.Change,
 		sale.Currency,
 		sale.CreatedAt,
+		sale.PointOfSaleID, // HITO POS-CASH
+		sale.CashSessionID,
+		nullableNumber(sale.PointOfSaleNumber), // HITO POS-NUMBER
+		nullableNumber(sale.PosNumber),
+		sale.PriceMode, // HITO TAX-IVA
+		sale.NetAmount,
+		sale.TaxAmount,
+		nullableJSON(customerSnapshot), // HITO CUSTOMER
 	)
 
 	if err != nil {
@@ -69,9 +102,11 @@ func (r *PosSalePostgresRepository) Create(ctx context.Context, sale *entity.Pos
 	queryItem := `
 		INSERT INTO pos_sale_items (
 			id, pos_sale_id, sku, product_name,
-			quantity, unit_price, subtotal, stock_entry_id, created_at
+			quantity, unit_price, subtotal, stock_entry_id,
+			tax_rate_code, tax_rate, net_amount, tax_amount, gross_amount,
+			list_price, price_overridden, price_overridden_by, created_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, NOW()
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW()
 		)
 	`
 
@@ -85,6 +120,14 @@ func (r *PosSalePostgresRepository) Create(ctx context.Context, sale *entity.Pos
 			item.UnitPrice,
 			item.Subtotal,
 			item.StockEntryID,
+			item.TaxRateCode, // HITO TAX-IVA
+			item.TaxRate,
+			item.NetAmount,
+			item.TaxAmount,
+			item.GrossAmount,
+			item.ListPrice, // HITO POS-PRICE
+			item.PriceOverridden,
+			nullableText(item.PriceOverriddenBy),
 		)
 
 		if err != nil {
@@ -92,107 +135,471 @@ func (r *PosSalePostgresRepository) Create(ctx context.Context, sale *entity.Pos
 		}
 	}
 
-	// Commit transacción
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("error committing transaction: %w", err)
+	// 3. Insertar pos_sale_payments (HITO POS-SPLIT)
+	queryPayment := `
+		INSERT INTO pos_sale_payments (
+			id, pos_sale_id, payment_method_id,
+			amount, change_amount, reference, is_cash, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, NOW()
+		)
+	`
+
+	for _, payment := range sale.Payments {
+		_, err = tx.ExecContext(ctx, queryPayment,
+			payment.ID,
+			payment.PosSaleID,
+			payment.PaymentMethodID,
+			payment.Amount,
+			payment.ChangeAmount,
+			payment.Reference,
+			payment.IsCash,
+		)
+
+		if err != nil {
+			return fmt.Errorf("error creating pos_sale_payment for method %s: %w", payment.PaymentMethodID, err)
+		}
 	}
 
 	return nil
 }
 
-// ListByTenant retorna todas las ventas POS de un tenant CON sus items
-// HITO B - Refactorizado para cargar items
-func (r *PosSalePostgresRepository) ListByTenant(ctx context.Context, tenantID uuid.UUID) ([]*entity.PosSale, error) {
-	// 1. Obtener pos_sales
-	// HITO: POST /pos/sale devuelve DTO listo para imprimir
-	querySales := `
-		SELECT 
-			id, tenant_id, customer_id, payment_method_id,
-			total_amount, discount_amount, final_amount,
-			amount_paid, change, currency, created_at
-		FROM pos_sales
-		WHERE tenant_id = $1
-		ORDER BY created_at DESC
-	`
+// posSaleColumns columnas de pos_sales leídas al cargar el aggregate
+const posSaleColumns = `
+	id, tenant_id, customer_id, payment_method_id,
+	total_amount, discount_amount, final_amount,
+	amount_paid, change, currency, created_at,
+	point_of_sale_id, cash_session_id,
+	COALESCE(point_of_sale_number, 0), COALESCE(pos_number, 0),
+	status, refunded_amount,
+	price_mode, net_amount, tax_amount, customer_snapshot
+`
 
-	rows, err := r.db.QueryContext(ctx, querySales, tenantID)
+// scanPosSale mapea una fila de posSaleColumns (sin items ni pagos)
+func scanPosSale(row rowScanner) (*entity.PosSale, error) {
+	sale := &entity.PosSale{}
+	var customerSnapshot []byte
+	err := row.Scan(
+		&sale.ID,
+		&sale.TenantID,
+		&sale.CustomerID,
+		&sale.PaymentMethodID,
+		&sale.TotalAmount,
+		&sale.DiscountAmount,
+		&sale.FinalAmount,
+		&sale.AmountPaid,
+		&sale.Change,
+		&sale.Currency,
+		&sale.CreatedAt,
+		&sale.PointOfSaleID,
+		&sale.CashSessionID,
+		&sale.PointOfSaleNumber,
+		&sale.PosNumber,
+		&sale.Status,
+		&sale.RefundedAmount,
+		&sale.PriceMode,
+		&sale.NetAmount,
+		&sale.TaxAmount,
+		&customerSnapshot,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if sale.Customer, err = entity.UnmarshalCustomerSnapshot(customerSnapshot); err != nil {
+		return nil, fmt.Errorf("error unmarshalling customer snapshot: %w", err)
+	}
+	return sale, nil
+}
+
+// posSaleCriteriaFields expresión SQL de cada campo filtrable
+// HITO POS-SEARCH - Solo estos campos llegan al SQL; el resto se rechaza
+var posSaleCriteriaFields = sqlCriteria.FieldMap{
+	"tenant_id":              "s.tenant_id",
+	"created_at":             "s.created_at",
+	"final_amount":           "s.final_amount",
+	"status":                 "s.status",
+	"point_of_sale_id":       "s.point_of_sale_id",
+	"customer_id":            "s.customer_id",
+	"customer_document":      sqlCriteria.JSONBText("s.customer_snapshot", "document_number"),
+	"customer_tax_condition": sqlCriteria.JSONBText("s.customer_snapshot", "tax_condition"),
+	"payment_method_id":      "ARRAY(SELECT p.payment_method_id::text FROM pos_sale_payments p WHERE p.pos_sale_id = s.id)",
+	"sku":                    "ARRAY(SELECT i.sku::text FROM pos_sale_items i WHERE i.pos_sale_id = s.id)",
+}
+
+// SearchPage retorna una página de ventas con sus items (HITO POS-SEARCH)
+// Keyset (created_at, id) sobre idx_pos_sales_tenant_created: la página siguiente
+// arranca en el cursor aunque entren ventas nuevas mientras se recorre
+func (r *PosSalePostgresRepository) SearchPage(ctx context.Context, c criteria.Criteria) ([]*entity.PosSale, error) {
+	// El desempate del keyset es s.id
+	query, args, err := r.converter.ToCursorSelectSQL(`SELECT `+posSaleColumns+` FROM pos_sales s`, c, "s.id")
+	if err != nil {
+		return nil, fmt.Errorf("pos sale criteria: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying pos_sales: %w", err)
 	}
 	defer rows.Close()
 
-	var sales []*entity.PosSale
-
+	sales := []*entity.PosSale{}
 	for rows.Next() {
-		sale := &entity.PosSale{}
-		err := rows.Scan(
-			&sale.ID,
-			&sale.TenantID,
-			&sale.CustomerID,
-			&sale.PaymentMethodID,
-			&sale.TotalAmount,
-			&sale.DiscountAmount,
-			&sale.FinalAmount,
-			&sale.AmountPaid,
-			&sale.Change,
-			&sale.Currency,
-			&sale.CreatedAt,
-		)
+		sale, err := scanPosSale(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning pos_sale: %w", err)
 		}
 		sales = append(sales, sale)
 	}
-
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating pos_sales: %w", err)
 	}
 
-	// 2. Obtener items para cada venta (N+1 query - simple para HITO B)
-	queryItems := `
-		SELECT 
-			id, pos_sale_id, sku, product_name,
-			quantity, unit_price, subtotal, stock_entry_id
-		FROM pos_sale_items
+	if err := r.loadPageItems(ctx, sales); err != nil {
+		return nil, err
+	}
+	return sales, nil
+}
+
+// Summarize agrega el conjunto filtrado (HITO POS-SEARCH)
+func (r *PosSalePostgresRepository) Summarize(ctx context.Context, c criteria.Criteria) (*port.PosSaleSummary, error) {
+	query, args, err := r.converter.ToCountSQL(`
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(s.total_amount), 0),
+			COALESCE(SUM(s.discount_amount), 0),
+			COALESCE(SUM(s.net_amount), 0),
+			COALESCE(SUM(s.tax_amount), 0),
+			COALESCE(SUM(s.final_amount), 0),
+			COALESCE(SUM(s.refunded_amount), 0)
+		FROM pos_sales s`, c)
+	if err != nil {
+		return nil, fmt.Errorf("pos sale criteria: %w", err)
+	}
+
+	summary := &port.PosSaleSummary{}
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(
+		&summary.SalesCount,
+		&summary.Subtotal,
+		&summary.Discount,
+		&summary.Net,
+		&summary.Tax,
+		&summary.Gross,
+		&summary.Refunded,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error summarizing pos_sales: %w", err)
+	}
+	return summary, nil
+}
+
+// FindByID retorna una venta POS con sus items y pagos
+// HITO POS-REFUND - Cada item indica si ya fue devuelto
+func (r *PosSalePostgresRepository) FindByID(ctx context.Context, tenantID, posSaleID uuid.UUID) (*entity.PosSale, error) {
+	querySale := `
+		SELECT ` + posSaleColumns + `
+		FROM pos_sales
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	sale, err := scanPosSale(r.db.QueryRowContext(ctx, querySale, posSaleID, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrPosSaleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding pos_sale: %w", err)
+	}
+
+	sale.Items, err = r.loadItems(ctx, sale.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	queryPayments := `
+		SELECT id, pos_sale_id, payment_method_id, amount, change_amount, reference, is_cash
+		FROM pos_sale_payments
 		WHERE pos_sale_id = $1
 		ORDER BY created_at
 	`
 
-	for _, sale := range sales {
-		itemRows, err := r.db.QueryContext(ctx, queryItems, sale.ID)
-		if err != nil {
-			return nil, fmt.Errorf("error querying pos_sale_items: %w", err)
+	rows, err := r.db.QueryContext(ctx, queryPayments, sale.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pos_sale_payments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var payment entity.PosSalePayment
+		if err := rows.Scan(
+			&payment.ID,
+			&payment.PosSaleID,
+			&payment.PaymentMethodID,
+			&payment.Amount,
+			&payment.ChangeAmount,
+			&payment.Reference,
+			&payment.IsCash,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning pos_sale_payment: %w", err)
 		}
+		sale.Payments = append(sale.Payments, payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pos_sale_payments: %w", err)
+	}
+
+	return sale, nil
+}
+
+// CreateRefund persiste el documento de devolución y actualiza la venta (atomically)
+// HITO POS-REFUND
+// HITO CREDIT-NOTE - Se suma a la transacción del contexto (devolución + nota de crédito)
+// - uq_pos_sale_refund_items_item impide devolver dos veces la misma línea
+// - el UPDATE condicionado a refunded_amount detecta devoluciones concurrentes
+func (r *PosSalePostgresRepository) CreateRefund(
+	ctx context.Context,
+	sale *entity.PosSale,
+	refund *entity.PosSaleRefund,
+	previousRefundedAmount decimal.Decimal,
+) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		return r.createRefund(ctx, tx, sale, refund, previousRefundedAmount)
+	})
+}
+
+// createRefund inserta el documento y actualiza la venta dentro de tx
+func (r *PosSalePostgresRepository) createRefund(
+	ctx context.Context,
+	tx *sql.Tx,
+	sale *entity.PosSale,
+	refund *entity.PosSaleRefund,
+	previousRefundedAmount decimal.Decimal,
+) error {
+	// 0. HITO POS-CASH: la caja desde la que se devuelve tiene que seguir abierta
+	if err := lockOpenCashSession(ctx, tx, refund.TenantID, refund.CashSessionID); err != nil {
+		return err
+	}
 
-		var items []entity.PosSaleItem
-
-		for itemRows.Next() {
-			item := entity.PosSaleItem{}
-			err := itemRows.Scan(
-				&item.ID,
-				&item.PosSaleID,
-				&item.SKU,
-				&item.ProductName,
-				&item.Quantity,
-				&item.UnitPrice,
-				&item.Subtotal,
-				&item.StockEntryID,
-			)
-			if err != nil {
-				itemRows.Close()
-				return nil, fmt.Errorf("error scanning pos_sale_item: %w", err)
+	// 1. Actualizar estado de la venta (solo si nadie devolvió algo en el medio)
+	querySale := `
+		UPDATE pos_sales
+		SET status = $1, refunded_amount = $2, updated_at = NOW(), version = COALESCE(version, 1) + 1
+		WHERE id = $3 AND tenant_id = $4 AND refunded_amount = $5
+	`
+
+	result, err := tx.ExecContext(ctx, querySale,
+		sale.Status,
+		sale.RefundedAmount,
+		sale.ID,
+		sale.TenantID,
+		previousRefundedAmount,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating pos_sale status: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return entity.ErrPosSaleRefundConflict
+	}
+
+	// 2. Insertar documento de devolución
+	queryRefund := `
+		INSERT INTO pos_sale_refunds (
+			id, tenant_id, pos_sale_id, refund_type, reason,
+			refund_amount, payment_method_id, currency,
+			cash_session_id, refunded_by, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+	`
+
+	_, err = tx.ExecContext(ctx, queryRefund,
+		refund.ID,
+		refund.TenantID,
+		refund.PosSaleID,
+		refund.Type,
+		refund.Reason,
+		refund.RefundAmount,
+		refund.PaymentMethodID,
+		refund.Currency,
+		refund.CashSessionID,
+		refund.RefundedBy,
+		refund.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating pos_sale_refund: %w", err)
+	}
+
+	// 3. Insertar líneas devueltas
+	queryItem := `
+		INSERT INTO pos_sale_refund_items (
+			id, pos_sale_refund_id, pos_sale_item_id, sku, quantity,
+			subtotal, refund_amount, stock_entry_id, stock_compensated, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, NOW()
+		)
+	`
+
+	for _, item := range refund.Items {
+		_, err = tx.ExecContext(ctx, queryItem,
+			item.ID,
+			item.RefundID,
+			item.PosSaleItemID,
+			item.SKU,
+			item.Quantity,
+			item.Subtotal,
+			item.RefundAmount,
+			item.StockEntryID,
+			item.StockCompensated,
+		)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+				return entity.ErrPosSaleItemAlreadyRefunded
 			}
-			items = append(items, item)
+			return fmt.Errorf("error creating pos_sale_refund_item for SKU %s: %w", item.SKU, err)
 		}
+	}
+
+	return nil
+}
+
+// MarkRefundStockCompensated marca la línea devuelta como compensada en stock-service
+func (r *PosSalePostgresRepository) MarkRefundStockCompensated(ctx context.Context, refundID uuid.UUID, stockEntryID string) error {
+	query := `
+		UPDATE pos_sale_refund_items
+		SET stock_compensated = TRUE, compensated_at = NOW()
+		WHERE pos_sale_refund_id = $1 AND stock_entry_id = $2 AND stock_compensated = FALSE
+	`
+
+	if _, err := database.Executor(ctx, r.db).ExecContext(ctx, query, refundID, stockEntryID); err != nil {
+		return fmt.Errorf("error marking stock entry %s of refund %s as compensated: %w", stockEntryID, refundID, err)
+	}
+
+	return nil
+}
+
+// lockOpenCashSession toma la fila de la sesión de caja (FOR SHARE) y verifica que siga OPEN
+// La sesión se leyó antes de tocar stock; el cierre toma la misma fila FOR UPDATE
+// (CashSessionPostgresRepository.FindByIDForUpdate), así que una venta o devolución no
+// puede caer en una caja ya arqueada y ventas simultáneas de la misma caja no se bloquean
+// entre sí. Sin sesión (nil) no hay nada que verificar
+func lockOpenCashSession(ctx context.Context, tx *sql.Tx, tenantID uuid.UUID, cashSessionID *uuid.UUID) error {
+	if cashSessionID == nil {
+		return nil
+	}
+
+	query := `
+		SELECT id
+		FROM cash_sessions
+		WHERE id = $1 AND tenant_id = $2 AND status = 'OPEN'
+		FOR SHARE
+	`
+
+	var id uuid.UUID
+	err := tx.QueryRowContext(ctx, query, *cashSessionID, tenantID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return entity.ErrCashSessionNotOpen
+	}
+	if err != nil {
+		return fmt.Errorf("error locking cash_session: %w", err)
+	}
+
+	return nil
+}
+
+// loadItems carga los items de una venta indicando si ya fueron devueltos
+func (r *PosSalePostgresRepository) loadItems(ctx context.Context, posSaleID uuid.UUID) ([]entity.PosSaleItem, error) {
+	return r.queryItems(ctx, "i.pos_sale_id = $1", posSaleID)
+}
+
+// loadPageItems carga los items de todas las ventas de una página en una sola consulta
+func (r *PosSalePostgresRepository) loadPageItems(ctx context.Context, sales []*entity.PosSale) error {
+	if len(sales) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(sales))
+	for _, sale := range sales {
+		ids = append(ids, sale.ID.String())
+	}
+
+	items, err := r.queryItems(ctx, "i.pos_sale_id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return err
+	}
 
-		itemRows.Close()
+	bySale := make(map[uuid.UUID][]entity.PosSaleItem, len(sales))
+	for _, item := range items {
+		bySale[item.PosSaleID] = append(bySale[item.PosSaleID], item)
+	}
+	for _, sale := range sales {
+		sale.Items = bySale[sale.ID]
+	}
+	return nil
+}
+
+// queryItems lee items de venta con la condición indicada (sobre el alias i)
+func (r *PosSalePostgresRepository) queryItems(ctx context.Context, condition string, arg interface{}) ([]entity.PosSaleItem, error) {
+	queryItems := `
+		SELECT
+			i.id, i.pos_sale_id, i.sku, i.product_name,
+			i.quantity, i.unit_price, i.subtotal, i.stock_entry_id,
+			(ri.id IS NOT NULL) as refunded,
+			i.tax_rate_code, i.tax_rate, i.net_amount, i.tax_amount, i.gross_amount,
+			i.list_price, i.price_overridden, COALESCE(i.price_overridden_by, '')
+		FROM pos_sale_items i
+		LEFT JOIN pos_sale_refund_items ri ON ri.pos_sale_item_id = i.id
+		WHERE ` + condition + `
+		ORDER BY i.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, queryItems, arg)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pos_sale_items: %w", err)
+	}
+	defer rows.Close()
 
-		if err = itemRows.Err(); err != nil {
-			return nil, fmt.Errorf("error iterating pos_sale_items: %w", err)
+	var items []entity.PosSaleItem
+	for rows.Next() {
+		item := entity.PosSaleItem{}
+		if err := rows.Scan(
+			&item.ID,
+			&item.PosSaleID,
+			&item.SKU,
+			&item.ProductName,
+			&item.Quantity,
+			&item.UnitPrice,
+			&item.Subtotal,
+			&item.StockEntryID,
+			&item.Refunded,
+			&item.TaxRateCode,
+			&item.TaxRate,
+			&item.NetAmount,
+			&item.TaxAmount,
+			&item.GrossAmount,
+			&item.ListPrice,
+			&item.PriceOverridden,
+			&item.PriceOverriddenBy,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning pos_sale_item: %w", err)
 		}
+		items = append(items, item)
+	}
 
-		sale.Items = items
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pos_sale_items: %w", err)
 	}
 
-	return sales, nil
+	return items, nil
+}
+
+// nullableNumber persiste 0 como NULL (ventas sin numeración)
+func nullableNumber(n int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(n), Valid: n > 0}
+}
+
+// nullableJSON convierte un JSON vacío en NULL (HITO CUSTOMER: venta sin snapshot)
+func nullableJSON(b []byte) sql.NullString {
+	return sql.NullString{String: string(b), Valid: len(b) > 0}
 }
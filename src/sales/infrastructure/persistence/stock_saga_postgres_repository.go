@@ -0,0 +1,365 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+	"sales/src/shared/infrastructure/database"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// stockSagaColumns columnas leídas en todas las consultas de stock_sagas
+const stockSagaColumns = `
+	id, tenant_id, saga_type, reference, status, COALESCE(compensation_reason, ''),
+	attempts, next_attempt_at, COALESCE(last_error, ''), created_at, updated_at
+`
+
+// StockSagaPostgresRepository implementa StockSagaRepository usando PostgreSQL
+// HITO STOCK-SAGA - Log de sagas de stock para compensaciones reintentables
+type StockSagaPostgresRepository struct {
+	db *sql.DB
+}
+
+// NewStockSagaPostgresRepository crea una nueva instancia del repositorio
+func NewStockSagaPostgresRepository(db *sql.DB) port.StockSagaRepository {
+	return &StockSagaPostgresRepository{
+		db: db,
+	}
+}
+
+// Create persiste una saga STARTED
+func (r *StockSagaPostgresRepository) Create(ctx context.Context, saga *entity.StockSaga) error {
+	query := `
+		INSERT INTO stock_sagas (
+			id, tenant_id, saga_type, reference, status, attempts,
+			next_attempt_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+	`
+
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, query,
+		saga.ID,
+		saga.TenantID,
+		saga.SagaType,
+		saga.Reference,
+		saga.Status,
+		saga.Attempts,
+		saga.NextAttemptAt,
+		saga.CreatedAt,
+		saga.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating stock saga: %w", err)
+	}
+
+	return nil
+}
+
+// AppendStep persiste un paso aplicado
+func (r *StockSagaPostgresRepository) AppendStep(ctx context.Context, step *entity.StockSagaStep) error {
+	if err := r.upsertStep(ctx, database.Executor(ctx, r.db), step); err != nil {
+		return err
+	}
+
+	query := `UPDATE stock_sagas SET updated_at = NOW() WHERE id = $1`
+	if _, err := database.Executor(ctx, r.db).ExecContext(ctx, query, step.SagaID); err != nil {
+		return fmt.Errorf("error touching stock saga %s: %w", step.SagaID, err)
+	}
+
+	return nil
+}
+
+// Update persiste la saga y sus pasos en una transacción (o en la del contexto)
+func (r *StockSagaPostgresRepository) Update(ctx context.Context, saga *entity.StockSaga) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			UPDATE stock_sagas
+			SET status = $2,
+				compensation_reason = $3,
+				attempts = $4,
+				next_attempt_at = $5,
+				last_error = $6,
+				updated_at = $7
+			WHERE id = $1
+		`
+
+		_, err := tx.ExecContext(ctx, query,
+			saga.ID,
+			saga.Status,
+			nullableText(saga.CompensationReason),
+			saga.Attempts,
+			saga.NextAttemptAt,
+			nullableText(saga.LastError),
+			saga.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("error updating stock saga %s: %w", saga.ID, err)
+		}
+
+		for i := range saga.Steps {
+			if err := r.upsertStep(ctx, tx, &saga.Steps[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ClaimDue toma sagas COMPENSATING vencidas corriendo su próximo intento en lease
+func (r *StockSagaPostgresRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.StockSaga, error) {
+	var claimed []*entity.StockSaga
+	err := database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query := `
+			UPDATE stock_sagas
+			SET next_attempt_at = $2, updated_at = NOW()
+			WHERE id IN (
+				SELECT id FROM stock_sagas
+				WHERE status = 'COMPENSATING' AND next_attempt_at <= NOW()
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + stockSagaColumns
+
+		rows, err := tx.QueryContext(ctx, query, limit, time.Now().Add(lease))
+		if err != nil {
+			return fmt.Errorf("error claiming due stock sagas: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			saga, err := scanStockSaga(rows)
+			if err != nil {
+				return fmt.Errorf("error scanning stock saga: %w", err)
+			}
+			claimed = append(claimed, saga)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return r.loadSteps(ctx, tx, claimed)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// FindCompletedByReference retorna la saga COMPLETED de una operación o nil si no hay
+func (r *StockSagaPostgresRepository) FindCompletedByReference(
+	ctx context.Context,
+	tenantID string,
+	sagaType entity.StockSagaType,
+	reference string,
+) (*entity.StockSaga, error) {
+	query := `
+		SELECT ` + stockSagaColumns + `
+		FROM stock_sagas
+		WHERE tenant_id = $1 AND saga_type = $2 AND reference = $3 AND status = 'COMPLETED'
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	saga, err := scanStockSaga(r.db.QueryRowContext(ctx, query, tenantID, sagaType, reference))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding stock saga: %w", err)
+	}
+
+	if err := r.loadSteps(ctx, r.db, []*entity.StockSaga{saga}); err != nil {
+		return nil, err
+	}
+
+	return saga, nil
+}
+
+// AbandonStale pasa a COMPENSATING las sagas STARTED abandonadas
+// La saga se completa en la misma tx que persiste la venta: STARTED vieja = venta no persistida
+func (r *StockSagaPostgresRepository) AbandonStale(ctx context.Context, olderThan time.Duration, reason string) (int64, error) {
+	query := `
+		UPDATE stock_sagas
+		SET status = 'COMPENSATING', compensation_reason = $2, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE status = 'STARTED' AND updated_at < $1
+	`
+
+	result, err := database.Executor(ctx, r.db).ExecContext(ctx, query, time.Now().Add(-olderThan), reason)
+	if err != nil {
+		return 0, fmt.Errorf("error abandoning stale stock sagas: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// ListStuck retorna las sagas sin terminar, las más viejas primero
+func (r *StockSagaPostgresRepository) ListStuck(
+	ctx context.Context,
+	tenantID string,
+	status entity.StockSagaStatus,
+	startedBefore time.Duration,
+	limit int,
+) ([]*entity.StockSaga, error) {
+	query := `
+		SELECT ` + stockSagaColumns + `
+		FROM stock_sagas
+		WHERE (
+			status IN ('COMPENSATING', 'ESCALATED')
+			OR (status = 'STARTED' AND updated_at < $1)
+		)
+		AND ($2 = '' OR status = $2)
+		AND ($3 = '' OR tenant_id = $3)
+		ORDER BY created_at
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, time.Now().Add(-startedBefore), string(status), tenantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error listing stuck stock sagas: %w", err)
+	}
+	defer rows.Close()
+
+	var sagas []*entity.StockSaga
+	for rows.Next() {
+		saga, err := scanStockSaga(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning stock saga: %w", err)
+		}
+		sagas = append(sagas, saga)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadSteps(ctx, r.db, sagas); err != nil {
+		return nil, err
+	}
+
+	return sagas, nil
+}
+
+// upsertStep inserta el paso o actualiza su estado de compensación
+func (r *StockSagaPostgresRepository) upsertStep(ctx context.Context, exec database.DBTX, step *entity.StockSagaStep) error {
+	query := `
+		INSERT INTO stock_saga_steps (
+			id, saga_id, sequence, sku, quantity, stock_entry_id, status,
+			last_error, created_at, compensated_at, step_type
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+		ON CONFLICT (id) DO UPDATE
+		SET status = EXCLUDED.status,
+			last_error = EXCLUDED.last_error,
+			compensated_at = EXCLUDED.compensated_at
+	`
+
+	_, err := exec.ExecContext(ctx, query,
+		step.ID,
+		step.SagaID,
+		step.Sequence,
+		step.SKU,
+		step.Quantity,
+		step.StockEntryID,
+		step.Status,
+		nullableText(step.LastError),
+		step.CreatedAt,
+		step.CompensatedAt,
+		step.StepType,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving stock saga step %s: %w", step.StockEntryID, err)
+	}
+
+	return nil
+}
+
+// loadSteps carga los pasos de las sagas en orden de ejecución
+func (r *StockSagaPostgresRepository) loadSteps(ctx context.Context, exec database.DBTX, sagas []*entity.StockSaga) error {
+	if len(sagas) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*entity.StockSaga, len(sagas))
+	ids := make([]string, 0, len(sagas))
+	for _, saga := range sagas {
+		byID[saga.ID] = saga
+		ids = append(ids, saga.ID.String())
+	}
+
+	query := `
+		SELECT id, saga_id, sequence, sku, quantity, stock_entry_id, status,
+			COALESCE(last_error, ''), created_at, compensated_at, step_type
+		FROM stock_saga_steps
+		WHERE saga_id = ANY($1::uuid[])
+		ORDER BY saga_id, sequence
+	`
+
+	rows, err := exec.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error loading stock saga steps: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var step entity.StockSagaStep
+		var compensatedAt sql.NullTime
+		err := rows.Scan(
+			&step.ID,
+			&step.SagaID,
+			&step.Sequence,
+			&step.SKU,
+			&step.Quantity,
+			&step.StockEntryID,
+			&step.Status,
+			&step.LastError,
+			&step.CreatedAt,
+			&compensatedAt,
+			&step.StepType,
+		)
+		if err != nil {
+			return fmt.Errorf("error scanning stock saga step: %w", err)
+		}
+		if compensatedAt.Valid {
+			step.CompensatedAt = &compensatedAt.Time
+		}
+		if saga, ok := byID[step.SagaID]; ok {
+			saga.Steps = append(saga.Steps, step)
+		}
+	}
+
+	return rows.Err()
+}
+
+// scanStockSaga mapea una fila de stockSagaColumns (sin pasos)
+func scanStockSaga(row rowScanner) (*entity.StockSaga, error) {
+	saga := &entity.StockSaga{Steps: []entity.StockSagaStep{}}
+
+	err := row.Scan(
+		&saga.ID,
+		&saga.TenantID,
+		&saga.SagaType,
+		&saga.Reference,
+		&saga.Status,
+		&saga.CompensationReason,
+		&saga.Attempts,
+		&saga.NextAttemptAt,
+		&saga.LastError,
+		&saga.CreatedAt,
+		&saga.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return saga, nil
+}
@@ -0,0 +1,167 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// TaxPostgresRepository implementa TaxRepository usando PostgreSQL
+// HITO TAX-IVA - tax_settings + tax_rules
+type TaxPostgresRepository struct {
+	db *sql.DB
+}
+
+// NewTaxPostgresRepository crea una nueva instancia del repositorio
+func NewTaxPostgresRepository(db *sql.DB) port.TaxRepository {
+	return &TaxPostgresRepository{
+		db: db,
+	}
+}
+
+// FindSettings retorna la configuración del tenant (o la configuración por defecto)
+func (r *TaxPostgresRepository) FindSettings(ctx context.Context, tenantID uuid.UUID) (*entity.TaxSettings, error) {
+	query := `
+		SELECT tenant_id, price_mode, default_rate_code, updated_at
+		FROM tax_settings
+		WHERE tenant_id = $1
+	`
+
+	settings := &entity.TaxSettings{}
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&settings.TenantID,
+		&settings.PriceMode,
+		&settings.DefaultRateCode,
+		&settings.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return entity.DefaultTaxSettings(tenantID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding tax settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// SaveSettings crea o reemplaza la configuración del tenant
+// Las ventas ya registradas conservan su propio desglose
+func (r *TaxPostgresRepository) SaveSettings(ctx context.Context, settings *entity.TaxSettings) error {
+	query := `
+		INSERT INTO tax_settings (
+			tenant_id, price_mode, default_rate_code, updated_at
+		) VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			price_mode = EXCLUDED.price_mode,
+			default_rate_code = EXCLUDED.default_rate_code,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		settings.TenantID,
+		settings.PriceMode,
+		settings.DefaultRateCode,
+		settings.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving tax settings: %w", err)
+	}
+
+	return nil
+}
+
+// ListRules retorna las reglas del tenant
+func (r *TaxPostgresRepository) ListRules(ctx context.Context, tenantID uuid.UUID) ([]*entity.TaxRule, error) {
+	query := `
+		SELECT id, tenant_id, scope, scope_value, rate_code, created_at, updated_at
+		FROM tax_rules
+		WHERE tenant_id = $1
+		ORDER BY scope, scope_value
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tax rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]*entity.TaxRule, 0)
+	for rows.Next() {
+		rule := &entity.TaxRule{}
+		if err := rows.Scan(
+			&rule.ID,
+			&rule.TenantID,
+			&rule.Scope,
+			&rule.ScopeValue,
+			&rule.RateCode,
+			&rule.CreatedAt,
+			&rule.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning tax rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tax rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// SaveRule crea o actualiza la regla (upsert por tenant + scope + scope_value)
+func (r *TaxPostgresRepository) SaveRule(ctx context.Context, rule *entity.TaxRule) error {
+	query := `
+		INSERT INTO tax_rules (
+			id, tenant_id, scope, scope_value, rate_code, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)
+		ON CONFLICT (tenant_id, scope, scope_value) DO UPDATE SET
+			rate_code = EXCLUDED.rate_code,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rule.ID,
+		rule.TenantID,
+		rule.Scope,
+		rule.ScopeValue,
+		rule.RateCode,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	).Scan(&rule.ID, &rule.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving tax rule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRule elimina una regla del tenant
+func (r *TaxPostgresRepository) DeleteRule(ctx context.Context, tenantID, ruleID uuid.UUID) error {
+	query := `
+		DELETE FROM tax_rules
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, ruleID, tenantID)
+	if err != nil {
+		return fmt.Errorf("error deleting tax rule: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return entity.ErrTaxRuleNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package stock
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/infrastructure/client"
+)
+
+// Handler expone el fake con la API HTTP de stock-service que consume StockClient
+// Uso: httptest.NewServer(fake.Handler()) + client.NewStockClientWithURL(server.URL, "")
+// Fallas inyectadas → 503, stock insuficiente → 409, SKU / movimiento inexistente → 404
+func (f *FakeStockService) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/availability", f.handleAvailability)
+	mux.HandleFunc("POST /api/v1/reserve", f.handleQuantityOperation(FakeOpReserve))
+	mux.HandleFunc("POST /api/v1/release", f.handleQuantityOperation(FakeOpRelease))
+	mux.HandleFunc("POST /api/v1/consume", f.handleQuantityOperation(FakeOpConsume))
+	mux.HandleFunc("POST /api/v1/revert-consume", f.handleQuantityOperation(FakeOpRevert))
+	mux.HandleFunc("POST /api/v1/sale", f.handleSale)
+	mux.HandleFunc("POST /api/v1/compensate-sale", f.handleCompensateSale)
+	mux.HandleFunc("GET /api/v1/capabilities", f.handleCapabilities)
+	mux.HandleFunc("POST /api/v1/sale/batch", f.handleSaleBatch)
+	return requireTenant(mux)
+}
+
+// handleAvailability GET /api/v1/availability?sku=...
+func (f *FakeStockService) handleAvailability(w http.ResponseWriter, r *http.Request) {
+	availability, _, err := f.ValidateStock(r.Context(), r.Header.Get("X-Tenant-ID"), r.Header.Get("Authorization"), r.URL.Query().Get("sku"), 0)
+	if err != nil {
+		writeFakeError(w, err)
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, availability)
+}
+
+// handleQuantityOperation reserve / release / consume / revert-consume (mismo body)
+func (f *FakeStockService) handleQuantityOperation(op FakeStockOperation) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req client.StockReserveRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeFakeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		tenantID, authToken := r.Header.Get("X-Tenant-ID"), r.Header.Get("Authorization")
+		var result interface{}
+		var err error
+		switch op {
+		case FakeOpReserve:
+			result, err = f.ReserveStock(r.Context(), tenantID, authToken, req.SKU, req.Quantity, req.Reference)
+		case FakeOpRelease:
+			result, err = f.ReleaseStock(r.Context(), tenantID, authToken, req.SKU, req.Quantity, req.Reference)
+		case FakeOpConsume:
+			result, err = f.ConsumeStock(r.Context(), tenantID, authToken, req.SKU, req.Quantity, req.Reference)
+		case FakeOpRevert:
+			result, err = f.RevertConsume(r.Context(), tenantID, authToken, req.SKU, req.Quantity, req.Reference)
+		}
+		if err != nil {
+			writeFakeError(w, err)
+			return
+		}
+		writeFakeJSON(w, http.StatusOK, result)
+	}
+}
+
+// handleSale POST /api/v1/sale (venta atómica; success=false → 400 con el mismo body)
+func (f *FakeStockService) handleSale(w http.ResponseWriter, r *http.Request) {
+	var req client.ProcessSaleAtomicRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeFakeJSON(w, http.StatusBadRequest, map[string]interface{}{"success": false, "message": err.Error()})
+		return
+	}
+
+	result, err := f.ProcessSaleAtomic(r.Context(), r.Header.Get("X-Tenant-ID"), r.Header.Get("Authorization"), req.VariantSKU, req.Quantity, req.Reference)
+	if err != nil {
+		writeFakeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"success": false, "message": err.Error()})
+		return
+	}
+	if !result.Success {
+		writeFakeJSON(w, http.StatusBadRequest, result)
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, result)
+}
+
+// handleCompensateSale POST /api/v1/compensate-sale
+func (f *FakeStockService) handleCompensateSale(w http.ResponseWriter, r *http.Request) {
+	var req client.CompensateSaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeFakeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if err := f.CompensateSale(r.Context(), r.Header.Get("X-Tenant-ID"), r.Header.Get("Authorization"), req.StockEntryID, req.Reason); err != nil {
+		writeFakeError(w, err)
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{"success": true, "stock_entry_id": req.StockEntryID})
+}
+
+// handleCapabilities GET /api/v1/capabilities (404 sin venta en lote, como un stock-service viejo)
+func (f *FakeStockService) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	if !f.SupportsBatchSale(r.Context(), r.Header.Get("X-Tenant-ID"), r.Header.Get("Authorization")) {
+		http.NotFound(w, r)
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, client.StockCapabilitiesResponse{Features: []string{"sale_batch"}})
+}
+
+// handleSaleBatch POST /api/v1/sale/batch (success=false → 409 con el detalle por línea)
+func (f *FakeStockService) handleSaleBatch(w http.ResponseWriter, r *http.Request) {
+	var req client.ProcessSaleBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeFakeJSON(w, http.StatusBadRequest, map[string]interface{}{"success": false, "message": err.Error()})
+		return
+	}
+
+	result, err := f.ProcessSaleBatch(r.Context(), r.Header.Get("X-Tenant-ID"), r.Header.Get("Authorization"), req.Lines, req.Reference)
+	if errors.Is(err, entity.ErrStockBatchNotSupported) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		writeFakeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"success": false, "message": err.Error()})
+		return
+	}
+	if !result.Success {
+		writeFakeJSON(w, http.StatusConflict, result)
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, result)
+}
+
+// requireTenant rechaza requests sin X-Tenant-ID (igual que stock-service)
+func requireTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Tenant-ID") == "" {
+			writeFakeJSON(w, http.StatusBadRequest, map[string]string{"error": "X-Tenant-ID header is required"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeFakeError mapea los errores del fake a status HTTP
+func writeFakeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrFakeStockUnavailable):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, errFakeInsufficientStock):
+		status = http.StatusConflict
+	case errors.Is(err, errFakeNotFound):
+		status = http.StatusNotFound
+	}
+	writeFakeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
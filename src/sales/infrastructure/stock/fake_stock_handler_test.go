@@ -0,0 +1,61 @@
+package stock
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"sales/src/sales/infrastructure/client"
+)
+
+// HITO RESILIENCE - StockClient contra el fake servido por HTTP: qué se reintenta y qué no
+
+func newFakeStockClient(t *testing.T) (*FakeStockService, *client.StockClient) {
+	t.Helper()
+	t.Setenv("STOCK_SERVICE_MAX_ATTEMPTS", "3")
+	fake := NewFakeStockService()
+	srv := httptest.NewServer(fake.Handler())
+	t.Cleanup(srv.Close)
+	return fake, client.NewStockClientWithURL(srv.URL, "")
+}
+
+func TestStockClientDoesNotRetryCompensateSale(t *testing.T) {
+	fake, stockClient := newFakeStockClient(t)
+	fake.SetStock("tenant-1", "SKU-A", 10)
+
+	sale, err := fake.ProcessSaleAtomic(context.Background(), "tenant-1", "", "SKU-A", 2, "POS-1")
+	if err != nil || !sale.Success {
+		t.Fatalf("ProcessSaleAtomic = %+v, %v", sale, err)
+	}
+
+	// Un 503 no se reintenta: stock-service podría haber creado el movimiento inverso
+	fake.FailNext(FakeOpCompensate, 1)
+	if err := stockClient.CompensateSale(context.Background(), "tenant-1", "", sale.StockEntryID, "test"); err == nil {
+		t.Fatal("CompensateSale succeeded, want the injected 503")
+	}
+	if got := fake.Calls(FakeOpCompensate); got != 1 {
+		t.Errorf("compensate calls = %d, want 1 (no retries)", got)
+	}
+
+	// El reintento es de la saga: la siguiente llamada compensa
+	if err := stockClient.CompensateSale(context.Background(), "tenant-1", "", sale.StockEntryID, "test"); err != nil {
+		t.Fatalf("CompensateSale: %v", err)
+	}
+	if level, _ := fake.Stock("tenant-1", "SKU-A"); level.Available != 10 {
+		t.Errorf("available = %.0f, want 10", level.Available)
+	}
+}
+
+func TestStockClientRetriesIdempotentReads(t *testing.T) {
+	fake, stockClient := newFakeStockClient(t)
+	fake.SetStock("tenant-1", "SKU-A", 10)
+
+	fake.FailNext(FakeOpValidate, 1)
+	_, ok, err := stockClient.ValidateStock(context.Background(), "tenant-1", "", "SKU-A", 2)
+	if err != nil || !ok {
+		t.Fatalf("ValidateStock = %v, %v; want available after one retry", ok, err)
+	}
+	if got := fake.Calls(FakeOpValidate); got != 2 {
+		t.Errorf("availability calls = %d, want 2", got)
+	}
+}
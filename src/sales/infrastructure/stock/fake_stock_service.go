@@ -0,0 +1,489 @@
+package stock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"sales/src/sales/domain/entity"
+	"sales/src/sales/domain/port"
+
+	"github.com/google/uuid"
+)
+
+// FakeStockOperation operación del fake sobre la que se inyectan fallas
+type FakeStockOperation string
+
+const (
+	FakeOpAny        FakeStockOperation = ""
+	FakeOpValidate   FakeStockOperation = "validate"
+	FakeOpReserve    FakeStockOperation = "reserve"
+	FakeOpRelease    FakeStockOperation = "release"
+	FakeOpConsume    FakeStockOperation = "consume"
+	FakeOpRevert     FakeStockOperation = "revert-consume"
+	FakeOpSale       FakeStockOperation = "sale"
+	FakeOpCompensate FakeStockOperation = "compensate-sale"
+	FakeOpSaleBatch  FakeStockOperation = "sale-batch"
+)
+
+// ErrFakeStockUnavailable error transitorio inyectado con FailNext
+// Vía HTTP se responde 503
+var ErrFakeStockUnavailable = errors.New("fake stock-service unavailable")
+
+// Errores internos del fake (mapeados a status en Handler)
+var (
+	// errFakeInsufficientStock rechazo de negocio (vía HTTP se responde 409)
+	// Es el error del contrato de StockGateway (ReserveStock)
+	errFakeInsufficientStock = entity.ErrInsufficientStock
+	// errFakeNotFound SKU sin inicializar o stock_entry_id inexistente (vía HTTP 404)
+	errFakeNotFound = errors.New("not found")
+)
+
+// FakeStockLevel niveles de stock de un SKU en el fake
+type FakeStockLevel struct {
+	Available float64
+	Reserved  float64
+	Total     float64
+}
+
+// FakeStockEntry movimiento de venta atómica registrado por el fake
+type FakeStockEntry struct {
+	ID            string
+	TenantID      string
+	SKU           string
+	Quantity      float64
+	Reference     string
+	Compensated   bool
+	Compensations int // Llamadas a CompensateSale recibidas (detecta compensaciones duplicadas)
+	Reason        string
+}
+
+// FakeStockService implementación en memoria de StockGateway (desarrollo / tests)
+// HITO STOCK-GATEWAY - Permite probar de punta a punta los caminos de compensación.
+// Simula stock-service por tenant + SKU: reservas, consumos, ventas atómicas con
+// stock_entry_id y compensaciones idempotentes. Permite fijar niveles de stock,
+// latencia por llamada e inyectar fallas transitorias por operación o rechazos por SKU.
+// Handler() lo expone con la misma API HTTP que consume StockClient (httptest)
+type FakeStockService struct {
+	mu          sync.Mutex
+	levels      map[string]*FakeStockLevel
+	entries     map[string]*FakeStockEntry
+	latency     time.Duration
+	failures    map[FakeStockOperation]int
+	rejectedSKU map[string]string
+	calls       map[FakeStockOperation]int
+	inFlight    map[FakeStockOperation]int
+	maxInFlight map[FakeStockOperation]int
+	noBatch     bool // HITO STOCK-BATCH: simula un stock-service sin POST /sale/batch
+}
+
+// NewFakeStockService crea una nueva instancia sin stock cargado
+func NewFakeStockService() *FakeStockService {
+	return &FakeStockService{
+		levels:      make(map[string]*FakeStockLevel),
+		entries:     make(map[string]*FakeStockEntry),
+		failures:    make(map[FakeStockOperation]int),
+		rejectedSKU: make(map[string]string),
+		calls:       make(map[FakeStockOperation]int),
+		inFlight:    make(map[FakeStockOperation]int),
+		maxInFlight: make(map[FakeStockOperation]int),
+	}
+}
+
+// SetStock fija el stock disponible de un SKU (sin reservas)
+func (f *FakeStockService) SetStock(tenantID, sku string, available float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.levels[levelKey(tenantID, sku)] = &FakeStockLevel{Available: available, Total: available}
+}
+
+// Stock retorna los niveles actuales de un SKU (ok = false si no está inicializado)
+func (f *FakeStockService) Stock(tenantID, sku string) (FakeStockLevel, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	level, ok := f.levels[levelKey(tenantID, sku)]
+	if !ok {
+		return FakeStockLevel{}, false
+	}
+	return *level, true
+}
+
+// SetLatency agrega una demora fija a cada llamada
+func (f *FakeStockService) SetLatency(latency time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = latency
+}
+
+// FailNext hace que las próximas n llamadas de op fallen con ErrFakeStockUnavailable
+// FakeOpAny aplica a cualquier operación
+func (f *FakeStockService) FailNext(op FakeStockOperation, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[op] = n
+}
+
+// RejectSKU hace que las ventas atómicas del SKU se rechacen (success=false) con message
+// Un message vacío quita el rechazo
+func (f *FakeStockService) RejectSKU(sku, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if message == "" {
+		delete(f.rejectedSKU, sku)
+		return
+	}
+	f.rejectedSKU[sku] = message
+}
+
+// SetBatchSupport habilita o deshabilita la venta en lote (habilitada por defecto)
+func (f *FakeStockService) SetBatchSupport(supported bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.noBatch = !supported
+}
+
+// Entry retorna un movimiento de venta por su stock_entry_id
+func (f *FakeStockService) Entry(stockEntryID string) (FakeStockEntry, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.entries[stockEntryID]
+	if !ok {
+		return FakeStockEntry{}, false
+	}
+	return *entry, true
+}
+
+// Calls cantidad de llamadas recibidas por operación (fallidas incluidas)
+func (f *FakeStockService) Calls(op FakeStockOperation) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[op]
+}
+
+// ValidateStock retorna la disponibilidad del SKU
+func (f *FakeStockService) ValidateStock(ctx context.Context, tenantID, authToken, sku string, quantity int) (*port.StockAvailability, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, FakeOpValidate); err != nil {
+		return nil, false, err
+	}
+
+	level, err := f.level(tenantID, sku)
+	if err != nil {
+		return nil, false, err
+	}
+
+	availability := &port.StockAvailability{
+		VariantSKU:        sku,
+		AvailableQuantity: level.Available,
+		ReservedQuantity:  level.Reserved,
+		TotalQuantity:     level.Total,
+		IsOutOfStock:      level.Available <= 0,
+	}
+	return availability, level.Available >= float64(quantity), nil
+}
+
+// ReserveStock reserva stock (available↓, reserved↑)
+func (f *FakeStockService) ReserveStock(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*port.StockReservation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, FakeOpReserve); err != nil {
+		return nil, err
+	}
+
+	level, err := f.level(tenantID, sku)
+	if err != nil {
+		return nil, err
+	}
+	if level.Available < float64(quantity) {
+		return nil, fmt.Errorf("%w: %s", errFakeInsufficientStock, sku)
+	}
+
+	level.Available -= float64(quantity)
+	level.Reserved += float64(quantity)
+	return &port.StockReservation{
+		SKU:          sku,
+		ReservedQty:  quantity,
+		RemainingQty: int(level.Available),
+		Reference:    reference,
+	}, nil
+}
+
+// ReleaseStock libera una reserva (reserved↓, available↑)
+func (f *FakeStockService) ReleaseStock(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*port.StockRelease, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, FakeOpRelease); err != nil {
+		return nil, err
+	}
+
+	level, err := f.level(tenantID, sku)
+	if err != nil {
+		return nil, err
+	}
+	if level.Reserved < float64(quantity) {
+		return nil, fmt.Errorf("%w: reserved %s", errFakeInsufficientStock, sku)
+	}
+
+	level.Reserved -= float64(quantity)
+	level.Available += float64(quantity)
+	return &port.StockRelease{
+		SKU:          sku,
+		ReleasedQty:  quantity,
+		AvailableQty: int(level.Available),
+		ReservedQty:  int(level.Reserved),
+		Reference:    reference,
+	}, nil
+}
+
+// ConsumeStock consume stock reservado (reserved↓, total↓)
+func (f *FakeStockService) ConsumeStock(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*port.StockConsumption, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, FakeOpConsume); err != nil {
+		return nil, err
+	}
+
+	level, err := f.level(tenantID, sku)
+	if err != nil {
+		return nil, err
+	}
+	if level.Reserved < float64(quantity) {
+		return nil, fmt.Errorf("%w: reserved %s", errFakeInsufficientStock, sku)
+	}
+
+	level.Reserved -= float64(quantity)
+	level.Total -= float64(quantity)
+	return &port.StockConsumption{
+		SKU:         sku,
+		ConsumedQty: quantity,
+		ReservedQty: int(level.Reserved),
+		Reference:   reference,
+	}, nil
+}
+
+// RevertConsume revierte un consumo (total↑, available↑)
+func (f *FakeStockService) RevertConsume(ctx context.Context, tenantID, authToken, sku string, quantity int, reference string) (*port.StockConsumeReversal, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, FakeOpRevert); err != nil {
+		return nil, err
+	}
+
+	level, err := f.level(tenantID, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	level.Total += float64(quantity)
+	level.Available += float64(quantity)
+	return &port.StockConsumeReversal{
+		SKU:          sku,
+		RevertedQty:  quantity,
+		AvailableQty: int(level.Available),
+		Reference:    reference,
+	}, nil
+}
+
+// ProcessSaleAtomic valida y descuenta en una sola operación
+// Stock insuficiente, SKU sin inicializar o rechazado con RejectSKU → Success=false
+func (f *FakeStockService) ProcessSaleAtomic(ctx context.Context, tenantID, authToken, sku string, quantity float64, reference string) (*port.StockSaleResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, FakeOpSale); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if message, rejected := f.rejectedSKU[sku]; rejected {
+		return &port.StockSaleResult{Success: false, Message: message, VariantSKU: sku, Timestamp: now}, nil
+	}
+	level, ok := f.levels[levelKey(tenantID, sku)]
+	if !ok {
+		return &port.StockSaleResult{Success: false, Message: "stock not initialized for sku " + sku, VariantSKU: sku, Timestamp: now}, nil
+	}
+	if level.Available < quantity {
+		return &port.StockSaleResult{
+			Success:        false,
+			Message:        fmt.Sprintf("insufficient stock: available %.2f, requested %.2f", level.Available, quantity),
+			VariantSKU:     sku,
+			RemainingStock: level.Available,
+			TotalQuantity:  level.Total,
+			Timestamp:      now,
+		}, nil
+	}
+
+	level.Available -= quantity
+	level.Total -= quantity
+	entry := &FakeStockEntry{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		SKU:       sku,
+		Quantity:  quantity,
+		Reference: reference,
+	}
+	f.entries[entry.ID] = entry
+
+	return &port.StockSaleResult{
+		Success:        true,
+		Message:        "sale processed",
+		VariantSKU:     sku,
+		QuantitySold:   quantity,
+		RemainingStock: level.Available,
+		TotalQuantity:  level.Total,
+		StockEntryID:   entry.ID,
+		Timestamp:      now,
+	}, nil
+}
+
+// CompensateSale revierte una venta atómica
+// El fake deduplica por stock_entry_id (la segunda vez no cambia el stock) y cuenta las
+// llamadas en Compensations; stock-service no lo garantiza, por eso StockClient no reintenta
+func (f *FakeStockService) CompensateSale(ctx context.Context, tenantID, authToken, stockEntryID, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, FakeOpCompensate); err != nil {
+		return err
+	}
+
+	entry, ok := f.entries[stockEntryID]
+	if !ok || entry.TenantID != tenantID {
+		return fmt.Errorf("%w: stock entry %s", errFakeNotFound, stockEntryID)
+	}
+
+	entry.Compensations++
+	if entry.Compensated {
+		return nil
+	}
+
+	level := f.levels[levelKey(tenantID, entry.SKU)]
+	level.Available += entry.Quantity
+	level.Total += entry.Quantity
+	entry.Compensated = true
+	entry.Reason = reason
+	return nil
+}
+
+// SupportsBatchSale indica si la venta en lote está habilitada (SetBatchSupport)
+func (f *FakeStockService) SupportsBatchSale(ctx context.Context, tenantID, authToken string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.noBatch
+}
+
+// ProcessSaleBatch valida todas las líneas y, solo si todas alcanzan, las descuenta
+// Las líneas de un mismo SKU se acumulan; cualquier rechazo deja el stock intacto
+func (f *FakeStockService) ProcessSaleBatch(ctx context.Context, tenantID, authToken string, lines []port.StockSaleLine, reference string) (*port.StockSaleBatchResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, FakeOpSaleBatch); err != nil {
+		return nil, err
+	}
+	if f.noBatch {
+		return nil, entity.ErrStockBatchNotSupported
+	}
+
+	now := time.Now()
+	result := &port.StockSaleBatchResult{Success: true, Message: "sale batch processed", Timestamp: now}
+	requested := make(map[string]float64)
+	for _, line := range lines {
+		lineResult := port.StockSaleResult{Success: true, VariantSKU: line.VariantSKU, QuantitySold: line.Quantity, Timestamp: now}
+		level, ok := f.levels[levelKey(tenantID, line.VariantSKU)]
+		message, rejected := f.rejectedSKU[line.VariantSKU]
+		switch {
+		case rejected:
+			lineResult.Message = message
+		case !ok:
+			lineResult.Message = "stock not initialized for sku " + line.VariantSKU
+		case level.Available < requested[line.VariantSKU]+line.Quantity:
+			lineResult.Message = fmt.Sprintf("insufficient stock: available %.2f, requested %.2f",
+				level.Available-requested[line.VariantSKU], line.Quantity)
+		default:
+			requested[line.VariantSKU] += line.Quantity
+			lineResult.RemainingStock = level.Available - requested[line.VariantSKU]
+			lineResult.TotalQuantity = level.Total - requested[line.VariantSKU]
+		}
+		if lineResult.Message != "" {
+			lineResult.Success = false
+			lineResult.QuantitySold = 0
+			result.Success = false
+			result.Message = "sale batch rejected"
+		}
+		result.Lines = append(result.Lines, lineResult)
+	}
+	if !result.Success {
+		return result, nil
+	}
+
+	for i, line := range lines {
+		level := f.levels[levelKey(tenantID, line.VariantSKU)]
+		level.Available -= line.Quantity
+		level.Total -= line.Quantity
+		entry := &FakeStockEntry{
+			ID:        uuid.New().String(),
+			TenantID:  tenantID,
+			SKU:       line.VariantSKU,
+			Quantity:  line.Quantity,
+			Reference: line.Reference,
+		}
+		f.entries[entry.ID] = entry
+		result.Lines[i].StockEntryID = entry.ID
+		result.Lines[i].Message = "sale processed"
+	}
+	return result, nil
+}
+
+// MaxInFlight máximo de llamadas de op atendidas a la vez (se superponen durante la latencia)
+func (f *FakeStockService) MaxInFlight(op FakeStockOperation) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.maxInFlight[op]
+}
+
+// begin cuenta la llamada, aplica la latencia y consume una falla inyectada
+// Se llama con el mutex tomado; la latencia se aplica sin bloquear otras llamadas
+func (f *FakeStockService) begin(ctx context.Context, op FakeStockOperation) error {
+	f.calls[op]++
+	f.inFlight[op]++
+	if f.inFlight[op] > f.maxInFlight[op] {
+		f.maxInFlight[op] = f.inFlight[op]
+	}
+	defer func() { f.inFlight[op]-- }()
+
+	if f.latency > 0 {
+		latency := f.latency
+		f.mu.Unlock()
+		timer := time.NewTimer(latency)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			f.mu.Lock()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		f.mu.Lock()
+	}
+
+	for _, key := range []FakeStockOperation{op, FakeOpAny} {
+		if f.failures[key] > 0 {
+			f.failures[key]--
+			return fmt.Errorf("%w (%s)", ErrFakeStockUnavailable, op)
+		}
+	}
+	return nil
+}
+
+// level retorna los niveles de un SKU inicializado
+func (f *FakeStockService) level(tenantID, sku string) (*FakeStockLevel, error) {
+	level, ok := f.levels[levelKey(tenantID, sku)]
+	if !ok {
+		return nil, fmt.Errorf("%w: sku %s", errFakeNotFound, sku)
+	}
+	return level, nil
+}
+
+func levelKey(tenantID, sku string) string {
+	return tenantID + "|" + sku
+}
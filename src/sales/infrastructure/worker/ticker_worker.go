@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// BatchProcessor procesa un lote de trabajo pendiente (hasta limit) y retorna cuántos procesó
+// Lo implementan los casos de uso ProcessDue: outbox, sagas de stock, reservas y CAE
+type BatchProcessor interface {
+	ProcessDue(ctx context.Context, limit int) (int, error)
+}
+
+// TickerWorker procesa en segundo plano el trabajo pendiente de un BatchProcessor
+// HITO OUTBOX / STOCK-SAGA / ORDER-RESERVATION / FISCAL - Los reintentos con backoff
+// (next_attempt_at) los decide cada caso de uso; el worker solo marca el ritmo
+type TickerWorker struct {
+	name      string
+	processor BatchProcessor
+	interval  time.Duration
+	batchSize int
+}
+
+// NewTickerWorker crea una nueva instancia del worker (name solo se usa en los logs)
+func NewTickerWorker(name string, processor BatchProcessor, interval time.Duration, batchSize int) *TickerWorker {
+	return &TickerWorker{
+		name:      name,
+		processor: processor,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run procesa lotes cada interval hasta que se cancele el contexto
+// Si un lote sale completo se procesa el siguiente sin esperar
+func (w *TickerWorker) Run(ctx context.Context) {
+	log.Printf("⏱️ %s worker started (interval=%s, batch=%d)", w.name, w.interval, w.batchSize)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			processed, err := w.processor.ProcessDue(ctx, w.batchSize)
+			if err != nil {
+				log.Printf("❌ %s worker: %v", w.name, err)
+				break
+			}
+			if processed < w.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("⏱️ %s worker stopped", w.name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// countingProcessor retorna los resultados en orden; el primer lote incompleto cancela el contexto
+type countingProcessor struct {
+	results []int
+	calls   int
+	cancel  context.CancelFunc
+}
+
+func (p *countingProcessor) ProcessDue(ctx context.Context, limit int) (int, error) {
+	processed := p.results[p.calls]
+	p.calls++
+	if processed < limit {
+		p.cancel()
+	}
+	return processed, nil
+}
+
+func TestTickerWorkerDrainsFullBatchesWithoutWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processor := &countingProcessor{results: []int{5, 5, 2}, cancel: cancel}
+
+	// interval largo: solo los lotes completos pueden disparar otra pasada antes del ticker
+	done := make(chan struct{})
+	go func() {
+		NewTickerWorker("Test", processor, time.Hour, 5).Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not stop after the context was canceled")
+	}
+	if processor.calls != 3 {
+		t.Errorf("ProcessDue calls = %d, want 3 (two full batches, then the last one)", processor.calls)
+	}
+}
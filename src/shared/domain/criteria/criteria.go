@@ -10,6 +10,7 @@ type Criteria struct {
 	Order   Order
 	Limit   *int
 	Offset  *int
+	Cursor  *Cursor // Paginación por cursor (keyset); excluye Offset
 }
 
 // NewCriteria crea un nuevo objeto Criteria
@@ -22,14 +23,25 @@ func NewCriteria(filters Filters, order Order, limit, offset *int) Criteria {
 	}
 }
 
+// WithCursor pasa el criteria a paginación por cursor (sin Offset)
+func (c Criteria) WithCursor(cursor *Cursor) Criteria {
+	c.Cursor = cursor
+	if cursor != nil {
+		c.Offset = nil
+	}
+	return c
+}
+
 // IsEmpty verifica si el criteria está vacío
 func (c Criteria) IsEmpty() bool {
-	return len(c.Filters.Items) == 0 && c.Order.Field == "" && c.Limit == nil && c.Offset == nil
+	return c.Filters.IsEmpty() && c.Order.Field == "" && c.Limit == nil && c.Offset == nil && c.Cursor == nil
 }
 
 // Filters representa una colección de filtros
+// Items y Groups se combinan con AND
 type Filters struct {
-	Items []Filter
+	Items  []Filter
+	Groups []FilterGroup
 }
 
 // Filter representa un filtro individual
@@ -58,14 +70,82 @@ func (f *Filters) Add(filter Filter) {
 	f.Items = append(f.Items, filter)
 }
 
+// AddGroup agrega un grupo AND / OR / NOT a la colección
+func (f *Filters) AddGroup(group FilterGroup) {
+	f.Groups = append(f.Groups, group)
+}
+
 // Count retorna el número de filtros
 func (f Filters) Count() int {
-	return len(f.Items)
+	return len(f.Items) + len(f.Groups)
 }
 
 // IsEmpty verifica si no hay filtros
 func (f Filters) IsEmpty() bool {
-	return len(f.Items) == 0
+	return len(f.Items) == 0 && len(f.Groups) == 0
+}
+
+// Fields retorna los campos de todos los filtros, incluidos los de los grupos
+func (f Filters) Fields() []string {
+	var fields []string
+	for _, filter := range f.Items {
+		fields = append(fields, filter.Field)
+	}
+	for _, group := range f.Groups {
+		fields = append(fields, group.Fields()...)
+	}
+	return fields
+}
+
+// LogicalOperator combina las condiciones de un grupo
+type LogicalOperator string
+
+const (
+	// LogicalAnd todas las condiciones del grupo
+	LogicalAnd LogicalOperator = "AND"
+	// LogicalOr alguna condición del grupo
+	LogicalOr LogicalOperator = "OR"
+	// LogicalNot niega el grupo (sus condiciones combinadas con AND)
+	LogicalNot LogicalOperator = "NOT"
+)
+
+// FilterGroup grupo de filtros anidable: and(...), or(...), not(...)
+type FilterGroup struct {
+	Operator LogicalOperator
+	Filters  []Filter
+	Groups   []FilterGroup
+}
+
+// NewFilterGroup crea un grupo con los filtros indicados
+func NewFilterGroup(operator LogicalOperator, filters ...Filter) FilterGroup {
+	return FilterGroup{Operator: operator, Filters: filters}
+}
+
+// Add agrega un filtro al grupo
+func (g *FilterGroup) Add(filter Filter) {
+	g.Filters = append(g.Filters, filter)
+}
+
+// AddGroup anida un grupo dentro del grupo
+func (g *FilterGroup) AddGroup(group FilterGroup) {
+	g.Groups = append(g.Groups, group)
+}
+
+// IsEmpty verifica si el grupo no tiene condiciones
+func (g FilterGroup) IsEmpty() bool {
+	return len(g.Filters) == 0 && len(g.Groups) == 0
+}
+
+// Fields retorna los campos de las condiciones del grupo y sus subgrupos
+func (g FilterGroup) Fields() []string {
+	var fields []string
+	for _, filter := range g.Filters {
+		fields = append(fields, filter.Field)
+	}
+	for _, group := range g.Groups {
+		fields = append(fields, group.Fields()...)
+	}
+	return fields
 }
 
 // Order representa un orden para la consulta
@@ -157,12 +237,16 @@ func GetTotalPagesFromLimit(totalCount, limit int) int {
 }
 
 // ListResponse representa una respuesta de listado genérica
+// Con paginación por cursor no hay número de página: se navega con next_cursor /
+// prev_cursor (ausentes en la última / primera página)
 type ListResponse[T any] struct {
-	Items      []*T `json:"items"`
-	TotalCount int  `json:"total_count"`
-	Page       int  `json:"page"`
-	PageSize   int  `json:"page_size"`
-	TotalPages int  `json:"total_pages"`
+	Items      []*T   `json:"items"`
+	TotalCount int    `json:"total_count"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 // NewListResponse crea una nueva respuesta de listado
@@ -192,3 +276,61 @@ func NewListResponse[T any](items []*T, totalCount int, criteria Criteria) *List
 		TotalPages: totalPages,
 	}
 }
+
+// NewCursorListResponse arma la página de una consulta por cursor
+// items son las filas tal como las devolvió el repositorio: hasta Limit+1, en el
+// orden de la consulta (invertido si el cursor es prev). La fila de más indica que
+// hay otra página en ese sentido; keys retorna las claves de orden de una fila
+// (campo de orden + desempate) con las que se arman next_cursor y prev_cursor
+func NewCursorListResponse[T any](items []*T, totalCount int, criteria Criteria, keys func(item *T) []interface{}) *ListResponse[T] {
+	cursor := criteria.Cursor
+	if cursor == nil {
+		cursor = NewFirstPageCursor(criteria.Order)
+	}
+
+	pageSize := len(items)
+	if criteria.Limit != nil {
+		pageSize = *criteria.Limit
+	}
+
+	hasMore := len(items) > pageSize
+	if hasMore {
+		items = items[:pageSize]
+	}
+	if cursor.Backward() {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	resp := &ListResponse[T]{
+		Items:      items,
+		TotalCount: totalCount,
+		PageSize:   pageSize,
+		TotalPages: GetTotalPagesFromLimit(totalCount, pageSize),
+	}
+	if len(items) == 0 {
+		return resp
+	}
+
+	// Hacia adelante siempre se vuelve de donde se vino; hacia atrás, lo mismo al revés
+	hasNext, hasPrev := hasMore, !cursor.IsFirstPage()
+	if cursor.Backward() {
+		hasNext, hasPrev = true, hasMore
+	}
+	if hasNext {
+		resp.NextCursor = EncodeCursor(Cursor{Direction: CursorNext, Order: cursor.Order, Values: cursorValues(keys(items[len(items)-1]))})
+	}
+	if hasPrev {
+		resp.PrevCursor = EncodeCursor(Cursor{Direction: CursorPrev, Order: cursor.Order, Values: cursorValues(keys(items[0]))})
+	}
+	return resp
+}
+
+func cursorValues(keys []interface{}) []string {
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, CursorValue(key))
+	}
+	return values
+}
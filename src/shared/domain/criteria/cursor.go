@@ -0,0 +1,164 @@
+package criteria
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidCursor el token no fue emitido por este servicio, fue alterado o no
+// corresponde al orden pedido
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorDirection sentido en que se recorre desde la fila frontera del cursor
+type CursorDirection string
+
+const (
+	// CursorNext filas que siguen a la frontera (next_cursor)
+	CursorNext CursorDirection = "next"
+	// CursorPrev filas que preceden a la frontera (prev_cursor)
+	CursorPrev CursorDirection = "prev"
+)
+
+// Cursor posición de una página en la paginación por cursor (keyset)
+// Values son las claves de orden de la fila frontera: el campo de orden y el
+// desempate del repositorio (ej. created_at, id). Sin Values es la primera página.
+// Se viaja como token opaco y firmado (EncodeCursor / DecodeCursor)
+type Cursor struct {
+	Direction CursorDirection
+	Order     Order
+	Values    []string
+}
+
+// NewFirstPageCursor activa la paginación por cursor desde la primera página
+func NewFirstPageCursor(order Order) *Cursor {
+	return &Cursor{Direction: CursorNext, Order: order}
+}
+
+// IsFirstPage indica que el cursor no tiene fila frontera
+func (c *Cursor) IsFirstPage() bool {
+	return len(c.Values) == 0
+}
+
+// Backward indica que la consulta recorre el orden al revés (prev)
+func (c *Cursor) Backward() bool {
+	return c.Direction == CursorPrev
+}
+
+// cursorPayload forma serializada del cursor (claves cortas: el token viaja en la URL)
+type cursorPayload struct {
+	Direction CursorDirection `json:"d"`
+	Field     string          `json:"f"`
+	OrderType OrderType       `json:"o"`
+	Values    []string        `json:"v"`
+}
+
+var (
+	cursorSecretMu sync.RWMutex
+	cursorSecret   = randomCursorSecret()
+)
+
+// SetCursorSecret fija la clave con la que se firman los cursores
+// Debe ser la misma en todas las réplicas; sin configurarla cada proceso usa una
+// clave aleatoria y los cursores no sobreviven a un reinicio
+func SetCursorSecret(secret []byte) {
+	if len(secret) == 0 {
+		return
+	}
+	cursorSecretMu.Lock()
+	defer cursorSecretMu.Unlock()
+	cursorSecret = append([]byte{}, secret...)
+}
+
+func randomCursorSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("criteria: cannot generate cursor secret: %v", err))
+	}
+	return secret
+}
+
+func signCursor(payload []byte) []byte {
+	cursorSecretMu.RLock()
+	defer cursorSecretMu.RUnlock()
+	mac := hmac.New(sha256.New, cursorSecret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// EncodeCursor serializa y firma el cursor: base64url(json) "." base64url(hmac-sha256)
+func EncodeCursor(c Cursor) string {
+	payload, _ := json.Marshal(cursorPayload{
+		Direction: c.Direction,
+		Field:     c.Order.Field,
+		OrderType: c.Order.OrderType,
+		Values:    c.Values,
+	})
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(signCursor(payload))
+}
+
+// DecodeCursor verifica la firma y reconstruye el cursor
+// Cualquier falla (formato, firma, dirección) es ErrInvalidCursor
+func DecodeCursor(token string) (*Cursor, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, signCursor(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var p cursorPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if p.Direction != CursorNext && p.Direction != CursorPrev {
+		return nil, ErrInvalidCursor
+	}
+	if p.Field == "" || len(p.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{
+		Direction: p.Direction,
+		Order:     NewOrder(p.Field, p.OrderType),
+		Values:    p.Values,
+	}, nil
+}
+
+// CursorValue representación estable de una clave de orden dentro del cursor
+// Los tiempos van en UTC con nanosegundos; el resto por su String() (uuid, decimal)
+func CursorValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339Nano)
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,259 @@
+package criteria
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidFilterExpression el parámetro filter no respeta la sintaxis o usa un campo no permitido
+var ErrInvalidFilterExpression = errors.New("invalid filter expression")
+
+// Límites de una expresión de filtro (evitan consultas desmedidas desde el query string)
+const (
+	maxFilterDepth      = 5
+	maxFilterConditions = 25
+)
+
+// filterOperators operadores de la sintaxis de query string → operadores del criteria
+var filterOperators = map[string]string{
+	"eq":       OpEqual,
+	"ne":       OpNotEqual,
+	"gt":       OpGreaterThan,
+	"gte":      OpGreaterThanOrEqual,
+	"lt":       OpLessThan,
+	"lte":      OpLessThanOrEqual,
+	"like":     OpLike,
+	"in":       OpIn,
+	"null":     OpIsNull,
+	"notnull":  OpIsNotNull,
+	"contains": OpArrayContains,
+}
+
+// ParseFilterExpression convierte el parámetro filter en un grupo AND
+//
+//	filter=or(status:eq:CONFIRMED,and(total_amount:gte:1000,not(sku:contains:ABC-1)))
+//
+// Condición: campo:operador:valor (null / notnull sin valor). Operadores: eq, ne, gt,
+// gte, lt, lte, like, in (valores separados por |), null, notnull, contains.
+// Grupos: and(...), or(...), not(...) con una sola condición o grupo.
+// Un valor con , ( ) | o comillas va entre comillas simples; dentro de ellas una
+// comilla se escribe dos veces.
+// Solo se aceptan los campos de allowedFields
+func ParseFilterExpression(expr string, allowedFields []string) (FilterGroup, error) {
+	p := &filterParser{input: expr, allowed: make(map[string]bool, len(allowedFields))}
+	for _, field := range allowedFields {
+		p.allowed[field] = true
+	}
+
+	node, err := p.parseNode(1)
+	if err != nil {
+		return FilterGroup{}, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return FilterGroup{}, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	if node.group != nil && node.group.Operator == LogicalAnd {
+		return *node.group, nil
+	}
+	root := NewFilterGroup(LogicalAnd)
+	node.addTo(&root)
+	return root, nil
+}
+
+// filterNode condición o grupo de la expresión
+type filterNode struct {
+	filter *Filter
+	group  *FilterGroup
+}
+
+func (n filterNode) addTo(group *FilterGroup) {
+	if n.filter != nil {
+		group.Add(*n.filter)
+	} else {
+		group.AddGroup(*n.group)
+	}
+}
+
+// filterParser descenso recursivo sobre la expresión
+type filterParser struct {
+	input      string
+	pos        int
+	allowed    map[string]bool
+	conditions int
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidFilterExpression, fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *filterParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *filterParser) peek() byte {
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+// parseName lee un nombre de campo, operador o grupo: letras, dígitos, _ y .
+func (p *filterParser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.') {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *filterParser) parseNode(depth int) (filterNode, error) {
+	if depth > maxFilterDepth {
+		return filterNode{}, p.errorf("nesting deeper than %d levels", maxFilterDepth)
+	}
+
+	p.skipSpaces()
+	name := p.parseName()
+	if name == "" {
+		return filterNode{}, p.errorf("expected field or group")
+	}
+
+	if p.peek() == '(' {
+		operator := LogicalOperator(strings.ToUpper(name))
+		if operator != LogicalAnd && operator != LogicalOr && operator != LogicalNot {
+			return filterNode{}, p.errorf("unknown group %q (use and, or, not)", name)
+		}
+		group, err := p.parseGroup(operator, depth)
+		if err != nil {
+			return filterNode{}, err
+		}
+		return filterNode{group: &group}, nil
+	}
+
+	filter, err := p.parseCondition(name)
+	if err != nil {
+		return filterNode{}, err
+	}
+	return filterNode{filter: &filter}, nil
+}
+
+func (p *filterParser) parseGroup(operator LogicalOperator, depth int) (FilterGroup, error) {
+	p.pos++ // (
+	group := NewFilterGroup(operator)
+	children := 0
+	for {
+		node, err := p.parseNode(depth + 1)
+		if err != nil {
+			return FilterGroup{}, err
+		}
+		node.addTo(&group)
+		children++
+
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+			continue
+		case ')':
+			p.pos++
+			if operator == LogicalNot && children != 1 {
+				return FilterGroup{}, p.errorf("not() takes exactly one condition or group")
+			}
+			return group, nil
+		default:
+			return FilterGroup{}, p.errorf("expected , or )")
+		}
+	}
+}
+
+func (p *filterParser) parseCondition(field string) (Filter, error) {
+	if !p.allowed[field] {
+		return Filter{}, p.errorf("unknown filter field %q", field)
+	}
+	p.conditions++
+	if p.conditions > maxFilterConditions {
+		return Filter{}, p.errorf("more than %d conditions", maxFilterConditions)
+	}
+
+	if p.peek() != ':' {
+		return Filter{}, p.errorf("expected :operator after %q", field)
+	}
+	p.pos++
+	opName := strings.ToLower(p.parseName())
+	operator, ok := filterOperators[opName]
+	if !ok {
+		return Filter{}, p.errorf("unknown operator %q", opName)
+	}
+
+	if operator == OpIsNull || operator == OpIsNotNull {
+		return NewFilter(field, operator, nil), nil
+	}
+
+	if p.peek() != ':' {
+		return Filter{}, p.errorf("expected :value after %s", opName)
+	}
+	p.pos++
+
+	if operator == OpIn {
+		var values []string
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return Filter{}, err
+			}
+			values = append(values, value)
+			if p.peek() != '|' {
+				break
+			}
+			p.pos++
+		}
+		return NewFilter(field, operator, values), nil
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return Filter{}, err
+	}
+	return NewFilter(field, operator, value), nil
+}
+
+// parseValue lee un valor sin comillas (hasta , ) |) o entre comillas simples
+func (p *filterParser) parseValue() (string, error) {
+	if p.peek() == '\'' {
+		p.pos++
+		var b strings.Builder
+		for p.pos < len(p.input) {
+			c := p.input[p.pos]
+			p.pos++
+			if c != '\'' {
+				b.WriteByte(c)
+				continue
+			}
+			if p.peek() == '\'' {
+				b.WriteByte('\'')
+				p.pos++
+				continue
+			}
+			return b.String(), nil
+		}
+		return "", p.errorf("unterminated quoted value")
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && !strings.ContainsRune(",)|('", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	value := strings.TrimSpace(p.input[start:p.pos])
+	if value == "" {
+		return "", p.errorf("empty value")
+	}
+	return value, nil
+}
@@ -2,6 +2,7 @@ package criteria
 
 import (
 	"context"
+	"fmt"
 )
 
 // CriteriaRepository define una interfaz genérica para repositorios que soportan criteria
@@ -13,6 +14,17 @@ type CriteriaRepository[T any] interface {
 	CountByCriteria(ctx context.Context, criteria Criteria) (int, error)
 }
 
+// CursorRepository lo implementan los repositorios que aceptan paginación por cursor
+// SearchByCriteria con criteria.Cursor debe devolver hasta Limit+1 filas en el orden
+// de la consulta (ver SQLCriteriaConverter.ToCursorSelectSQL)
+type CursorRepository[T any] interface {
+	CriteriaRepository[T]
+
+	// CursorKeys retorna las claves de orden de una fila: el valor del campo de
+	// orden y el desempate, en el mismo orden que el seek del repositorio
+	CursorKeys(item *T, order Order) []interface{}
+}
+
 // ListRepository define una interfaz para operaciones de listado con criteria
 type ListRepository[T any] interface {
 	CriteriaRepository[T]
@@ -35,6 +47,15 @@ func NewBaseListRepository[T any](criteriaRepo CriteriaRepository[T]) *BaseListR
 
 // ListByCriteria implementa la lógica común para listado con criteria
 func (r *BaseListRepository[T]) ListByCriteria(ctx context.Context, criteria Criteria) (*ListResponse[T], error) {
+	// Paginación por cursor: solo si el repositorio la soporta
+	var cursorRepo CursorRepository[T]
+	if criteria.Cursor != nil {
+		var ok bool
+		if cursorRepo, ok = r.criteriaRepo.(CursorRepository[T]); !ok {
+			return nil, fmt.Errorf("criteria: repository does not support cursor pagination")
+		}
+	}
+
 	// Obtener elementos
 	items, err := r.criteriaRepo.SearchByCriteria(ctx, criteria)
 	if err != nil {
@@ -47,6 +68,12 @@ func (r *BaseListRepository[T]) ListByCriteria(ctx context.Context, criteria Cri
 		return nil, err
 	}
 
+	if cursorRepo != nil {
+		return NewCursorListResponse(items, total, criteria, func(item *T) []interface{} {
+			return cursorRepo.CursorKeys(item, criteria.Order)
+		}), nil
+	}
+
 	// Crear respuesta
 	return NewListResponse(items, total, criteria), nil
 }
@@ -73,10 +73,13 @@ func (r BaseListRequest) ToCriteria() Criteria {
 // CriteriaBuilder facilita la construcción de criterios usando el patrón builder
 type CriteriaBuilder struct {
 	filters    []Filter
+	groups     []FilterGroup
 	orderField string
 	orderDir   OrderType
 	page       int
 	pageSize   int
+	cursor     *Cursor
+	cursorMode bool
 }
 
 // NewCriteriaBuilder crea un nuevo builder
@@ -224,12 +227,68 @@ func (b *CriteriaBuilder) SetPagination(page, pageSize int) *CriteriaBuilder {
 	return b
 }
 
+// UseCursor pagina por cursor en lugar de page / offset (primera página si no hay SetCursor)
+func (b *CriteriaBuilder) UseCursor() *CriteriaBuilder {
+	b.cursorMode = true
+	return b
+}
+
+// SetCursor continúa desde un next_cursor / prev_cursor de una respuesta anterior
+// Llamar después de fijar el orden: un cursor emitido con otro sort_by / sort_dir
+// (o adulterado) es ErrInvalidCursor. Token vacío = primera página
+func (b *CriteriaBuilder) SetCursor(token string) error {
+	b.cursorMode = true
+	if token == "" {
+		b.cursor = nil
+		return nil
+	}
+
+	cursor, err := DecodeCursor(token)
+	if err != nil {
+		return err
+	}
+	if cursor.Order != NewOrder(b.orderField, b.orderDir) {
+		return ErrInvalidCursor
+	}
+	b.cursor = cursor
+	return nil
+}
+
+// AddFilterGroup agrega un grupo AND / OR / NOT (se combina con AND con el resto)
+func (b *CriteriaBuilder) AddFilterGroup(group FilterGroup) *CriteriaBuilder {
+	if !group.IsEmpty() {
+		b.groups = append(b.groups, group)
+	}
+	return b
+}
+
+// AddFilterExpression parsea una expresión filter (ver ParseFilterExpression) y la agrega
+func (b *CriteriaBuilder) AddFilterExpression(expr string, allowedFields []string) error {
+	group, err := ParseFilterExpression(expr, allowedFields)
+	if err != nil {
+		return err
+	}
+	b.AddFilterGroup(group)
+	return nil
+}
+
 // Build construye el criteria final
 func (b *CriteriaBuilder) Build() Criteria {
 	filters := NewFilters(b.filters...)
+	filters.Groups = append(filters.Groups, b.groups...)
 
 	order := NewOrder(b.orderField, b.orderDir)
 
+	// Paginación por cursor: solo limit, la posición la da el cursor
+	if b.cursorMode {
+		limit := b.pageSize
+		cursor := b.cursor
+		if cursor == nil {
+			cursor = NewFirstPageCursor(order)
+		}
+		return NewCriteria(filters, order, &limit, nil).WithCursor(cursor)
+	}
+
 	// Calcular limit y offset
 	limit := b.pageSize
 	offset := (b.page - 1) * b.pageSize
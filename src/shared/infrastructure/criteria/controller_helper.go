@@ -21,6 +21,23 @@ func (h *ControllerHelper) BuildCriteriaFromQuery(c *gin.Context) *domainCriteri
 	return domainCriteria.NewCriteriaBuilder().FromURLValues(c.Request.URL.Query())
 }
 
+// ApplyCursorFromQuery pagina por cursor con el query param cursor (vacío = primera página)
+// Para endpoints que optan por cursor; llamar después de fijar el orden del builder
+func (h *ControllerHelper) ApplyCursorFromQuery(c *gin.Context, builder *domainCriteria.CriteriaBuilder) error {
+	return builder.SetCursor(c.Query("cursor"))
+}
+
+// ApplyFilterExpressionsFromQuery agrega los query params filter (and / or / not)
+// Cada filter repetido se combina con AND; un campo fuera de allowedFields es un error
+func (h *ControllerHelper) ApplyFilterExpressionsFromQuery(c *gin.Context, builder *domainCriteria.CriteriaBuilder, allowedFields []string) error {
+	for _, expr := range c.QueryArray("filter") {
+		if err := builder.AddFilterExpression(expr, allowedFields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // BuildCriteriaFromURLValues construye criterios base desde url.Values
 func (h *ControllerHelper) BuildCriteriaFromURLValues(values url.Values) *domainCriteria.CriteriaBuilder {
 	return domainCriteria.NewCriteriaBuilder().FromURLValues(values)
@@ -46,13 +63,33 @@ func (h *ControllerHelper) ValidateAndSanitizeCriteria(criteria domainCriteria.C
 		}
 	}
 
+	// Un grupo se descarta entero si usa algún campo no permitido (quitar una
+	// condición de un OR / NOT cambiaría su significado)
+	for _, group := range criteria.Filters.Groups {
+		allowed := true
+		for _, field := range group.Fields() {
+			if !allowedMap[field] {
+				allowed = false
+				break
+			}
+		}
+		if allowed {
+			validFilters.AddGroup(group)
+		}
+	}
+
 	// Validar campo de ordenamiento
 	validOrder := criteria.Order
+	cursor := criteria.Cursor
 	if validOrder.Field != "" && !allowedMap[validOrder.Field] {
 		validOrder = domainCriteria.NewOrder("created_at", domainCriteria.DESC)
+		// El cursor se emitió con el orden descartado: se recorre desde el principio
+		if cursor != nil {
+			cursor = domainCriteria.NewFirstPageCursor(validOrder)
+		}
 	}
 
-	return domainCriteria.NewCriteria(validFilters, validOrder, criteria.Limit, criteria.Offset)
+	return domainCriteria.NewCriteria(validFilters, validOrder, criteria.Limit, criteria.Offset).WithCursor(cursor)
 }
 
 // BaseCriteriaBuilder interface que deben implementar los builders específicos de cada módulo
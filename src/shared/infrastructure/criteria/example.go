@@ -15,11 +15,19 @@ type ProductRepository struct {
 	converter *SQLCriteriaConverter
 }
 
+// productCriteriaFields campos de la API → expresiones SQL del repositorio de ejemplo
+var productCriteriaFields = FieldMap{
+	"name":       "name",
+	"price":      "price",
+	"created_at": "created_at",
+	"brand":      JSONBText("metadata", "brand", "name"),
+}
+
 // NewProductRepository crea un nuevo repositorio de productos
 func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{
 		db:        db,
-		converter: NewSQLCriteriaConverter(),
+		converter: NewMappedSQLCriteriaConverter(productCriteriaFields),
 	}
 }
 
@@ -28,8 +36,11 @@ func (r *ProductRepository) SearchByCriteria(criteria domainCriteria.Criteria) (
 	// Crear base query
 	baseQuery := "SELECT * FROM products"
 
-	// Convertir criteria a SQL
-	sqlClauses, params := r.converter.ToSQL(criteria)
+	// Convertir criteria a SQL (un campo fuera del FieldMap es ErrUnknownField)
+	sqlClauses, params, err := r.converter.ToSQL(criteria)
+	if err != nil {
+		return nil, err
+	}
 
 	// Construir la consulta completa
 	query := fmt.Sprintf("%s %s", baseQuery, sqlClauses)
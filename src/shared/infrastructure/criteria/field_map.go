@@ -0,0 +1,47 @@
+package criteria
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrUnknownField el criteria usa un campo que el repositorio no declaró (o que no es una columna)
+var ErrUnknownField = errors.New("unknown criteria field")
+
+// FieldMap traduce los campos de la API a expresiones SQL de un repositorio
+// Las expresiones son código del repositorio (nunca entrada del usuario): columnas,
+// rutas JSONB (JSONBText) o subconsultas ARRAY(...) para filtrar con contains.
+// Un campo que no está en el mapa no llega al SQL
+type FieldMap map[string]string
+
+// Resolve retorna la expresión SQL del campo
+func (m FieldMap) Resolve(field string) (string, error) {
+	expression, ok := m[field]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownField, field)
+	}
+	return expression, nil
+}
+
+// jsonbKeyPattern claves admitidas en una ruta JSONB
+var jsonbKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// JSONBText expresión de texto de una ruta dentro de una columna JSONB
+// JSONBText("i.product_snapshot", "category_id") = (i.product_snapshot #>> '{category_id}')
+// Se usa al declarar el FieldMap: una clave fuera de [A-Za-z0-9_] es un error de programación
+func JSONBText(column string, path ...string) string {
+	if len(path) == 0 {
+		panic("criteria: JSONBText requires a path")
+	}
+	for _, key := range path {
+		if !jsonbKeyPattern.MatchString(key) {
+			panic(fmt.Sprintf("criteria: invalid JSONB key %q", key))
+		}
+	}
+	return fmt.Sprintf("(%s #>> '{%s}')", column, strings.Join(path, ","))
+}
+
+// identifierPattern campo admitido sin FieldMap: columna o alias.columna
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
@@ -6,34 +6,52 @@ import (
 	"strings"
 
 	domainCriteria "sales/src/shared/domain/criteria"
+
+	"github.com/lib/pq"
 )
 
 // SQLCriteriaConverter convierte un objeto Criteria en una consulta SQL
-type SQLCriteriaConverter struct{}
+// Los campos de filtros y orden nunca se copian tal cual: con FieldMap se traducen
+// (y un campo no declarado es ErrUnknownField); sin FieldMap solo se aceptan
+// identificadores simples (columna o alias.columna). Los valores viajan como parámetros
+type SQLCriteriaConverter struct {
+	fields FieldMap
+}
 
-// NewSQLCriteriaConverter crea una nueva instancia del conversor
+// NewSQLCriteriaConverter crea una nueva instancia del conversor (campos = columnas)
 func NewSQLCriteriaConverter() *SQLCriteriaConverter {
 	return &SQLCriteriaConverter{}
 }
 
+// NewMappedSQLCriteriaConverter crea un conversor que traduce los campos de la API
+// con el FieldMap del repositorio y rechaza el resto
+func NewMappedSQLCriteriaConverter(fields FieldMap) *SQLCriteriaConverter {
+	return &SQLCriteriaConverter{fields: fields}
+}
+
 // ToSelectSQL convierte un criteria a una consulta SQL SELECT completa con sus parámetros
-func (s *SQLCriteriaConverter) ToSelectSQL(baseQuery string, criteria domainCriteria.Criteria) (string, []interface{}) {
+func (s *SQLCriteriaConverter) ToSelectSQL(baseQuery string, criteria domainCriteria.Criteria) (string, []interface{}, error) {
 	var parts []string
-	var params []interface{}
+	w := &whereBuilder{converter: s}
 
 	// Query base
 	parts = append(parts, baseQuery)
 
 	// Agregar WHERE clause si hay filtros
-	if !criteria.Filters.IsEmpty() {
-		whereClause, whereParams := s.buildWhereClause(criteria.Filters)
+	whereClause, err := w.where(criteria.Filters)
+	if err != nil {
+		return "", nil, err
+	}
+	if whereClause != "" {
 		parts = append(parts, whereClause)
-		params = append(params, whereParams...)
 	}
 
 	// Agregar ORDER BY clause si hay ordenamiento
 	if !criteria.Order.IsEmpty() {
-		orderClause := s.buildOrderClause(criteria.Order)
+		orderClause, err := s.buildOrderClause(criteria.Order)
+		if err != nil {
+			return "", nil, err
+		}
 		parts = append(parts, orderClause)
 	}
 
@@ -44,99 +62,136 @@ func (s *SQLCriteriaConverter) ToSelectSQL(baseQuery string, criteria domainCrit
 	}
 
 	query := strings.Join(parts, " ")
-	return query, params
+	return query, w.params, nil
 }
 
 // ToCountSQL convierte un criteria a una consulta SQL COUNT con sus parámetros
-func (s *SQLCriteriaConverter) ToCountSQL(baseCountQuery string, criteria domainCriteria.Criteria) (string, []interface{}) {
+func (s *SQLCriteriaConverter) ToCountSQL(baseCountQuery string, criteria domainCriteria.Criteria) (string, []interface{}, error) {
 	var parts []string
-	var params []interface{}
+	w := &whereBuilder{converter: s}
 
 	// Query base (generalmente "SELECT COUNT(*) FROM table")
 	parts = append(parts, baseCountQuery)
 
 	// Agregar WHERE clause si hay filtros
-	if !criteria.Filters.IsEmpty() {
-		whereClause, whereParams := s.buildWhereClause(criteria.Filters)
+	whereClause, err := w.where(criteria.Filters)
+	if err != nil {
+		return "", nil, err
+	}
+	if whereClause != "" {
 		parts = append(parts, whereClause)
-		params = append(params, whereParams...)
 	}
 
 	// No necesitamos ORDER BY ni LIMIT para COUNT
 
 	query := strings.Join(parts, " ")
-	return query, params
+	return query, w.params, nil
 }
 
-// ToSQL convierte un criteria a una consulta SQL con sus parámetros (mantener para compatibilidad)
-func (s *SQLCriteriaConverter) ToSQL(criteria domainCriteria.Criteria) (string, []interface{}) {
-	var conditions []string
-	var params []interface{}
+// ToCursorSelectSQL convierte un criteria paginado por cursor (keyset) en un SELECT
+// Las claves de orden son criteria.Order.Field y tieBreaker, una columna única que
+// desempata (ej. "s.id", código del repositorio); ninguna puede ser NULL. En lugar de
+// OFFSET agrega el seek (campo, desempate) > / < (valores del cursor), ordena por ambas
+// claves (invertido si el cursor es prev) y pide Limit+1 filas para saber si hay más
+func (s *SQLCriteriaConverter) ToCursorSelectSQL(baseQuery string, criteria domainCriteria.Criteria, tieBreaker string) (string, []interface{}, error) {
+	var parts []string
+	w := &whereBuilder{converter: s}
 
-	// Procesar los filtros
-	for _, filter := range criteria.Filters.Items {
-		condition, value := s.processFilter(filter)
-		conditions = append(conditions, condition)
-		if value != nil {
-			params = append(params, value)
+	// Query base
+	parts = append(parts, baseQuery)
+
+	columns := []string{tieBreaker}
+	if !criteria.Order.IsEmpty() {
+		column, err := s.resolveField(criteria.Order.Field)
+		if err != nil {
+			return "", nil, err
+		}
+		if column != tieBreaker {
+			columns = []string{column, tieBreaker}
 		}
 	}
 
-	// Construir la cláusula WHERE
-	var whereClause string
-	if len(conditions) > 0 {
-		whereClause = fmt.Sprintf("WHERE %s", strings.Join(conditions, " AND "))
+	// Sentido del recorrido: el cursor prev lee el orden pedido al revés
+	direction := normalizeOrderType(criteria.Order.OrderType)
+	cursor := criteria.Cursor
+	if cursor != nil && cursor.Backward() {
+		if direction == domainCriteria.ASC {
+			direction = domainCriteria.DESC
+		} else {
+			direction = domainCriteria.ASC
+		}
 	}
 
-	// Construir la cláusula ORDER BY
-	var orderByClause string
-	if !criteria.Order.IsEmpty() {
-		orderByClause = fmt.Sprintf("ORDER BY %s %s", criteria.Order.Field, string(criteria.Order.OrderType))
+	// WHERE filtros AND seek
+	var conditions []string
+	filterConditions, err := w.conditions(criteria.Filters)
+	if err != nil {
+		return "", nil, err
+	}
+	conditions = append(conditions, filterConditions...)
+	if cursor != nil && !cursor.IsFirstPage() {
+		if len(cursor.Values) != len(columns) {
+			return "", nil, domainCriteria.ErrInvalidCursor
+		}
+		operator := ">"
+		if direction == domainCriteria.DESC {
+			operator = "<"
+		}
+		placeholders := make([]string, 0, len(columns))
+		for _, value := range cursor.Values {
+			placeholders = append(placeholders, w.param(value))
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(columns, ", "), operator, strings.Join(placeholders, ", ")))
+	}
+	if len(conditions) > 0 {
+		parts = append(parts, "WHERE "+strings.Join(conditions, " AND "))
 	}
 
-	// Construir la cláusula LIMIT y OFFSET
-	var limitOffsetClause string
-	if criteria.Limit != nil && criteria.Offset != nil {
-		limitOffsetClause = fmt.Sprintf("LIMIT %d OFFSET %d", *criteria.Limit, *criteria.Offset)
+	// ORDER BY ambas claves en el sentido del recorrido
+	orderBy := make([]string, 0, len(columns))
+	for _, column := range columns {
+		orderBy = append(orderBy, fmt.Sprintf("%s %s", column, string(direction)))
 	}
+	parts = append(parts, "ORDER BY "+strings.Join(orderBy, ", "))
 
-	// Combinar las cláusulas
-	clauses := []string{whereClause, orderByClause, limitOffsetClause}
-	var filteredClauses []string
-	for _, clause := range clauses {
-		if clause != "" {
-			filteredClauses = append(filteredClauses, clause)
-		}
+	// Una fila de más indica que hay otra página
+	if criteria.Limit != nil {
+		parts = append(parts, fmt.Sprintf("LIMIT %d", *criteria.Limit+1))
 	}
 
-	return strings.Join(filteredClauses, " "), params
+	query := strings.Join(parts, " ")
+	return query, w.params, nil
 }
 
-// buildWhereClause construye la cláusula WHERE con sus parámetros
-func (s *SQLCriteriaConverter) buildWhereClause(filters domainCriteria.Filters) (string, []interface{}) {
-	var conditions []string
-	var params []interface{}
-
-	paramIndex := 1
-	for _, filter := range filters.Items {
-		condition, value := s.processFilterWithIndex(filter, paramIndex)
-		conditions = append(conditions, condition)
-		if value != nil {
-			params = append(params, value)
-			paramIndex++
-		}
+// ToSQL convierte un criteria a una consulta SQL con sus parámetros (mantener para compatibilidad)
+// Retorna solo las cláusulas WHERE / ORDER BY / LIMIT OFFSET para agregar a una query base
+func (s *SQLCriteriaConverter) ToSQL(criteria domainCriteria.Criteria) (string, []interface{}, error) {
+	query, params, err := s.ToSelectSQL("", criteria)
+	if err != nil {
+		return "", nil, err
 	}
+	return strings.TrimSpace(query), params, nil
+}
 
-	if len(conditions) > 0 {
-		return fmt.Sprintf("WHERE %s", strings.Join(conditions, " AND ")), params
+// resolveField traduce un campo del criteria a su expresión SQL
+func (s *SQLCriteriaConverter) resolveField(field string) (string, error) {
+	if s.fields != nil {
+		return s.fields.Resolve(field)
 	}
-
-	return "", params
+	if !identifierPattern.MatchString(field) {
+		return "", fmt.Errorf("%w: %q", ErrUnknownField, field)
+	}
+	return field, nil
 }
 
 // buildOrderClause construye la cláusula ORDER BY
-func (s *SQLCriteriaConverter) buildOrderClause(order domainCriteria.Order) string {
-	return fmt.Sprintf("ORDER BY %s %s", order.Field, string(order.OrderType))
+func (s *SQLCriteriaConverter) buildOrderClause(order domainCriteria.Order) (string, error) {
+	column, err := s.resolveField(order.Field)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ORDER BY %s %s", column, string(normalizeOrderType(order.OrderType))), nil
 }
 
 // buildLimitClause construye la cláusula LIMIT y OFFSET
@@ -144,72 +199,122 @@ func (s *SQLCriteriaConverter) buildLimitClause(limit, offset *int) string {
 	return fmt.Sprintf("LIMIT %d OFFSET %d", *limit, *offset)
 }
 
-// processFilterWithIndex convierte un filtro en una condición SQL con índice de parámetro
-func (s *SQLCriteriaConverter) processFilterWithIndex(filter domainCriteria.Filter, paramIndex int) (string, interface{}) {
-	var condition string
-	placeholder := "$" + strconv.Itoa(paramIndex)
+// normalizeOrderType solo asc o desc llegan al SQL (cualquier otro valor es desc)
+func normalizeOrderType(orderType domainCriteria.OrderType) domainCriteria.OrderType {
+	if strings.EqualFold(string(orderType), string(domainCriteria.ASC)) {
+		return domainCriteria.ASC
+	}
+	return domainCriteria.DESC
+}
+
+// whereBuilder arma las condiciones numerando los parámetros ($1..$n) en orden
+type whereBuilder struct {
+	converter *SQLCriteriaConverter
+	params    []interface{}
+}
+
+// param agrega un parámetro y retorna su placeholder
+func (w *whereBuilder) param(value interface{}) string {
+	w.params = append(w.params, value)
+	return "$" + strconv.Itoa(len(w.params))
+}
+
+// where construye la cláusula WHERE ("" sin filtros)
+func (w *whereBuilder) where(filters domainCriteria.Filters) (string, error) {
+	conditions, err := w.conditions(filters)
+	if err != nil || len(conditions) == 0 {
+		return "", err
+	}
+	return fmt.Sprintf("WHERE %s", strings.Join(conditions, " AND ")), nil
+}
+
+// conditions retorna las condiciones de los filtros y grupos de primer nivel (se unen con AND)
+func (w *whereBuilder) conditions(filters domainCriteria.Filters) ([]string, error) {
+	var conditions []string
+	for _, filter := range filters.Items {
+		condition, err := w.filter(filter)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+	for _, group := range filters.Groups {
+		condition, err := w.group(group)
+		if err != nil {
+			return nil, err
+		}
+		if condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+	return conditions, nil
+}
+
+// group convierte un grupo AND / OR / NOT en una condición entre paréntesis
+func (w *whereBuilder) group(group domainCriteria.FilterGroup) (string, error) {
+	var conditions []string
+	for _, filter := range group.Filters {
+		condition, err := w.filter(filter)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+	for _, nested := range group.Groups {
+		condition, err := w.group(nested)
+		if err != nil {
+			return "", err
+		}
+		if condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	if len(conditions) == 1 && group.Operator != domainCriteria.LogicalNot {
+		return conditions[0], nil
+	}
+
+	switch group.Operator {
+	case domainCriteria.LogicalOr:
+		return "(" + strings.Join(conditions, " OR ") + ")", nil
+	case domainCriteria.LogicalNot:
+		return "NOT (" + strings.Join(conditions, " AND ") + ")", nil
+	default:
+		return "(" + strings.Join(conditions, " AND ") + ")", nil
+	}
+}
+
+// filter convierte un filtro en una condición SQL con su parámetro
+func (w *whereBuilder) filter(filter domainCriteria.Filter) (string, error) {
+	column, err := w.converter.resolveField(filter.Field)
+	if err != nil {
+		return "", err
+	}
 
 	switch filter.Operator {
 	case domainCriteria.OpEqual, domainCriteria.OpNotEqual, domainCriteria.OpGreaterThan,
 		domainCriteria.OpGreaterThanOrEqual, domainCriteria.OpLessThan, domainCriteria.OpLessThanOrEqual:
-		condition = fmt.Sprintf("%s %s %s", filter.Field, filter.Operator, placeholder)
+		return fmt.Sprintf("%s %s %s", column, filter.Operator, w.param(filter.Value)), nil
 	case domainCriteria.OpLike:
-		condition = fmt.Sprintf("%s LIKE %s", filter.Field, placeholder)
 		// Asegurar que el valor sea compatible con LIKE
-		if str, ok := filter.Value.(string); ok {
-			if !strings.Contains(str, "%") {
-				filter.Value = "%" + str + "%"
-			}
+		value := filter.Value
+		if str, ok := value.(string); ok && !strings.Contains(str, "%") {
+			value = "%" + str + "%"
 		}
+		return fmt.Sprintf("%s LIKE %s", column, w.param(value)), nil
 	case domainCriteria.OpIn:
-		// Manejar arrays para cláusulas IN
-		condition = fmt.Sprintf("%s IN (%s)", filter.Field, placeholder)
+		// PostgreSQL: el slice viaja como un único array (IN ($n) no admite slices)
+		return fmt.Sprintf("%s = ANY(%s)", column, w.param(pq.Array(filter.Value))), nil
 	case domainCriteria.OpIsNull:
-		condition = fmt.Sprintf("%s IS NULL", filter.Field)
-		return condition, nil
+		return fmt.Sprintf("%s IS NULL", column), nil
 	case domainCriteria.OpIsNotNull:
-		condition = fmt.Sprintf("%s IS NOT NULL", filter.Field)
-		return condition, nil
+		return fmt.Sprintf("%s IS NOT NULL", column), nil
 	case domainCriteria.OpArrayContains:
 		// PostgreSQL: para verificar si un array contiene un valor específico
-		condition = fmt.Sprintf("%s @> ARRAY[%s]", filter.Field, placeholder)
+		return fmt.Sprintf("%s @> ARRAY[%s]", column, w.param(filter.Value)), nil
 	default:
-		condition = fmt.Sprintf("%s = %s", filter.Field, placeholder)
+		return fmt.Sprintf("%s = %s", column, w.param(filter.Value)), nil
 	}
-
-	return condition, filter.Value
-}
-
-// processFilter convierte un filtro en una condición SQL (mantener para compatibilidad)
-func (s *SQLCriteriaConverter) processFilter(filter domainCriteria.Filter) (string, interface{}) {
-	var condition string
-
-	switch filter.Operator {
-	case "=", "!=", ">", ">=", "<", "<=":
-		condition = fmt.Sprintf("%s %s $?", filter.Field, filter.Operator)
-	case "LIKE":
-		condition = fmt.Sprintf("%s LIKE $?", filter.Field)
-		// Asegurar que el valor sea compatible con LIKE
-		if str, ok := filter.Value.(string); ok {
-			if !strings.Contains(str, "%") {
-				filter.Value = "%" + str + "%"
-			}
-		}
-	case "IN":
-		// Manejar arrays para cláusulas IN
-		condition = fmt.Sprintf("%s IN ($?)", filter.Field)
-	case "NULL":
-		condition = fmt.Sprintf("%s IS NULL", filter.Field)
-		return condition, nil
-	case "NOT NULL":
-		condition = fmt.Sprintf("%s IS NOT NULL", filter.Field)
-		return condition, nil
-	case "ARRAY_CONTAINS":
-		// PostgreSQL: para verificar si un array contiene un valor específico
-		condition = fmt.Sprintf("%s @> ARRAY[$?]", filter.Field)
-	default:
-		condition = fmt.Sprintf("%s = $?", filter.Field)
-	}
-
-	return condition, filter.Value
 }
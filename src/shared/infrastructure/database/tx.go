@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DBTX operaciones comunes a *sql.DB y *sql.Tx
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// txKey clave privada para guardar la transacción en el contexto
+type txKey struct{}
+
+// TxManager abre transacciones y las propaga por contexto
+// Los repositorios que usan RunInTx se suman a la transacción del contexto
+// en lugar de abrir una propia (ej: numeración + insert en una misma tx)
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager crea una nueva instancia
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{
+		db: db,
+	}
+}
+
+// WithinTx ejecuta fn dentro de una transacción (commit si fn no falla, rollback si falla)
+// Si el contexto ya trae una transacción, fn se ejecuta dentro de ella
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	return RunInTx(ctx, m.db, fn)
+}
+
+// WithTx retorna un contexto que transporta la transacción
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext retorna la transacción del contexto, si existe
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok && tx != nil
+}
+
+// Executor retorna la transacción del contexto o, si no hay, la conexión
+// Permite que una operación de una sola sentencia se sume a una tx en curso
+func Executor(ctx context.Context, db *sql.DB) DBTX {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return db
+}
+
+// RunInTx ejecuta fn en la transacción del contexto o, si no hay, en una nueva
+// Solo quien abre la transacción hace commit / rollback
+func RunInTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	if tx, ok := TxFromContext(ctx); ok {
+		return fn(ctx, tx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(WithTx(ctx, tx), tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
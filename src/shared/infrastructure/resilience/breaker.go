@@ -0,0 +1,150 @@
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen la dependencia está marcada como caída y la llamada no se intentó
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitOpenError error tipado del circuit breaker abierto
+// errors.Is(err, ErrCircuitOpen) lo reconoce aunque venga envuelto
+type CircuitOpenError struct {
+	Dependency string
+	RetryAfter time.Duration // Tiempo hasta el próximo intento de prueba (half-open)
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: circuit breaker open, retry in %s", e.Dependency, e.RetryAfter.Round(time.Second))
+}
+
+// Is permite errors.Is(err, ErrCircuitOpen)
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// BreakerState estado del circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = 0 // Llamadas normales
+	BreakerHalfOpen BreakerState = 1 // Una llamada de prueba en curso
+	BreakerOpen     BreakerState = 2 // Falla rápido sin llamar
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half_open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker breaker por dependencia con fallas consecutivas
+// CLOSED → OPEN tras FailureThreshold fallas seguidas; pasado OpenTimeout deja pasar
+// una única llamada de prueba (HALF_OPEN): si responde vuelve a CLOSED, si falla a OPEN
+type CircuitBreaker struct {
+	name             string
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker crea un breaker cerrado para la dependencia
+func NewCircuitBreaker(name string, failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 1
+	}
+	b := &CircuitBreaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		state:            BreakerClosed,
+	}
+	breakerState.WithLabelValues(name).Set(float64(BreakerClosed))
+	return b
+}
+
+// State estado actual del breaker
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow indica si la llamada puede intentarse
+// Con el breaker abierto retorna *CircuitOpenError sin tocar la dependencia
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		elapsed := time.Since(b.openedAt)
+		if elapsed < b.openTimeout {
+			return &CircuitOpenError{Dependency: b.name, RetryAfter: b.openTimeout - elapsed}
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return nil
+	case BreakerHalfOpen:
+		if b.probing {
+			return &CircuitOpenError{Dependency: b.name, RetryAfter: time.Second}
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success registra una respuesta de la dependencia (cierra el breaker)
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	if b.state != BreakerClosed {
+		b.setState(BreakerClosed)
+	}
+}
+
+// Failure registra una falla técnica (red, timeout, 5xx)
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.failureThreshold {
+		b.openedAt = time.Now()
+		if b.state != BreakerOpen {
+			b.setState(BreakerOpen)
+		}
+	}
+}
+
+// Cancel libera la llamada de prueba sin resultado (el caller canceló el contexto)
+func (b *CircuitBreaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *CircuitBreaker) setState(state BreakerState) {
+	b.state = state
+	breakerState.WithLabelValues(b.name).Set(float64(state))
+	breakerTransitions.WithLabelValues(b.name, state.String()).Inc()
+}
@@ -0,0 +1,239 @@
+package resilience
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config parámetros de resiliencia de una dependencia
+type Config struct {
+	Name             string        // Nombre de la dependencia (label de métricas)
+	AttemptTimeout   time.Duration // Timeout de cada intento (además del deadline del contexto)
+	MaxAttempts      int           // Intentos totales de una llamada idempotente
+	BaseBackoff      time.Duration // Backoff del primer reintento (con jitter)
+	MaxBackoff       time.Duration // Tope del backoff
+	FailureThreshold int           // Fallas consecutivas que abren el breaker
+	OpenTimeout      time.Duration // Tiempo abierto antes de la llamada de prueba
+}
+
+// DefaultConfig valores por defecto para una dependencia interna vía Kong
+func DefaultConfig(name string) Config {
+	return Config{
+		Name:             name,
+		AttemptTimeout:   5 * time.Second,
+		MaxAttempts:      3,
+		BaseBackoff:      100 * time.Millisecond,
+		MaxBackoff:       time.Second,
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+// ConfigFromEnv DefaultConfig con overrides de entorno <prefix>_TIMEOUT, <prefix>_MAX_ATTEMPTS,
+// <prefix>_BREAKER_THRESHOLD y <prefix>_BREAKER_OPEN_TIMEOUT (ej: prefix STOCK_SERVICE)
+func ConfigFromEnv(name, prefix string) Config {
+	cfg := DefaultConfig(name)
+	if d, err := time.ParseDuration(os.Getenv(prefix + "_TIMEOUT")); err == nil && d > 0 {
+		cfg.AttemptTimeout = d
+	}
+	if n, err := strconv.Atoi(os.Getenv(prefix + "_MAX_ATTEMPTS")); err == nil && n > 0 {
+		cfg.MaxAttempts = n
+	}
+	if n, err := strconv.Atoi(os.Getenv(prefix + "_BREAKER_THRESHOLD")); err == nil && n > 0 {
+		cfg.FailureThreshold = n
+	}
+	if d, err := time.ParseDuration(os.Getenv(prefix + "_BREAKER_OPEN_TIMEOUT")); err == nil && d > 0 {
+		cfg.OpenTimeout = d
+	}
+	return cfg
+}
+
+// Request llamada HTTP a una dependencia
+type Request struct {
+	Operation  string // Label de métricas (ej: process_sale)
+	Method     string
+	URL        string
+	Header     http.Header
+	Body       []byte
+	Idempotent bool // Solo las llamadas idempotentes se reintentan
+}
+
+// Response respuesta ya leída (el body no queda abierto)
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Client cliente HTTP con timeouts, reintentos y circuit breaker por dependencia
+// HITO RESILIENCE - Una dependencia lenta o caída no bloquea cada request hasta su
+// timeout: tras FailureThreshold fallas el breaker responde al instante con
+// *CircuitOpenError. Las respuestas 4xx son respuestas válidas (la dependencia está sana)
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+	breaker    *CircuitBreaker
+}
+
+// NewClient crea el cliente y su breaker
+func NewClient(cfg Config) *Client {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{},
+		breaker:    NewCircuitBreaker(cfg.Name, cfg.FailureThreshold, cfg.OpenTimeout),
+	}
+}
+
+// Breaker circuit breaker de la dependencia
+func (c *Client) Breaker() *CircuitBreaker {
+	return c.breaker
+}
+
+// errInvalidRequest el request no se pudo construir (no es una falla de la dependencia)
+var errInvalidRequest = errors.New("invalid request")
+
+// Do ejecuta la llamada respetando el deadline de ctx
+// Retorna la respuesta para cualquier status HTTP; error solo si no hubo respuesta
+// (red, timeout, breaker abierto, contexto cancelado). Las llamadas idempotentes se
+// reintentan con backoff exponencial y jitter ante errores de red, timeouts y 502/503/504
+func (c *Client) Do(ctx context.Context, req Request) (*Response, error) {
+	attempts := 1
+	if req.Idempotent {
+		attempts = c.cfg.MaxAttempts
+	}
+
+	var lastResp *Response
+	var lastErr error
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			if !c.waitBackoff(ctx, attempt-1) {
+				break
+			}
+			requestRetries.WithLabelValues(c.cfg.Name, req.Operation).Inc()
+		}
+
+		if err := c.breaker.Allow(); err != nil {
+			breakerRejections.WithLabelValues(c.cfg.Name, req.Operation).Inc()
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, err := c.send(ctx, req)
+		outcome := classify(ctx, resp, err)
+		if !errors.Is(err, errInvalidRequest) {
+			requestDuration.WithLabelValues(c.cfg.Name, req.Operation, outcome).Observe(time.Since(start).Seconds())
+		}
+
+		switch outcome {
+		case "success", "client_error":
+			c.breaker.Success()
+			return resp, nil
+		case "canceled":
+			// El caller abandonó la llamada: no es evidencia de que la dependencia esté caída
+			c.breaker.Cancel()
+			return nil, err
+		}
+
+		c.breaker.Failure()
+		lastResp, lastErr = resp, err
+		if resp != nil && !retryableStatus(resp.StatusCode) {
+			break
+		}
+	}
+
+	if lastResp != nil {
+		return lastResp, nil
+	}
+	return nil, lastErr
+}
+
+// send un intento con su propio timeout; el body se lee antes de liberar el contexto
+func (c *Client) send(ctx context.Context, req Request) (*Response, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, c.cfg.AttemptTimeout)
+	defer cancel()
+
+	var body io.Reader
+	if req.Body != nil {
+		body = bytes.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(attemptCtx, req.Method, req.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidRequest, err)
+	}
+	for key, values := range req.Header {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	return &Response{StatusCode: resp.StatusCode, Body: respBody}, nil
+}
+
+// waitBackoff espera el backoff del reintento n (full jitter)
+// Retorna false si el contexto termina antes o no alcanza el deadline para reintentar
+func (c *Client) waitBackoff(ctx context.Context, retry int) bool {
+	backoff := c.cfg.BaseBackoff << (retry - 1)
+	if backoff > c.cfg.MaxBackoff || backoff <= 0 {
+		backoff = c.cfg.MaxBackoff
+	}
+	wait := time.Duration(rand.Int63n(int64(backoff) + 1))
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		return false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// classify resultado de un intento para métricas y breaker
+func classify(ctx context.Context, resp *Response, err error) string {
+	switch {
+	case err == nil && resp.StatusCode >= http.StatusInternalServerError:
+		return "server_error"
+	case err == nil && resp.StatusCode >= http.StatusBadRequest:
+		return "client_error"
+	case err == nil:
+		return "success"
+	case errors.Is(err, errInvalidRequest), ctx.Err() != nil:
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "network_error"
+	}
+}
+
+// retryableStatus status que indican una falla transitoria del gateway / servicio
+func retryableStatus(status int) bool {
+	return status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout
+}
@@ -0,0 +1,35 @@
+package resilience
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Métricas de llamadas a dependencias (registro por defecto, expuesto en /metrics)
+var (
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_client_request_duration_seconds",
+		Help:    "Latency of outgoing HTTP calls per dependency, operation and outcome (one observation per attempt).",
+		Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"dependency", "operation", "outcome"})
+
+	requestRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_client_retries_total",
+		Help: "Retries of idempotent outgoing HTTP calls.",
+	}, []string{"dependency", "operation"})
+
+	breakerRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_client_circuit_breaker_rejections_total",
+		Help: "Calls rejected without contacting the dependency because its circuit breaker was open.",
+	}, []string{"dependency", "operation"})
+
+	breakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_client_circuit_breaker_state",
+		Help: "Circuit breaker state per dependency (0=closed, 1=half_open, 2=open).",
+	}, []string{"dependency"})
+
+	breakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_client_circuit_breaker_transitions_total",
+		Help: "Circuit breaker state transitions per dependency and target state.",
+	}, []string{"dependency", "state"})
+)